@@ -330,6 +330,7 @@ function EditLayoutDialog({ layout, onClose, onSuccess }: EditLayoutDialogProps)
         name: name.trim(),
         description: description.trim() || undefined,
         cameras: fullLayout.cameras || [],
+        version: fullLayout.version,
       };
 
       await api.updateLayout(layout.id, updateRequest);
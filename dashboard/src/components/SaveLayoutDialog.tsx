@@ -65,7 +65,6 @@ export function SaveLayoutDialog({
         layout_type: layoutType,
         grid_layout: gridLayout,
         scope,
-        created_by: 'current-user', // TODO: Get from auth context
         cameras: cameraAssignments,
       });
 
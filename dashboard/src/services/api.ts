@@ -38,6 +38,7 @@ class APIClient {
     endpoint: string,
     options?: RequestInit
   ): Promise<T> {
+    // No identity header: the API gateway sets X-User-ID from the authenticated session
     const response = await fetch(`${this.baseURL}${endpoint}`, {
       ...options,
       headers: {
@@ -277,9 +277,22 @@ export interface LayoutPreference {
   scope: LayoutScope;
   created_by: string;
   is_active: boolean;
+  version: number;
+  permission?: LayoutPermission; // The current user's access
   created_at: string;
   updated_at: string;
   cameras?: LayoutCameraAssignment[];
+  shares?: LayoutShare[]; // Only returned to the owner
+}
+
+export type LayoutPermission = 'view' | 'edit' | 'owner';
+
+export interface LayoutShare {
+  principal_type: 'user' | 'group';
+  principal_id: string;
+  permission: 'view' | 'edit';
+  granted_by?: string;
+  created_at?: string;
 }
 
 export interface LayoutPreferenceSummary {
@@ -291,6 +304,8 @@ export interface LayoutPreferenceSummary {
   scope: LayoutScope;
   created_by: string;
   camera_count: number;
+  version: number;
+  permission: LayoutPermission;
   created_at: string;
   updated_at: string;
 }
@@ -301,7 +316,6 @@ export interface CreateLayoutRequest {
   layout_type: LayoutType;
   grid_layout: string; // "2x2", "3x3", "9-way-1-hotspot", etc.
   scope: LayoutScope;
-  created_by: string;
   cameras: LayoutCameraAssignment[];
 }
 
@@ -309,6 +323,7 @@ export interface UpdateLayoutRequest {
   name: string;
   description?: string;
   cameras: LayoutCameraAssignment[];
+  version: number; // Version being edited; rejected with 409 if the layout changed since
 }
 
 export interface LayoutListResponse {
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,8 +17,10 @@ import (
 	"github.com/rta/cctv/go-api/internal/client"
 	deliveryHttp "github.com/rta/cctv/go-api/internal/delivery/http"
 	deliveryWS "github.com/rta/cctv/go-api/internal/delivery/websocket"
+	"github.com/rta/cctv/go-api/internal/domain"
 	"github.com/rta/cctv/go-api/internal/repository/postgres"
 	"github.com/rta/cctv/go-api/internal/repository/valkey"
+	"github.com/rta/cctv/go-api/internal/secrets"
 	"github.com/rta/cctv/go-api/internal/usecase"
 	"github.com/rs/zerolog"
 )
@@ -28,6 +32,26 @@ func main() {
 	// Load configuration
 	config := loadConfig()
 
+	// Camera credentials are envelope-encrypted with the master key
+	credentialsKey, err := secrets.LoadKey(config.CredentialsKey, config.CredentialsKeyFile)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load credentials key (set CREDENTIALS_KEY or CREDENTIALS_KEY_FILE)")
+	}
+	envelope, err := secrets.NewEnvelope(config.CredentialsKeyID, credentialsKey)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to initialize credentials encryption")
+	}
+
+	// Guest share link tokens and PINs are signed with their own key
+	shareLinkKey, err := secrets.LoadKey(config.ShareLinkKey, config.ShareLinkKeyFile)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load share link key (set SHARE_LINK_KEY or SHARE_LINK_KEY_FILE)")
+	}
+	shareLinkSigner, err := secrets.NewSigner("share-link", shareLinkKey)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to initialize share link signing")
+	}
+
 	// Connect to Valkey
 	valkeyClient := redis.NewClient(&redis.Options{
 		Addr:     config.ValkeyAddr,
@@ -55,22 +79,76 @@ func main() {
 	// Initialize repositories
 	streamRepo := valkey.NewStreamRepository(valkeyClient, logger)
 	layoutRepo := postgres.NewLayoutRepository(db, logger)
+	cameraGroupRepo := postgres.NewCameraGroupRepository(db, logger)
+	tourRepo := postgres.NewTourRepository(db, logger)
+	videoWallRepo := postgres.NewVideoWallRepository(db, logger)
+	shareLinkRepo := postgres.NewShareLinkRepository(db, logger)
+	ptzLockRepo := valkey.NewPTZLockRepository(valkeyClient, logger)
+	ptzHistoryRepo := postgres.NewPTZHistoryRepository(db, logger)
 	cameraRepo := postgres.NewCameraRepository(db)
+	auditRepo := postgres.NewAuditRepository(db)
+	sessionRepo := postgres.NewStreamSessionRepository(db)
+	vmsCameraCache := valkey.NewVMSCameraCache(valkeyClient, config.VMSCacheNamespace, logger)
+
+	// Move credentials left in plain text by older versions into encrypted storage
+	vault := usecase.NewCredentialVault(cameraRepo, envelope, vmsCameraCache, logger)
+	if _, err := vault.SealStoredCredentials(ctx); err != nil {
+		logger.Error().Err(err).Msg("Failed to seal stored camera credentials")
+	}
+
+	// Downstream dependencies, each with its own timeout, retry budget and circuit breaker
+	vmsDep := client.NewDependency(client.Policy{
+		Name:             "vms-service",
+		Timeout:          30 * time.Second, // PTZ on slow cameras (TrueView ~16s)
+		MaxRetries:       2,
+		MaxBackoff:       time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}, logger)
+	streamCounterDep := client.NewDependency(client.Policy{
+		Name:             "stream-counter",
+		Timeout:          5 * time.Second,
+		MaxRetries:       2,
+		MaxBackoff:       500 * time.Millisecond,
+		FailureThreshold: 5,
+		OpenTimeout:      15 * time.Second,
+	}, logger)
+	mediaMTXDep := client.NewDependency(client.Policy{
+		Name:             "mediamtx",
+		Timeout:          5 * time.Second,
+		MaxRetries:       2,
+		MaxBackoff:       500 * time.Millisecond,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}, logger)
+	livekitDep := client.NewDependency(client.Policy{
+		Name:             "livekit",
+		Timeout:          10 * time.Second,
+		MaxRetries:       1,
+		MaxBackoff:       time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}, logger)
 
 	// Initialize clients
-	streamCounterClient := client.NewStreamCounterClient(config.StreamCounterURL, logger)
-	vmsClient := client.NewVMSClient(config.VMSServiceURL, logger)
+	streamCounterClient := client.NewStreamCounterClient(config.StreamCounterURL, streamCounterDep, logger)
+	vmsClient := client.NewVMSClient(config.VMSServiceURL, vmsDep, logger)
 	livekitClient := client.NewLiveKitClient(
 		config.LiveKitURL,
 		config.LiveKitAPIKey,
 		config.LiveKitAPISecret,
+		livekitDep,
 		logger,
 	)
-	mediaMTXClient := client.NewMediaMTXClient(config.MediaMTXURL, logger)
+	mediaMTXClient := client.NewMediaMTXClient(config.MediaMTXURL, mediaMTXDep, logger)
+	onvifClient := client.NewOnvifClient(logger)
+	ffmpegClient := client.NewFFmpegClient(config.FFmpegPath, logger)
+	// Ingress API lives on the same LiveKit server, so it shares the breaker
 	livekitIngressClient := client.NewLiveKitIngressClient(
 		config.LiveKitURL,
 		config.LiveKitAPIKey,
 		config.LiveKitAPISecret,
+		livekitDep,
 		logger,
 	)
 
@@ -90,24 +168,85 @@ func main() {
 		livekitIngressClient,
 		dockerClient,
 		streamRepo,
+		auditRepo,
+		sessionRepo,
+		vault,
 		config.LiveKitWSURL,
+		splitList(config.ViewerEvictGroups),
 		logger,
 	)
-	layoutUseCase := usecase.NewLayoutUseCase(layoutRepo, logger)
-	cameraUseCase := usecase.NewCameraUsecase(cameraRepo, logger)
+	layoutUseCase := usecase.NewLayoutUseCase(layoutRepo, cameraGroupRepo, logger)
+	cameraGroupUseCase := usecase.NewCameraGroupUseCase(cameraGroupRepo, logger)
+	tourUseCase := usecase.NewTourUseCase(tourRepo, logger)
+	cameraUseCase := usecase.NewCameraUsecase(cameraRepo, client.NewHostProber(2*time.Second), vault, vmsCameraCache, logger)
+	snapshotUseCase := usecase.NewSnapshotUseCase(
+		cameraRepo,
+		vault,
+		onvifClient,
+		ffmpegClient,
+		valkey.NewSnapshotCache(valkeyClient, logger),
+		time.Duration(config.SnapshotCacheTTL)*time.Second,
+		logger,
+	)
+
+	// Close viewing sessions whose reservations expired without a release
+	go streamUseCase.RunSessionReaper(ctx, time.Minute)
+
+	// Guest share links reserve streams under the GUEST quota; the reaper disconnects
+	// guests still watching once their link has expired or was revoked
+	shareLinkUseCase := usecase.NewShareLinkUseCase(shareLinkRepo, streamUseCase, auditRepo, shareLinkSigner, config.GuestViewURL, logger)
+	go shareLinkUseCase.RunReaper(ctx, 30*time.Second)
 
 	// Initialize WebSocket hub
 	wsHub := deliveryWS.NewHub(streamUseCase, logger)
 	go wsHub.Run(ctx)
 
+	// Video walls push commands to display clients through the hub
+	videoWallUseCase := usecase.NewVideoWallUseCase(videoWallRepo, layoutUseCase, wsHub, logger)
+	wsHub.OnMonitorConnect(func(monitorID string) {
+		videoWallUseCase.ResendCommand(ctx, monitorID)
+	})
+
+	// PTZ commands go through per-camera locks and are recorded in the PTZ history; lock
+	// changes are broadcast over the hub. Tours recall presets only on cameras no operator is driving.
+	ptzRolePriorities, err := parseRolePriorities(config.PTZRolePriorities)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid PTZ_ROLE_PRIORITIES")
+	}
+	ptzUseCase := usecase.NewPTZUseCase(ptzLockRepo, ptzHistoryRepo, vmsClient, vmsClient, vmsClient, wsHub, ptzRolePriorities, time.Duration(config.PTZLockLease)*time.Second, logger)
+	tourEngine := usecase.NewTourEngine(tourRepo, streamUseCase, ptzUseCase.Unattended(domain.PTZSourceTour), logger)
+
+	// Return cameras with a home policy to their home position once idle
+	go ptzUseCase.RunHomeReturn(ctx, time.Minute)
+
+	// Readiness checks; only Postgres and Valkey are critical, so an outage of a shared
+	// downstream service degrades every instance instead of taking them all out of rotation
+	healthUseCase := usecase.NewHealthUseCase([]usecase.HealthCheck{
+		{Name: "postgres", Critical: true, Ping: db.PingContext},
+		{Name: "valkey", Critical: true, Ping: func(ctx context.Context) error {
+			return valkeyClient.Ping(ctx).Err()
+		}},
+		{Name: "vms-service", Ping: vmsClient.Ping, Breaker: vmsDep},
+		{Name: "stream-counter", Ping: streamCounterClient.Ping, Breaker: streamCounterDep},
+		{Name: "mediamtx", Ping: mediaMTXClient.Ping, Breaker: mediaMTXDep},
+		{Name: "livekit", Ping: livekitClient.Ping, Breaker: livekitDep},
+		{Name: "docker", Ping: dockerClient.Ping},
+	}, time.Duration(config.HealthCheckTimeout)*time.Second, logger)
+
 	// Initialize HTTP handlers
 	streamHandler := deliveryHttp.NewStreamHandler(streamUseCase, logger)
-	cameraHandler := deliveryHttp.NewCameraHandler(vmsClient, cameraUseCase, logger)
+	cameraHandler := deliveryHttp.NewCameraHandler(vmsClient, cameraUseCase, snapshotUseCase, logger)
+	ptzHandler := deliveryHttp.NewPTZHandler(ptzUseCase, logger)
 	wsHandler := deliveryWS.NewHandler(wsHub, logger)
 	layoutHandler := deliveryHttp.NewLayoutHandler(layoutUseCase, logger)
+	cameraGroupHandler := deliveryHttp.NewCameraGroupHandler(cameraGroupUseCase, logger)
+	tourHandler := deliveryHttp.NewTourHandler(tourUseCase, tourEngine, logger)
+	videoWallHandler := deliveryHttp.NewVideoWallHandler(videoWallUseCase, logger)
+	shareLinkHandler := deliveryHttp.NewShareLinkHandler(shareLinkUseCase, logger)
+	healthHandler := deliveryHttp.NewHealthHandler(healthUseCase, vmsDep, streamCounterDep, mediaMTXDep, livekitDep)
 
 	// Setup router
-	router := deliveryHttp.NewRouter(streamHandler, cameraHandler, wsHandler, layoutHandler)
+	router := deliveryHttp.NewRouter(healthHandler, streamHandler, cameraHandler, ptzHandler, wsHandler, layoutHandler, cameraGroupHandler, tourHandler, videoWallHandler, shareLinkHandler)
 
 	// Start HTTP server
 	srv := &http.Server{
@@ -141,6 +280,9 @@ func main() {
 		logger.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
 
+	// Release the stream reservations held by playing tours
+	tourEngine.StopAll(shutdownCtx)
+
 	logger.Info().Msg("Server exited")
 }
 
@@ -161,6 +303,19 @@ type Config struct {
 	LiveKitWSURL       string // External LiveKit WebSocket URL for clients
 	LiveKitAPIKey      string
 	LiveKitAPISecret   string
+	FFmpegPath         string // ffmpeg binary used for RTSP snapshot fallback
+	SnapshotCacheTTL   int    // Seconds a camera snapshot is served from cache
+	CredentialsKey     string // Base64 AES-256 master key for camera credentials
+	CredentialsKeyFile string // File holding the master key, used when CredentialsKey is empty
+	CredentialsKeyID   string // ID stored with every encrypted value, change it when rotating the key
+	HealthCheckTimeout int    // Seconds each readiness check may take
+	ShareLinkKey       string // Base64 key signing guest share link tokens
+	ShareLinkKeyFile   string // File holding the share link key, used when ShareLinkKey is empty
+	GuestViewURL       string // Guest viewer page; share link URLs are this plus #<token>
+	PTZLockLease       int    // Seconds an operator keeps PTZ control after their last command
+	PTZRolePriorities  string // group:priority pairs, e.g. "operator:10,supervisor:20"
+	ViewerEvictGroups  string // Comma-separated groups allowed to evict viewers
+	VMSCacheNamespace  string // CACHE_NAMESPACE of vms-service, whose cached cameras are dropped on camera writes
 }
 
 func loadConfig() Config {
@@ -181,6 +336,19 @@ func loadConfig() Config {
 		LiveKitWSURL:       getEnv("LIVEKIT_WS_URL", "ws://localhost:7880"),
 		LiveKitAPIKey:      getEnv("LIVEKIT_API_KEY", "devkey"),
 		LiveKitAPISecret:   getEnv("LIVEKIT_API_SECRET", "devsecret"),
+		FFmpegPath:         getEnv("FFMPEG_PATH", "ffmpeg"),
+		SnapshotCacheTTL:   getEnvInt("SNAPSHOT_CACHE_TTL", 10),
+		CredentialsKey:     getEnv("CREDENTIALS_KEY", ""),
+		CredentialsKeyFile: getEnv("CREDENTIALS_KEY_FILE", ""),
+		CredentialsKeyID:   getEnv("CREDENTIALS_KEY_ID", "k1"),
+		HealthCheckTimeout: getEnvInt("HEALTH_CHECK_TIMEOUT", 2),
+		ShareLinkKey:       getEnv("SHARE_LINK_KEY", ""),
+		ShareLinkKeyFile:   getEnv("SHARE_LINK_KEY_FILE", ""),
+		GuestViewURL:       getEnv("GUEST_VIEW_URL", "http://localhost:3000/guest"),
+		PTZLockLease:       getEnvInt("PTZ_LOCK_LEASE", 30),
+		PTZRolePriorities:  getEnv("PTZ_ROLE_PRIORITIES", "operator:10,supervisor:20,admin:30"),
+		ViewerEvictGroups:  getEnv("VIEWER_EVICT_GROUPS", "supervisor,admin"),
+		VMSCacheNamespace:  getEnv("VMS_CACHE_NAMESPACE", valkey.DefaultVMSCacheNamespace),
 	}
 }
 
@@ -200,6 +368,37 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseRolePriorities parses comma-separated group:priority pairs
+func parseRolePriorities(value string) (map[string]int, error) {
+	priorities := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		group, priority, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("%q is not group:priority", pair)
+		}
+		p, err := strconv.Atoi(strings.TrimSpace(priority))
+		if err != nil {
+			return nil, fmt.Errorf("%q: priority must be a number", pair)
+		}
+		priorities[strings.TrimSpace(group)] = p
+	}
+	return priorities, nil
+}
+
 func initPostgreSQL(ctx context.Context, config Config, logger zerolog.Logger) (*sql.DB, error) {
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		config.PostgresHost,
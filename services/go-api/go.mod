@@ -14,5 +14,6 @@ require (
 	github.com/prometheus/client_golang v1.18.0
 	github.com/redis/go-redis/v9 v9.4.0
 	github.com/rs/zerolog v1.31.0
+	golang.org/x/sync v0.6.0
 	golang.org/x/time v0.5.0  // Use compatible version for Go 1.23
 )
@@ -8,6 +8,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
+	"github.com/rta/cctv/go-api/internal/secrets"
 	"github.com/rs/zerolog"
 )
 
@@ -96,7 +97,7 @@ func (d *DockerClient) StartWHIPPusher(ctx context.Context, config WHIPPusherCon
 	d.logger.Info().
 		Str("container_id", resp.ID).
 		Str("container_name", config.ContainerName).
-		Str("rtsp_url", config.RTSPURL).
+		Str("rtsp_url", secrets.MaskURL(config.RTSPURL)).
 		Str("whip_endpoint", config.WHIPEndpoint).
 		Msg("Started WHIP pusher container")
 
@@ -151,6 +152,12 @@ func (d *DockerClient) GetContainerLogs(ctx context.Context, containerName strin
 	return string(logBytes), nil
 }
 
+// Ping checks that the Docker daemon answers
+func (d *DockerClient) Ping(ctx context.Context) error {
+	_, err := d.cli.Ping(ctx)
+	return err
+}
+
 // Close closes the Docker client connection
 func (d *DockerClient) Close() error {
 	return d.cli.Close()
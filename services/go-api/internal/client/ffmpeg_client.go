@@ -0,0 +1,64 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// FFmpegClient grabs still frames from RTSP streams using the ffmpeg binary
+type FFmpegClient struct {
+	binary  string
+	timeout time.Duration
+	logger  zerolog.Logger
+}
+
+// NewFFmpegClient creates a new ffmpeg client
+func NewFFmpegClient(binary string, logger zerolog.Logger) *FFmpegClient {
+	return &FFmpegClient{
+		binary:  binary,
+		timeout: 15 * time.Second,
+		logger:  logger,
+	}
+}
+
+// GrabFrame captures a single JPEG frame from an RTSP URL
+func (c *FFmpegClient) GrabFrame(ctx context.Context, rtspURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.binary,
+		"-hide_banner",
+		"-loglevel", "error",
+		"-rtsp_transport", "tcp",
+		"-i", rtspURL,
+		"-frames:v", "1",
+		"-q:v", "5",
+		"-f", "image2",
+		"-c:v", "mjpeg",
+		"pipe:1",
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("ffmpeg timed out after %s", c.timeout)
+		}
+		// Never echo the RTSP URL back, it carries camera credentials
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, strings.ReplaceAll(stderr.String(), rtspURL, "<rtsp_url>"))
+	}
+
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("ffmpeg produced no frame")
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// HostProber checks whether camera hosts accept TCP connections
+type HostProber struct {
+	timeout time.Duration
+}
+
+// NewHostProber creates a new host prober with a per-connection timeout
+func NewHostProber(timeout time.Duration) *HostProber {
+	return &HostProber{timeout: timeout}
+}
+
+// CheckTCP opens and closes a TCP connection to address (host:port)
+func (p *HostProber) CheckTCP(ctx context.Context, address string) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("%s unreachable: %w", address, err)
+	}
+
+	return conn.Close()
+}
@@ -2,13 +2,16 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
 	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/rta/cctv/go-api/internal/domain"
 	"github.com/rs/zerolog"
+	"github.com/twitchtv/twirp"
 )
 
 // LiveKitClient handles communication with LiveKit server
@@ -16,15 +19,17 @@ type LiveKitClient struct {
 	url       string
 	apiKey    string
 	apiSecret string
+	dep       *Dependency
 	logger    zerolog.Logger
 }
 
 // NewLiveKitClient creates a new LiveKit client
-func NewLiveKitClient(url, apiKey, apiSecret string, logger zerolog.Logger) *LiveKitClient {
+func NewLiveKitClient(url, apiKey, apiSecret string, dep *Dependency, logger zerolog.Logger) *LiveKitClient {
 	return &LiveKitClient{
 		url:       url,
 		apiKey:    apiKey,
 		apiSecret: apiSecret,
+		dep:       dep,
 		logger:    logger,
 	}
 }
@@ -33,12 +38,18 @@ func NewLiveKitClient(url, apiKey, apiSecret string, logger zerolog.Logger) *Liv
 func (c *LiveKitClient) CreateRoom(ctx context.Context, roomName string, maxParticipants int32) error {
 	roomClient := lksdk.NewRoomServiceClient(c.url, c.apiKey, c.apiSecret)
 
-	_, err := roomClient.CreateRoom(ctx, &livekit.CreateRoomRequest{
-		Name:            roomName,
-		EmptyTimeout:    60, // 60 seconds
-		MaxParticipants: uint32(maxParticipants),
+	err := c.dep.Call(ctx, true, func(ctx context.Context) error {
+		_, err := roomClient.CreateRoom(ctx, &livekit.CreateRoomRequest{
+			Name:            roomName,
+			EmptyTimeout:    60, // 60 seconds
+			MaxParticipants: uint32(maxParticipants),
+		})
+		return livekitError(err)
 	})
 
+	if errors.Is(err, domain.ErrDependencyUnavailable) {
+		return err
+	}
 	if err != nil {
 		// Room might already exist, which is fine
 		c.logger.Debug().Str("room", roomName).Msg("Room might already exist")
@@ -53,8 +64,11 @@ func (c *LiveKitClient) CreateRoom(ctx context.Context, roomName string, maxPart
 func (c *LiveKitClient) DeleteRoom(ctx context.Context, roomName string) error {
 	roomClient := lksdk.NewRoomServiceClient(c.url, c.apiKey, c.apiSecret)
 
-	_, err := roomClient.DeleteRoom(ctx, &livekit.DeleteRoomRequest{
-		Room: roomName,
+	err := c.dep.Call(ctx, true, func(ctx context.Context) error {
+		_, err := roomClient.DeleteRoom(ctx, &livekit.DeleteRoomRequest{
+			Room: roomName,
+		})
+		return livekitError(err)
 	})
 
 	if err != nil {
@@ -99,7 +113,12 @@ func (c *LiveKitClient) GenerateToken(roomName, participantName string, canPubli
 func (c *LiveKitClient) ListRooms(ctx context.Context) ([]*livekit.Room, error) {
 	roomClient := lksdk.NewRoomServiceClient(c.url, c.apiKey, c.apiSecret)
 
-	resp, err := roomClient.ListRooms(ctx, &livekit.ListRoomsRequest{})
+	var resp *livekit.ListRoomsResponse
+	err := c.dep.Call(ctx, true, func(ctx context.Context) error {
+		var err error
+		resp, err = roomClient.ListRooms(ctx, &livekit.ListRoomsRequest{})
+		return livekitError(err)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list rooms: %w", err)
 	}
@@ -107,6 +126,14 @@ func (c *LiveKitClient) ListRooms(ctx context.Context) ([]*livekit.Room, error)
 	return resp.Rooms, nil
 }
 
+// Ping checks that the LiveKit API answers, without going through the circuit breaker
+func (c *LiveKitClient) Ping(ctx context.Context) error {
+	roomClient := lksdk.NewRoomServiceClient(c.url, c.apiKey, c.apiSecret)
+
+	_, err := roomClient.ListRooms(ctx, &livekit.ListRoomsRequest{})
+	return err
+}
+
 // GetRoomInfo gets information about a specific room
 func (c *LiveKitClient) GetRoomInfo(ctx context.Context, roomName string) (*livekit.Room, error) {
 	rooms, err := c.ListRooms(ctx)
@@ -127,8 +154,13 @@ func (c *LiveKitClient) GetRoomInfo(ctx context.Context, roomName string) (*live
 func (c *LiveKitClient) ListParticipants(ctx context.Context, roomName string) ([]*livekit.ParticipantInfo, error) {
 	roomClient := lksdk.NewRoomServiceClient(c.url, c.apiKey, c.apiSecret)
 
-	resp, err := roomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{
-		Room: roomName,
+	var resp *livekit.ListParticipantsResponse
+	err := c.dep.Call(ctx, true, func(ctx context.Context) error {
+		var err error
+		resp, err = roomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{
+			Room: roomName,
+		})
+		return livekitError(err)
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list participants: %w", err)
@@ -141,9 +173,12 @@ func (c *LiveKitClient) ListParticipants(ctx context.Context, roomName string) (
 func (c *LiveKitClient) RemoveParticipant(ctx context.Context, roomName, participantIdentity string) error {
 	roomClient := lksdk.NewRoomServiceClient(c.url, c.apiKey, c.apiSecret)
 
-	_, err := roomClient.RemoveParticipant(ctx, &livekit.RoomParticipantIdentity{
-		Room:     roomName,
-		Identity: participantIdentity,
+	err := c.dep.Call(ctx, true, func(ctx context.Context) error {
+		_, err := roomClient.RemoveParticipant(ctx, &livekit.RoomParticipantIdentity{
+			Room:     roomName,
+			Identity: participantIdentity,
+		})
+		return livekitError(err)
 	})
 
 	if err != nil {
@@ -158,3 +193,17 @@ func (c *LiveKitClient) RemoveParticipant(ctx context.Context, roomName, partici
 	return nil
 }
 
+
+// livekitError marks LiveKit errors caused by the request, such as an unknown room, as Permanent
+// so they are neither retried nor counted against the breaker
+func livekitError(err error) error {
+	var twirpErr twirp.Error
+	if errors.As(err, &twirpErr) {
+		switch twirpErr.Code() {
+		case twirp.NotFound, twirp.AlreadyExists, twirp.InvalidArgument, twirp.OutOfRange,
+			twirp.FailedPrecondition, twirp.PermissionDenied, twirp.Unauthenticated:
+			return Permanent(err)
+		}
+	}
+	return err
+}
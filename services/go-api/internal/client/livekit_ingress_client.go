@@ -14,15 +14,17 @@ type LiveKitIngressClient struct {
 	apiURL    string
 	apiKey    string
 	apiSecret string
+	dep       *Dependency
 	logger    zerolog.Logger
 }
 
 // NewLiveKitIngressClient creates a new LiveKit Ingress client
-func NewLiveKitIngressClient(apiURL, apiKey, apiSecret string, logger zerolog.Logger) *LiveKitIngressClient {
+func NewLiveKitIngressClient(apiURL, apiKey, apiSecret string, dep *Dependency, logger zerolog.Logger) *LiveKitIngressClient {
 	return &LiveKitIngressClient{
 		apiURL:    apiURL,
 		apiKey:    apiKey,
 		apiSecret: apiSecret,
+		dep:       dep,
 		logger:    logger,
 	}
 }
@@ -44,8 +46,13 @@ func (c *LiveKitIngressClient) CreateWHIPIngress(ctx context.Context, roomName,
 		// EnableTranscoding is false by default for WHIP (bypass transcoding)
 	}
 
-	// Create ingress
-	ingressInfo, err := ingressClient.CreateIngress(ctx, req)
+	// Create ingress, not retried so a slow success does not leave a second ingress behind
+	var ingressInfo *livekit.IngressInfo
+	err := c.dep.Call(ctx, false, func(ctx context.Context) error {
+		var err error
+		ingressInfo, err = ingressClient.CreateIngress(ctx, req)
+		return livekitError(err)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create WHIP ingress: %w", err)
 	}
@@ -71,8 +78,11 @@ func (c *LiveKitIngressClient) DeleteIngress(ctx context.Context, ingressID stri
 	ingressClient := lksdk.NewIngressClient(c.apiURL, c.apiKey, c.apiSecret)
 
 	// Delete ingress
-	_, err := ingressClient.DeleteIngress(ctx, &livekit.DeleteIngressRequest{
-		IngressId: ingressID,
+	err := c.dep.Call(ctx, true, func(ctx context.Context) error {
+		_, err := ingressClient.DeleteIngress(ctx, &livekit.DeleteIngressRequest{
+			IngressId: ingressID,
+		})
+		return livekitError(err)
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete ingress: %w", err)
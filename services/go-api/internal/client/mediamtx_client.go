@@ -7,20 +7,24 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
+	"github.com/rta/cctv/go-api/internal/secrets"
 	"github.com/rs/zerolog"
 )
 
 // MediaMTXClient handles communication with MediaMTX server
 type MediaMTXClient struct {
 	apiURL string
+	dep    *Dependency
 	logger zerolog.Logger
 }
 
 // NewMediaMTXClient creates a new MediaMTX client
-func NewMediaMTXClient(apiURL string, logger zerolog.Logger) *MediaMTXClient {
+func NewMediaMTXClient(apiURL string, dep *Dependency, logger zerolog.Logger) *MediaMTXClient {
 	return &MediaMTXClient{
 		apiURL: apiURL,
+		dep:    dep,
 		logger: logger,
 	}
 }
@@ -65,7 +69,7 @@ func (c *MediaMTXClient) ConfigurePath(ctx context.Context, pathName, rtspURL st
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.dep.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -75,7 +79,7 @@ func (c *MediaMTXClient) ConfigurePath(ctx context.Context, pathName, rtspURL st
 	if resp.StatusCode == http.StatusOK {
 		c.logger.Info().
 			Str("path", pathName).
-			Str("rtsp_url", rtspURL).
+			Str("rtsp_url", secrets.MaskURL(rtspURL)).
 			Msg("Configured MediaMTX path")
 		return nil
 	}
@@ -91,7 +95,7 @@ func (c *MediaMTXClient) ConfigurePath(ctx context.Context, pathName, rtspURL st
 		}
 		req.Header.Set("Content-Type", "application/json")
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := c.dep.DoIdempotent(req) // Patching to the same config is idempotent
 		if err != nil {
 			return fmt.Errorf("failed to send patch request: %w", err)
 		}
@@ -99,18 +103,18 @@ func (c *MediaMTXClient) ConfigurePath(ctx context.Context, pathName, rtspURL st
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			return fmt.Errorf("MediaMTX PATCH API returned status %d: %s", resp.StatusCode, string(body))
+			return fmt.Errorf("MediaMTX PATCH API returned status %d: %s", resp.StatusCode, strings.ReplaceAll(string(body), rtspURL, "<rtsp_url>"))
 		}
 
 		c.logger.Info().
 			Str("path", pathName).
-			Str("rtsp_url", rtspURL).
+			Str("rtsp_url", secrets.MaskURL(rtspURL)).
 			Msg("Updated MediaMTX path")
 		return nil
 	}
 
 	// Other error
-	return fmt.Errorf("MediaMTX API returned status %d: %s", resp.StatusCode, string(body))
+	return fmt.Errorf("MediaMTX API returned status %d: %s", resp.StatusCode, strings.ReplaceAll(string(body), rtspURL, "<rtsp_url>"))
 }
 
 // DeletePath removes a path configuration from MediaMTX
@@ -122,7 +126,7 @@ func (c *MediaMTXClient) DeletePath(ctx context.Context, pathName string) error
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.dep.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -147,7 +151,7 @@ func (c *MediaMTXClient) GetPath(ctx context.Context, pathName string) (*PathInf
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.dep.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -181,3 +185,13 @@ type PathInfo struct {
 	BytesReceived int64  `json:"bytesReceived"`
 	Readers       int    `json:"readers"`
 }
+
+// Ping checks that the MediaMTX API answers, without going through the circuit breaker
+func (c *MediaMTXClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.apiURL+"/v3/paths/list?itemsPerPage=1", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return c.dep.Probe(req)
+}
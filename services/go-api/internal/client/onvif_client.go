@@ -0,0 +1,295 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// maxSnapshotBytes caps the size of a snapshot image downloaded from a camera
+const maxSnapshotBytes = 10 << 20
+
+// OnvifClient talks to cameras directly over ONVIF SOAP
+type OnvifClient struct {
+	httpClient *http.Client
+	logger     zerolog.Logger
+}
+
+// NewOnvifClient creates a new ONVIF client
+func NewOnvifClient(logger zerolog.Logger) *OnvifClient {
+	return &OnvifClient{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// GetSnapshotURI resolves the JPEG snapshot URI of a camera's first media profile
+// deviceURL is the ONVIF device service, e.g. http://192.168.1.13/onvif/device_service
+func (c *OnvifClient) GetSnapshotURI(ctx context.Context, deviceURL, username, password string) (string, error) {
+	// Resolve the media service address (falls back to the device service)
+	mediaURL := deviceURL
+	var caps struct {
+		XAddr string `xml:"Body>GetCapabilitiesResponse>Capabilities>Media>XAddr"`
+	}
+	err := c.call(ctx, deviceURL, username, password,
+		`<tds:GetCapabilities xmlns:tds="http://www.onvif.org/ver10/device/wsdl"><tds:Category>Media</tds:Category></tds:GetCapabilities>`,
+		&caps)
+	if err == nil && caps.XAddr != "" {
+		mediaURL = caps.XAddr
+	}
+
+	// Use the first media profile
+	var profiles struct {
+		Profiles []struct {
+			Token string `xml:"token,attr"`
+		} `xml:"Body>GetProfilesResponse>Profiles"`
+	}
+	err = c.call(ctx, mediaURL, username, password,
+		`<trt:GetProfiles xmlns:trt="http://www.onvif.org/ver10/media/wsdl"/>`,
+		&profiles)
+	if err != nil {
+		return "", fmt.Errorf("failed to get profiles: %w", err)
+	}
+	if len(profiles.Profiles) == 0 {
+		return "", fmt.Errorf("camera has no media profiles")
+	}
+
+	var snapshot struct {
+		URI string `xml:"Body>GetSnapshotUriResponse>MediaUri>Uri"`
+	}
+	err = c.call(ctx, mediaURL, username, password,
+		fmt.Sprintf(`<trt:GetSnapshotUri xmlns:trt="http://www.onvif.org/ver10/media/wsdl"><trt:ProfileToken>%s</trt:ProfileToken></trt:GetSnapshotUri>`,
+			xmlEscape(profiles.Profiles[0].Token)),
+		&snapshot)
+	if err != nil {
+		return "", fmt.Errorf("failed to get snapshot uri: %w", err)
+	}
+	if snapshot.URI == "" {
+		return "", fmt.Errorf("camera returned an empty snapshot uri")
+	}
+
+	return strings.TrimSpace(snapshot.URI), nil
+}
+
+// FetchSnapshot downloads an image from a snapshot URI
+// The first request carries no credentials. A digest challenge is answered once; Basic is only
+// sent when the camera asks for it and the URI is on the device's own host, since it puts the
+// password on the wire in clear text.
+func (c *OnvifClient) FetchSnapshot(ctx context.Context, deviceURL, snapshotURI, username, password string) ([]byte, string, error) {
+	resp, err := c.get(ctx, snapshotURI, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && username != "" {
+		challenges := resp.Header.Values("WWW-Authenticate")
+		requestURI := resp.Request.URL.RequestURI()
+		resp.Body.Close()
+
+		authorize, err := snapshotAuthorizer(challenges, deviceURL, snapshotURI, requestURI, username, password)
+		if err != nil {
+			return nil, "", err
+		}
+		resp, err = c.get(ctx, snapshotURI, authorize)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("snapshot request returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSnapshotBytes))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(data)
+		if !strings.HasPrefix(contentType, "image/") {
+			return nil, "", fmt.Errorf("snapshot response is not an image: %s", contentType)
+		}
+	}
+
+	return data, contentType, nil
+}
+
+// snapshotAuthorizer picks how to answer the snapshot URI's auth challenges
+// Digest is preferred; Basic is refused for a URI on another host than the device.
+func snapshotAuthorizer(challenges []string, deviceURL, snapshotURI, requestURI, username, password string) (func(*http.Request), error) {
+	basic := false
+	for _, challenge := range challenges {
+		scheme := strings.ToLower(strings.TrimSpace(challenge))
+		switch {
+		case strings.HasPrefix(scheme, "digest "):
+			header := digestAuthorization(strings.TrimSpace(challenge), http.MethodGet, requestURI, username, password)
+			return func(req *http.Request) { req.Header.Set("Authorization", header) }, nil
+		case strings.HasPrefix(scheme, "basic"):
+			basic = true
+		}
+	}
+
+	if !basic {
+		return nil, fmt.Errorf("snapshot request unauthorized")
+	}
+	if !sameHost(deviceURL, snapshotURI) {
+		return nil, fmt.Errorf("snapshot uri host differs from the device; refusing to send basic auth")
+	}
+	return func(req *http.Request) { req.SetBasicAuth(username, password) }, nil
+}
+
+// sameHost reports whether two URLs name the same host, ignoring the port
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Hostname() != "" && strings.EqualFold(ua.Hostname(), ub.Hostname())
+}
+
+// get issues a GET, letting authorize add credentials when set
+func (c *OnvifClient) get(ctx context.Context, uri string, authorize func(*http.Request)) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if authorize != nil {
+		authorize(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch snapshot: %w", err)
+	}
+
+	return resp, nil
+}
+
+// call sends a SOAP request and decodes the envelope into result
+func (c *OnvifClient) call(ctx context.Context, endpoint, username, password, body string, result interface{}) error {
+	securityHeader := ""
+	if username != "" {
+		securityHeader = wsSecurityHeader(username, password)
+	}
+
+	envelope := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope">
+  <s:Header>%s</s:Header>
+  <s:Body>%s</s:Body>
+</s:Envelope>`, securityHeader, body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBufferString(envelope))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/soap+xml; charset=utf-8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("onvif request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read onvif response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("onvif request returned status %d", resp.StatusCode)
+	}
+
+	if err := xml.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("failed to decode onvif response: %w", err)
+	}
+
+	return nil
+}
+
+// wsSecurityHeader generates a WS-Security UsernameToken header with password digest
+func wsSecurityHeader(username, password string) string {
+	nonceBytes := make([]byte, 20)
+	rand.Read(nonceBytes)
+	nonce := base64.StdEncoding.EncodeToString(nonceBytes)
+	created := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+
+	// Password Digest = Base64(SHA1(nonce_bytes + created + password))
+	hash := sha1.New()
+	hash.Write(nonceBytes)
+	hash.Write([]byte(created))
+	hash.Write([]byte(password))
+	digest := base64.StdEncoding.EncodeToString(hash.Sum(nil))
+
+	return fmt.Sprintf(`<Security s:mustUnderstand="1" xmlns="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd">
+    <UsernameToken>
+      <Username>%s</Username>
+      <Password Type="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest">%s</Password>
+      <Nonce EncodingType="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary">%s</Nonce>
+      <Created xmlns="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd">%s</Created>
+    </UsernameToken>
+  </Security>`, xmlEscape(username), digest, nonce, created)
+}
+
+// xmlEscape escapes a value for use as SOAP element text
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// digestAuthorization builds an RFC 2617 Authorization header (MD5, qop=auth) for a digest challenge
+func digestAuthorization(challenge, method, uri, username, password string) string {
+	params := map[string]string{}
+	for _, part := range strings.Split(challenge[len("Digest "):], ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+
+	md5hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	cnonceBytes := make([]byte, 8)
+	rand.Read(cnonceBytes)
+	cnonce := hex.EncodeToString(cnonceBytes)
+	nc := "00000001"
+
+	ha1 := md5hex(username + ":" + params["realm"] + ":" + password)
+	ha2 := md5hex(method + ":" + uri)
+
+	var response string
+	if strings.Contains(params["qop"], "auth") {
+		response = md5hex(ha1 + ":" + params["nonce"] + ":" + nc + ":" + cnonce + ":auth:" + ha2)
+		return fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", qop=auth, nc=%s, cnonce="%s", response="%s", opaque="%s"`,
+			username, params["realm"], params["nonce"], uri, nc, cnonce, response, params["opaque"])
+	}
+
+	response = md5hex(ha1 + ":" + params["nonce"] + ":" + ha2)
+	return fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s", opaque="%s"`,
+		username, params["realm"], params["nonce"], uri, response, params["opaque"])
+}
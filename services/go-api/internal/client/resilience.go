@@ -0,0 +1,343 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+var (
+	dependencyBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "go_api_dependency_breaker_state",
+		Help: "Circuit breaker state per downstream service (0 closed, 1 half-open, 2 open)",
+	}, []string{"dependency"})
+
+	dependencyCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "go_api_dependency_calls_total",
+		Help: "Downstream calls per service and outcome (success, failure, retry, rejected)",
+	}, []string{"dependency", "outcome"})
+
+	dependencyCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "go_api_dependency_call_duration_seconds",
+		Help:    "Duration of downstream call attempts per service",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"dependency"})
+)
+
+// Policy configures how a downstream service is called
+type Policy struct {
+	Name             string        // Dependency name in logs, metrics and /health
+	Timeout          time.Duration // Per attempt, including reading the response body
+	MaxRetries       int           // Extra attempts for idempotent calls
+	BaseBackoff      time.Duration // Backoff before the first retry, doubled per retry
+	MaxBackoff       time.Duration
+	FailureThreshold int           // Consecutive failures that open the breaker
+	OpenTimeout      time.Duration // How long the breaker stays open before a trial call
+}
+
+// Dependency calls a downstream service with a per-attempt timeout, bounded retries
+// with jitter for idempotent calls, and a circuit breaker
+// Transport errors, 5xx and 429 responses count as failures; other responses mean the service is up
+type Dependency struct {
+	policy     Policy
+	httpClient *http.Client
+	logger     zerolog.Logger
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	trial    bool // A half-open trial call is in flight
+}
+
+// NewDependency creates a dependency with a closed breaker
+func NewDependency(policy Policy, logger zerolog.Logger) *Dependency {
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = 5
+	}
+	if policy.OpenTimeout <= 0 {
+		policy.OpenTimeout = 30 * time.Second
+	}
+	if policy.BaseBackoff <= 0 {
+		policy.BaseBackoff = 100 * time.Millisecond
+	}
+	if policy.MaxBackoff < policy.BaseBackoff {
+		policy.MaxBackoff = policy.BaseBackoff
+	}
+
+	dependencyBreakerState.WithLabelValues(policy.Name).Set(0)
+
+	return &Dependency{
+		policy:     policy,
+		httpClient: &http.Client{Timeout: policy.Timeout},
+		state:      domain.BreakerClosed,
+		logger:     logger.With().Str("dependency", policy.Name).Logger(),
+	}
+}
+
+// Name returns the dependency name
+func (d *Dependency) Name() string {
+	return d.policy.Name
+}
+
+// Do sends req; GET, HEAD, OPTIONS, PUT and DELETE requests are retried
+// The response of the last attempt is returned as-is, so callers still see 5xx statuses
+func (d *Dependency) Do(req *http.Request) (*http.Response, error) {
+	return d.do(req, isIdempotentMethod(req.Method))
+}
+
+// DoIdempotent sends req and retries it whatever the method
+// Use it for POSTs the downstream service treats as idempotent, such as heartbeats
+func (d *Dependency) DoIdempotent(req *http.Request) (*http.Response, error) {
+	return d.do(req, true)
+}
+
+func (d *Dependency) do(req *http.Request, idempotent bool) (*http.Response, error) {
+	// A body can only be sent again if it can be rewound
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		idempotent = false
+	}
+
+	var resp *http.Response
+	err := d.run(req.Context(), idempotent, func(ctx context.Context, attempt int) (bool, error) {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return false, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		var err error
+		resp, err = d.httpClient.Do(attemptReq)
+		if err != nil {
+			return true, err
+		}
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return true, nil
+		}
+		return false, nil
+	}, func() {
+		// Drop the failed response before retrying
+		resp.Body.Close()
+		resp = nil
+	})
+
+	return resp, err
+}
+
+// Probe sends req once, outside the breaker and the retry budget, so health checks
+// neither trip nor reset it; 5xx responses are returned as errors
+func (d *Dependency) Probe(req *http.Request) error {
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("%s returned status %d", d.policy.Name, resp.StatusCode)
+	}
+	return nil
+}
+
+// Call runs fn, for SDK clients that do not expose HTTP requests
+// fn gets a context bounded by the policy timeout; errors marked Permanent do not
+// count against the breaker and are not retried
+func (d *Dependency) Call(ctx context.Context, idempotent bool, fn func(ctx context.Context) error) error {
+	return d.run(ctx, idempotent, func(ctx context.Context, attempt int) (bool, error) {
+		attemptCtx, cancel := context.WithTimeout(ctx, d.policy.Timeout)
+		defer cancel()
+
+		err := fn(attemptCtx)
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return false, permanent.err
+		}
+		return err != nil, err
+	}, nil)
+}
+
+// run drives the attempts of one call
+// attempt reports whether the outcome is a failure; a failed outcome without error is a bad response
+// discard is called before retrying after a bad response
+func (d *Dependency) run(ctx context.Context, idempotent bool, attempt func(ctx context.Context, n int) (bool, error), discard func()) error {
+	maxAttempts := 1
+	if idempotent {
+		maxAttempts += d.policy.MaxRetries
+	}
+
+	for n := 0; ; n++ {
+		if err := d.allow(); err != nil {
+			dependencyCalls.WithLabelValues(d.policy.Name, "rejected").Inc()
+			return err
+		}
+
+		start := time.Now()
+		failed, err := attempt(ctx, n)
+		dependencyCallDuration.WithLabelValues(d.policy.Name).Observe(time.Since(start).Seconds())
+
+		// The caller gave up; that says nothing about the dependency
+		if failed && err != nil && ctx.Err() != nil {
+			d.release()
+			return err
+		}
+
+		if !failed {
+			d.record(true)
+			dependencyCalls.WithLabelValues(d.policy.Name, "success").Inc()
+			return err
+		}
+
+		d.record(false)
+		dependencyCalls.WithLabelValues(d.policy.Name, "failure").Inc()
+
+		if n+1 >= maxAttempts {
+			if err != nil {
+				return fmt.Errorf("%s: %w", d.policy.Name, err)
+			}
+			return nil // Bad response, returned to the caller
+		}
+
+		if err == nil && discard != nil {
+			discard()
+		}
+
+		d.logger.Debug().Err(err).Int("attempt", n+1).Msg("Retrying downstream call")
+		dependencyCalls.WithLabelValues(d.policy.Name, "retry").Inc()
+
+		select {
+		case <-time.After(d.backoff(n)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// backoff returns the delay before retry n+1: exponential, with equal jitter
+func (d *Dependency) backoff(n int) time.Duration {
+	delay := d.policy.BaseBackoff << n
+	if delay <= 0 || delay > d.policy.MaxBackoff {
+		delay = d.policy.MaxBackoff
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// allow rejects the call while the breaker is open, and lets one trial call through once it may close
+func (d *Dependency) allow() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch d.state {
+	case domain.BreakerOpen:
+		if time.Since(d.openedAt) < d.policy.OpenTimeout {
+			return fmt.Errorf("%w: %s circuit breaker is open", domain.ErrDependencyUnavailable, d.policy.Name)
+		}
+		d.setState(domain.BreakerHalfOpen)
+		d.trial = true
+		return nil
+	case domain.BreakerHalfOpen:
+		if d.trial {
+			return fmt.Errorf("%w: %s is recovering", domain.ErrDependencyUnavailable, d.policy.Name)
+		}
+		d.trial = true
+	}
+	return nil
+}
+
+// release ends a trial call without an outcome
+func (d *Dependency) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.trial = false
+}
+
+// record updates the breaker with the outcome of an attempt
+func (d *Dependency) record(success bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.trial = false
+	if success {
+		d.failures = 0
+		if d.state != domain.BreakerClosed {
+			d.logger.Info().Msg("Circuit breaker closed")
+			d.setState(domain.BreakerClosed)
+		}
+		return
+	}
+
+	d.failures++
+	if d.state == domain.BreakerHalfOpen || d.failures >= d.policy.FailureThreshold {
+		if d.state != domain.BreakerOpen {
+			d.logger.Warn().Int("consecutive_failures", d.failures).Msg("Circuit breaker opened")
+		}
+		d.openedAt = time.Now()
+		d.setState(domain.BreakerOpen)
+	}
+}
+
+func (d *Dependency) setState(state string) {
+	d.state = state
+	value := 0.0
+	switch state {
+	case domain.BreakerHalfOpen:
+		value = 1
+	case domain.BreakerOpen:
+		value = 2
+	}
+	dependencyBreakerState.WithLabelValues(d.policy.Name).Set(value)
+}
+
+// Health returns the breaker state
+func (d *Dependency) Health() domain.DependencyHealth {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	health := domain.DependencyHealth{
+		Name:                d.policy.Name,
+		State:               d.state,
+		ConsecutiveFailures: d.failures,
+	}
+	if d.state != domain.BreakerClosed {
+		openedAt := d.openedAt
+		health.OpenedAt = &openedAt
+	}
+	return health
+}
+
+// permanentError marks an error that says nothing about the dependency's health
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err, returned from a Call function, as a client-side error such as not found
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -13,19 +13,17 @@ import (
 
 // StreamCounterClient handles communication with Stream Counter Service
 type StreamCounterClient struct {
-	baseURL    string
-	httpClient *http.Client
-	logger     zerolog.Logger
+	baseURL string
+	dep     *Dependency
+	logger  zerolog.Logger
 }
 
 // NewStreamCounterClient creates a new stream counter client
-func NewStreamCounterClient(baseURL string, logger zerolog.Logger) *StreamCounterClient {
+func NewStreamCounterClient(baseURL string, dep *Dependency, logger zerolog.Logger) *StreamCounterClient {
 	return &StreamCounterClient{
 		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		logger: logger,
+		dep:     dep,
+		logger:  logger,
 	}
 }
 
@@ -70,7 +68,7 @@ func (c *StreamCounterClient) ReserveStream(ctx context.Context, cameraID, sourc
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.dep.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to reserve stream: %w", err)
 	}
@@ -111,7 +109,7 @@ func (c *StreamCounterClient) ReleaseStream(ctx context.Context, reservationID s
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.dep.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to release stream: %w", err)
 	}
@@ -134,7 +132,7 @@ func (c *StreamCounterClient) SendHeartbeat(ctx context.Context, reservationID s
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.dep.DoIdempotent(req) // Extending a reservation twice is harmless
 	if err != nil {
 		return fmt.Errorf("failed to send heartbeat: %w", err)
 	}
@@ -156,7 +154,7 @@ func (c *StreamCounterClient) GetStats(ctx context.Context) (map[string]interfac
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.dep.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stats: %w", err)
 	}
@@ -169,3 +167,13 @@ func (c *StreamCounterClient) GetStats(ctx context.Context) (map[string]interfac
 
 	return stats, nil
 }
+
+// Ping checks that the stream counter service answers, without going through the circuit breaker
+func (c *StreamCounterClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return c.dep.Probe(req)
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/rta/cctv/go-api/internal/domain"
 	"github.com/rs/zerolog"
@@ -13,19 +12,18 @@ import (
 
 // VMSClient handles communication with VMS Service
 type VMSClient struct {
-	baseURL    string
-	httpClient *http.Client
-	logger     zerolog.Logger
+	baseURL string
+	dep     *Dependency
+	logger  zerolog.Logger
 }
 
 // NewVMSClient creates a new VMS service client
-func NewVMSClient(baseURL string, logger zerolog.Logger) *VMSClient {
+// The dependency timeout must allow for slow PTZ cameras (TrueView ~16s)
+func NewVMSClient(baseURL string, dep *Dependency, logger zerolog.Logger) *VMSClient {
 	return &VMSClient{
 		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second, // Increased for slow PTZ cameras (TrueView ~16s)
-		},
-		logger: logger,
+		dep:     dep,
+		logger:  logger,
 	}
 }
 
@@ -38,7 +36,7 @@ func (c *VMSClient) GetCamera(ctx context.Context, cameraID string) (*domain.Cam
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.dep.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get camera: %w", err)
 	}
@@ -93,7 +91,7 @@ func (c *VMSClient) ListCameras(ctx context.Context, query domain.CameraQuery) (
 		Str("url", req.URL.String()).
 		Msg("Requesting cameras from VMS")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.dep.Do(req)
 	if err != nil {
 		c.logger.Error().Err(err).Str("endpoint", endpoint).Msg("Failed to connect to VMS service")
 		return nil, fmt.Errorf("failed to list cameras: %w", err)
@@ -125,3 +123,13 @@ func (c *VMSClient) ControlPTZ(ctx context.Context, cmd domain.PTZCommand) error
 	// Use the translated version which converts commands to VMS format
 	return c.ControlPTZTranslated(ctx, cmd)
 }
+
+// Ping checks that the VMS service answers, without going through the circuit breaker
+func (c *VMSClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return c.dep.Probe(req)
+}
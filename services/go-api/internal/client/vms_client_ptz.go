@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/rta/cctv/go-api/internal/domain"
@@ -59,8 +60,29 @@ func (c *VMSClient) translatePTZCommand(cmd domain.PTZCommand) map[string]interf
 		vmsCmd["action"] = "STOP"
 
 	case "home":
+		vmsCmd["action"] = "GO_HOME"
+
+	case "preset":
 		vmsCmd["action"] = "GO_TO_PRESET"
-		vmsCmd["preset"] = 1 // Preset 1 = home position
+		vmsCmd["preset"] = cmd.PresetID
+
+	case "absolute":
+		vmsCmd["action"] = "ABSOLUTE_MOVE"
+		vmsCmd["pan"] = cmd.Pan
+		vmsCmd["tilt"] = cmd.Tilt
+		vmsCmd["zoom"] = cmd.Zoom
+
+	case "relative":
+		vmsCmd["action"] = "RELATIVE_MOVE"
+		vmsCmd["pan"] = cmd.Pan
+		vmsCmd["tilt"] = cmd.Tilt
+		vmsCmd["zoom"] = cmd.Zoom
+
+	case "center":
+		vmsCmd["action"] = "CENTER_ON_POINT"
+		vmsCmd["x"] = cmd.X
+		vmsCmd["y"] = cmd.Y
+		vmsCmd["zoom_factor"] = cmd.ZoomFactor
 
 	default:
 		vmsCmd["action"] = "MOVE"
@@ -90,14 +112,15 @@ func (c *VMSClient) ControlPTZTranslated(ctx context.Context, cmd domain.PTZComm
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	// Not retried: a repeated move command would move the camera twice
+	resp, err := c.dep.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to control PTZ: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("vms service returned status %d", resp.StatusCode)
+		return ptzError(resp, cmd.CameraID)
 	}
 
 	c.logger.Info().
@@ -109,3 +132,167 @@ func (c *VMSClient) ControlPTZTranslated(ctx context.Context, cmd domain.PTZComm
 
 	return nil
 }
+
+// GetPTZStatus reads the current pan, tilt and zoom of a camera
+func (c *VMSClient) GetPTZStatus(ctx context.Context, cameraID string) (*domain.PTZStatus, error) {
+	endpoint := fmt.Sprintf("%s/vms/cameras/%s/ptz/status", c.baseURL, cameraID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.dep.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PTZ status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ptzError(resp, cameraID)
+	}
+
+	var status domain.PTZStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &status, nil
+}
+
+// ListPTZPresets retrieves the presets of a camera; refresh makes VMS read them from the camera first
+func (c *VMSClient) ListPTZPresets(ctx context.Context, cameraID string, refresh bool) ([]*domain.PTZPreset, error) {
+	endpoint := fmt.Sprintf("%s/vms/cameras/%s/ptz/presets", c.baseURL, cameraID)
+	if refresh {
+		endpoint += "?refresh=true"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.dep.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PTZ presets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ptzError(resp, cameraID)
+	}
+
+	var response struct {
+		Presets []*domain.PTZPreset `json:"presets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.Presets, nil
+}
+
+// CreatePTZPreset saves the camera's current position as a new preset
+// preset is filled in with the number and token VMS assigned
+func (c *VMSClient) CreatePTZPreset(ctx context.Context, preset *domain.PTZPreset) error {
+	endpoint := fmt.Sprintf("%s/vms/cameras/%s/ptz/presets", c.baseURL, preset.CameraID)
+
+	return c.sendPTZPreset(ctx, "POST", endpoint, map[string]interface{}{
+		"number":     preset.Number,
+		"name":       preset.Name,
+		"name_ar":    preset.NameAr,
+		"created_by": preset.CreatedBy,
+	}, http.StatusCreated, preset)
+}
+
+// UpdatePTZPreset renames a preset and, with savePosition, stores the current position under it
+func (c *VMSClient) UpdatePTZPreset(ctx context.Context, preset *domain.PTZPreset, savePosition bool) error {
+	endpoint := fmt.Sprintf("%s/vms/cameras/%s/ptz/presets/%d", c.baseURL, preset.CameraID, preset.Number)
+
+	return c.sendPTZPreset(ctx, "PUT", endpoint, map[string]interface{}{
+		"name":          preset.Name,
+		"name_ar":       preset.NameAr,
+		"save_position": savePosition,
+	}, http.StatusOK, preset)
+}
+
+// DeletePTZPreset removes a preset from the camera
+func (c *VMSClient) DeletePTZPreset(ctx context.Context, cameraID string, number int) error {
+	endpoint := fmt.Sprintf("%s/vms/cameras/%s/ptz/presets/%d", c.baseURL, cameraID, number)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.dep.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete PTZ preset: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return ptzError(resp, cameraID)
+	}
+
+	return nil
+}
+
+// sendPTZPreset sends a preset create or update and decodes the stored preset into out
+func (c *VMSClient) sendPTZPreset(ctx context.Context, method, endpoint string, body map[string]interface{}, expected int, out *domain.PTZPreset) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.dep.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to save PTZ preset: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expected {
+		return ptzError(resp, out.CameraID)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// ptzError maps a failed VMS PTZ response to a domain error
+// VMS answers 404 for both unknown cameras and unknown presets; the message tells them apart.
+func ptzError(resp *http.Response, cameraID string) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	json.Unmarshal(data, &body)
+	message := body.Error.Message
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		if message == "Camera not found" {
+			return fmt.Errorf("%w: %s", domain.ErrCameraNotFound, cameraID)
+		}
+		return fmt.Errorf("%w: %s", domain.ErrPTZPresetNotFound, cameraID)
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %s", domain.ErrPTZPresetExists, message)
+	case http.StatusBadRequest:
+		return fmt.Errorf("%w: %s", domain.ErrInvalidPTZRequest, message)
+	case http.StatusBadGateway:
+		return fmt.Errorf("%w: %s", domain.ErrPTZCommandFailed, message)
+	default:
+		return fmt.Errorf("vms service returned status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+)
+
+// Identity headers set by the API gateway from the authenticated consumer
+// Kong drops the copies clients send; go-api must not be reachable around it.
+const (
+	headerUserID     = "X-User-ID"
+	headerUserGroups = "X-User-Groups" // Comma-separated; the consumer's tags at Kong
+)
+
+// actorFromRequest returns the identity of the caller
+// The actor is anonymous when no X-User-ID header is present
+func actorFromRequest(r *http.Request) *domain.Actor {
+	actor := &domain.Actor{
+		UserID: strings.TrimSpace(r.Header.Get(headerUserID)),
+	}
+
+	for _, group := range strings.Split(r.Header.Get(headerUserGroups), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			actor.Groups = append(actor.Groups, group)
+		}
+	}
+
+	return actor
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// CameraGroupHandler handles camera group HTTP requests
+type CameraGroupHandler struct {
+	groupUseCase domain.CameraGroupUseCase
+	logger       zerolog.Logger
+}
+
+// NewCameraGroupHandler creates a new camera group handler
+func NewCameraGroupHandler(groupUseCase domain.CameraGroupUseCase, logger zerolog.Logger) *CameraGroupHandler {
+	return &CameraGroupHandler{
+		groupUseCase: groupUseCase,
+		logger:       logger,
+	}
+}
+
+// CreateGroup handles camera group creation request
+// POST /api/v1/camera-groups
+func (h *CameraGroupHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var request domain.CreateCameraGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	request.CreatedBy = actorFromRequest(r).UserID
+
+	if request.ParentID != nil && !isUUID(*request.ParentID) {
+		respondError(w, http.StatusBadRequest, "Invalid parent_id")
+		return
+	}
+
+	group, err := h.groupUseCase.CreateGroup(r.Context(), &request)
+	if err != nil {
+		h.respondGroupError(w, err, "Failed to create camera group")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, group)
+}
+
+// ListGroups handles flat camera group list request
+// GET /api/v1/camera-groups
+func (h *CameraGroupHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.groupUseCase.ListGroups(r.Context())
+	if err != nil {
+		h.respondGroupError(w, err, "Failed to list camera groups")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"groups": groups,
+		"total":  len(groups),
+	})
+}
+
+// GetTree handles camera tree request
+// GET /api/v1/camera-groups/tree?include_cameras=true
+func (h *CameraGroupHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+	includeCameras := r.URL.Query().Get("include_cameras") == "true"
+
+	tree, err := h.groupUseCase.GetTree(r.Context(), includeCameras)
+	if err != nil {
+		h.respondGroupError(w, err, "Failed to get camera tree")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tree)
+}
+
+// GetGroup handles single camera group request
+// GET /api/v1/camera-groups/{id}
+func (h *CameraGroupHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	group, err := h.groupUseCase.GetGroup(r.Context(), id)
+	if err != nil {
+		h.respondGroupError(w, err, "Failed to get camera group")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+// UpdateGroup handles camera group update request
+// PUT /api/v1/camera-groups/{id}
+func (h *CameraGroupHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	var request domain.UpdateCameraGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if request.ParentID != nil && !isUUID(*request.ParentID) {
+		respondError(w, http.StatusBadRequest, "Invalid parent_id")
+		return
+	}
+
+	group, err := h.groupUseCase.UpdateGroup(r.Context(), id, &request)
+	if err != nil {
+		h.respondGroupError(w, err, "Failed to update camera group")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+// DeleteGroup handles camera group deletion request
+// DELETE /api/v1/camera-groups/{id}
+func (h *CameraGroupHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	if err := h.groupUseCase.DeleteGroup(r.Context(), id); err != nil {
+		h.respondGroupError(w, err, "Failed to delete camera group")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddCameras handles adding cameras to a group
+// POST /api/v1/camera-groups/{id}/cameras
+func (h *CameraGroupHandler) AddCameras(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	var request domain.CameraGroupMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response, err := h.groupUseCase.AddCameras(r.Context(), id, &request)
+	if err != nil {
+		h.respondGroupError(w, err, "Failed to add cameras to group")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// RemoveCamera handles removing a camera from a group
+// DELETE /api/v1/camera-groups/{id}/cameras/{cameraId}
+func (h *CameraGroupHandler) RemoveCamera(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid group ID")
+		return
+	}
+
+	if err := h.groupUseCase.RemoveCamera(r.Context(), id, chi.URLParam(r, "cameraId")); err != nil {
+		h.respondGroupError(w, err, "Failed to remove camera from group")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondGroupError maps camera group errors to HTTP status codes
+func (h *CameraGroupHandler) respondGroupError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCameraGroup):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrCameraGroupNotFound):
+		respondError(w, http.StatusNotFound, "Camera group not found")
+	case errors.Is(err, domain.ErrCameraNotFound):
+		respondError(w, http.StatusNotFound, "Camera not in group")
+	default:
+		h.logger.Error().Err(err).Msg(message)
+		respondError(w, http.StatusInternalServerError, message)
+	}
+}
+
+// isUUID reports whether s is a valid UUID
+func isUUID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil
+}
@@ -1,14 +1,23 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rta/cctv/go-api/internal/client"
 	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rta/cctv/go-api/internal/secrets"
 	"github.com/rs/zerolog"
 )
 
@@ -18,21 +27,36 @@ type CameraUsecase interface {
 	GetCamera(ctx context.Context, id string) (*domain.Camera, error)
 	ListCameras(ctx context.Context, query domain.CameraQuery) ([]*domain.Camera, error)
 	DeleteCamera(ctx context.Context, id string) error
+	UpdateCameraLocation(ctx context.Context, update *domain.CameraLocationUpdate) error
+	BulkUpdateLocations(ctx context.Context, req domain.BulkLocationUpdateRequest) (*domain.BulkLocationUpdateResponse, error)
+	GetCamerasGeoJSON(ctx context.Context, query domain.CameraQuery) (*domain.GeoJSONFeatureCollection, error)
+	ImportCameraFile(ctx context.Context, file io.Reader, opts domain.CameraImportOptions) (*domain.CameraImportReport, error)
+	ExportCameras(ctx context.Context, query domain.CameraQuery, format string, w io.Writer) error
+}
+
+// maxCameraFileBytes caps the size of an uploaded camera import file
+const maxCameraFileBytes = 10 << 20
+
+// SnapshotUsecase defines the interface for camera snapshot capture
+type SnapshotUsecase interface {
+	GetSnapshot(ctx context.Context, cameraID string) (*domain.Snapshot, error)
 }
 
 // CameraHandler handles camera-related HTTP requests
 type CameraHandler struct {
-	vmsClient     *client.VMSClient
-	cameraUsecase CameraUsecase
-	logger        zerolog.Logger
+	vmsClient       *client.VMSClient
+	cameraUsecase   CameraUsecase
+	snapshotUsecase SnapshotUsecase
+	logger          zerolog.Logger
 }
 
 // NewCameraHandler creates a new camera handler
-func NewCameraHandler(vmsClient *client.VMSClient, cameraUsecase CameraUsecase, logger zerolog.Logger) *CameraHandler {
+func NewCameraHandler(vmsClient *client.VMSClient, cameraUsecase CameraUsecase, snapshotUsecase SnapshotUsecase, logger zerolog.Logger) *CameraHandler {
 	return &CameraHandler{
-		vmsClient:     vmsClient,
-		cameraUsecase: cameraUsecase,
-		logger:        logger,
+		vmsClient:       vmsClient,
+		cameraUsecase:   cameraUsecase,
+		snapshotUsecase: snapshotUsecase,
+		logger:          logger,
 	}
 }
 
@@ -41,9 +65,10 @@ func NewCameraHandler(vmsClient *client.VMSClient, cameraUsecase CameraUsecase,
 func (h *CameraHandler) ListCameras(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	query := domain.CameraQuery{
-		Source: r.URL.Query().Get("source"),
-		Status: r.URL.Query().Get("status"),
-		Search: r.URL.Query().Get("search"),
+		Source:  r.URL.Query().Get("source"),
+		Status:  r.URL.Query().Get("status"),
+		Search:  r.URL.Query().Get("search"),
+		GroupID: r.URL.Query().Get("group_id"),
 	}
 
 	// Parse pagination
@@ -57,6 +82,18 @@ func (h *CameraHandler) ListCameras(w http.ResponseWriter, r *http.Request) {
 		fmt.Sscanf(offset, "%d", &query.Offset)
 	}
 
+	if query.GroupID != "" {
+		if !isUUID(query.GroupID) {
+			h.respondError(w, http.StatusBadRequest, "Invalid group_id")
+			return
+		}
+	}
+
+	if err := parseGeoQuery(r, &query); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	cameras, err := h.cameraUsecase.ListCameras(r.Context(), query)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to list cameras")
@@ -70,6 +107,99 @@ func (h *CameraHandler) ListCameras(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetCamerasGeoJSON handles map view request
+// GET /api/v1/cameras/geojson
+func (h *CameraHandler) GetCamerasGeoJSON(w http.ResponseWriter, r *http.Request) {
+	// Accepts the same filters as ListCameras, without pagination
+	query := domain.CameraQuery{
+		Source:  r.URL.Query().Get("source"),
+		Status:  r.URL.Query().Get("status"),
+		Search:  r.URL.Query().Get("search"),
+		GroupID: r.URL.Query().Get("group_id"),
+	}
+
+	if query.GroupID != "" {
+		if !isUUID(query.GroupID) {
+			h.respondError(w, http.StatusBadRequest, "Invalid group_id")
+			return
+		}
+	}
+
+	if err := parseGeoQuery(r, &query); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	collection, err := h.cameraUsecase.GetCamerasGeoJSON(r.Context(), query)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to build camera GeoJSON")
+		h.respondError(w, http.StatusInternalServerError, "Failed to list cameras")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(collection)
+}
+
+// UpdateCameraLocation handles camera coordinates update request
+// PUT /api/v1/cameras/{id}/location
+func (h *CameraHandler) UpdateCameraLocation(w http.ResponseWriter, r *http.Request) {
+	var update domain.CameraLocationUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	update.CameraID = chi.URLParam(r, "id")
+
+	err := h.cameraUsecase.UpdateCameraLocation(r.Context(), &update)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidLocation):
+			h.respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrCameraNotFound):
+			h.respondError(w, http.StatusNotFound, "Camera not found")
+		default:
+			h.logger.Error().Err(err).Str("camera_id", update.CameraID).Msg("Failed to update camera location")
+			h.respondError(w, http.StatusInternalServerError, "Failed to update camera location")
+		}
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, update)
+}
+
+// BulkUpdateLocations handles batch camera coordinates update request
+// PUT /api/v1/cameras/locations
+func (h *CameraHandler) BulkUpdateLocations(w http.ResponseWriter, r *http.Request) {
+	var req domain.BulkLocationUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(req.Locations) == 0 {
+		h.respondError(w, http.StatusBadRequest, "No locations to update")
+		return
+	}
+
+	response, err := h.cameraUsecase.BulkUpdateLocations(r.Context(), req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to bulk update camera locations")
+		h.respondError(w, http.StatusInternalServerError, "Failed to update camera locations")
+		return
+	}
+
+	// Return success even if some cameras failed
+	statusCode := http.StatusOK
+	if response.Failed > 0 && response.Updated == 0 {
+		statusCode = http.StatusBadRequest
+	}
+
+	h.respondJSON(w, statusCode, response)
+}
+
 // GetCamera handles single camera request
 // GET /api/v1/cameras/{id}
 func (h *CameraHandler) GetCamera(w http.ResponseWriter, r *http.Request) {
@@ -83,10 +213,14 @@ func (h *CameraHandler) GetCamera(w http.ResponseWriter, r *http.Request) {
 	camera, err := h.vmsClient.GetCamera(r.Context(), cameraID)
 	if err != nil {
 		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to get camera")
+		if respondUnavailable(w, err) {
+			return
+		}
 		h.respondError(w, http.StatusNotFound, "Camera not found")
 		return
 	}
 
+	camera.RTSPURL = secrets.MaskURL(camera.RTSPURL)
 	h.respondJSON(w, http.StatusOK, camera)
 }
 
@@ -111,48 +245,35 @@ func (h *CameraHandler) DeleteCamera(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// ControlPTZ handles PTZ control request
-// POST /api/v1/cameras/{id}/ptz
-func (h *CameraHandler) ControlPTZ(w http.ResponseWriter, r *http.Request) {
+// GetSnapshot handles camera snapshot request
+// GET /api/v1/cameras/{id}/snapshot
+func (h *CameraHandler) GetSnapshot(w http.ResponseWriter, r *http.Request) {
 	cameraID := chi.URLParam(r, "id")
 
-	var cmd domain.PTZCommand
-	if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid request body")
-		return
-	}
-
-	cmd.CameraID = cameraID
-
-	// Validate command
-	validCommands := map[string]bool{
-		"pan_left":  true,
-		"pan_right": true,
-		"tilt_up":   true,
-		"tilt_down": true,
-		"zoom_in":   true,
-		"zoom_out":  true,
-		"preset":    true,
-		"home":      true,
-		"stop":      true,
-	}
-
-	if !validCommands[cmd.Command] {
-		h.respondError(w, http.StatusBadRequest, "Invalid PTZ command")
+	if cameraID == "" {
+		h.respondError(w, http.StatusBadRequest, "camera_id is required")
 		return
 	}
 
-	err := h.vmsClient.ControlPTZ(r.Context(), cmd)
+	snapshot, err := h.snapshotUsecase.GetSnapshot(r.Context(), cameraID)
 	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to control PTZ")
-		h.respondError(w, http.StatusInternalServerError, "Failed to control PTZ")
+		if errors.Is(err, domain.ErrCameraNotFound) {
+			h.respondError(w, http.StatusNotFound, "Camera not found")
+			return
+		}
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to get snapshot")
+		h.respondError(w, http.StatusBadGateway, "Failed to capture snapshot")
 		return
 	}
 
-	h.respondJSON(w, http.StatusOK, map[string]string{
-		"status":  "success",
-		"message": "PTZ command executed",
-	})
+	w.Header().Set("Content-Type", snapshot.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(snapshot.Image)))
+	w.Header().Set("Cache-Control", "private, max-age=5")
+	w.Header().Set("Last-Modified", snapshot.CapturedAt.UTC().Format(http.TimeFormat))
+	w.Header().Set("X-Snapshot-Source", snapshot.Source)
+	w.Header().Set("X-Snapshot-Captured-At", snapshot.CapturedAt.UTC().Format(time.RFC3339))
+	w.WriteHeader(http.StatusOK)
+	w.Write(snapshot.Image)
 }
 
 // ImportCameras handles camera import request
@@ -190,8 +311,203 @@ func (h *CameraHandler) ImportCameras(w http.ResponseWriter, r *http.Request) {
 	h.respondJSON(w, statusCode, response)
 }
 
+// ImportCameraFile handles a CSV or JSON camera file upload
+// The body is the file itself (Content-Type text/csv or application/json) or a multipart
+// form with a "file" field. mode=dry_run (default) only validates; mode=commit writes the
+// valid rows; mode=atomic writes every row or none. check_reachability=false skips the RTSP probes.
+// POST /api/v1/cameras/import/file
+func (h *CameraHandler) ImportCameraFile(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCameraFileBytes)
+
+	opts := domain.CameraImportOptions{
+		Format:            r.URL.Query().Get("format"),
+		Mode:              r.URL.Query().Get("mode"),
+		CheckReachability: r.URL.Query().Get("check_reachability") != "false",
+	}
+	if opts.Mode == "" {
+		opts.Mode = domain.CameraImportDryRun
+	}
+
+	var file io.Reader = r.Body
+	contentType := r.Header.Get("Content-Type")
+
+	if strings.HasPrefix(contentType, "multipart/form-data") {
+		upload, header, err := r.FormFile("file")
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Missing file field")
+			return
+		}
+		defer upload.Close()
+		file = upload
+
+		if opts.Format == "" {
+			opts.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+		}
+	}
+
+	if opts.Format == "" {
+		switch {
+		case strings.Contains(contentType, "csv"):
+			opts.Format = domain.CameraFileCSV
+		case strings.Contains(contentType, "json"):
+			opts.Format = domain.CameraFileJSON
+		}
+	}
+
+	report, err := h.cameraUsecase.ImportCameraFile(r.Context(), file, opts)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCameraFile) {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error().Err(err).Str("mode", opts.Mode).Msg("Failed to import camera file")
+		h.respondError(w, http.StatusInternalServerError, "Failed to import camera file")
+		return
+	}
+
+	// An atomic import that was refused because of invalid rows
+	statusCode := http.StatusOK
+	if opts.Mode == domain.CameraImportAtomic && !report.Committed {
+		statusCode = http.StatusUnprocessableEntity
+	}
+
+	h.respondJSON(w, statusCode, report)
+}
+
+// ExportCameras handles camera export as a file that the import accepts
+// Accepts the ListCameras filters, without pagination
+// GET /api/v1/cameras/export?format=csv|json
+func (h *CameraHandler) ExportCameras(w http.ResponseWriter, r *http.Request) {
+	query := domain.CameraQuery{
+		Source:  r.URL.Query().Get("source"),
+		Status:  r.URL.Query().Get("status"),
+		Search:  r.URL.Query().Get("search"),
+		GroupID: r.URL.Query().Get("group_id"),
+	}
+
+	if query.GroupID != "" && !isUUID(query.GroupID) {
+		h.respondError(w, http.StatusBadRequest, "Invalid group_id")
+		return
+	}
+
+	if err := parseGeoQuery(r, &query); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = domain.CameraFileCSV
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	switch format {
+	case domain.CameraFileCSV:
+	case domain.CameraFileJSON:
+		contentType = "application/json"
+	default:
+		h.respondError(w, http.StatusBadRequest, "format must be csv or json")
+		return
+	}
+
+	// Buffered so a failure can still be reported as an error response
+	var file bytes.Buffer
+	if err := h.cameraUsecase.ExportCameras(r.Context(), query, format, &file); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to export cameras")
+		h.respondError(w, http.StatusInternalServerError, "Failed to export cameras")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="cameras-%s.%s"`, time.Now().UTC().Format("20060102-150405"), format))
+	w.WriteHeader(http.StatusOK)
+	w.Write(file.Bytes())
+}
+
 // Helper methods
 
+// parseGeoQuery parses geospatial filters into the camera query
+//   bbox=minLon,minLat,maxLon,maxLat       (GeoJSON bbox order)
+//   lat=..&lon=..&radius=..                (radius in meters)
+//   polygon=lon,lat;lon,lat;lon,lat;...    (at least 3 vertices)
+func parseGeoQuery(r *http.Request, query *domain.CameraQuery) error {
+	params := r.URL.Query()
+
+	if bbox := params.Get("bbox"); bbox != "" {
+		values, err := parseFloats(bbox, ",")
+		if err != nil || len(values) != 4 {
+			return fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat")
+		}
+		if !validPoint(values[1], values[0]) || !validPoint(values[3], values[2]) {
+			return fmt.Errorf("bbox latitudes must be within ±90 and longitudes within ±180")
+		}
+		query.BoundingBox = &domain.BoundingBox{
+			MinLongitude: values[0],
+			MinLatitude:  values[1],
+			MaxLongitude: values[2],
+			MaxLatitude:  values[3],
+		}
+	}
+
+	if radius := params.Get("radius"); radius != "" {
+		meters, err := strconv.ParseFloat(radius, 64)
+		if err != nil || math.IsNaN(meters) || math.IsInf(meters, 0) || meters <= 0 {
+			return fmt.Errorf("radius must be a positive number of meters")
+		}
+		lat, latErr := strconv.ParseFloat(params.Get("lat"), 64)
+		lon, lonErr := strconv.ParseFloat(params.Get("lon"), 64)
+		if latErr != nil || lonErr != nil {
+			return fmt.Errorf("lat and lon are required with radius")
+		}
+		if !validPoint(lat, lon) {
+			return fmt.Errorf("lat must be within ±90 and lon within ±180")
+		}
+		query.Radius = &domain.GeoRadius{
+			Center:       domain.GeoPoint{Latitude: lat, Longitude: lon},
+			RadiusMeters: meters,
+		}
+	}
+
+	if polygon := params.Get("polygon"); polygon != "" {
+		vertices := strings.Split(polygon, ";")
+		if len(vertices) < 3 {
+			return fmt.Errorf("polygon needs at least 3 vertices")
+		}
+		for _, vertex := range vertices {
+			values, err := parseFloats(vertex, ",")
+			if err != nil || len(values) != 2 {
+				return fmt.Errorf("polygon vertices must be lon,lat pairs separated by ';'")
+			}
+			if !validPoint(values[1], values[0]) {
+				return fmt.Errorf("polygon latitudes must be within ±90 and longitudes within ±180")
+			}
+			query.Polygon = append(query.Polygon, domain.GeoPoint{Longitude: values[0], Latitude: values[1]})
+		}
+	}
+
+	return nil
+}
+
+// validPoint reports whether lat and lon are finite WGS84 coordinates
+// NaN fails both comparisons, so it is rejected along with out of range values.
+func validPoint(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// parseFloats splits s by sep and parses each part as a float
+func parseFloats(s, sep string) ([]float64, error) {
+	parts := strings.Split(s, sep)
+	values := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
 func (h *CameraHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rta/cctv/go-api/internal/usecase"
+)
+
+// HealthHandler handles health check requests
+type HealthHandler struct {
+	healthUseCase *usecase.HealthUseCase
+	dependencies  []usecase.BreakerReporter
+}
+
+// NewHealthHandler creates a new health handler
+// dependencies are the downstream services whose breaker state /health reports
+func NewHealthHandler(healthUseCase *usecase.HealthUseCase, dependencies ...usecase.BreakerReporter) *HealthHandler {
+	return &HealthHandler{
+		healthUseCase: healthUseCase,
+		dependencies:  dependencies,
+	}
+}
+
+// Health handles liveness request with the breaker state of each dependency
+// Status is "degraded" while any breaker is not closed; the response stays 200 because
+// the process itself is alive and keeps serving what does not need that dependency
+// GET /health
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	status := "healthy"
+	dependencies := make([]domain.DependencyHealth, 0, len(h.dependencies))
+	for _, dependency := range h.dependencies {
+		health := dependency.Health()
+		if health.State != domain.BreakerClosed {
+			status = "degraded"
+		}
+		dependencies = append(dependencies, health)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":       status,
+		"dependencies": dependencies,
+	})
+}
+
+// Live handles liveness request; it only says the process is serving HTTP
+// GET /health/live
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, map[string]string{"status": "alive"})
+}
+
+// Ready handles readiness request, checking every dependency
+// Returns 503 when the instance should be taken out of rotation, 200 when healthy or degraded
+// GET /health/ready
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.healthUseCase.Readiness(r.Context())
+
+	status := http.StatusOK
+	if report.Status == domain.HealthUnhealthy {
+		status = http.StatusServiceUnavailable
+	}
+
+	respondJSON(w, status, report)
+}
+
+// respondUnavailable answers 503 when err comes from a dependency whose circuit breaker is open
+// Returns false for any other error
+func respondUnavailable(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, domain.ErrDependencyUnavailable) {
+		return false
+	}
+
+	w.Header().Set("Retry-After", "30")
+	respondError(w, http.StatusServiceUnavailable, err.Error())
+	return true
+}
@@ -2,7 +2,11 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rta/cctv/go-api/internal/domain"
@@ -35,10 +39,47 @@ func (h *LayoutHandler) CreateLayout(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.Info().Str("name", request.Name).Str("layout_type", string(request.LayoutType)).Str("grid_layout", request.GridLayout).Msg("Received create layout request")
 
+	// The caller owns the layout; an owner named in the body is ignored
+	request.CreatedBy = actorFromRequest(r).UserID
+
 	layout, err := h.layoutUseCase.CreateLayout(&request)
 	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to create layout")
-		respondError(w, http.StatusInternalServerError, "Failed to create layout")
+		h.respondLayoutError(w, err, "", "Failed to create layout")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, layout)
+}
+
+// CreateLayoutFromGroup handles layout creation from a camera group
+// POST /api/v1/layouts/from-group
+func (h *LayoutHandler) CreateLayoutFromGroup(w http.ResponseWriter, r *http.Request) {
+	var request domain.CreateLayoutFromGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode create layout from group request")
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if !isUUID(request.GroupID) {
+		respondError(w, http.StatusBadRequest, "Invalid group_id")
+		return
+	}
+	request.CreatedBy = actorFromRequest(r).UserID
+
+	layout, err := h.layoutUseCase.CreateLayoutFromGroup(r.Context(), &request)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCameraGroupNotFound):
+			respondError(w, http.StatusNotFound, "Camera group not found")
+		case errors.Is(err, domain.ErrInvalidCameraGroup), errors.Is(err, domain.ErrInvalidLayout):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrLayoutForbidden):
+			respondError(w, http.StatusForbidden, err.Error())
+		default:
+			h.logger.Error().Err(err).Str("group_id", request.GroupID).Msg("Failed to create layout from group")
+			respondError(w, http.StatusInternalServerError, "Failed to create layout")
+		}
 		return
 	}
 
@@ -54,13 +95,13 @@ func (h *LayoutHandler) GetLayout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	layout, err := h.layoutUseCase.GetLayout(id)
+	layout, err := h.layoutUseCase.GetLayout(id, actorFromRequest(r))
 	if err != nil {
-		h.logger.Error().Err(err).Str("layout_id", id).Msg("Failed to get layout")
-		respondError(w, http.StatusNotFound, "Layout not found")
+		h.respondLayoutError(w, err, id, "Failed to get layout")
 		return
 	}
 
+	setLayoutETag(w, layout.Version)
 	respondJSON(w, http.StatusOK, layout)
 }
 
@@ -86,7 +127,7 @@ func (h *LayoutHandler) ListLayouts(w http.ResponseWriter, r *http.Request) {
 		createdBy = &cb
 	}
 
-	response, err := h.layoutUseCase.ListLayouts(layoutType, scope, createdBy)
+	response, err := h.layoutUseCase.ListLayouts(layoutType, scope, createdBy, actorFromRequest(r))
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to list layouts")
 		respondError(w, http.StatusInternalServerError, "Failed to list layouts")
@@ -97,6 +138,7 @@ func (h *LayoutHandler) ListLayouts(w http.ResponseWriter, r *http.Request) {
 }
 
 // UpdateLayout handles layout update request
+// The version being edited comes from the body or the If-Match header
 // PUT /api/v1/layouts/:id
 func (h *LayoutHandler) UpdateLayout(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
@@ -112,13 +154,17 @@ func (h *LayoutHandler) UpdateLayout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	layout, err := h.layoutUseCase.UpdateLayout(id, &request)
+	if request.Version == 0 {
+		request.Version = parseIfMatch(r)
+	}
+
+	layout, err := h.layoutUseCase.UpdateLayout(id, &request, actorFromRequest(r))
 	if err != nil {
-		h.logger.Error().Err(err).Str("layout_id", id).Msg("Failed to update layout")
-		respondError(w, http.StatusInternalServerError, "Failed to update layout")
+		h.respondLayoutError(w, err, id, "Failed to update layout")
 		return
 	}
 
+	setLayoutETag(w, layout.Version)
 	respondJSON(w, http.StatusOK, layout)
 }
 
@@ -131,22 +177,136 @@ func (h *LayoutHandler) DeleteLayout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.layoutUseCase.DeleteLayout(id); err != nil {
-		// Check if it's a "not found" error
-		if err.Error() == "layout not found: "+id ||
-		   err.Error() == "failed to delete layout: layout not found: "+id {
-			h.logger.Warn().Str("layout_id", id).Msg("Layout not found for deletion")
-			respondError(w, http.StatusNotFound, "Layout not found")
-			return
-		}
-		h.logger.Error().Err(err).Str("layout_id", id).Msg("Failed to delete layout")
-		respondError(w, http.StatusInternalServerError, "Failed to delete layout")
+	if err := h.layoutUseCase.DeleteLayout(id, actorFromRequest(r)); err != nil {
+		h.respondLayoutError(w, err, id, "Failed to delete layout")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetShares handles layout shares request
+// GET /api/v1/layouts/:id/shares
+func (h *LayoutHandler) GetShares(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	shares, err := h.layoutUseCase.GetLayoutShares(id, actorFromRequest(r))
+	if err != nil {
+		h.respondLayoutError(w, err, id, "Failed to get layout shares")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"shares": shares,
+		"total":  len(shares),
+	})
+}
+
+// ShareLayout handles replacing the shares of a layout
+// PUT /api/v1/layouts/:id/shares
+func (h *LayoutHandler) ShareLayout(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var request domain.ShareLayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	shares, err := h.layoutUseCase.ShareLayout(id, &request, actorFromRequest(r))
+	if err != nil {
+		h.respondLayoutError(w, err, id, "Failed to share layout")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"shares": shares,
+		"total":  len(shares),
+	})
+}
+
+// ListVersions handles layout version history request
+// GET /api/v1/layouts/:id/versions
+func (h *LayoutHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	versions, err := h.layoutUseCase.ListLayoutVersions(id, actorFromRequest(r))
+	if err != nil {
+		h.respondLayoutError(w, err, id, "Failed to list layout versions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"versions": versions,
+		"total":    len(versions),
+	})
+}
+
+// RevertLayout handles restoring an earlier layout version
+// POST /api/v1/layouts/:id/revert
+func (h *LayoutHandler) RevertLayout(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var request domain.RevertLayoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if request.Version == 0 {
+		request.Version = parseIfMatch(r)
+	}
+
+	layout, err := h.layoutUseCase.RevertLayout(id, &request, actorFromRequest(r))
+	if err != nil {
+		h.respondLayoutError(w, err, id, "Failed to revert layout")
+		return
+	}
+
+	setLayoutETag(w, layout.Version)
+	respondJSON(w, http.StatusOK, layout)
+}
+
+// respondLayoutError maps layout errors to HTTP status codes
+func (h *LayoutHandler) respondLayoutError(w http.ResponseWriter, err error, id, message string) {
+	switch {
+	case errors.Is(err, domain.ErrLayoutNotFound):
+		respondError(w, http.StatusNotFound, "Layout not found")
+	case errors.Is(err, domain.ErrLayoutVersionNotFound):
+		respondError(w, http.StatusNotFound, "Layout version not found")
+	case errors.Is(err, domain.ErrInvalidLayout):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrLayoutForbidden):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrLayoutVersionConflict):
+		respondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrLayoutVersionRequired):
+		respondError(w, http.StatusPreconditionRequired, "Layout version is required (body version or If-Match header)")
+	default:
+		h.logger.Error().Err(err).Str("layout_id", id).Msg(message)
+		respondError(w, http.StatusInternalServerError, message)
+	}
+}
+
+// setLayoutETag sets the ETag header to the layout version
+func setLayoutETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", fmt.Sprintf("%q", strconv.Itoa(version)))
+}
+
+// parseIfMatch returns the layout version from an If-Match header ("3", W/"3" or 3)
+// Returns 0 when the header is missing or not a version
+func parseIfMatch(r *http.Request) int {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	tag = strings.TrimPrefix(tag, "W/")
+	tag = strings.Trim(tag, `"`)
+
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 0 {
+		return 0
+	}
+	return version
+}
+
 // Helper functions for consistent response format
 
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
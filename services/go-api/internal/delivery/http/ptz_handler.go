@@ -0,0 +1,347 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// PTZUsecase defines the interface for PTZ control and camera locking
+type PTZUsecase interface {
+	Control(ctx context.Context, cmd domain.PTZCommand, actor *domain.Actor) (*domain.PTZLock, error)
+	AcquireLock(ctx context.Context, cameraID string, actor *domain.Actor) (*domain.PTZLock, error)
+	ReleaseLock(ctx context.Context, cameraID string, actor *domain.Actor) error
+	GetLock(ctx context.Context, cameraID string) (*domain.PTZLock, error)
+	ListHistory(ctx context.Context, query domain.PTZHistoryQuery) ([]*domain.PTZCommandRecord, error)
+	GetStatus(ctx context.Context, cameraID string) (*domain.PTZStatus, error)
+	ListPresets(ctx context.Context, cameraID string, refresh bool) ([]*domain.PTZPreset, error)
+	SavePreset(ctx context.Context, cameraID string, request *domain.SavePTZPresetRequest, actor *domain.Actor) (*domain.PTZPreset, error)
+	UpdatePreset(ctx context.Context, cameraID string, number int, request *domain.SavePTZPresetRequest, actor *domain.Actor) (*domain.PTZPreset, error)
+	DeletePreset(ctx context.Context, cameraID string, number int, actor *domain.Actor) error
+	GetHomePolicy(ctx context.Context, cameraID string) (*domain.PTZHomePolicy, error)
+	SetHomePolicy(ctx context.Context, cameraID string, request *domain.SetPTZHomePolicyRequest) (*domain.PTZHomePolicy, error)
+	DeleteHomePolicy(ctx context.Context, cameraID string) error
+}
+
+// validPTZCommands are the commands accepted by ControlPTZ
+var validPTZCommands = map[string]bool{
+	"pan_left":  true,
+	"pan_right": true,
+	"tilt_up":   true,
+	"tilt_down": true,
+	"zoom_in":   true,
+	"zoom_out":  true,
+	"preset":    true,
+	"home":      true,
+	"stop":      true,
+	"absolute":  true,
+	"relative":  true,
+	"center":    true,
+}
+
+// PTZHandler handles PTZ control HTTP requests
+type PTZHandler struct {
+	ptzUsecase PTZUsecase
+	logger     zerolog.Logger
+}
+
+// NewPTZHandler creates a new PTZ handler
+func NewPTZHandler(ptzUsecase PTZUsecase, logger zerolog.Logger) *PTZHandler {
+	return &PTZHandler{
+		ptzUsecase: ptzUsecase,
+		logger:     logger,
+	}
+}
+
+// ControlPTZ handles PTZ control request; the caller takes or renews the camera's PTZ lock
+// POST /api/v1/cameras/{id}/ptz
+func (h *PTZHandler) ControlPTZ(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+
+	var cmd domain.PTZCommand
+	if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	cmd.CameraID = cameraID
+
+	if !validPTZCommands[cmd.Command] {
+		respondError(w, http.StatusBadRequest, "Invalid PTZ command")
+		return
+	}
+
+	lock, err := h.ptzUsecase.Control(r.Context(), cmd, actorFromRequest(r))
+	if err != nil {
+		h.respondPTZError(w, err, "Failed to control PTZ")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "success",
+		"message": "PTZ command executed",
+		"lock":    lock,
+	})
+}
+
+// GetLock handles PTZ lock status request
+// GET /api/v1/cameras/{id}/ptz/lock
+func (h *PTZHandler) GetLock(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+
+	lock, err := h.ptzUsecase.GetLock(r.Context(), cameraID)
+	if err != nil {
+		h.respondPTZError(w, err, "Failed to get PTZ lock")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"camera_id": cameraID,
+		"locked":    lock != nil,
+		"lock":      lock,
+	})
+}
+
+// AcquireLock handles PTZ lock request; renews the lease when the caller already holds it
+// POST /api/v1/cameras/{id}/ptz/lock
+func (h *PTZHandler) AcquireLock(w http.ResponseWriter, r *http.Request) {
+	lock, err := h.ptzUsecase.AcquireLock(r.Context(), chi.URLParam(r, "id"), actorFromRequest(r))
+	if err != nil {
+		h.respondPTZError(w, err, "Failed to acquire PTZ lock")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, lock)
+}
+
+// ReleaseLock handles PTZ lock release request
+// DELETE /api/v1/cameras/{id}/ptz/lock
+func (h *PTZHandler) ReleaseLock(w http.ResponseWriter, r *http.Request) {
+	if err := h.ptzUsecase.ReleaseLock(r.Context(), chi.URLParam(r, "id"), actorFromRequest(r)); err != nil {
+		h.respondPTZError(w, err, "Failed to release PTZ lock")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListHistory handles PTZ command history request
+// GET /api/v1/ptz/history?camera_id=&user_id=&from=&to=
+func (h *PTZHandler) ListHistory(w http.ResponseWriter, r *http.Request) {
+	query := domain.PTZHistoryQuery{
+		CameraID: r.URL.Query().Get("camera_id"),
+		UserID:   r.URL.Query().Get("user_id"),
+	}
+
+	if from := r.URL.Query().Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+			return
+		}
+		query.From = &t
+	}
+
+	if to := r.URL.Query().Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+		query.To = &t
+	}
+
+	// Parse pagination
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		fmt.Sscanf(limit, "%d", &query.Limit)
+	} else {
+		query.Limit = 100 // Default
+	}
+
+	if offset := r.URL.Query().Get("offset"); offset != "" {
+		fmt.Sscanf(offset, "%d", &query.Offset)
+	}
+
+	records, err := h.ptzUsecase.ListHistory(r.Context(), query)
+	if err != nil {
+		h.respondPTZError(w, err, "Failed to list PTZ history")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"commands": records,
+		"count":    len(records),
+	})
+}
+
+// GetStatus handles PTZ position request
+// GET /api/v1/cameras/{id}/ptz/status
+func (h *PTZHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.ptzUsecase.GetStatus(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondPTZError(w, err, "Failed to get PTZ status")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
+
+// ListPresets handles PTZ preset list request
+// GET /api/v1/cameras/{id}/ptz/presets?refresh=true
+func (h *PTZHandler) ListPresets(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+
+	presets, err := h.ptzUsecase.ListPresets(r.Context(), cameraID, r.URL.Query().Get("refresh") == "true")
+	if err != nil {
+		h.respondPTZError(w, err, "Failed to list PTZ presets")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"camera_id": cameraID,
+		"presets":   presets,
+		"count":     len(presets),
+	})
+}
+
+// SavePreset handles request to save the camera's current position as a named preset
+// POST /api/v1/cameras/{id}/ptz/presets
+func (h *PTZHandler) SavePreset(w http.ResponseWriter, r *http.Request) {
+	var request domain.SavePTZPresetRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	preset, err := h.ptzUsecase.SavePreset(r.Context(), chi.URLParam(r, "id"), &request, actorFromRequest(r))
+	if err != nil {
+		h.respondPTZError(w, err, "Failed to save PTZ preset")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, preset)
+}
+
+// UpdatePreset handles preset rename request; save_position also stores the current position
+// PUT /api/v1/cameras/{id}/ptz/presets/{number}
+func (h *PTZHandler) UpdatePreset(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid preset number")
+		return
+	}
+
+	var request domain.SavePTZPresetRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	preset, err := h.ptzUsecase.UpdatePreset(r.Context(), chi.URLParam(r, "id"), number, &request, actorFromRequest(r))
+	if err != nil {
+		h.respondPTZError(w, err, "Failed to update PTZ preset")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, preset)
+}
+
+// DeletePreset handles preset removal request
+// DELETE /api/v1/cameras/{id}/ptz/presets/{number}
+func (h *PTZHandler) DeletePreset(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid preset number")
+		return
+	}
+
+	if err := h.ptzUsecase.DeletePreset(r.Context(), chi.URLParam(r, "id"), number, actorFromRequest(r)); err != nil {
+		h.respondPTZError(w, err, "Failed to delete PTZ preset")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetHomePolicy handles return-to-home policy request
+// GET /api/v1/cameras/{id}/ptz/home-policy
+func (h *PTZHandler) GetHomePolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.ptzUsecase.GetHomePolicy(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondPTZError(w, err, "Failed to get PTZ home policy")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+// SetHomePolicy handles return-to-home policy update request
+// PUT /api/v1/cameras/{id}/ptz/home-policy
+func (h *PTZHandler) SetHomePolicy(w http.ResponseWriter, r *http.Request) {
+	var request domain.SetPTZHomePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	request.UpdatedBy = actorFromRequest(r).UserID
+
+	policy, err := h.ptzUsecase.SetHomePolicy(r.Context(), chi.URLParam(r, "id"), &request)
+	if err != nil {
+		h.respondPTZError(w, err, "Failed to save PTZ home policy")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+// DeleteHomePolicy handles return-to-home policy removal request
+// DELETE /api/v1/cameras/{id}/ptz/home-policy
+func (h *PTZHandler) DeleteHomePolicy(w http.ResponseWriter, r *http.Request) {
+	if err := h.ptzUsecase.DeleteHomePolicy(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.respondPTZError(w, err, "Failed to delete PTZ home policy")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondPTZError maps PTZ errors to HTTP status codes
+// 423 Locked carries the holder so the console can show who is driving the camera
+func (h *PTZHandler) respondPTZError(w http.ResponseWriter, err error, message string) {
+	var locked *domain.PTZLockedError
+	switch {
+	case errors.As(err, &locked):
+		respondJSON(w, http.StatusLocked, map[string]interface{}{
+			"error":  domain.ErrPTZLocked.Error(),
+			"code":   "PTZ_LOCKED",
+			"holder": locked.Holder,
+		})
+	case errors.Is(err, domain.ErrInvalidPTZRequest):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrPTZHomePolicyNotFound):
+		respondError(w, http.StatusNotFound, "PTZ home policy not found")
+	case errors.Is(err, domain.ErrPTZPresetNotFound):
+		respondError(w, http.StatusNotFound, "PTZ preset not found")
+	case errors.Is(err, domain.ErrPTZPresetExists):
+		respondError(w, http.StatusConflict, "A preset with this number or name already exists")
+	case errors.Is(err, domain.ErrPTZCommandFailed):
+		h.logger.Warn().Err(err).Msg(message)
+		respondError(w, http.StatusBadGateway, "Camera did not carry out the PTZ request")
+	case errors.Is(err, domain.ErrCameraNotFound):
+		respondError(w, http.StatusNotFound, "Camera not found")
+	default:
+		h.logger.Error().Err(err).Msg(message)
+		if respondUnavailable(w, err) {
+			return
+		}
+		respondError(w, http.StatusInternalServerError, message)
+	}
+}
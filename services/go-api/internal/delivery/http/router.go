@@ -12,10 +12,16 @@ import (
 
 // Router creates the HTTP router
 func NewRouter(
+	healthHandler *HealthHandler,
 	streamHandler *StreamHandler,
 	cameraHandler *CameraHandler,
+	ptzHandler *PTZHandler,
 	wsHandler *wsDelivery.Handler,
 	layoutHandler *LayoutHandler,
+	cameraGroupHandler *CameraGroupHandler,
+	tourHandler *TourHandler,
+	videoWallHandler *VideoWallHandler,
+	shareLinkHandler *ShareLinkHandler,
 ) *chi.Mux {
 	r := chi.NewRouter()
 
@@ -31,7 +37,8 @@ func NewRouter(
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Authorization, If-Match, X-User-ID, X-User-Groups")
+			w.Header().Set("Access-Control-Expose-Headers", "ETag")
 
 			// Handle preflight OPTIONS request
 			if r.Method == "OPTIONS" {
@@ -44,15 +51,14 @@ func NewRouter(
 	})
 
 	// Health and metrics
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(200)
-		w.Write([]byte(`{"status":"healthy"}`))
-	})
+	r.Get("/health", healthHandler.Health)
+	r.Get("/health/live", healthHandler.Live)
+	r.Get("/health/ready", healthHandler.Ready)
 	r.Handle("/metrics", promhttp.Handler())
 
 	// WebSocket endpoint
 	r.Get("/ws/stream/stats", wsHandler.ServeWS)
+	r.Get("/ws/video-wall", wsHandler.ServeWallDisplay) // ?monitor_id=
 
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
@@ -62,25 +68,101 @@ func NewRouter(
 			r.Delete("/release/{id}", streamHandler.ReleaseStream)
 			r.Post("/heartbeat/{id}", streamHandler.SendHeartbeat)
 			r.Get("/stats", streamHandler.GetStreamStats)
+			r.Get("/sessions", streamHandler.ListSessions)
 		})
 
 		// Camera management
 		r.Route("/cameras", func(r chi.Router) {
 			r.Get("/", cameraHandler.ListCameras)
 			r.Post("/import", cameraHandler.ImportCameras)
+			r.Post("/import/file", cameraHandler.ImportCameraFile)
+			r.Get("/export", cameraHandler.ExportCameras)
+			r.Get("/geojson", cameraHandler.GetCamerasGeoJSON)
+			r.Put("/locations", cameraHandler.BulkUpdateLocations)
 			r.Get("/{id}", cameraHandler.GetCamera)
+			r.Get("/{id}/snapshot", cameraHandler.GetSnapshot)
+			r.Put("/{id}/location", cameraHandler.UpdateCameraLocation)
 			r.Delete("/{id}", cameraHandler.DeleteCamera)
-			r.Post("/{id}/ptz", cameraHandler.ControlPTZ)
+			r.Post("/{id}/ptz", ptzHandler.ControlPTZ)
+			r.Get("/{id}/ptz/lock", ptzHandler.GetLock)
+			r.Post("/{id}/ptz/lock", ptzHandler.AcquireLock)
+			r.Delete("/{id}/ptz/lock", ptzHandler.ReleaseLock)
+			r.Get("/{id}/ptz/status", ptzHandler.GetStatus)
+			r.Get("/{id}/ptz/presets", ptzHandler.ListPresets)
+			r.Post("/{id}/ptz/presets", ptzHandler.SavePreset)
+			r.Put("/{id}/ptz/presets/{number}", ptzHandler.UpdatePreset)
+			r.Delete("/{id}/ptz/presets/{number}", ptzHandler.DeletePreset)
+			r.Get("/{id}/ptz/home-policy", ptzHandler.GetHomePolicy)
+			r.Put("/{id}/ptz/home-policy", ptzHandler.SetHomePolicy)
+			r.Delete("/{id}/ptz/home-policy", ptzHandler.DeleteHomePolicy)
+			r.Get("/{id}/viewers", streamHandler.ListViewers)
+			r.Delete("/{id}/viewers/{identity}", streamHandler.EvictViewer)
 		})
 
+		// PTZ movement history (who moved which camera, and when)
+		r.Get("/ptz/history", ptzHandler.ListHistory)
+
 		// Layout management
 		r.Route("/layouts", func(r chi.Router) {
 			r.Post("/", layoutHandler.CreateLayout)
+			r.Post("/from-group", layoutHandler.CreateLayoutFromGroup)
 			r.Get("/", layoutHandler.ListLayouts)
 			r.Get("/{id}", layoutHandler.GetLayout)
 			r.Put("/{id}", layoutHandler.UpdateLayout)
 			r.Delete("/{id}", layoutHandler.DeleteLayout)
+			r.Get("/{id}/shares", layoutHandler.GetShares)
+			r.Put("/{id}/shares", layoutHandler.ShareLayout)
+			r.Get("/{id}/versions", layoutHandler.ListVersions)
+			r.Post("/{id}/revert", layoutHandler.RevertLayout)
+		})
+
+		// Camera groups (hierarchical camera tree)
+		r.Route("/camera-groups", func(r chi.Router) {
+			r.Post("/", cameraGroupHandler.CreateGroup)
+			r.Get("/", cameraGroupHandler.ListGroups)
+			r.Get("/tree", cameraGroupHandler.GetTree)
+			r.Get("/{id}", cameraGroupHandler.GetGroup)
+			r.Put("/{id}", cameraGroupHandler.UpdateGroup)
+			r.Delete("/{id}", cameraGroupHandler.DeleteGroup)
+			r.Post("/{id}/cameras", cameraGroupHandler.AddCameras)
+			r.Delete("/{id}/cameras/{cameraId}", cameraGroupHandler.RemoveCamera)
+		})
+
+		// Camera tours (timed camera rotation inside layout cells)
+		r.Route("/tours", func(r chi.Router) {
+			r.Post("/", tourHandler.CreateTour)
+			r.Get("/", tourHandler.ListTours)
+			r.Get("/runs/{runId}", tourHandler.GetRun)
+			r.Post("/runs/{runId}/keepalive", tourHandler.KeepAlive)
+			r.Delete("/runs/{runId}", tourHandler.StopRun)
+			r.Get("/{id}", tourHandler.GetTour)
+			r.Put("/{id}", tourHandler.UpdateTour)
+			r.Delete("/{id}", tourHandler.DeleteTour)
+			r.Post("/{id}/runs", tourHandler.StartRun)
+		})
+
+		// Video walls (control room monitors driven by display clients)
+		r.Route("/video-walls", func(r chi.Router) {
+			r.Post("/", videoWallHandler.CreateWall)
+			r.Get("/", videoWallHandler.ListWalls)
+			r.Get("/{id}", videoWallHandler.GetWall)
+			r.Put("/{id}", videoWallHandler.UpdateWall)
+			r.Delete("/{id}", videoWallHandler.DeleteWall)
+			r.Put("/{id}/display", videoWallHandler.DisplayBatch)
+			r.Post("/{id}/monitors", videoWallHandler.AddMonitor)
+			r.Put("/{id}/monitors/{monitorId}", videoWallHandler.UpdateMonitor)
+			r.Delete("/{id}/monitors/{monitorId}", videoWallHandler.DeleteMonitor)
+			r.Put("/{id}/monitors/{monitorId}/display", videoWallHandler.Display)
+		})
+
+		// Guest share links (temporary live view of one camera for partners without an account)
+		r.Route("/share-links", func(r chi.Router) {
+			r.Post("/", shareLinkHandler.CreateLink)
+			r.Get("/", shareLinkHandler.ListLinks)
+			r.Get("/{id}", shareLinkHandler.GetLink)
+			r.Delete("/{id}", shareLinkHandler.RevokeLink)
 		})
+		r.Post("/guest/streams", shareLinkHandler.Redeem)
 	})
 
 	return r
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// ShareLinkHandler handles guest share link HTTP requests
+type ShareLinkHandler struct {
+	linkUseCase domain.ShareLinkUseCase
+	logger      zerolog.Logger
+}
+
+// NewShareLinkHandler creates a new share link handler
+func NewShareLinkHandler(linkUseCase domain.ShareLinkUseCase, logger zerolog.Logger) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		linkUseCase: linkUseCase,
+		logger:      logger,
+	}
+}
+
+// CreateLink handles share link creation request; the token is only returned here
+// POST /api/v1/share-links
+func (h *ShareLinkHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	var request domain.CreateShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// The caller is the audited creator; it cannot be named in the body
+	request.CreatedBy = actorFromRequest(r).UserID
+
+	link, err := h.linkUseCase.CreateLink(r.Context(), &request)
+	if err != nil {
+		h.respondShareLinkError(w, err, "Failed to create share link")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, link)
+}
+
+// ListLinks handles share link list request
+// GET /api/v1/share-links?camera_id=
+func (h *ShareLinkHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.linkUseCase.ListLinks(r.Context(), r.URL.Query().Get("camera_id"))
+	if err != nil {
+		h.respondShareLinkError(w, err, "Failed to list share links")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"share_links": links,
+		"total":       len(links),
+	})
+}
+
+// GetLink handles single share link request
+// GET /api/v1/share-links/{id}
+func (h *ShareLinkHandler) GetLink(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid share link ID")
+		return
+	}
+
+	link, err := h.linkUseCase.GetLink(r.Context(), id)
+	if err != nil {
+		h.respondShareLinkError(w, err, "Failed to get share link")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, link)
+}
+
+// RevokeLink handles share link revocation request; guests watching are disconnected
+// DELETE /api/v1/share-links/{id}
+func (h *ShareLinkHandler) RevokeLink(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid share link ID")
+		return
+	}
+
+	// The body is optional
+	var request domain.RevokeShareLinkRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	request.ActorID = actorFromRequest(r).UserID
+
+	link, err := h.linkUseCase.RevokeLink(r.Context(), id, &request)
+	if err != nil {
+		h.respondShareLinkError(w, err, "Failed to revoke share link")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, link)
+}
+
+// Redeem handles a guest opening a share link; no user account is needed
+// Returns a view-only LiveKit token, like a stream reservation
+// POST /api/v1/guest/streams
+func (h *ShareLinkHandler) Redeem(w http.ResponseWriter, r *http.Request) {
+	var request domain.RedeemShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if request.Token == "" {
+		respondError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	// RealIP middleware has already rewritten RemoteAddr from X-Real-IP / X-Forwarded-For
+	request.ClientIP = clientIP(r)
+
+	response, err := h.linkUseCase.Redeem(r.Context(), &request)
+	if err != nil {
+		h.respondShareLinkError(w, err, "Failed to start guest stream")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, response)
+}
+
+// respondShareLinkError maps share link errors to HTTP status codes
+func (h *ShareLinkHandler) respondShareLinkError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidShareLink):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrShareLinkNotFound):
+		respondError(w, http.StatusNotFound, "Share link not found")
+	case errors.Is(err, domain.ErrCameraNotFound):
+		respondError(w, http.StatusNotFound, "Camera not found")
+	case errors.Is(err, domain.ErrShareLinkPIN):
+		respondError(w, http.StatusUnauthorized, domain.ErrShareLinkPIN.Error())
+	case errors.Is(err, domain.ErrShareLinkUnavailable):
+		respondError(w, http.StatusGone, domain.ErrShareLinkUnavailable.Error())
+	case errors.Is(err, domain.ErrShareLinkFull):
+		respondError(w, http.StatusConflict, domain.ErrShareLinkFull.Error())
+	default:
+		h.logger.Error().Err(err).Msg(message)
+		if respondUnavailable(w, err) {
+			return
+		}
+		respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -2,8 +2,12 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rta/cctv/go-api/internal/domain"
@@ -40,6 +44,9 @@ func (h *StreamHandler) RequestStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// RealIP middleware has already rewritten RemoteAddr from X-Real-IP / X-Forwarded-For
+	req.ClientIP = clientIP(r)
+
 	// Request stream
 	response, err := h.streamUseCase.RequestStream(r.Context(), req)
 	if err != nil {
@@ -58,6 +65,11 @@ func (h *StreamHandler) RequestStream(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if respondUnavailable(w, err) {
+			h.logger.Warn().Err(err).Msg("Stream request rejected, dependency unavailable")
+			return
+		}
+
 		h.logger.Error().Err(err).Msg("Failed to request stream")
 		h.respondError(w, http.StatusInternalServerError, "Failed to request stream")
 		return
@@ -124,6 +136,122 @@ func (h *StreamHandler) GetStreamStats(w http.ResponseWriter, r *http.Request) {
 	h.respondJSON(w, http.StatusOK, stats)
 }
 
+// ListSessions handles viewing session history request
+// GET /api/v1/stream/sessions?camera_id=&user_id=&from=&to=
+func (h *StreamHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	query := domain.StreamSessionQuery{
+		CameraID: r.URL.Query().Get("camera_id"),
+		UserID:   r.URL.Query().Get("user_id"),
+	}
+
+	if from := r.URL.Query().Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+			return
+		}
+		query.From = &t
+	}
+
+	if to := r.URL.Query().Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+			return
+		}
+		query.To = &t
+	}
+
+	// Parse pagination
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		fmt.Sscanf(limit, "%d", &query.Limit)
+	} else {
+		query.Limit = 100 // Default
+	}
+
+	if offset := r.URL.Query().Get("offset"); offset != "" {
+		fmt.Sscanf(offset, "%d", &query.Offset)
+	}
+
+	sessions, err := h.streamUseCase.ListSessions(r.Context(), query)
+	if errors.Is(err, domain.ErrInvalidSessionQuery) {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list stream sessions")
+		h.respondError(w, http.StatusInternalServerError, "Failed to list stream sessions")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// ListViewers handles camera viewer list request
+// GET /api/v1/cameras/{id}/viewers
+func (h *StreamHandler) ListViewers(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+
+	if cameraID == "" {
+		h.respondError(w, http.StatusBadRequest, "camera_id is required")
+		return
+	}
+
+	viewers, err := h.streamUseCase.ListViewers(r.Context(), cameraID)
+	if err != nil {
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to list viewers")
+		h.respondError(w, http.StatusInternalServerError, "Failed to list viewers")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"camera_id": cameraID,
+		"viewers":   viewers,
+		"count":     len(viewers),
+	})
+}
+
+// EvictViewer handles viewer eviction request
+// DELETE /api/v1/cameras/{id}/viewers/{identity}
+func (h *StreamHandler) EvictViewer(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+	identity := chi.URLParam(r, "identity")
+
+	if cameraID == "" || identity == "" {
+		h.respondError(w, http.StatusBadRequest, "camera_id and identity are required")
+		return
+	}
+
+	var req domain.EvictViewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	err := h.streamUseCase.EvictViewer(r.Context(), cameraID, identity, actorFromRequest(r), req)
+	if err != nil {
+		if errors.Is(err, domain.ErrEvictForbidden) {
+			h.respondError(w, http.StatusForbidden, "Evicting viewers requires a supervisor")
+			return
+		}
+		if errors.Is(err, domain.ErrViewerNotFound) {
+			h.respondError(w, http.StatusNotFound, "Viewer not found")
+			return
+		}
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Str("identity", identity).Msg("Failed to evict viewer")
+		h.respondError(w, http.StatusInternalServerError, "Failed to evict viewer")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, map[string]string{
+		"status":  "evicted",
+		"message": "Viewer disconnected and reservation released",
+	})
+}
+
 // Helper methods
 
 func (h *StreamHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -136,6 +264,15 @@ func (h *StreamHandler) respondError(w http.ResponseWriter, status int, message
 	h.respondJSON(w, status, map[string]string{"error": message})
 }
 
+// clientIP extracts the client address without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func (h *StreamHandler) translateAgencyLimitError(err *domain.AgencyLimitError) string {
 	// Arabic translation
 	return fmt.Sprintf("تم الوصول إلى حد الوكالة لـ %s (%d/%d)", err.Source, err.Current, err.Limit)
@@ -0,0 +1,203 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// TourEngine defines the interface for playing tours in layout cells
+type TourEngine interface {
+	StartRun(ctx context.Context, tourID string, req domain.StartTourRequest) (*domain.TourRun, error)
+	GetRun(runID string) (*domain.TourRun, error)
+	KeepAlive(runID string) (*domain.TourRun, error)
+	StopRun(ctx context.Context, runID string) error
+}
+
+// TourHandler handles camera tour HTTP requests
+type TourHandler struct {
+	tourUseCase domain.TourUseCase
+	tourEngine  TourEngine
+	logger      zerolog.Logger
+}
+
+// NewTourHandler creates a new tour handler
+func NewTourHandler(tourUseCase domain.TourUseCase, tourEngine TourEngine, logger zerolog.Logger) *TourHandler {
+	return &TourHandler{
+		tourUseCase: tourUseCase,
+		tourEngine:  tourEngine,
+		logger:      logger,
+	}
+}
+
+// CreateTour handles tour creation request
+// POST /api/v1/tours
+func (h *TourHandler) CreateTour(w http.ResponseWriter, r *http.Request) {
+	var request domain.CreateTourRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tour, err := h.tourUseCase.CreateTour(r.Context(), &request)
+	if err != nil {
+		h.respondTourError(w, err, "Failed to create tour")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, tour)
+}
+
+// ListTours handles tour list request
+// GET /api/v1/tours
+func (h *TourHandler) ListTours(w http.ResponseWriter, r *http.Request) {
+	tours, err := h.tourUseCase.ListTours(r.Context())
+	if err != nil {
+		h.respondTourError(w, err, "Failed to list tours")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"tours": tours,
+		"total": len(tours),
+	})
+}
+
+// GetTour handles single tour request
+// GET /api/v1/tours/{id}
+func (h *TourHandler) GetTour(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid tour ID")
+		return
+	}
+
+	tour, err := h.tourUseCase.GetTour(r.Context(), id)
+	if err != nil {
+		h.respondTourError(w, err, "Failed to get tour")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tour)
+}
+
+// UpdateTour handles tour update request
+// PUT /api/v1/tours/{id}
+func (h *TourHandler) UpdateTour(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid tour ID")
+		return
+	}
+
+	var request domain.UpdateTourRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tour, err := h.tourUseCase.UpdateTour(r.Context(), id, &request)
+	if err != nil {
+		h.respondTourError(w, err, "Failed to update tour")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tour)
+}
+
+// DeleteTour handles tour deletion request
+// DELETE /api/v1/tours/{id}
+func (h *TourHandler) DeleteTour(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid tour ID")
+		return
+	}
+
+	if err := h.tourUseCase.DeleteTour(r.Context(), id); err != nil {
+		h.respondTourError(w, err, "Failed to delete tour")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// StartRun handles playing a tour in a layout cell
+// POST /api/v1/tours/{id}/runs
+func (h *TourHandler) StartRun(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid tour ID")
+		return
+	}
+
+	var request domain.StartTourRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	request.ClientIP = clientIP(r)
+
+	run, err := h.tourEngine.StartRun(r.Context(), id, request)
+	if err != nil {
+		h.respondTourError(w, err, "Failed to start tour")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, run)
+}
+
+// GetRun handles tour run state request; clients poll it to pick up the next stream
+// GET /api/v1/tours/runs/{runId}
+func (h *TourHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	run, err := h.tourEngine.GetRun(chi.URLParam(r, "runId"))
+	if err != nil {
+		h.respondTourError(w, err, "Failed to get tour run")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, run)
+}
+
+// KeepAlive handles tour run keep-alive request
+// POST /api/v1/tours/runs/{runId}/keepalive
+func (h *TourHandler) KeepAlive(w http.ResponseWriter, r *http.Request) {
+	run, err := h.tourEngine.KeepAlive(chi.URLParam(r, "runId"))
+	if err != nil {
+		h.respondTourError(w, err, "Failed to keep tour run alive")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, run)
+}
+
+// StopRun handles tour run stop request
+// DELETE /api/v1/tours/runs/{runId}
+func (h *TourHandler) StopRun(w http.ResponseWriter, r *http.Request) {
+	if err := h.tourEngine.StopRun(r.Context(), chi.URLParam(r, "runId")); err != nil {
+		h.respondTourError(w, err, "Failed to stop tour run")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondTourError maps tour errors to HTTP status codes
+func (h *TourHandler) respondTourError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTour):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrTourNotFound):
+		respondError(w, http.StatusNotFound, "Tour not found")
+	case errors.Is(err, domain.ErrTourRunNotFound):
+		respondError(w, http.StatusNotFound, "Tour run not found")
+	default:
+		h.logger.Error().Err(err).Msg(message)
+		respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,281 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// VideoWallHandler handles video wall HTTP requests
+type VideoWallHandler struct {
+	wallUseCase domain.VideoWallUseCase
+	logger      zerolog.Logger
+}
+
+// NewVideoWallHandler creates a new video wall handler
+func NewVideoWallHandler(wallUseCase domain.VideoWallUseCase, logger zerolog.Logger) *VideoWallHandler {
+	return &VideoWallHandler{
+		wallUseCase: wallUseCase,
+		logger:      logger,
+	}
+}
+
+// CreateWall handles video wall creation request
+// POST /api/v1/video-walls
+func (h *VideoWallHandler) CreateWall(w http.ResponseWriter, r *http.Request) {
+	var request domain.CreateVideoWallRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	request.CreatedBy = actorFromRequest(r).UserID
+
+	wall, err := h.wallUseCase.CreateWall(r.Context(), &request)
+	if err != nil {
+		h.respondWallError(w, err, "Failed to create video wall")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, wall)
+}
+
+// ListWalls handles video wall list request
+// GET /api/v1/video-walls
+func (h *VideoWallHandler) ListWalls(w http.ResponseWriter, r *http.Request) {
+	walls, err := h.wallUseCase.ListWalls(r.Context())
+	if err != nil {
+		h.respondWallError(w, err, "Failed to list video walls")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"walls": walls,
+		"total": len(walls),
+	})
+}
+
+// GetWall handles single video wall request, including what each monitor is showing
+// GET /api/v1/video-walls/{id}
+func (h *VideoWallHandler) GetWall(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid video wall ID")
+		return
+	}
+
+	wall, err := h.wallUseCase.GetWall(r.Context(), id)
+	if err != nil {
+		h.respondWallError(w, err, "Failed to get video wall")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, wall)
+}
+
+// UpdateWall handles video wall update request
+// PUT /api/v1/video-walls/{id}
+func (h *VideoWallHandler) UpdateWall(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid video wall ID")
+		return
+	}
+
+	var request domain.UpdateVideoWallRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	wall, err := h.wallUseCase.UpdateWall(r.Context(), id, &request)
+	if err != nil {
+		h.respondWallError(w, err, "Failed to update video wall")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, wall)
+}
+
+// DeleteWall handles video wall deletion request
+// DELETE /api/v1/video-walls/{id}
+func (h *VideoWallHandler) DeleteWall(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid video wall ID")
+		return
+	}
+
+	if err := h.wallUseCase.DeleteWall(r.Context(), id); err != nil {
+		h.respondWallError(w, err, "Failed to delete video wall")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddMonitor handles adding a monitor to a video wall
+// POST /api/v1/video-walls/{id}/monitors
+func (h *VideoWallHandler) AddMonitor(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid video wall ID")
+		return
+	}
+
+	var request domain.WallMonitorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	monitor, err := h.wallUseCase.AddMonitor(r.Context(), id, &request)
+	if err != nil {
+		h.respondWallError(w, err, "Failed to add video wall monitor")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, monitor)
+}
+
+// UpdateMonitor handles renaming or moving a monitor
+// PUT /api/v1/video-walls/{id}/monitors/{monitorId}
+func (h *VideoWallHandler) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
+	id, monitorID, ok := wallMonitorParams(w, r)
+	if !ok {
+		return
+	}
+
+	var request domain.WallMonitorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	monitor, err := h.wallUseCase.UpdateMonitor(r.Context(), id, monitorID, &request)
+	if err != nil {
+		h.respondWallError(w, err, "Failed to update video wall monitor")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, monitor)
+}
+
+// DeleteMonitor handles removing a monitor from a video wall
+// DELETE /api/v1/video-walls/{id}/monitors/{monitorId}
+func (h *VideoWallHandler) DeleteMonitor(w http.ResponseWriter, r *http.Request) {
+	id, monitorID, ok := wallMonitorParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.wallUseCase.DeleteMonitor(r.Context(), id, monitorID); err != nil {
+		h.respondWallError(w, err, "Failed to delete video wall monitor")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Display handles pushing a layout or a camera to a monitor
+// PUT /api/v1/video-walls/{id}/monitors/{monitorId}/display
+func (h *VideoWallHandler) Display(w http.ResponseWriter, r *http.Request) {
+	id, monitorID, ok := wallMonitorParams(w, r)
+	if !ok {
+		return
+	}
+
+	var request domain.WallDisplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if request.LayoutID != "" && !isUUID(request.LayoutID) {
+		respondError(w, http.StatusBadRequest, "Invalid layout_id")
+		return
+	}
+
+	monitor, err := h.wallUseCase.Display(r.Context(), id, monitorID, &request, actorFromRequest(r))
+	if err != nil {
+		h.respondWallError(w, err, "Failed to switch video wall monitor")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, monitor)
+}
+
+// DisplayBatch handles switching several monitors of a wall at once
+// PUT /api/v1/video-walls/{id}/display
+func (h *VideoWallHandler) DisplayBatch(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid video wall ID")
+		return
+	}
+
+	var request domain.WallDisplayBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	for _, assignment := range request.Assignments {
+		if !isUUID(assignment.MonitorID) {
+			respondError(w, http.StatusBadRequest, "Invalid monitor_id")
+			return
+		}
+		if assignment.LayoutID != "" && !isUUID(assignment.LayoutID) {
+			respondError(w, http.StatusBadRequest, "Invalid layout_id")
+			return
+		}
+	}
+
+	wall, err := h.wallUseCase.DisplayBatch(r.Context(), id, &request, actorFromRequest(r))
+	if err != nil {
+		h.respondWallError(w, err, "Failed to switch video wall")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, wall)
+}
+
+// wallMonitorParams reads and validates the wall and monitor IDs of a monitor route
+func wallMonitorParams(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	id := chi.URLParam(r, "id")
+	if !isUUID(id) {
+		respondError(w, http.StatusBadRequest, "Invalid video wall ID")
+		return "", "", false
+	}
+
+	monitorID := chi.URLParam(r, "monitorId")
+	if !isUUID(monitorID) {
+		respondError(w, http.StatusBadRequest, "Invalid monitor ID")
+		return "", "", false
+	}
+
+	return id, monitorID, true
+}
+
+// respondWallError maps video wall errors to HTTP status codes
+func (h *VideoWallHandler) respondWallError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidVideoWall):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrVideoWallNotFound):
+		respondError(w, http.StatusNotFound, "Video wall not found")
+	case errors.Is(err, domain.ErrWallMonitorNotFound):
+		respondError(w, http.StatusNotFound, "Video wall monitor not found")
+	case errors.Is(err, domain.ErrLayoutNotFound):
+		respondError(w, http.StatusNotFound, "Layout not found")
+	case errors.Is(err, domain.ErrLayoutForbidden):
+		respondError(w, http.StatusForbidden, err.Error())
+	default:
+		h.logger.Error().Err(err).Msg(message)
+		respondError(w, http.StatusInternalServerError, message)
+	}
+}
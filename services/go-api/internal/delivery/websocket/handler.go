@@ -51,3 +51,28 @@ func (h *Handler) ServeWS(w http.ResponseWriter, r *http.Request) {
 	go client.WritePump()
 	go client.ReadPump()
 }
+
+// ServeWallDisplay handles WebSocket requests from video wall display clients
+// The display identifies its monitor with the monitor_id query parameter and receives
+// WALL_SWITCH commands for it, starting with the content the monitor should be showing
+func (h *Handler) ServeWallDisplay(w http.ResponseWriter, r *http.Request) {
+	monitorID := r.URL.Query().Get("monitor_id")
+	if monitorID == "" {
+		http.Error(w, "monitor_id is required", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to upgrade connection")
+		return
+	}
+
+	client := h.hub.NewClient(conn, "display:"+monitorID)
+	client.monitorID = monitorID
+	h.hub.register <- client
+
+	// Start client pumps
+	go client.WritePump()
+	go client.ReadPump()
+}
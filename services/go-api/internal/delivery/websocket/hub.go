@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/rta/cctv/go-api/internal/domain"
 	"github.com/rta/cctv/go-api/internal/usecase"
 	"github.com/rs/zerolog"
 )
@@ -19,6 +20,9 @@ const (
 	MessageTypeCameraStatus   MessageType = "CAMERA_STATUS"
 	MessageTypeAgencyLimit    MessageType = "AGENCY_LIMIT_UPDATE"
 	MessageTypeAlert          MessageType = "ALERT"
+	MessageTypeWallSwitch     MessageType = "WALL_SWITCH" // Sent to one monitor's display clients
+	MessageTypeWallUpdate     MessageType = "WALL_UPDATE" // Broadcast when a monitor's content or status changes
+	MessageTypePTZLock        MessageType = "PTZ_LOCK"    // Broadcast when a camera's PTZ lock changes hands or renews
 )
 
 // Message represents a WebSocket message
@@ -30,11 +34,12 @@ type Message struct {
 
 // Client represents a WebSocket client
 type Client struct {
-	hub      *Hub
-	conn     *websocket.Conn
-	send     chan Message
-	userID   string
-	logger   zerolog.Logger
+	hub       *Hub
+	conn      *websocket.Conn
+	send      chan Message
+	userID    string
+	monitorID string // Set for video wall display clients
+	logger    zerolog.Logger
 }
 
 // Hub manages WebSocket connections and broadcasts
@@ -46,6 +51,9 @@ type Hub struct {
 	streamUseCase *usecase.StreamUseCase
 	logger        zerolog.Logger
 	mu            sync.RWMutex
+
+	// onMonitorConnect is called when a video wall display client connects
+	onMonitorConnect func(monitorID string)
 }
 
 // NewHub creates a new WebSocket hub
@@ -74,17 +82,30 @@ func (h *Hub) Run(ctx context.Context) {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
+			onMonitorConnect := h.onMonitorConnect
 			h.mu.Unlock()
-			h.logger.Info().Str("user_id", client.userID).Msg("Client connected")
+			h.logger.Info().Str("user_id", client.userID).Str("monitor_id", client.monitorID).Msg("Client connected")
+
+			if client.monitorID != "" && onMonitorConnect != nil {
+				go onMonitorConnect(client.monitorID)
+			}
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
+			_, ok := h.clients[client]
+			if ok {
 				delete(h.clients, client)
 				close(client.send)
 			}
 			h.mu.Unlock()
-			h.logger.Info().Str("user_id", client.userID).Msg("Client disconnected")
+			h.logger.Info().Str("user_id", client.userID).Str("monitor_id", client.monitorID).Msg("Client disconnected")
+
+			if ok && client.monitorID != "" && !h.IsMonitorConnected(client.monitorID) {
+				go h.BroadcastMessage(MessageTypeWallUpdate, map[string]interface{}{
+					"monitor_id": client.monitorID,
+					"online":     false,
+				})
+			}
 
 		case message := <-h.broadcast:
 			h.mu.RLock()
@@ -165,6 +186,63 @@ func (h *Hub) BroadcastAlert(alertType, message string, severity string) {
 	})
 }
 
+// OnMonitorConnect sets the function called when a video wall display client connects
+// It is used to send the display the content its monitor should be showing
+func (h *Hub) OnMonitorConnect(fn func(monitorID string)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onMonitorConnect = fn
+}
+
+// SendWallCommand sends a switch command to the display clients of a monitor
+// Returns false when no display client for the monitor is connected
+func (h *Hub) SendWallCommand(monitorID string, command *domain.WallCommand) bool {
+	message := Message{
+		Type:      MessageTypeWallSwitch,
+		Data:      command,
+		Timestamp: time.Now(),
+	}
+
+	delivered := false
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if client.monitorID != monitorID {
+			continue
+		}
+		select {
+		case client.send <- message:
+			delivered = true
+		default:
+			h.logger.Warn().Str("monitor_id", monitorID).Msg("Display client send buffer full, command dropped")
+		}
+	}
+
+	return delivered
+}
+
+// IsMonitorConnected reports whether a display client for the monitor is connected
+func (h *Hub) IsMonitorConnected(monitorID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if client.monitorID == monitorID {
+			return true
+		}
+	}
+	return false
+}
+
+// BroadcastWallUpdate broadcasts a video wall monitor's content and status
+func (h *Hub) BroadcastWallUpdate(monitor *domain.WallMonitor) {
+	h.BroadcastMessage(MessageTypeWallUpdate, monitor)
+}
+
+// BroadcastPTZLock broadcasts who controls a camera's PTZ
+func (h *Hub) BroadcastPTZLock(event *domain.PTZLockEvent) {
+	h.BroadcastMessage(MessageTypePTZLock, event)
+}
+
 // NewClient creates a new WebSocket client
 func (h *Hub) NewClient(conn *websocket.Conn, userID string) *Client {
 	return &Client{
@@ -0,0 +1,26 @@
+package domain
+
+// Actor identifies the user making a request
+// It is taken from the X-User-ID / X-User-Groups headers set by the API gateway
+type Actor struct {
+	UserID string   `json:"user_id"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// IsAnonymous reports whether the request carried no user identity
+func (a *Actor) IsAnonymous() bool {
+	return a == nil || a.UserID == ""
+}
+
+// InGroup reports whether the actor belongs to the given group
+func (a *Actor) InGroup(group string) bool {
+	if a == nil {
+		return false
+	}
+	for _, g := range a.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
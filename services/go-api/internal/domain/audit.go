@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Audit actions
+const (
+	AuditActionViewerEvict         = "viewer.evict"
+	AuditActionShareLinkCreate     = "share_link.create"
+	AuditActionShareLinkRevoke     = "share_link.revoke"
+	AuditActionShareLinkRedeem     = "share_link.redeem"
+	AuditActionShareLinkRedeemDeny = "share_link.redeem_denied"
+)
+
+// Audit target types
+const (
+	AuditTargetViewer    = "viewer"
+	AuditTargetShareLink = "share_link"
+)
+
+// AuditEvent represents a single operator action recorded for compliance
+type AuditEvent struct {
+	ID         string                 `json:"id"`
+	Action     string                 `json:"action"`
+	ActorID    string                 `json:"actor_id"`
+	TargetType string                 `json:"target_type"`
+	TargetID   string                 `json:"target_id"`
+	CameraID   string                 `json:"camera_id,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
@@ -1,6 +1,15 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrCameraNotFound is returned when a camera does not exist
+var ErrCameraNotFound = errors.New("camera not found")
+
+// ErrInvalidLocation is returned when coordinates are missing or out of range
+var ErrInvalidLocation = errors.New("invalid location")
 
 // Camera represents a camera from VMS
 type Camera struct {
@@ -15,10 +24,22 @@ type Camera struct {
 	MilestoneDeviceID string                 `json:"milestone_device_id,omitempty"`
 	Metadata          map[string]interface{} `json:"metadata"`
 	Location          *Location              `json:"location,omitempty"`
+	IPAddress         string                 `json:"ip_address,omitempty"`
+	OnvifPort         int                    `json:"onvif_port,omitempty"`
+	OnvifEndpoint     string                 `json:"onvif_endpoint,omitempty"` // ONVIF device service URL
 	CreatedAt         time.Time              `json:"created_at"`
 	UpdatedAt         time.Time              `json:"updated_at"`
 }
 
+// CameraCredentials are the stored credentials of a camera, used for both RTSP and ONVIF
+// The password is envelope-encrypted; RTSPURL may still carry plain credentials until they are sealed
+type CameraCredentials struct {
+	CameraID               string
+	RTSPURL                string
+	OnvifUsername          string
+	OnvifPasswordEncrypted string
+}
+
 // Location represents camera geographical location
 type Location struct {
 	Latitude  float64 `json:"latitude"`
@@ -29,11 +50,18 @@ type Location struct {
 
 // PTZCommand represents a PTZ control command
 type PTZCommand struct {
-	CameraID  string  `json:"camera_id" validate:"required,uuid"`
-	Command   string  `json:"command" validate:"required,oneof=pan_left pan_right tilt_up tilt_down zoom_in zoom_out preset home"`
-	Speed     float64 `json:"speed,omitempty"`     // 0.0 - 1.0
-	PresetID  int     `json:"preset_id,omitempty"` // For preset command
-	UserID    string  `json:"user_id" validate:"required"`
+	CameraID   string  `json:"camera_id" validate:"required,uuid"`
+	Command    string  `json:"command" validate:"required,oneof=pan_left pan_right tilt_up tilt_down zoom_in zoom_out preset home absolute relative center"`
+	Speed      float64 `json:"speed,omitempty"`       // 0.0 - 1.0
+	PresetID   int     `json:"preset_id,omitempty"`   // For preset command
+	PresetName string  `json:"preset_name,omitempty"` // For preset command, recalls the preset by name
+	Pan        float64 `json:"pan,omitempty"`         // For absolute (position) and relative (translation) commands
+	Tilt       float64 `json:"tilt,omitempty"`        // For absolute and relative commands
+	Zoom       float64 `json:"zoom,omitempty"`        // For absolute and relative commands
+	X          float64 `json:"x,omitempty"`           // For center command, 0.0 (left) - 1.0 (right) on the image
+	Y          float64 `json:"y,omitempty"`           // For center command, 0.0 (top) - 1.0 (bottom) on the image
+	ZoomFactor float64 `json:"zoom_factor,omitempty"` // For center command, magnification change; 0 keeps the zoom
+	UserID     string  `json:"-"`                     // Set from the caller's identity, never from the body
 }
 
 // CameraQuery represents a camera search query
@@ -41,8 +69,76 @@ type CameraQuery struct {
 	Source   string `json:"source,omitempty"`
 	Status   string `json:"status,omitempty"`
 	Search   string `json:"search,omitempty"` // Search by name
+	GroupID  string `json:"group_id,omitempty"` // Cameras in this group or any subgroup
 	Limit    int    `json:"limit,omitempty"`
 	Offset   int    `json:"offset,omitempty"`
+
+	// Geospatial filters (only cameras with coordinates match)
+	BoundingBox *BoundingBox `json:"bbox,omitempty"`
+	Radius      *GeoRadius   `json:"radius,omitempty"`
+	Polygon     []GeoPoint   `json:"polygon,omitempty"`
+}
+
+// GeoPoint represents a WGS84 coordinate
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// BoundingBox represents a lat/long rectangle
+type BoundingBox struct {
+	MinLatitude  float64 `json:"min_latitude"`
+	MinLongitude float64 `json:"min_longitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+}
+
+// GeoRadius represents a circle around a point
+type GeoRadius struct {
+	Center       GeoPoint `json:"center"`
+	RadiusMeters float64  `json:"radius_meters"`
+}
+
+// CameraLocationUpdate represents a request to set a camera's coordinates
+type CameraLocationUpdate struct {
+	CameraID  string   `json:"camera_id"`
+	Latitude  *float64 `json:"latitude"`  // Required; a pointer so an omitted field is not read as 0
+	Longitude *float64 `json:"longitude"` // Required
+	Address   string   `json:"address,omitempty"`
+	AddressAr string   `json:"address_ar,omitempty"`
+}
+
+// BulkLocationUpdateRequest represents a batch coordinates update
+type BulkLocationUpdateRequest struct {
+	Locations []CameraLocationUpdate `json:"locations"`
+}
+
+// BulkLocationUpdateResponse represents the batch coordinates update result
+type BulkLocationUpdateResponse struct {
+	Updated int      `json:"updated"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// GeoJSONFeatureCollection represents a GeoJSON FeatureCollection (RFC 7946)
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature represents a GeoJSON Feature
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Geometry   GeoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONGeometry represents a GeoJSON Point geometry
+// Coordinates are [longitude, latitude] per RFC 7946
+type GeoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
 }
 
 // ImportCameraRequest represents a request to import a discovered camera
@@ -57,8 +153,8 @@ type ImportCameraRequest struct {
 	Device                  DeviceInfo          `json:"device"`
 	OnvifEndpoint           string              `json:"onvifEndpoint"`
 	OnvifUsername           string              `json:"onvifUsername"`
-	OnvifPassword           string              `json:"onvifPassword"`            // Plain text password for RTSP/ONVIF
-	OnvifPasswordEncrypted  string              `json:"onvifPasswordEncrypted"`   // Encrypted password for storage
+	OnvifPassword           string              `json:"onvifPassword"`            // Plain text password for RTSP/ONVIF, never stored
+	OnvifPasswordEncrypted  string              `json:"-"`                        // Set by the use case from OnvifPassword
 	Streams                 []StreamProfile     `json:"streams"`
 	PtzCapabilities         PtzCapabilitiesInfo `json:"ptzCapabilities"`
 }
@@ -106,3 +202,18 @@ type ImportCamerasResponse struct {
 	Failed   int      `json:"failed"`
 	Errors   []string `json:"errors,omitempty"`
 }
+
+// Snapshot sources
+const (
+	SnapshotSourceONVIF = "onvif"
+	SnapshotSourceRTSP  = "rtsp"
+)
+
+// Snapshot represents a still image captured from a camera
+type Snapshot struct {
+	CameraID    string
+	Image       []byte
+	ContentType string
+	Source      string // onvif or rtsp
+	CapturedAt  time.Time
+}
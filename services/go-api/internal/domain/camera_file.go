@@ -0,0 +1,85 @@
+package domain
+
+import "errors"
+
+// ErrInvalidCameraFile is returned when an uploaded camera file cannot be read at all
+// Problems with single rows are reported per row instead
+var ErrInvalidCameraFile = errors.New("invalid camera file")
+
+// Camera file formats
+const (
+	CameraFileCSV  = "csv"
+	CameraFileJSON = "json"
+)
+
+// CameraFileColumns are the columns of camera import/export files, in export order
+// id, name and rtsp_url are required on import
+var CameraFileColumns = []string{
+	"id", "name", "name_ar", "source", "rtsp_url", "ptz_enabled", "status",
+	"latitude", "longitude", "address", "address_ar",
+}
+
+// Camera import modes
+const (
+	CameraImportDryRun = "dry_run" // Validate only
+	CameraImportCommit = "commit"  // Write the valid rows, skip the invalid ones
+	CameraImportAtomic = "atomic"  // Write every row or none
+)
+
+// Camera import row actions
+const (
+	CameraImportCreate = "create"
+	CameraImportUpdate = "update"
+	CameraImportSkip   = "skip" // The row has errors
+)
+
+// CameraRecord is one camera in an import or export file
+// Empty optional fields keep the existing value when a camera is updated
+// Exported RTSP URLs never contain credentials
+type CameraRecord struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	NameAr     string   `json:"name_ar,omitempty"`
+	Source     string   `json:"source,omitempty"` // DUBAI_POLICE, METRO, BUS, OTHER (default for new cameras)
+	RTSPURL    string   `json:"rtsp_url"`
+	PTZEnabled *bool    `json:"ptz_enabled,omitempty"` // false for new cameras when omitted
+	Status     string   `json:"status,omitempty"` // ONLINE, OFFLINE, ERROR
+	Latitude   *float64 `json:"latitude,omitempty"`
+	Longitude  *float64 `json:"longitude,omitempty"`
+	Address    string   `json:"address,omitempty"`
+	AddressAr  string   `json:"address_ar,omitempty"`
+
+	// Credentials taken out of RTSPURL on import, never read from or written to files
+	Username          string `json:"-"`
+	PasswordEncrypted string `json:"-"`
+}
+
+// CameraImportOptions controls a camera file import
+type CameraImportOptions struct {
+	Format            string // csv or json
+	Mode              string // dry_run, commit or atomic
+	CheckReachability bool   // Probe each RTSP host and warn when it cannot be reached
+}
+
+// CameraImportReport is the result of validating, and possibly writing, a camera file
+type CameraImportReport struct {
+	Mode      string                  `json:"mode"`
+	Committed bool                    `json:"committed"` // Rows were written
+	Total     int                     `json:"total"`
+	Valid     int                     `json:"valid"`
+	Invalid   int                     `json:"invalid"`
+	Warnings  int                     `json:"warnings"`
+	Created   int                     `json:"created"`
+	Updated   int                     `json:"updated"`
+	Rows      []CameraImportRowResult `json:"rows"`
+}
+
+// CameraImportRowResult is the validation result of one file row
+// Row is the CSV line number or the 1-based JSON array index
+type CameraImportRowResult struct {
+	Row      int      `json:"row"`
+	ID       string   `json:"id,omitempty"`
+	Action   string   `json:"action"` // create, update or skip
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCameraGroupNotFound is returned when a camera group does not exist
+var ErrCameraGroupNotFound = errors.New("camera group not found")
+
+// ErrInvalidCameraGroup is returned when a group request is malformed or would create a cycle
+var ErrInvalidCameraGroup = errors.New("invalid camera group")
+
+// CameraGroup represents a folder in the camera tree
+type CameraGroup struct {
+	ID          string         `json:"id"`
+	ParentID    *string        `json:"parent_id"`
+	Name        string         `json:"name"`
+	NameAr      string         `json:"name_ar,omitempty"`
+	Description string         `json:"description,omitempty"`
+	SortOrder   int            `json:"sort_order"`
+	CreatedBy   string         `json:"created_by"`
+	CameraCount int            `json:"camera_count"` // Direct members only
+	CameraIDs   []string       `json:"camera_ids,omitempty"`
+	Children    []*CameraGroup `json:"children,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// CreateCameraGroupRequest represents the request to create a camera group
+type CreateCameraGroupRequest struct {
+	ParentID    *string  `json:"parent_id"`
+	Name        string   `json:"name"`
+	NameAr      string   `json:"name_ar"`
+	Description string   `json:"description"`
+	SortOrder   int      `json:"sort_order"`
+	CreatedBy   string   `json:"-"` // Set from the caller's identity
+	CameraIDs   []string `json:"camera_ids"`
+}
+
+// UpdateCameraGroupRequest represents the request to update a camera group
+// A nil ParentID moves the group to the root
+type UpdateCameraGroupRequest struct {
+	ParentID    *string `json:"parent_id"`
+	Name        string  `json:"name"`
+	NameAr      string  `json:"name_ar"`
+	Description string  `json:"description"`
+	SortOrder   int     `json:"sort_order"`
+}
+
+// CameraGroupMembersRequest represents a request to add cameras to a group
+type CameraGroupMembersRequest struct {
+	CameraIDs []string `json:"camera_ids"`
+}
+
+// CameraGroupMembersResponse represents the result of adding cameras to a group
+type CameraGroupMembersResponse struct {
+	Added   int      `json:"added"`
+	Unknown []string `json:"unknown,omitempty"` // Camera IDs that do not exist
+}
+
+// CameraGroupTreeResponse represents the full camera tree
+type CameraGroupTreeResponse struct {
+	Groups []*CameraGroup `json:"groups"`
+	Total  int            `json:"total"`
+}
+
+// CameraGroupRepository defines the interface for camera group data access
+type CameraGroupRepository interface {
+	// Create creates a new camera group
+	Create(ctx context.Context, group *CameraGroup) error
+
+	// GetByID retrieves a group with its direct camera IDs
+	GetByID(ctx context.Context, id string) (*CameraGroup, error)
+
+	// List retrieves all groups (flat) with direct camera counts
+	List(ctx context.Context) ([]*CameraGroup, error)
+
+	// Update updates a group's name, description, order and parent
+	Update(ctx context.Context, id string, request *UpdateCameraGroupRequest) (*CameraGroup, error)
+
+	// Delete deletes a group and its subgroups
+	Delete(ctx context.Context, id string) error
+
+	// SubtreeIDs returns the IDs of a group and all of its descendants
+	SubtreeIDs(ctx context.Context, id string) ([]string, error)
+
+	// AddCameras adds cameras to a group and returns the IDs that do not exist
+	AddCameras(ctx context.Context, groupID string, cameraIDs []string) (int, []string, error)
+
+	// RemoveCamera removes a camera from a group
+	RemoveCamera(ctx context.Context, groupID, cameraID string) error
+
+	// ListCameraIDs lists the cameras of a group, optionally including all subgroups
+	ListCameraIDs(ctx context.Context, groupID string, includeSubgroups bool) ([]string, error)
+
+	// ListMemberships returns the direct camera IDs of every group keyed by group ID
+	ListMemberships(ctx context.Context) (map[string][]string, error)
+}
+
+// CameraGroupUseCase defines the interface for camera group business logic
+type CameraGroupUseCase interface {
+	// CreateGroup creates a new camera group
+	CreateGroup(ctx context.Context, request *CreateCameraGroupRequest) (*CameraGroup, error)
+
+	// GetGroup retrieves a group by ID
+	GetGroup(ctx context.Context, id string) (*CameraGroup, error)
+
+	// ListGroups retrieves all groups as a flat list
+	ListGroups(ctx context.Context) ([]*CameraGroup, error)
+
+	// GetTree retrieves all groups nested under their parents
+	GetTree(ctx context.Context, includeCameras bool) (*CameraGroupTreeResponse, error)
+
+	// UpdateGroup updates a group, including moving it to another parent
+	UpdateGroup(ctx context.Context, id string, request *UpdateCameraGroupRequest) (*CameraGroup, error)
+
+	// DeleteGroup deletes a group and its subgroups
+	DeleteGroup(ctx context.Context, id string) error
+
+	// AddCameras adds cameras to a group
+	AddCameras(ctx context.Context, groupID string, request *CameraGroupMembersRequest) (*CameraGroupMembersResponse, error)
+
+	// RemoveCamera removes a camera from a group
+	RemoveCamera(ctx context.Context, groupID, cameraID string) error
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDependencyUnavailable is returned without calling a downstream service whose circuit breaker is open
+var ErrDependencyUnavailable = errors.New("dependency unavailable")
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"    // Calls go through
+	BreakerOpen     = "open"      // Calls fail fast until the open timeout passes
+	BreakerHalfOpen = "half_open" // One trial call decides whether to close again
+)
+
+// DependencyHealth is the circuit breaker state of a downstream service
+type DependencyHealth struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// Readiness statuses
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"  // Serving, but some features are failing
+	HealthUnhealthy = "unhealthy" // Should not receive traffic
+)
+
+// DependencyStatus is the result of checking one dependency for readiness
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`          // The instance is unhealthy without it
+	LatencyMS float64 `json:"latency_ms"`
+	Breaker   string  `json:"breaker,omitempty"` // Circuit breaker state, for downstream services
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessReport is the result of checking every dependency
+type ReadinessReport struct {
+	Status       string             `json:"status"`
+	CheckedAt    time.Time          `json:"checked_at"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
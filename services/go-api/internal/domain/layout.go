@@ -1,6 +1,28 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLayoutNotFound is returned when a layout does not exist
+var ErrLayoutNotFound = errors.New("layout not found")
+
+// ErrLayoutVersionNotFound is returned when a layout has no such version
+var ErrLayoutVersionNotFound = errors.New("layout version not found")
+
+// ErrLayoutForbidden is returned when the actor lacks the permission for a layout operation
+var ErrLayoutForbidden = errors.New("layout access denied")
+
+// ErrLayoutVersionConflict is returned when a layout changed since the version the client edited
+var ErrLayoutVersionConflict = errors.New("layout version conflict")
+
+// ErrLayoutVersionRequired is returned when an update does not say which version it edits
+var ErrLayoutVersionRequired = errors.New("layout version required")
+
+// ErrInvalidLayout is returned when a layout request is malformed
+var ErrInvalidLayout = errors.New("invalid layout")
 
 // LayoutType represents the type of layout
 type LayoutType string
@@ -18,6 +40,38 @@ const (
 	LayoutScopeLocal  LayoutScope = "local"
 )
 
+// LayoutPermission represents an actor's access level on a layout
+type LayoutPermission string
+
+const (
+	LayoutPermissionView  LayoutPermission = "view"
+	LayoutPermissionEdit  LayoutPermission = "edit"
+	LayoutPermissionOwner LayoutPermission = "owner" // The creator: edit, delete and share
+)
+
+// Allows reports whether p grants at least the required permission
+func (p LayoutPermission) Allows(required LayoutPermission) bool {
+	rank := map[LayoutPermission]int{
+		LayoutPermissionView:  1,
+		LayoutPermissionEdit:  2,
+		LayoutPermissionOwner: 3,
+	}
+	return rank[p] >= rank[required]
+}
+
+// Layout share principal types
+const (
+	LayoutPrincipalUser  = "user"
+	LayoutPrincipalGroup = "group"
+)
+
+// Layout version change types
+const (
+	LayoutChangeCreate = "create"
+	LayoutChangeUpdate = "update"
+	LayoutChangeRevert = "revert"
+)
+
 // LayoutPreference represents a saved camera layout configuration
 type LayoutPreference struct {
 	ID          string                  `json:"id"`
@@ -28,16 +82,54 @@ type LayoutPreference struct {
 	Scope       LayoutScope             `json:"scope"`
 	CreatedBy   string                  `json:"created_by"`
 	IsActive    bool                    `json:"is_active"`
+	Version     int                     `json:"version"`
+	Permission  LayoutPermission        `json:"permission,omitempty"` // The requesting actor's access
 	CreatedAt   time.Time               `json:"created_at"`
 	UpdatedAt   time.Time               `json:"updated_at"`
 	Cameras     []LayoutCameraAssignment `json:"cameras,omitempty"`
+	Shares      []LayoutShare           `json:"shares,omitempty"` // Only returned to the owner
+}
+
+// LayoutShare grants a user or group access to a layout
+type LayoutShare struct {
+	PrincipalType string           `json:"principal_type"` // user or group
+	PrincipalID   string           `json:"principal_id"`
+	Permission    LayoutPermission `json:"permission"` // view or edit
+	GrantedBy     string           `json:"granted_by,omitempty"`
+	CreatedAt     time.Time        `json:"created_at,omitempty"`
+}
+
+// ShareLayoutRequest replaces the shares of a layout
+type ShareLayoutRequest struct {
+	Shares []LayoutShare `json:"shares"`
+}
+
+// LayoutVersion represents a snapshot of a layout after a change
+type LayoutVersion struct {
+	Version     int                      `json:"version"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description,omitempty"`
+	LayoutType  LayoutType               `json:"layout_type"`
+	GridLayout  string                   `json:"grid_layout"`
+	Cameras     []LayoutCameraAssignment `json:"cameras"`
+	ChangedBy   string                   `json:"changed_by"`
+	ChangeType  string                   `json:"change_type"` // create, update or revert
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+// RevertLayoutRequest represents the request to restore an earlier layout version
+type RevertLayoutRequest struct {
+	ToVersion int `json:"to_version"`
+	Version   int `json:"version"` // Current version the client has, as for updates
 }
 
 // LayoutCameraAssignment represents a camera-to-position assignment
+// A cell shows either a fixed camera or a tour (TourID set, CameraID empty)
 type LayoutCameraAssignment struct {
 	ID            string    `json:"id,omitempty"`
 	LayoutID      string    `json:"layout_id,omitempty"`
-	CameraID      string    `json:"camera_id"`
+	CameraID      string    `json:"camera_id,omitempty"`
+	TourID        string    `json:"tour_id,omitempty"`
 	PositionIndex int       `json:"position_index"`
 	CellSize      string    `json:"cell_size,omitempty"` // 'small', 'medium', 'large', 'hotspot'
 	CreatedAt     time.Time `json:"created_at,omitempty"`
@@ -50,15 +142,30 @@ type CreateLayoutRequest struct {
 	LayoutType  LayoutType              `json:"layout_type" binding:"required,oneof=standard hotspot"`
 	GridLayout  string                  `json:"grid_layout" binding:"required"` // e.g., "2x2", "3x3", "9-way-1-hotspot"
 	Scope       LayoutScope             `json:"scope" binding:"required,oneof=global local"`
-	CreatedBy   string                  `json:"created_by" binding:"required"`
+	CreatedBy   string                  `json:"-"` // Set from the caller's identity
 	Cameras     []LayoutCameraAssignment `json:"cameras" binding:"required,min=1"`
 }
 
+// CreateLayoutFromGroupRequest represents the request to build a layout from a camera group
+// Cameras fill the grid in name order; extra cameras beyond the grid size are left out
+type CreateLayoutFromGroupRequest struct {
+	GroupID          string      `json:"group_id"`
+	IncludeSubgroups bool        `json:"include_subgroups"`
+	Name             string      `json:"name"`
+	Description      string      `json:"description"`
+	LayoutType       LayoutType  `json:"layout_type"`
+	GridLayout       string      `json:"grid_layout"`
+	Scope            LayoutScope `json:"scope"`
+	CreatedBy        string      `json:"-"` // Set from the caller's identity
+}
+
 // UpdateLayoutRequest represents the request to update a layout
+// Version is the version being edited; the If-Match header may be used instead
 type UpdateLayoutRequest struct {
 	Name        string                  `json:"name" binding:"required"`
 	Description string                  `json:"description"`
 	Cameras     []LayoutCameraAssignment `json:"cameras" binding:"required,min=1"`
+	Version     int                     `json:"version"`
 }
 
 // LayoutListResponse represents the response for listing layouts
@@ -69,16 +176,18 @@ type LayoutListResponse struct {
 
 // LayoutPreferenceSummary represents a summary of a layout (without camera details)
 type LayoutPreferenceSummary struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description,omitempty"`
-	LayoutType  LayoutType  `json:"layout_type"`
-	GridLayout  string      `json:"grid_layout"`
-	Scope       LayoutScope `json:"scope"`
-	CreatedBy   string      `json:"created_by"`
-	CameraCount int         `json:"camera_count"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	LayoutType  LayoutType       `json:"layout_type"`
+	GridLayout  string           `json:"grid_layout"`
+	Scope       LayoutScope      `json:"scope"`
+	CreatedBy   string           `json:"created_by"`
+	CameraCount int              `json:"camera_count"`
+	Version     int              `json:"version"`
+	Permission  LayoutPermission `json:"permission"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
 // LayoutRepository defines the interface for layout data access
@@ -89,14 +198,26 @@ type LayoutRepository interface {
 	// GetByID retrieves a layout by ID with camera assignments
 	GetByID(id string) (*LayoutPreference, error)
 
-	// List retrieves layouts with optional filtering
-	List(layoutType *LayoutType, scope *LayoutScope, createdBy *string) ([]*LayoutPreferenceSummary, error)
+	// List retrieves the layouts visible to an actor with optional filtering
+	List(layoutType *LayoutType, scope *LayoutScope, createdBy *string, actor *Actor) ([]*LayoutPreferenceSummary, error)
 
-	// Update updates an existing layout
-	Update(id string, request *UpdateLayoutRequest) (*LayoutPreference, error)
+	// Update updates an existing layout if it is still at request.Version, and records the new version
+	Update(id string, request *UpdateLayoutRequest, changedBy, changeType string) (*LayoutPreference, error)
 
 	// Delete deletes a layout by ID
 	Delete(id string) error
+
+	// GetShares retrieves the shares of a layout
+	GetShares(id string) ([]LayoutShare, error)
+
+	// ReplaceShares replaces all shares of a layout
+	ReplaceShares(id string, shares []LayoutShare, grantedBy string) error
+
+	// ListVersions retrieves the version history of a layout, newest first
+	ListVersions(id string) ([]*LayoutVersion, error)
+
+	// GetVersion retrieves one version of a layout
+	GetVersion(id string, version int) (*LayoutVersion, error)
 }
 
 // LayoutUseCase defines the interface for layout business logic
@@ -105,14 +226,29 @@ type LayoutUseCase interface {
 	CreateLayout(request *CreateLayoutRequest) (*LayoutPreference, error)
 
 	// GetLayout retrieves a layout by ID
-	GetLayout(id string) (*LayoutPreference, error)
+	GetLayout(id string, actor *Actor) (*LayoutPreference, error)
 
-	// ListLayouts retrieves all layouts with optional filtering
-	ListLayouts(layoutType *LayoutType, scope *LayoutScope, createdBy *string) (*LayoutListResponse, error)
+	// ListLayouts retrieves all layouts visible to the actor with optional filtering
+	ListLayouts(layoutType *LayoutType, scope *LayoutScope, createdBy *string, actor *Actor) (*LayoutListResponse, error)
 
 	// UpdateLayout updates an existing layout
-	UpdateLayout(id string, request *UpdateLayoutRequest) (*LayoutPreference, error)
+	UpdateLayout(id string, request *UpdateLayoutRequest, actor *Actor) (*LayoutPreference, error)
 
 	// DeleteLayout deletes a layout by ID
-	DeleteLayout(id string) error
+	DeleteLayout(id string, actor *Actor) error
+
+	// GetLayoutShares retrieves the shares of a layout
+	GetLayoutShares(id string, actor *Actor) ([]LayoutShare, error)
+
+	// ShareLayout replaces the shares of a layout
+	ShareLayout(id string, request *ShareLayoutRequest, actor *Actor) ([]LayoutShare, error)
+
+	// ListLayoutVersions retrieves the version history of a layout
+	ListLayoutVersions(id string, actor *Actor) ([]*LayoutVersion, error)
+
+	// RevertLayout restores an earlier version as a new version
+	RevertLayout(id string, request *RevertLayoutRequest, actor *Actor) (*LayoutPreference, error)
+
+	// CreateLayoutFromGroup creates a layout filled with the cameras of a camera group
+	CreateLayoutFromGroup(ctx context.Context, request *CreateLayoutFromGroupRequest) (*LayoutPreference, error)
 }
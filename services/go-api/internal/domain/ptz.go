@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPTZLocked is returned when another operator controls the camera
+// The error returned is a *PTZLockedError carrying the holder
+var ErrPTZLocked = errors.New("PTZ is controlled by another operator")
+
+// ErrInvalidPTZRequest is returned when a PTZ request is malformed
+var ErrInvalidPTZRequest = errors.New("invalid PTZ request")
+
+// PTZLockedError is returned when a PTZ command or lock request is refused
+// because the camera is held by an operator of equal or higher priority
+type PTZLockedError struct {
+	Holder *PTZLock
+}
+
+func (e *PTZLockedError) Error() string {
+	return fmt.Sprintf("%s: %s until %s", ErrPTZLocked, e.Holder.UserID, e.Holder.ExpiresAt.Format(time.RFC3339))
+}
+
+// Unwrap lets errors.Is match ErrPTZLocked
+func (e *PTZLockedError) Unwrap() error {
+	return ErrPTZLocked
+}
+
+// PTZLock gives one operator control of a camera's PTZ for a lease
+// The lease renews on every command the holder sends
+type PTZLock struct {
+	CameraID   string    `json:"camera_id"`
+	UserID     string    `json:"user_id"`
+	Role       string    `json:"role,omitempty"` // Group that gave the holder its priority
+	Priority   int       `json:"priority"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// PTZ lock events broadcast to WebSocket clients
+const (
+	PTZLockAcquired = "acquired"
+	PTZLockRenewed  = "renewed"
+	PTZLockTakeover = "taken_over" // A higher-priority operator took control
+	PTZLockReleased = "released"
+)
+
+// PTZLockEvent describes a change of who controls a camera
+type PTZLockEvent struct {
+	Event          string   `json:"event"`
+	CameraID       string   `json:"camera_id"`
+	Lock           *PTZLock `json:"lock,omitempty"`             // Nil once released
+	PreviousUserID string   `json:"previous_user_id,omitempty"` // Set on takeover and release
+}
+
+// PTZLockRepository defines the interface for PTZ lock storage
+// Locks are shared by every go-api instance and expire on their own
+type PTZLockRepository interface {
+	// Acquire stores lock unless the camera is held by another user with equal or higher priority
+	// Renewing keeps the original AcquiredAt. Returns the lock it replaced, nil if the camera was free,
+	// or a *PTZLockedError
+	Acquire(ctx context.Context, lock *PTZLock, lease time.Duration) (*PTZLock, error)
+
+	// Release removes the lock if userID holds it or priority is higher than the holder's
+	// Returns the removed lock, nil if the camera was free, or a *PTZLockedError
+	Release(ctx context.Context, cameraID, userID string, priority int) (*PTZLock, error)
+
+	// Get returns the current lock, nil if the camera is free
+	Get(ctx context.Context, cameraID string) (*PTZLock, error)
+}
+
+// ErrPTZHomePolicyNotFound is returned when a camera has no return-to-home policy
+var ErrPTZHomePolicyNotFound = errors.New("PTZ home policy not found")
+
+// ErrPTZPresetNotFound is returned when a camera has no preset with the requested number or name
+var ErrPTZPresetNotFound = errors.New("PTZ preset not found")
+
+// ErrPTZPresetExists is returned when a preset number or name is already used on the camera
+var ErrPTZPresetExists = errors.New("PTZ preset already exists")
+
+// ErrPTZCommandFailed is returned when the camera did not carry out a PTZ request
+var ErrPTZCommandFailed = errors.New("camera did not carry out the PTZ request")
+
+// MaxPTZPresetNumber is the highest preset number a camera accepts
+const MaxPTZPresetNumber = 256
+
+// PTZPreset is a camera position saved under a number and a name in English and Arabic
+type PTZPreset struct {
+	CameraID  string    `json:"camera_id"`
+	Number    int       `json:"number"`
+	Token     string    `json:"token"`
+	Name      string    `json:"name"`
+	NameAr    string    `json:"name_ar,omitempty"`
+	OnCamera  bool      `json:"on_camera"` // False once the camera no longer had the preset
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SavePTZPresetRequest creates or renames a preset
+type SavePTZPresetRequest struct {
+	Number       int    `json:"number,omitempty"` // On create, 0 takes the lowest free number
+	Name         string `json:"name"`
+	NameAr       string `json:"name_ar,omitempty"`
+	SavePosition bool   `json:"save_position,omitempty"` // On rename, also store the current position
+}
+
+// PTZPosition is a camera position in the ONVIF generic spaces
+// Pan and Tilt run from -1 to 1 and Zoom from 0 (wide) to 1 (tele).
+type PTZPosition struct {
+	Pan  float64  `json:"pan"`
+	Tilt float64  `json:"tilt"`
+	Zoom *float64 `json:"zoom,omitempty"` // Nil for cameras without zoom
+}
+
+// PTZStatus is the position and movement of a camera as read from it by VMS
+type PTZStatus struct {
+	CameraID      string       `json:"camera_id"`
+	Position      *PTZPosition `json:"position,omitempty"`
+	PanTiltStatus string       `json:"pan_tilt_status,omitempty"` // IDLE, MOVING or UNKNOWN
+	ZoomStatus    string       `json:"zoom_status,omitempty"`
+	Error         string       `json:"error,omitempty"`
+	UTCTime       *time.Time   `json:"utc_time,omitempty"`
+	ReadAt        time.Time    `json:"read_at"`
+}
+
+// PTZ command sources
+const (
+	PTZSourceOperator   = "operator"    // Sent through the PTZ API
+	PTZSourceTour       = "tour"        // Preset recalled ahead of a tour step
+	PTZSourceHomePolicy = "home_policy" // Camera returned home after being idle
+)
+
+// PTZ command results
+const (
+	PTZResultSuccess = "success"
+	PTZResultFailed  = "failed"
+	PTZResultLocked  = "locked" // Refused, another operator held the camera
+)
+
+// PTZHomePolicyUserID is recorded as the user of commands sent by return-to-home policies
+const PTZHomePolicyUserID = "system"
+
+// PTZCommandRecord is one PTZ command in the movement history
+type PTZCommandRecord struct {
+	ID        string    `json:"id"`
+	CameraID  string    `json:"camera_id"`
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role,omitempty"`
+	Source    string    `json:"source"`
+	Command   string    `json:"command"`
+	Speed     *float64  `json:"speed,omitempty"`
+	PresetID  *int      `json:"preset_id,omitempty"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMS int64     `json:"latency_ms"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PTZHistoryQuery represents a PTZ history search query
+type PTZHistoryQuery struct {
+	CameraID string
+	UserID   string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
+
+// PTZHomePolicy returns a camera to a home position once nobody has moved it for IdleMinutes
+type PTZHomePolicy struct {
+	CameraID    string    `json:"camera_id"`
+	IdleMinutes int       `json:"idle_minutes"`
+	PresetID    *int      `json:"preset_id,omitempty"` // Nil uses the camera's home position
+	Enabled     bool      `json:"enabled"`
+	UpdatedBy   string    `json:"updated_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SetPTZHomePolicyRequest represents the request to set a camera's return-to-home policy
+type SetPTZHomePolicyRequest struct {
+	IdleMinutes int    `json:"idle_minutes"` // 1 to 1440
+	PresetID    *int   `json:"preset_id"`    // Omit to use the camera's home position
+	Enabled     *bool  `json:"enabled"`      // Default true
+	UpdatedBy   string `json:"-"`
+}
+
+// PTZHistoryRepository defines the interface for PTZ command history and home policies
+type PTZHistoryRepository interface {
+	// Record appends a command to the history
+	Record(ctx context.Context, record *PTZCommandRecord) error
+
+	// List retrieves commands, newest first
+	List(ctx context.Context, query PTZHistoryQuery) ([]*PTZCommandRecord, error)
+
+	// GetHomePolicy retrieves a camera's return-to-home policy
+	GetHomePolicy(ctx context.Context, cameraID string) (*PTZHomePolicy, error)
+
+	// SetHomePolicy creates or replaces a camera's return-to-home policy
+	SetHomePolicy(ctx context.Context, policy *PTZHomePolicy) error
+
+	// DeleteHomePolicy removes a camera's return-to-home policy
+	DeleteHomePolicy(ctx context.Context, cameraID string) error
+
+	// ListHomeReturnsDue returns the enabled policies of cameras idle for their idle period
+	// whose last command was not a successful return home
+	ListHomeReturnsDue(ctx context.Context) ([]*PTZHomePolicy, error)
+}
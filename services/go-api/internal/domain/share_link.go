@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrShareLinkNotFound is returned when a share link does not exist
+var ErrShareLinkNotFound = errors.New("share link not found")
+
+// ErrInvalidShareLink is returned when a share link request is malformed
+var ErrInvalidShareLink = errors.New("invalid share link")
+
+// ErrShareLinkUnavailable is returned when a share link token is invalid, expired, revoked or locked
+// Guests get the same answer whatever the reason; the audit log records the actual one
+var ErrShareLinkUnavailable = errors.New("share link is invalid or no longer available")
+
+// ErrShareLinkPIN is returned when a share link needs a PIN and the given one is missing or wrong
+var ErrShareLinkPIN = errors.New("share link PIN is missing or wrong")
+
+// ErrShareLinkFull is returned when a share link already has its maximum number of viewers
+var ErrShareLinkFull = errors.New("share link has reached its viewer limit")
+
+// StreamSourceGuest is the stream-counter quota every guest viewer is counted against
+const StreamSourceGuest = "GUEST"
+
+// Share link limits
+const (
+	ShareLinkMaxDuration    = 24 * time.Hour
+	ShareLinkMaxViewers     = 20
+	ShareLinkMaxPINFailures = 5 // Wrong PINs before the link locks
+)
+
+// Share link statuses
+const (
+	ShareLinkActive  = "active"
+	ShareLinkExpired = "expired"
+	ShareLinkRevoked = "revoked"
+	ShareLinkLocked  = "locked" // Too many wrong PINs
+)
+
+// ShareLink lets a partner without an account watch one camera live until it expires
+type ShareLink struct {
+	ID            string     `json:"id"`
+	CameraID      string     `json:"camera_id"`
+	Label         string     `json:"label,omitempty"` // Who the link was shared with, e.g. "Ambulance dispatch"
+	CreatedBy     string     `json:"created_by"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	MaxViewers    int        `json:"max_viewers"`
+	PINRequired   bool       `json:"pin_required"`
+	PINHash       string     `json:"-"`
+	PINFailures   int        `json:"pin_failures"`
+	ActiveViewers int        `json:"active_viewers"`
+	Redemptions   int        `json:"redemptions"`
+	Status        string     `json:"status"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy     string     `json:"revoked_by,omitempty"`
+	RevokeReason  string     `json:"revoke_reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// CreateShareLinkRequest represents the request to create a share link
+type CreateShareLinkRequest struct {
+	CameraID         string `json:"camera_id"`
+	Label            string `json:"label"`
+	ExpiresInMinutes int    `json:"expires_in_minutes"` // Up to 24 hours
+	MaxViewers       int    `json:"max_viewers"`        // Concurrent guest viewers, default 1
+	PIN              string `json:"pin,omitempty"`      // Optional, 4 to 12 digits
+	CreatedBy        string `json:"-"`                  // Set from the caller's identity
+}
+
+// ShareLinkCreated is returned once when a share link is created
+// The token is not stored and cannot be retrieved again
+type ShareLinkCreated struct {
+	*ShareLink
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// RedeemShareLinkRequest represents a guest opening a share link
+type RedeemShareLinkRequest struct {
+	Token    string `json:"token"`
+	PIN      string `json:"pin,omitempty"`
+	ClientIP string `json:"-"` // Set by the HTTP handler from the request's remote address
+}
+
+// RevokeShareLinkRequest represents the request to revoke a share link
+type RevokeShareLinkRequest struct {
+	ActorID string `json:"-"`
+	Reason  string `json:"reason"`
+}
+
+// ShareLinkRepository defines the interface for share link data access
+type ShareLinkRepository interface {
+	// Create creates a new share link
+	Create(ctx context.Context, link *ShareLink) error
+
+	// GetByID retrieves a share link with its viewer counts
+	GetByID(ctx context.Context, id string) (*ShareLink, error)
+
+	// List retrieves share links, newest first; an empty camera ID lists them all
+	List(ctx context.Context, cameraID string) ([]*ShareLink, error)
+
+	// Revoke marks a share link revoked; revoking twice keeps the first revocation
+	Revoke(ctx context.Context, id string, request *RevokeShareLinkRequest) (*ShareLink, error)
+
+	// RecordPINFailure counts a wrong PIN and returns the failures so far
+	RecordPINFailure(ctx context.Context, id string) (int, error)
+
+	// ClaimViewer reserves a viewer slot on a usable link and returns the redemption ID
+	// Fails with ErrShareLinkFull when every slot is taken and ErrShareLinkUnavailable
+	// when the link is expired, revoked or locked
+	ClaimViewer(ctx context.Context, id, clientIP string) (string, error)
+
+	// AttachReservation links a claimed redemption to the stream reservation it produced
+	AttachReservation(ctx context.Context, redemptionID, reservationID string) error
+
+	// ReleaseClaim frees a claimed slot whose stream could not be started
+	ReleaseClaim(ctx context.Context, redemptionID string) error
+
+	// ListActiveReservationIDs returns the reservations of the link's viewers still watching
+	ListActiveReservationIDs(ctx context.Context, id string) ([]string, error)
+
+	// ListEndedWithViewers returns expired or revoked links that still have viewers watching
+	ListEndedWithViewers(ctx context.Context) ([]*ShareLink, error)
+}
+
+// ShareLinkUseCase defines the interface for share link business logic
+type ShareLinkUseCase interface {
+	// CreateLink creates a share link and its signed token
+	CreateLink(ctx context.Context, request *CreateShareLinkRequest) (*ShareLinkCreated, error)
+
+	// GetLink retrieves a share link
+	GetLink(ctx context.Context, id string) (*ShareLink, error)
+
+	// ListLinks retrieves share links, optionally for one camera
+	ListLinks(ctx context.Context, cameraID string) ([]*ShareLink, error)
+
+	// RevokeLink revokes a share link and disconnects its viewers
+	RevokeLink(ctx context.Context, id string, request *RevokeShareLinkRequest) (*ShareLink, error)
+
+	// Redeem starts a view-only stream for a guest holding a share link token
+	Redeem(ctx context.Context, request *RedeemShareLinkRequest) (*StreamResponse, error)
+}
@@ -1,6 +1,21 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrReservationNotFound is returned when a stream reservation does not exist (released or expired)
+var ErrReservationNotFound = errors.New("reservation not found")
+
+// ErrViewerNotFound is returned when a viewer identity does not match an active viewer of the camera
+var ErrViewerNotFound = errors.New("viewer not found")
+
+// ErrEvictForbidden is returned when a caller outside the supervisor groups evicts a viewer
+var ErrEvictForbidden = errors.New("viewer eviction requires a supervisor")
+
+// ErrInvalidSessionQuery is returned when a viewing session query is malformed
+var ErrInvalidSessionQuery = errors.New("invalid session query")
 
 // StreamReservation represents an active stream reservation
 type StreamReservation struct {
@@ -8,7 +23,7 @@ type StreamReservation struct {
 	CameraID      string    `json:"camera_id"`
 	CameraName    string    `json:"camera_name"`
 	UserID        string    `json:"user_id"`
-	Source        string    `json:"source"`        // DUBAI_POLICE, METRO, BUS, OTHER
+	Source        string    `json:"source"`        // DUBAI_POLICE, METRO, BUS, OTHER, GUEST
 	RoomName      string    `json:"room_name"`     // LiveKit room name
 	Token         string    `json:"token"`         // LiveKit access token
 	IngressID     string    `json:"ingress_id"`    // LiveKit ingress ID for RTSP stream
@@ -22,6 +37,11 @@ type StreamRequest struct {
 	CameraID string `json:"camera_id" validate:"required,uuid"`
 	UserID   string `json:"user_id" validate:"required"`
 	Quality  string `json:"quality,omitempty"` // high, medium, low (default: medium)
+	ClientIP string `json:"-"`                 // Set by the HTTP handler from the request's remote address
+
+	// Set by guest share links only
+	QuotaSource string        `json:"-"` // Stream-counter quota to use instead of the camera's agency
+	ValidFor    time.Duration `json:"-"` // Shortens the one hour token and reservation lifetime
 }
 
 // StreamResponse represents the response after stream reservation
@@ -79,3 +99,59 @@ type AgencyLimitError struct {
 func (e *AgencyLimitError) Error() string {
 	return e.Message
 }
+
+// Viewer represents a LiveKit participant watching a camera
+type Viewer struct {
+	Identity      string    `json:"identity"`       // LiveKit participant identity (viewer_<reservation_id>)
+	ReservationID string    `json:"reservation_id"` // Stream-counter reservation backing this viewer
+	UserID        string    `json:"user_id"`        // Empty if the reservation has already expired
+	CameraID      string    `json:"camera_id"`
+	State         string    `json:"state"` // JOINING, JOINED, ACTIVE, DISCONNECTED
+	JoinedAt      time.Time `json:"joined_at"`
+}
+
+// EvictViewerRequest represents a request to disconnect a viewer
+// The supervisor performing it is the caller, never a body field
+type EvictViewerRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// Stream session statuses (match the streams table CHECK constraint)
+const (
+	StreamSessionActive   = "ACTIVE"
+	StreamSessionInactive = "INACTIVE"
+)
+
+// Stream session end reasons
+const (
+	SessionEndReleased = "released"
+	SessionEndEvicted  = "evicted"
+	SessionEndExpired  = "expired"
+)
+
+// StreamSession represents a persisted live viewing session
+type StreamSession struct {
+	ID            string     `json:"id"`
+	ReservationID string     `json:"reservation_id"`
+	CameraID      string     `json:"camera_id"`
+	Agency        string     `json:"agency"` // Source the session counted against
+	ViewerID      string     `json:"viewer_id"`
+	ViewerIP      string     `json:"viewer_ip,omitempty"`
+	RoomName      string     `json:"livekit_room_name"`
+	Status        string     `json:"status"`
+	EndReason     string     `json:"end_reason,omitempty"`
+	StartedAt     time.Time  `json:"started_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+	LastHeartbeat time.Time  `json:"last_heartbeat"`
+}
+
+// StreamSessionQuery represents a viewing session search query
+// From/To select sessions overlapping the window
+type StreamSessionQuery struct {
+	CameraID string
+	UserID   string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
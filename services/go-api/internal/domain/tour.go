@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// MinTourDwellSeconds is the shortest time a tour step can be shown
+// It must leave room to reserve the next camera ahead of the switch
+const MinTourDwellSeconds = 5
+
+// DefaultTourDwellSeconds is used when a tour does not set a dwell time
+const DefaultTourDwellSeconds = 10
+
+// ErrTourNotFound is returned when a tour does not exist
+var ErrTourNotFound = errors.New("tour not found")
+
+// ErrTourRunNotFound is returned when a tour run does not exist or has stopped
+var ErrTourRunNotFound = errors.New("tour run not found")
+
+// ErrInvalidTour is returned when a tour request is malformed
+var ErrInvalidTour = errors.New("invalid tour")
+
+// CameraTour represents a guard tour: cameras shown one after another in a layout cell
+type CameraTour struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description,omitempty"`
+	DwellSeconds int        `json:"dwell_seconds"` // Default time each step is shown
+	CreatedBy    string     `json:"created_by"`
+	IsActive     bool       `json:"is_active"`
+	StepCount    int        `json:"step_count"`
+	Steps        []TourStep `json:"steps,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TourStep represents one camera in a tour
+type TourStep struct {
+	StepIndex    int    `json:"step_index"`
+	CameraID     string `json:"camera_id"`
+	DwellSeconds int    `json:"dwell_seconds,omitempty"` // 0 uses the tour default
+	PresetID     *int   `json:"preset_id,omitempty"`     // PTZ preset recalled before the step is shown
+}
+
+// Dwell returns how long a step is shown
+func (t *CameraTour) Dwell(step TourStep) time.Duration {
+	if step.DwellSeconds > 0 {
+		return time.Duration(step.DwellSeconds) * time.Second
+	}
+	return time.Duration(t.DwellSeconds) * time.Second
+}
+
+// CreateTourRequest represents the request to create a tour
+type CreateTourRequest struct {
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	DwellSeconds int        `json:"dwell_seconds"`
+	CreatedBy    string     `json:"created_by"`
+	Steps        []TourStep `json:"steps"`
+}
+
+// UpdateTourRequest represents the request to update a tour
+type UpdateTourRequest struct {
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	DwellSeconds int        `json:"dwell_seconds"`
+	Steps        []TourStep `json:"steps"`
+}
+
+// StartTourRequest represents the request to play a tour in a layout cell
+type StartTourRequest struct {
+	UserID        string `json:"user_id"`
+	LayoutID      string `json:"layout_id,omitempty"`
+	PositionIndex int    `json:"position_index"`
+	Quality       string `json:"quality,omitempty"`
+	ClientIP      string `json:"-"`
+}
+
+// TourRun represents a tour being played in a layout cell
+// The engine keeps the current step's stream and, shortly before the switch, the next one
+type TourRun struct {
+	ID            string       `json:"id"`
+	TourID        string       `json:"tour_id"`
+	UserID        string       `json:"user_id"`
+	LayoutID      string       `json:"layout_id,omitempty"`
+	PositionIndex int          `json:"position_index"`
+	Current       *TourRunStep `json:"current"`
+	Next          *TourRunStep `json:"next,omitempty"` // Set once the next stream is reserved
+	SwitchAt      time.Time    `json:"switch_at"`
+	StartedAt     time.Time    `json:"started_at"`
+	LastKeepAlive time.Time    `json:"last_keepalive"`
+}
+
+// TourRunStep represents the stream of one tour step
+type TourRunStep struct {
+	StepIndex int             `json:"step_index"`
+	CameraID  string          `json:"camera_id"`
+	PresetID  *int            `json:"preset_id,omitempty"`
+	Stream    *StreamResponse `json:"stream,omitempty"`
+}
+
+// TourRepository defines the interface for tour data access
+type TourRepository interface {
+	// Create creates a new tour with its steps
+	Create(ctx context.Context, tour *CameraTour) error
+
+	// GetByID retrieves a tour with its steps
+	GetByID(ctx context.Context, id string) (*CameraTour, error)
+
+	// List retrieves all active tours without steps
+	List(ctx context.Context) ([]*CameraTour, error)
+
+	// Update replaces a tour's settings and steps
+	Update(ctx context.Context, id string, request *UpdateTourRequest) (*CameraTour, error)
+
+	// Delete deletes a tour by ID (soft delete)
+	Delete(ctx context.Context, id string) error
+}
+
+// TourUseCase defines the interface for tour business logic
+type TourUseCase interface {
+	// CreateTour creates a new tour
+	CreateTour(ctx context.Context, request *CreateTourRequest) (*CameraTour, error)
+
+	// GetTour retrieves a tour by ID
+	GetTour(ctx context.Context, id string) (*CameraTour, error)
+
+	// ListTours retrieves all tours
+	ListTours(ctx context.Context) ([]*CameraTour, error)
+
+	// UpdateTour updates an existing tour
+	UpdateTour(ctx context.Context, id string, request *UpdateTourRequest) (*CameraTour, error)
+
+	// DeleteTour deletes a tour by ID
+	DeleteTour(ctx context.Context, id string) error
+}
@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrVideoWallNotFound is returned when a video wall does not exist
+var ErrVideoWallNotFound = errors.New("video wall not found")
+
+// ErrWallMonitorNotFound is returned when a monitor does not exist on a video wall
+var ErrWallMonitorNotFound = errors.New("video wall monitor not found")
+
+// ErrInvalidVideoWall is returned when a video wall request is malformed
+var ErrInvalidVideoWall = errors.New("invalid video wall")
+
+// WallContentType represents what a wall monitor is showing
+type WallContentType string
+
+const (
+	WallContentLayout WallContentType = "layout"
+	WallContentCamera WallContentType = "camera"
+	WallContentBlank  WallContentType = "blank"
+)
+
+// VideoWall represents a physical video wall in the control room
+type VideoWall struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	CreatedBy   string        `json:"created_by"`
+	Monitors    []WallMonitor `json:"monitors"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// WallMonitor represents one monitor of a video wall, driven by a dashboard display client
+type WallMonitor struct {
+	ID            string          `json:"id"`
+	WallID        string          `json:"wall_id"`
+	Name          string          `json:"name"`
+	PositionIndex int             `json:"position_index"` // Left to right, top to bottom
+	ContentType   WallContentType `json:"content_type"`
+	LayoutID      string          `json:"layout_id,omitempty"`
+	LayoutName    string          `json:"layout_name,omitempty"`
+	CameraID      string          `json:"camera_id,omitempty"`
+	AssignedBy    string          `json:"assigned_by,omitempty"`
+	AssignedAt    *time.Time      `json:"assigned_at,omitempty"`
+	Online        bool            `json:"online"` // A display client is connected
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// CreateVideoWallRequest represents the request to create a video wall
+type CreateVideoWallRequest struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	CreatedBy   string               `json:"-"` // Set from the caller's identity
+	Monitors    []WallMonitorRequest `json:"monitors"`
+}
+
+// UpdateVideoWallRequest represents the request to update a video wall
+type UpdateVideoWallRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// WallMonitorRequest represents the request to add or update a wall monitor
+type WallMonitorRequest struct {
+	Name          string `json:"name"`
+	PositionIndex int    `json:"position_index"`
+}
+
+// WallDisplayRequest represents the request to show a layout or a camera on a monitor
+// Leaving both empty blanks the monitor
+type WallDisplayRequest struct {
+	LayoutID string `json:"layout_id,omitempty"`
+	CameraID string `json:"camera_id,omitempty"`
+}
+
+// WallDisplayAssignment is one monitor's content in a wall-wide display request
+type WallDisplayAssignment struct {
+	MonitorID string `json:"monitor_id"`
+	WallDisplayRequest
+}
+
+// WallDisplayBatchRequest represents the request to switch several monitors of a wall at once
+type WallDisplayBatchRequest struct {
+	Assignments []WallDisplayAssignment `json:"assignments"`
+}
+
+// WallCommand is the "switch to" command sent to a monitor's display client
+// Layouts are sent in full so the display does not need access to them
+type WallCommand struct {
+	WallID      string            `json:"wall_id"`
+	MonitorID   string            `json:"monitor_id"`
+	ContentType WallContentType   `json:"content_type"`
+	Layout      *LayoutPreference `json:"layout,omitempty"`
+	CameraID    string            `json:"camera_id,omitempty"`
+	IssuedBy    string            `json:"issued_by"`
+	IssuedAt    time.Time         `json:"issued_at"`
+}
+
+// VideoWallRepository defines the interface for video wall data access
+type VideoWallRepository interface {
+	// Create creates a new video wall with its monitors
+	Create(ctx context.Context, wall *VideoWall) error
+
+	// GetByID retrieves a video wall with its monitors
+	GetByID(ctx context.Context, id string) (*VideoWall, error)
+
+	// List retrieves all video walls with their monitors
+	List(ctx context.Context) ([]*VideoWall, error)
+
+	// Update updates a video wall's name and description
+	Update(ctx context.Context, id string, request *UpdateVideoWallRequest) (*VideoWall, error)
+
+	// Delete deletes a video wall and its monitors
+	Delete(ctx context.Context, id string) error
+
+	// AddMonitor adds a monitor to a video wall
+	AddMonitor(ctx context.Context, monitor *WallMonitor) error
+
+	// GetMonitor retrieves a monitor by ID
+	GetMonitor(ctx context.Context, monitorID string) (*WallMonitor, error)
+
+	// UpdateMonitor renames or moves a monitor
+	UpdateMonitor(ctx context.Context, wallID, monitorID string, request *WallMonitorRequest) (*WallMonitor, error)
+
+	// DeleteMonitor removes a monitor from a video wall
+	DeleteMonitor(ctx context.Context, wallID, monitorID string) error
+
+	// SetMonitorContent records what a monitor is showing; empty IDs blank it
+	SetMonitorContent(ctx context.Context, wallID, monitorID, layoutID, cameraID, assignedBy string) (*WallMonitor, error)
+}
+
+// VideoWallUseCase defines the interface for video wall business logic
+type VideoWallUseCase interface {
+	// CreateWall creates a new video wall
+	CreateWall(ctx context.Context, request *CreateVideoWallRequest) (*VideoWall, error)
+
+	// GetWall retrieves a video wall with the content and status of each monitor
+	GetWall(ctx context.Context, id string) (*VideoWall, error)
+
+	// ListWalls retrieves all video walls
+	ListWalls(ctx context.Context) ([]*VideoWall, error)
+
+	// UpdateWall updates a video wall
+	UpdateWall(ctx context.Context, id string, request *UpdateVideoWallRequest) (*VideoWall, error)
+
+	// DeleteWall deletes a video wall
+	DeleteWall(ctx context.Context, id string) error
+
+	// AddMonitor adds a monitor to a video wall
+	AddMonitor(ctx context.Context, wallID string, request *WallMonitorRequest) (*WallMonitor, error)
+
+	// UpdateMonitor renames or moves a monitor
+	UpdateMonitor(ctx context.Context, wallID, monitorID string, request *WallMonitorRequest) (*WallMonitor, error)
+
+	// DeleteMonitor removes a monitor from a video wall
+	DeleteMonitor(ctx context.Context, wallID, monitorID string) error
+
+	// Display shows a layout or a camera on a monitor
+	Display(ctx context.Context, wallID, monitorID string, request *WallDisplayRequest, actor *Actor) (*WallMonitor, error)
+
+	// DisplayBatch switches several monitors of a wall at once
+	DisplayBatch(ctx context.Context, wallID string, request *WallDisplayBatchRequest, actor *Actor) (*VideoWall, error)
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rta/cctv/go-api/internal/domain"
+)
+
+// AuditRepository handles audit log database operations
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// RecordEvent appends an event to the audit log
+func (r *AuditRepository) RecordEvent(ctx context.Context, event *domain.AuditEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	var detailsJSON []byte
+	if len(event.Details) > 0 {
+		var err error
+		detailsJSON, err = json.Marshal(event.Details)
+		if err != nil {
+			return fmt.Errorf("failed to marshal details: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO audit_log (
+			id, action, actor_id, target_type, target_id, camera_id, reason, details, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		event.ID,
+		event.Action,
+		event.ActorID,
+		event.TargetType,
+		event.TargetID,
+		sql.NullString{String: event.CameraID, Valid: event.CameraID != ""},
+		sql.NullString{String: event.Reason, Valid: event.Reason != ""},
+		detailsJSON,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// subtreeCTE selects a group and all of its descendants; $1 is the root group ID
+const subtreeCTE = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM camera_groups WHERE id = $1
+		UNION ALL
+		SELECT g.id FROM camera_groups g JOIN subtree s ON g.parent_id = s.id
+	)
+`
+
+// CameraGroupRepository implements domain.CameraGroupRepository using PostgreSQL
+type CameraGroupRepository struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+// NewCameraGroupRepository creates a new PostgreSQL camera group repository
+func NewCameraGroupRepository(db *sql.DB, logger zerolog.Logger) *CameraGroupRepository {
+	return &CameraGroupRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new camera group
+func (r *CameraGroupRepository) Create(ctx context.Context, group *domain.CameraGroup) error {
+	query := `
+		INSERT INTO camera_groups (parent_id, name, name_ar, description, sort_order, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		group.ParentID,
+		group.Name,
+		nullString(group.NameAr),
+		nullString(group.Description),
+		group.SortOrder,
+		group.CreatedBy,
+	).Scan(&group.ID, &group.CreatedAt, &group.UpdatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: a group named %q already exists here", domain.ErrInvalidCameraGroup, group.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert camera group: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a group with its direct camera IDs
+func (r *CameraGroupRepository) GetByID(ctx context.Context, id string) (*domain.CameraGroup, error) {
+	query := `
+		SELECT id, parent_id, name, name_ar, description, sort_order, created_by, created_at, updated_at
+		FROM camera_groups
+		WHERE id = $1
+	`
+
+	group, err := scanCameraGroup(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrCameraGroupNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera group: %w", err)
+	}
+
+	group.CameraIDs, err = r.ListCameraIDs(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	group.CameraCount = len(group.CameraIDs)
+
+	return group, nil
+}
+
+// List retrieves all groups (flat) with direct camera counts
+func (r *CameraGroupRepository) List(ctx context.Context) ([]*domain.CameraGroup, error) {
+	query := `
+		SELECT g.id, g.parent_id, g.name, g.name_ar, g.description, g.sort_order, g.created_by,
+		       g.created_at, g.updated_at, COUNT(m.camera_id) AS camera_count
+		FROM camera_groups g
+		LEFT JOIN camera_group_members m ON m.group_id = g.id
+		GROUP BY g.id
+		ORDER BY g.sort_order ASC, g.name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list camera groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []*domain.CameraGroup{}
+	for rows.Next() {
+		var group domain.CameraGroup
+		var parentID, nameAr, description sql.NullString
+
+		err := rows.Scan(
+			&group.ID, &parentID, &group.Name, &nameAr, &description, &group.SortOrder,
+			&group.CreatedBy, &group.CreatedAt, &group.UpdatedAt, &group.CameraCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan camera group: %w", err)
+		}
+
+		if parentID.Valid {
+			group.ParentID = &parentID.String
+		}
+		group.NameAr = nameAr.String
+		group.Description = description.String
+
+		groups = append(groups, &group)
+	}
+
+	return groups, rows.Err()
+}
+
+// Update updates a group's name, description, order and parent
+func (r *CameraGroupRepository) Update(ctx context.Context, id string, request *domain.UpdateCameraGroupRequest) (*domain.CameraGroup, error) {
+	query := `
+		UPDATE camera_groups
+		SET parent_id = $1, name = $2, name_ar = $3, description = $4, sort_order = $5, updated_at = NOW()
+		WHERE id = $6
+		RETURNING id, parent_id, name, name_ar, description, sort_order, created_by, created_at, updated_at
+	`
+
+	group, err := scanCameraGroup(r.db.QueryRowContext(ctx, query,
+		request.ParentID,
+		request.Name,
+		nullString(request.NameAr),
+		nullString(request.Description),
+		request.SortOrder,
+		id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrCameraGroupNotFound, id)
+	}
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("%w: a group named %q already exists here", domain.ErrInvalidCameraGroup, request.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update camera group: %w", err)
+	}
+
+	r.logger.Info().
+		Str("group_id", id).
+		Str("name", request.Name).
+		Msg("Camera group updated successfully")
+
+	return group, nil
+}
+
+// Delete deletes a group; subgroups and memberships are removed by cascade
+func (r *CameraGroupRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM camera_groups WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete camera group: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrCameraGroupNotFound, id)
+	}
+
+	r.logger.Info().
+		Str("group_id", id).
+		Msg("Camera group deleted successfully")
+
+	return nil
+}
+
+// SubtreeIDs returns the IDs of a group and all of its descendants
+func (r *CameraGroupRepository) SubtreeIDs(ctx context.Context, id string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, subtreeCTE+"SELECT id FROM subtree", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve camera group subtree: %w", err)
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+// AddCameras adds cameras to a group and returns the IDs that do not exist
+// Cameras already in the group are left untouched
+func (r *CameraGroupRepository) AddCameras(ctx context.Context, groupID string, cameraIDs []string) (int, []string, error) {
+	// Only insert cameras that exist so one bad ID does not fail the batch
+	rows, err := r.db.QueryContext(ctx, `
+		WITH inserted AS (
+			INSERT INTO camera_group_members (group_id, camera_id)
+			SELECT $1, id FROM cameras WHERE id = ANY($2)
+			ON CONFLICT (group_id, camera_id) DO NOTHING
+			RETURNING camera_id
+		)
+		SELECT requested.id, EXISTS (SELECT 1 FROM cameras c WHERE c.id = requested.id), requested.id IN (SELECT camera_id FROM inserted)
+		FROM unnest($2::VARCHAR[]) AS requested(id)
+	`, groupID, pq.Array(cameraIDs))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to add cameras to group: %w", err)
+	}
+	defer rows.Close()
+
+	added := 0
+	unknown := []string{}
+	for rows.Next() {
+		var cameraID string
+		var exists, inserted bool
+		if err := rows.Scan(&cameraID, &exists, &inserted); err != nil {
+			return 0, nil, fmt.Errorf("failed to scan group membership: %w", err)
+		}
+		if !exists {
+			unknown = append(unknown, cameraID)
+		}
+		if inserted {
+			added++
+		}
+	}
+
+	return added, unknown, rows.Err()
+}
+
+// RemoveCamera removes a camera from a group
+func (r *CameraGroupRepository) RemoveCamera(ctx context.Context, groupID, cameraID string) error {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM camera_group_members WHERE group_id = $1 AND camera_id = $2",
+		groupID, cameraID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove camera from group: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: camera %s is not in group %s", domain.ErrCameraNotFound, cameraID, groupID)
+	}
+
+	return nil
+}
+
+// ListCameraIDs lists the cameras of a group, optionally including all subgroups
+// Cameras are ordered by name so layouts built from a group are stable
+func (r *CameraGroupRepository) ListCameraIDs(ctx context.Context, groupID string, includeSubgroups bool) ([]string, error) {
+	query := `
+		SELECT c.id
+		FROM cameras c
+		JOIN camera_group_members m ON m.camera_id = c.id
+		WHERE m.group_id = $1
+		ORDER BY c.name ASC
+	`
+	if includeSubgroups {
+		query = subtreeCTE + `
+			SELECT DISTINCT ON (c.name, c.id) c.id
+			FROM cameras c
+			JOIN camera_group_members m ON m.camera_id = c.id
+			WHERE m.group_id IN (SELECT id FROM subtree)
+			ORDER BY c.name ASC, c.id ASC
+		`
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group cameras: %w", err)
+	}
+	defer rows.Close()
+
+	return scanStrings(rows)
+}
+
+// ListMemberships returns the direct camera IDs of every group keyed by group ID
+func (r *CameraGroupRepository) ListMemberships(ctx context.Context) (map[string][]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.group_id, m.camera_id
+		FROM camera_group_members m
+		JOIN cameras c ON c.id = m.camera_id
+		ORDER BY c.name ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group memberships: %w", err)
+	}
+	defer rows.Close()
+
+	memberships := map[string][]string{}
+	for rows.Next() {
+		var groupID, cameraID string
+		if err := rows.Scan(&groupID, &cameraID); err != nil {
+			return nil, fmt.Errorf("failed to scan group membership: %w", err)
+		}
+		memberships[groupID] = append(memberships[groupID], cameraID)
+	}
+
+	return memberships, rows.Err()
+}
+
+// scanCameraGroup scans a single camera group row
+func scanCameraGroup(row *sql.Row) (*domain.CameraGroup, error) {
+	var group domain.CameraGroup
+	var parentID, nameAr, description sql.NullString
+
+	err := row.Scan(
+		&group.ID, &parentID, &group.Name, &nameAr, &description, &group.SortOrder,
+		&group.CreatedBy, &group.CreatedAt, &group.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID.Valid {
+		group.ParentID = &parentID.String
+	}
+	group.NameAr = nameAr.String
+	group.Description = description.String
+
+	return &group, nil
+}
+
+// scanStrings collects a single string column from all rows
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// nullString maps an empty string to SQL NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -2,10 +2,13 @@ package postgres
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/rta/cctv/go-api/internal/domain"
 	"github.com/rs/zerolog"
 )
@@ -63,25 +66,14 @@ func (r *LayoutRepository) Create(layout *domain.LayoutPreference) error {
 	}
 
 	// Insert camera assignments
-	cameraQuery := `
-		INSERT INTO layout_camera_assignments (
-			id, layout_id, camera_id, position_index, cell_size, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6)
-	`
+	if err := insertCameraAssignments(tx, layout.ID, layout.Cameras, now); err != nil {
+		return err
+	}
 
-	for _, camera := range layout.Cameras {
-		cameraID := uuid.New().String()
-		_, err = tx.Exec(cameraQuery,
-			cameraID,
-			layout.ID,
-			camera.CameraID,
-			camera.PositionIndex,
-			camera.CellSize,
-			now,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to insert camera assignment: %w", err)
-		}
+	// Start the version history
+	layout.Version = 1
+	if err := insertLayoutVersion(tx, layout, layout.CreatedBy, domain.LayoutChangeCreate, now); err != nil {
+		return err
 	}
 
 	// Commit transaction
@@ -105,34 +97,37 @@ func (r *LayoutRepository) Create(layout *domain.LayoutPreference) error {
 func (r *LayoutRepository) GetByID(id string) (*domain.LayoutPreference, error) {
 	// Get layout preference
 	query := `
-		SELECT id, name, description, layout_type, grid_layout, scope, created_by, is_active, created_at, updated_at
+		SELECT id, name, description, layout_type, grid_layout, scope, created_by, is_active, version, created_at, updated_at
 		FROM layout_preferences
 		WHERE id = $1 AND is_active = true
 	`
 
 	layout := &domain.LayoutPreference{}
+	var description sql.NullString
 	err := r.db.QueryRow(query, id).Scan(
 		&layout.ID,
 		&layout.Name,
-		&layout.Description,
+		&description,
 		&layout.LayoutType,
 		&layout.GridLayout,
 		&layout.Scope,
 		&layout.CreatedBy,
 		&layout.IsActive,
+		&layout.Version,
 		&layout.CreatedAt,
 		&layout.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("layout not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", domain.ErrLayoutNotFound, id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get layout: %w", err)
 	}
+	layout.Description = description.String
 
 	// Get camera assignments
 	cameraQuery := `
-		SELECT id, camera_id, position_index, cell_size, created_at
+		SELECT id, camera_id, tour_id, position_index, cell_size, created_at
 		FROM layout_camera_assignments
 		WHERE layout_id = $1
 		ORDER BY position_index ASC
@@ -147,11 +142,12 @@ func (r *LayoutRepository) GetByID(id string) (*domain.LayoutPreference, error)
 	cameras := []domain.LayoutCameraAssignment{}
 	for rows.Next() {
 		var camera domain.LayoutCameraAssignment
-		var cellSize sql.NullString
+		var cameraID, tourID, cellSize sql.NullString
 
 		err := rows.Scan(
 			&camera.ID,
-			&camera.CameraID,
+			&cameraID,
+			&tourID,
 			&camera.PositionIndex,
 			&cellSize,
 			&camera.CreatedAt,
@@ -161,6 +157,8 @@ func (r *LayoutRepository) GetByID(id string) (*domain.LayoutPreference, error)
 		}
 
 		camera.LayoutID = id
+		camera.CameraID = cameraID.String
+		camera.TourID = tourID.String
 		if cellSize.Valid {
 			camera.CellSize = cellSize.String
 		}
@@ -172,9 +170,23 @@ func (r *LayoutRepository) GetByID(id string) (*domain.LayoutPreference, error)
 	return layout, nil
 }
 
-// List retrieves layouts with optional filtering
-func (r *LayoutRepository) List(layoutType *domain.LayoutType, scope *domain.LayoutScope, createdBy *string) ([]*domain.LayoutPreferenceSummary, error) {
+// List retrieves the layouts visible to an actor with optional filtering
+// Visible means global, created by the actor, or shared with the actor or one of its groups
+func (r *LayoutRepository) List(layoutType *domain.LayoutType, scope *domain.LayoutScope, createdBy *string, actor *domain.Actor) ([]*domain.LayoutPreferenceSummary, error) {
+	userID, groups := "", []string{}
+	if actor != nil {
+		userID, groups = actor.UserID, actor.Groups
+	}
+
+	// $1 = actor user ID, $2 = actor groups
 	query := `
+		WITH actor_shares AS (
+			SELECT layout_id, bool_or(permission = 'edit') AS can_edit
+			FROM layout_shares
+			WHERE (principal_type = 'user' AND principal_id = $1)
+			   OR (principal_type = 'group' AND principal_id = ANY($2))
+			GROUP BY layout_id
+		)
 		SELECT
 			lp.id,
 			lp.name,
@@ -183,16 +195,24 @@ func (r *LayoutRepository) List(layoutType *domain.LayoutType, scope *domain.Lay
 			lp.grid_layout,
 			lp.scope,
 			lp.created_by,
+			lp.version,
+			CASE
+				WHEN $1 <> '' AND lp.created_by = $1 THEN 'owner'
+				WHEN s.can_edit THEN 'edit'
+				ELSE 'view'
+			END AS permission,
 			lp.created_at,
 			lp.updated_at,
 			COUNT(lca.id) as camera_count
 		FROM layout_preferences lp
 		LEFT JOIN layout_camera_assignments lca ON lp.id = lca.layout_id
+		LEFT JOIN actor_shares s ON s.layout_id = lp.id
 		WHERE lp.is_active = true
+		  AND (lp.scope = 'global' OR ($1 <> '' AND lp.created_by = $1) OR s.layout_id IS NOT NULL)
 	`
 
-	args := []interface{}{}
-	argCount := 1
+	args := []interface{}{userID, pq.Array(groups)}
+	argCount := 3
 
 	if layoutType != nil {
 		query += fmt.Sprintf(" AND lp.layout_type = $%d", argCount)
@@ -212,7 +232,7 @@ func (r *LayoutRepository) List(layoutType *domain.LayoutType, scope *domain.Lay
 		argCount++
 	}
 
-	query += " GROUP BY lp.id, lp.name, lp.description, lp.layout_type, lp.scope, lp.created_by, lp.created_at, lp.updated_at"
+	query += " GROUP BY lp.id, s.can_edit"
 	query += " ORDER BY lp.updated_at DESC"
 
 	rows, err := r.db.Query(query, args...)
@@ -234,6 +254,8 @@ func (r *LayoutRepository) List(layoutType *domain.LayoutType, scope *domain.Lay
 		&layout.GridLayout,
 			&layout.Scope,
 			&layout.CreatedBy,
+			&layout.Version,
+			&layout.Permission,
 			&layout.CreatedAt,
 			&layout.UpdatedAt,
 			&layout.CameraCount,
@@ -252,8 +274,8 @@ func (r *LayoutRepository) List(layoutType *domain.LayoutType, scope *domain.Lay
 	return layouts, nil
 }
 
-// Update updates an existing layout
-func (r *LayoutRepository) Update(id string, request *domain.UpdateLayoutRequest) (*domain.LayoutPreference, error) {
+// Update updates an existing layout if it is still at request.Version, and records the new version
+func (r *LayoutRepository) Update(id string, request *domain.UpdateLayoutRequest, changedBy, changeType string) (*domain.LayoutPreference, error) {
 	// Start transaction
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -261,12 +283,12 @@ func (r *LayoutRepository) Update(id string, request *domain.UpdateLayoutRequest
 	}
 	defer tx.Rollback()
 
-	// Update layout preference
+	// Update layout preference only if nobody else changed it in the meantime
 	query := `
 		UPDATE layout_preferences
-		SET name = $1, description = $2, updated_at = $3
-		WHERE id = $4 AND is_active = true
-		RETURNING created_by, layout_type, scope, is_active, created_at
+		SET name = $1, description = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND is_active = true AND version = $5
+		RETURNING created_by, layout_type, grid_layout, scope, is_active, version, created_at
 	`
 
 	now := time.Now()
@@ -277,16 +299,26 @@ func (r *LayoutRepository) Update(id string, request *domain.UpdateLayoutRequest
 		UpdatedAt:   now,
 	}
 
-	err = tx.QueryRow(query, request.Name, request.Description, now, id).Scan(
+	err = tx.QueryRow(query, request.Name, request.Description, now, id, request.Version).Scan(
 		&layout.CreatedBy,
 		&layout.LayoutType,
 		&layout.GridLayout,
 		&layout.Scope,
 		&layout.IsActive,
+		&layout.Version,
 		&layout.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("layout not found: %s", id)
+		// Either the layout is gone or it moved past the expected version
+		var current int
+		err := tx.QueryRow("SELECT version FROM layout_preferences WHERE id = $1 AND is_active = true", id).Scan(&current)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", domain.ErrLayoutNotFound, id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check layout version: %w", err)
+		}
+		return nil, fmt.Errorf("%w: layout is at version %d, not %d", domain.ErrLayoutVersionConflict, current, request.Version)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update layout: %w", err)
@@ -299,25 +331,15 @@ func (r *LayoutRepository) Update(id string, request *domain.UpdateLayoutRequest
 	}
 
 	// Insert new camera assignments
-	cameraQuery := `
-		INSERT INTO layout_camera_assignments (
-			id, layout_id, camera_id, position_index, cell_size, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6)
-	`
+	if err := insertCameraAssignments(tx, id, request.Cameras, now); err != nil {
+		return nil, err
+	}
 
-	for _, camera := range request.Cameras {
-		cameraID := uuid.New().String()
-		_, err = tx.Exec(cameraQuery,
-			cameraID,
-			id,
-			camera.CameraID,
-			camera.PositionIndex,
-			camera.CellSize,
-			now,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to insert camera assignment: %w", err)
-		}
+	// Set cameras in response
+	layout.Cameras = request.Cameras
+
+	if err := insertLayoutVersion(tx, layout, changedBy, changeType, now); err != nil {
+		return nil, err
 	}
 
 	// Commit transaction
@@ -325,12 +347,11 @@ func (r *LayoutRepository) Update(id string, request *domain.UpdateLayoutRequest
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// Set cameras in response
-	layout.Cameras = request.Cameras
-
 	r.logger.Info().
 		Str("layout_id", id).
 		Str("name", request.Name).
+		Int("version", layout.Version).
+		Str("changed_by", changedBy).
 		Msg("Layout updated successfully")
 
 	return layout, nil
@@ -355,7 +376,7 @@ func (r *LayoutRepository) Delete(id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("layout not found: %s", id)
+		return fmt.Errorf("%w: %s", domain.ErrLayoutNotFound, id)
 	}
 
 	r.logger.Info().
@@ -364,3 +385,213 @@ func (r *LayoutRepository) Delete(id string) error {
 
 	return nil
 }
+
+// GetShares retrieves the shares of a layout
+func (r *LayoutRepository) GetShares(id string) ([]domain.LayoutShare, error) {
+	rows, err := r.db.Query(`
+		SELECT principal_type, principal_id, permission, granted_by, created_at
+		FROM layout_shares
+		WHERE layout_id = $1
+		ORDER BY principal_type, principal_id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get layout shares: %w", err)
+	}
+	defer rows.Close()
+
+	shares := []domain.LayoutShare{}
+	for rows.Next() {
+		var share domain.LayoutShare
+		err := rows.Scan(
+			&share.PrincipalType,
+			&share.PrincipalID,
+			&share.Permission,
+			&share.GrantedBy,
+			&share.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan layout share: %w", err)
+		}
+		shares = append(shares, share)
+	}
+
+	return shares, rows.Err()
+}
+
+// ReplaceShares replaces all shares of a layout
+func (r *LayoutRepository) ReplaceShares(id string, shares []domain.LayoutShare, grantedBy string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM layout_shares WHERE layout_id = $1", id); err != nil {
+		return fmt.Errorf("failed to delete layout shares: %w", err)
+	}
+
+	for _, share := range shares {
+		_, err := tx.Exec(`
+			INSERT INTO layout_shares (layout_id, principal_type, principal_id, permission, granted_by)
+			VALUES ($1, $2, $3, $4, $5)
+		`, id, share.PrincipalType, share.PrincipalID, share.Permission, grantedBy)
+		if err != nil {
+			return fmt.Errorf("failed to insert layout share: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.logger.Info().
+		Str("layout_id", id).
+		Int("shares", len(shares)).
+		Str("granted_by", grantedBy).
+		Msg("Layout shares updated")
+
+	return nil
+}
+
+// ListVersions retrieves the version history of a layout, newest first
+func (r *LayoutRepository) ListVersions(id string) ([]*domain.LayoutVersion, error) {
+	rows, err := r.db.Query(`
+		SELECT version, name, description, layout_type, grid_layout, cameras, changed_by, change_type, created_at
+		FROM layout_versions
+		WHERE layout_id = $1
+		ORDER BY version DESC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list layout versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []*domain.LayoutVersion{}
+	for rows.Next() {
+		version, err := scanLayoutVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// GetVersion retrieves one version of a layout
+func (r *LayoutRepository) GetVersion(id string, version int) (*domain.LayoutVersion, error) {
+	row := r.db.QueryRow(`
+		SELECT version, name, description, layout_type, grid_layout, cameras, changed_by, change_type, created_at
+		FROM layout_versions
+		WHERE layout_id = $1 AND version = $2
+	`, id, version)
+
+	layoutVersion, err := scanLayoutVersion(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s version %d", domain.ErrLayoutVersionNotFound, id, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return layoutVersion, nil
+}
+
+// insertCameraAssignments inserts the camera assignments of a layout
+func insertCameraAssignments(tx *sql.Tx, layoutID string, cameras []domain.LayoutCameraAssignment, now time.Time) error {
+	cameraQuery := `
+		INSERT INTO layout_camera_assignments (
+			id, layout_id, camera_id, tour_id, position_index, cell_size, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	for _, camera := range cameras {
+		_, err := tx.Exec(cameraQuery,
+			uuid.New().String(),
+			layoutID,
+			nullString(camera.CameraID),
+			nullString(camera.TourID),
+			camera.PositionIndex,
+			camera.CellSize,
+			now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert camera assignment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// insertLayoutVersion records a snapshot of the layout at its current version
+func insertLayoutVersion(tx *sql.Tx, layout *domain.LayoutPreference, changedBy, changeType string, now time.Time) error {
+	cameras := layout.Cameras
+	if cameras == nil {
+		cameras = []domain.LayoutCameraAssignment{}
+	}
+
+	camerasJSON, err := json.Marshal(cameras)
+	if err != nil {
+		return fmt.Errorf("failed to marshal layout cameras: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO layout_versions (
+			layout_id, version, name, description, layout_type, grid_layout, cameras, changed_by, change_type, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		layout.ID,
+		layout.Version,
+		layout.Name,
+		nullString(layout.Description),
+		layout.LayoutType,
+		layout.GridLayout,
+		camerasJSON,
+		changedBy,
+		changeType,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert layout version: %w", err)
+	}
+
+	return nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanLayoutVersion scans a layout_versions row
+func scanLayoutVersion(row rowScanner) (*domain.LayoutVersion, error) {
+	var version domain.LayoutVersion
+	var description, gridLayout sql.NullString
+	var camerasJSON []byte
+
+	err := row.Scan(
+		&version.Version,
+		&version.Name,
+		&description,
+		&version.LayoutType,
+		&gridLayout,
+		&camerasJSON,
+		&version.ChangedBy,
+		&version.ChangeType,
+		&version.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan layout version: %w", err)
+	}
+
+	version.Description = description.String
+	version.GridLayout = gridLayout.String
+	if err := json.Unmarshal(camerasJSON, &version.Cameras); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal layout version cameras: %w", err)
+	}
+
+	return &version, nil
+}
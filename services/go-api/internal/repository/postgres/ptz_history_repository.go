@@ -0,0 +1,267 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// PTZHistoryRepository implements domain.PTZHistoryRepository using PostgreSQL
+type PTZHistoryRepository struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+// NewPTZHistoryRepository creates a new PostgreSQL PTZ history repository
+func NewPTZHistoryRepository(db *sql.DB, logger zerolog.Logger) *PTZHistoryRepository {
+	return &PTZHistoryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Record appends a command to the history
+func (r *PTZHistoryRepository) Record(ctx context.Context, record *domain.PTZCommandRecord) error {
+	var speed sql.NullFloat64
+	if record.Speed != nil {
+		speed = sql.NullFloat64{Float64: *record.Speed, Valid: true}
+	}
+	var presetID sql.NullInt64
+	if record.PresetID != nil {
+		presetID = sql.NullInt64{Int64: int64(*record.PresetID), Valid: true}
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO ptz_commands (
+			camera_id, user_id, role, source, command, speed, preset_id, result, error, latency_ms
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`,
+		record.CameraID,
+		record.UserID,
+		nullString(record.Role),
+		record.Source,
+		record.Command,
+		speed,
+		presetID,
+		record.Result,
+		nullString(record.Error),
+		record.LatencyMS,
+	).Scan(&record.ID, &record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert PTZ command: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves commands, newest first
+func (r *PTZHistoryRepository) List(ctx context.Context, query domain.PTZHistoryQuery) ([]*domain.PTZCommandRecord, error) {
+	sqlQuery := `
+		SELECT id, camera_id, user_id, role, source, command, speed, preset_id, result, error, latency_ms, created_at
+		FROM ptz_commands
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argCount := 1
+
+	if query.CameraID != "" {
+		sqlQuery += fmt.Sprintf(" AND camera_id = $%d", argCount)
+		args = append(args, query.CameraID)
+		argCount++
+	}
+
+	if query.UserID != "" {
+		sqlQuery += fmt.Sprintf(" AND user_id = $%d", argCount)
+		args = append(args, query.UserID)
+		argCount++
+	}
+
+	if query.From != nil {
+		sqlQuery += fmt.Sprintf(" AND created_at >= $%d", argCount)
+		args = append(args, *query.From)
+		argCount++
+	}
+
+	if query.To != nil {
+		sqlQuery += fmt.Sprintf(" AND created_at <= $%d", argCount)
+		args = append(args, *query.To)
+		argCount++
+	}
+
+	sqlQuery += " ORDER BY created_at DESC"
+
+	if query.Limit > 0 {
+		sqlQuery += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, query.Limit)
+		argCount++
+	}
+
+	if query.Offset > 0 {
+		sqlQuery += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, query.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PTZ commands: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*domain.PTZCommandRecord{}
+	for rows.Next() {
+		var record domain.PTZCommandRecord
+		var role, commandErr sql.NullString
+		var speed sql.NullFloat64
+		var presetID sql.NullInt64
+
+		err := rows.Scan(
+			&record.ID, &record.CameraID, &record.UserID, &role, &record.Source, &record.Command,
+			&speed, &presetID, &record.Result, &commandErr, &record.LatencyMS, &record.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PTZ command: %w", err)
+		}
+
+		record.Role = role.String
+		record.Error = commandErr.String
+		if speed.Valid {
+			record.Speed = &speed.Float64
+		}
+		if presetID.Valid {
+			id := int(presetID.Int64)
+			record.PresetID = &id
+		}
+
+		records = append(records, &record)
+	}
+
+	return records, rows.Err()
+}
+
+// GetHomePolicy retrieves a camera's return-to-home policy
+func (r *PTZHistoryRepository) GetHomePolicy(ctx context.Context, cameraID string) (*domain.PTZHomePolicy, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT camera_id, idle_minutes, preset_id, enabled, updated_by, updated_at
+		FROM ptz_home_policies
+		WHERE camera_id = $1
+	`, cameraID)
+
+	policy, err := scanHomePolicy(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrPTZHomePolicyNotFound, cameraID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// SetHomePolicy creates or replaces a camera's return-to-home policy
+func (r *PTZHistoryRepository) SetHomePolicy(ctx context.Context, policy *domain.PTZHomePolicy) error {
+	var presetID sql.NullInt64
+	if policy.PresetID != nil {
+		presetID = sql.NullInt64{Int64: int64(*policy.PresetID), Valid: true}
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO ptz_home_policies (camera_id, idle_minutes, preset_id, enabled, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (camera_id) DO UPDATE SET
+			idle_minutes = EXCLUDED.idle_minutes,
+			preset_id = EXCLUDED.preset_id,
+			enabled = EXCLUDED.enabled,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`,
+		policy.CameraID,
+		policy.IdleMinutes,
+		presetID,
+		policy.Enabled,
+		policy.UpdatedBy,
+	).Scan(&policy.UpdatedAt)
+	if isForeignKeyViolation(err) {
+		return fmt.Errorf("%w: %s", domain.ErrCameraNotFound, policy.CameraID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save PTZ home policy: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteHomePolicy removes a camera's return-to-home policy
+func (r *PTZHistoryRepository) DeleteHomePolicy(ctx context.Context, cameraID string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM ptz_home_policies WHERE camera_id = $1", cameraID)
+	if err != nil {
+		return fmt.Errorf("failed to delete PTZ home policy: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrPTZHomePolicyNotFound, cameraID)
+	}
+
+	return nil
+}
+
+// ListHomeReturnsDue returns the enabled policies of cameras idle for their idle period
+// whose last command was not a successful return home
+// Refused commands do not count as movement; a failed return home is retried after another idle period.
+// Cameras never moved through go-api have no history and are left alone.
+func (r *PTZHistoryRepository) ListHomeReturnsDue(ctx context.Context) ([]*domain.PTZHomePolicy, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.camera_id, p.idle_minutes, p.preset_id, p.enabled, p.updated_by, p.updated_at
+		FROM ptz_home_policies p
+		JOIN LATERAL (
+			SELECT c.source, c.result, c.created_at
+			FROM ptz_commands c
+			WHERE c.camera_id = p.camera_id AND c.result <> $1
+			ORDER BY c.created_at DESC
+			LIMIT 1
+		) last ON TRUE
+		WHERE p.enabled
+		  AND NOT (last.source = $2 AND last.result = $3)
+		  AND last.created_at < NOW() - make_interval(mins => p.idle_minutes)
+	`, domain.PTZResultLocked, domain.PTZSourceHomePolicy, domain.PTZResultSuccess)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due PTZ home returns: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []*domain.PTZHomePolicy{}
+	for rows.Next() {
+		policy, err := scanHomePolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+// scanHomePolicy reads camera_id, idle_minutes, preset_id, enabled, updated_by, updated_at
+func scanHomePolicy(row rowScanner) (*domain.PTZHomePolicy, error) {
+	var policy domain.PTZHomePolicy
+	var presetID sql.NullInt64
+
+	err := row.Scan(&policy.CameraID, &policy.IdleMinutes, &presetID, &policy.Enabled, &policy.UpdatedBy, &policy.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan PTZ home policy: %w", err)
+	}
+
+	if presetID.Valid {
+		id := int(presetID.Int64)
+		policy.PresetID = &id
+	}
+
+	return &policy, nil
+}
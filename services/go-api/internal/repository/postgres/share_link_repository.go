@@ -0,0 +1,319 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// activeRedemption matches redemptions r whose guest is still watching, or whose stream is
+// still being started; a claim that never got a reservation stops counting after two minutes
+const activeRedemption = `(
+	(r.reservation_id IS NULL AND r.redeemed_at > NOW() - INTERVAL '2 minutes')
+	OR EXISTS (SELECT 1 FROM streams s WHERE s.reservation_id = r.reservation_id AND s.ended_at IS NULL)
+)`
+
+// shareLinkColumns are the columns read by scanShareLink; l is share_links
+const shareLinkColumns = `
+	l.id, l.camera_id, l.label, l.created_by, l.expires_at, l.max_viewers, l.pin_hash, l.pin_failures,
+	l.revoked_at, l.revoked_by, l.revoke_reason, l.created_at,
+	(SELECT COUNT(*) FROM share_link_redemptions r WHERE r.link_id = l.id AND ` + activeRedemption + `),
+	(SELECT COUNT(*) FROM share_link_redemptions r WHERE r.link_id = l.id)
+`
+
+// ShareLinkRepository implements domain.ShareLinkRepository using PostgreSQL
+type ShareLinkRepository struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+// NewShareLinkRepository creates a new PostgreSQL share link repository
+func NewShareLinkRepository(db *sql.DB, logger zerolog.Logger) *ShareLinkRepository {
+	return &ShareLinkRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new share link; the ID is chosen by the caller because the PIN hash depends on it
+func (r *ShareLinkRepository) Create(ctx context.Context, link *domain.ShareLink) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO share_links (id, camera_id, label, created_by, expires_at, max_viewers, pin_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`,
+		link.ID,
+		link.CameraID,
+		nullString(link.Label),
+		link.CreatedBy,
+		link.ExpiresAt,
+		link.MaxViewers,
+		nullString(link.PINHash),
+	).Scan(&link.CreatedAt)
+	if isForeignKeyViolation(err) {
+		return fmt.Errorf("%w: %s", domain.ErrCameraNotFound, link.CameraID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert share link: %w", err)
+	}
+
+	link.Status = shareLinkStatus(link)
+	return nil
+}
+
+// GetByID retrieves a share link with its viewer counts
+func (r *ShareLinkRepository) GetByID(ctx context.Context, id string) (*domain.ShareLink, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+shareLinkColumns+" FROM share_links l WHERE l.id = $1", id)
+
+	link, err := scanShareLink(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrShareLinkNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// List retrieves share links, newest first; an empty camera ID lists them all
+func (r *ShareLinkRepository) List(ctx context.Context, cameraID string) ([]*domain.ShareLink, error) {
+	query := "SELECT " + shareLinkColumns + " FROM share_links l WHERE ($1 = '' OR l.camera_id = $1) ORDER BY l.created_at DESC"
+	return r.list(ctx, query, cameraID)
+}
+
+// Revoke marks a share link revoked; revoking twice keeps the first revocation
+func (r *ShareLinkRepository) Revoke(ctx context.Context, id string, request *domain.RevokeShareLinkRequest) (*domain.ShareLink, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE share_links
+		SET revoked_at = COALESCE(revoked_at, NOW()),
+		    revoked_by = COALESCE(revoked_by, $2),
+		    revoke_reason = COALESCE(revoke_reason, $3)
+		WHERE id = $1
+	`, id, request.ActorID, nullString(request.Reason))
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke share link: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrShareLinkNotFound, id)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// RecordPINFailure counts a wrong PIN and returns the failures so far
+func (r *ShareLinkRepository) RecordPINFailure(ctx context.Context, id string) (int, error) {
+	var failures int
+	err := r.db.QueryRowContext(ctx,
+		"UPDATE share_links SET pin_failures = pin_failures + 1 WHERE id = $1 RETURNING pin_failures",
+		id,
+	).Scan(&failures)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %s", domain.ErrShareLinkNotFound, id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to record PIN failure: %w", err)
+	}
+
+	return failures, nil
+}
+
+// ClaimViewer reserves a viewer slot on a usable link and returns the redemption ID
+// The link row is locked so concurrent redemptions cannot exceed the viewer limit
+func (r *ShareLinkRepository) ClaimViewer(ctx context.Context, id, clientIP string) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var link domain.ShareLink
+	var revokedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		SELECT expires_at, max_viewers, pin_failures, revoked_at
+		FROM share_links
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&link.ExpiresAt, &link.MaxViewers, &link.PINFailures, &revokedAt)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: %s", domain.ErrShareLinkNotFound, id)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to lock share link: %w", err)
+	}
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+
+	if status := shareLinkStatus(&link); status != domain.ShareLinkActive {
+		return "", fmt.Errorf("%w: link is %s", domain.ErrShareLinkUnavailable, status)
+	}
+
+	var active int
+	err = tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM share_link_redemptions r WHERE r.link_id = $1 AND "+activeRedemption,
+		id,
+	).Scan(&active)
+	if err != nil {
+		return "", fmt.Errorf("failed to count share link viewers: %w", err)
+	}
+	if active >= link.MaxViewers {
+		return "", fmt.Errorf("%w: %d/%d", domain.ErrShareLinkFull, active, link.MaxViewers)
+	}
+
+	var redemptionID string
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO share_link_redemptions (link_id, client_ip) VALUES ($1, $2) RETURNING id",
+		id, nullString(clientIP),
+	).Scan(&redemptionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert share link redemption: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return redemptionID, nil
+}
+
+// AttachReservation links a claimed redemption to the stream reservation it produced
+func (r *ShareLinkRepository) AttachReservation(ctx context.Context, redemptionID, reservationID string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE share_link_redemptions SET reservation_id = $2 WHERE id = $1",
+		redemptionID, reservationID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to attach reservation to share link redemption: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseClaim frees a claimed slot whose stream could not be started
+func (r *ShareLinkRepository) ReleaseClaim(ctx context.Context, redemptionID string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM share_link_redemptions WHERE id = $1 AND reservation_id IS NULL", redemptionID)
+	if err != nil {
+		return fmt.Errorf("failed to release share link claim: %w", err)
+	}
+
+	return nil
+}
+
+// ListActiveReservationIDs returns the reservations of the link's viewers still watching
+func (r *ShareLinkRepository) ListActiveReservationIDs(ctx context.Context, id string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.reservation_id
+		FROM share_link_redemptions r
+		JOIN streams s ON s.reservation_id = r.reservation_id
+		WHERE r.link_id = $1 AND s.ended_at IS NULL
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share link viewers: %w", err)
+	}
+	defer rows.Close()
+
+	reservationIDs := []string{}
+	for rows.Next() {
+		var reservationID string
+		if err := rows.Scan(&reservationID); err != nil {
+			return nil, fmt.Errorf("failed to scan reservation ID: %w", err)
+		}
+		reservationIDs = append(reservationIDs, reservationID)
+	}
+
+	return reservationIDs, rows.Err()
+}
+
+// ListEndedWithViewers returns expired or revoked links that still have viewers watching
+// Locked links are left alone: wrong PINs must not disconnect the guests already watching
+func (r *ShareLinkRepository) ListEndedWithViewers(ctx context.Context) ([]*domain.ShareLink, error) {
+	query := "SELECT " + shareLinkColumns + ` FROM share_links l
+		WHERE (l.revoked_at IS NOT NULL OR l.expires_at <= NOW())
+		AND EXISTS (
+			SELECT 1 FROM share_link_redemptions r
+			JOIN streams s ON s.reservation_id = r.reservation_id
+			WHERE r.link_id = l.id AND s.ended_at IS NULL
+		)`
+	return r.list(ctx, query)
+}
+
+func (r *ShareLinkRepository) list(ctx context.Context, query string, args ...interface{}) ([]*domain.ShareLink, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	defer rows.Close()
+
+	links := []*domain.ShareLink{}
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// scanShareLink reads the columns of shareLinkColumns
+func scanShareLink(row rowScanner) (*domain.ShareLink, error) {
+	var link domain.ShareLink
+	var label, pinHash, revokedBy, revokeReason sql.NullString
+	var revokedAt sql.NullTime
+
+	err := row.Scan(
+		&link.ID,
+		&link.CameraID,
+		&label,
+		&link.CreatedBy,
+		&link.ExpiresAt,
+		&link.MaxViewers,
+		&pinHash,
+		&link.PINFailures,
+		&revokedAt,
+		&revokedBy,
+		&revokeReason,
+		&link.CreatedAt,
+		&link.ActiveViewers,
+		&link.Redemptions,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan share link: %w", err)
+	}
+
+	link.Label = label.String
+	link.PINHash = pinHash.String
+	link.PINRequired = pinHash.Valid
+	link.RevokedBy = revokedBy.String
+	link.RevokeReason = revokeReason.String
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+	link.Status = shareLinkStatus(&link)
+
+	return &link, nil
+}
+
+// shareLinkStatus derives the status of a link; revocation wins over locking and expiry
+func shareLinkStatus(link *domain.ShareLink) string {
+	switch {
+	case link.RevokedAt != nil:
+		return domain.ShareLinkRevoked
+	case link.PINFailures >= domain.ShareLinkMaxPINFailures:
+		return domain.ShareLinkLocked
+	case !time.Now().Before(link.ExpiresAt):
+		return domain.ShareLinkExpired
+	default:
+		return domain.ShareLinkActive
+	}
+}
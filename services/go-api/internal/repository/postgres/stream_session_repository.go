@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+)
+
+// StreamSessionRepository handles live viewing session persistence in the streams table
+type StreamSessionRepository struct {
+	db *sql.DB
+}
+
+// NewStreamSessionRepository creates a new stream session repository
+func NewStreamSessionRepository(db *sql.DB) *StreamSessionRepository {
+	return &StreamSessionRepository{db: db}
+}
+
+// CreateSession inserts a new active viewing session
+func (r *StreamSessionRepository) CreateSession(ctx context.Context, session *domain.StreamSession) error {
+	query := `
+		INSERT INTO streams (
+			reservation_id, camera_id, agency, livekit_room_name, viewer_id, viewer_ip,
+			status, started_at, last_heartbeat
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		RETURNING id
+	`
+
+	if session.StartedAt.IsZero() {
+		session.StartedAt = time.Now()
+	}
+	session.Status = domain.StreamSessionActive
+	session.LastHeartbeat = session.StartedAt
+
+	err := r.db.QueryRowContext(ctx, query,
+		session.ReservationID,
+		session.CameraID,
+		session.Agency,
+		session.RoomName,
+		session.ViewerID,
+		sql.NullString{String: session.ViewerIP, Valid: session.ViewerIP != ""},
+		session.Status,
+		session.StartedAt,
+	).Scan(&session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert stream session: %w", err)
+	}
+
+	return nil
+}
+
+// TouchSession updates the last heartbeat of an active session
+func (r *StreamSessionRepository) TouchSession(ctx context.Context, reservationID string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE streams SET last_heartbeat = NOW() WHERE reservation_id = $1 AND status = $2",
+		reservationID, domain.StreamSessionActive,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update stream session heartbeat: %w", err)
+	}
+
+	return nil
+}
+
+// EndSession closes an active session with the given end reason
+// Closing an already closed session is a no-op
+func (r *StreamSessionRepository) EndSession(ctx context.Context, reservationID, endReason string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE streams
+		SET status = $1, end_reason = $2, ended_at = NOW()
+		WHERE reservation_id = $3 AND status = $4
+	`, domain.StreamSessionInactive, endReason, reservationID, domain.StreamSessionActive)
+	if err != nil {
+		return fmt.Errorf("failed to end stream session: %w", err)
+	}
+
+	return nil
+}
+
+// ListActiveReservationIDs returns the reservation IDs of all sessions still marked active
+func (r *StreamSessionRepository) ListActiveReservationIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT reservation_id FROM streams WHERE status = $1 AND reservation_id IS NOT NULL",
+		domain.StreamSessionActive,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active stream sessions: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan reservation id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ListSessions retrieves viewing sessions with optional filters
+func (r *StreamSessionRepository) ListSessions(ctx context.Context, query domain.StreamSessionQuery) ([]*domain.StreamSession, error) {
+	sqlQuery := `
+		SELECT id, reservation_id, camera_id, agency, livekit_room_name, viewer_id, viewer_ip,
+		       status, end_reason, started_at, ended_at, last_heartbeat
+		FROM streams
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argCount := 1
+
+	if query.CameraID != "" {
+		sqlQuery += fmt.Sprintf(" AND camera_id = $%d", argCount)
+		args = append(args, query.CameraID)
+		argCount++
+	}
+
+	if query.UserID != "" {
+		sqlQuery += fmt.Sprintf(" AND viewer_id = $%d", argCount)
+		args = append(args, query.UserID)
+		argCount++
+	}
+
+	// Sessions overlapping the [from, to] window
+	if query.From != nil {
+		sqlQuery += fmt.Sprintf(" AND (ended_at IS NULL OR ended_at >= $%d)", argCount)
+		args = append(args, *query.From)
+		argCount++
+	}
+
+	if query.To != nil {
+		sqlQuery += fmt.Sprintf(" AND started_at <= $%d", argCount)
+		args = append(args, *query.To)
+		argCount++
+	}
+
+	sqlQuery += " ORDER BY started_at DESC"
+
+	if query.Limit > 0 {
+		sqlQuery += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, query.Limit)
+		argCount++
+	}
+
+	if query.Offset > 0 {
+		sqlQuery += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, query.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stream sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*domain.StreamSession{}
+	for rows.Next() {
+		var session domain.StreamSession
+		var reservationID, viewerIP, endReason sql.NullString
+		var endedAt sql.NullTime
+
+		err := rows.Scan(
+			&session.ID, &reservationID, &session.CameraID, &session.Agency, &session.RoomName,
+			&session.ViewerID, &viewerIP, &session.Status, &endReason,
+			&session.StartedAt, &endedAt, &session.LastHeartbeat,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stream session: %w", err)
+		}
+
+		session.ReservationID = reservationID.String
+		session.ViewerIP = viewerIP.String
+		session.EndReason = endReason.String
+		if endedAt.Valid {
+			session.EndedAt = &endedAt.Time
+		}
+
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, rows.Err()
+}
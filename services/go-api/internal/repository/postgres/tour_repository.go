@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// TourRepository implements domain.TourRepository using PostgreSQL
+type TourRepository struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+// NewTourRepository creates a new PostgreSQL tour repository
+func NewTourRepository(db *sql.DB, logger zerolog.Logger) *TourRepository {
+	return &TourRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new tour with its steps
+func (r *TourRepository) Create(ctx context.Context, tour *domain.CameraTour) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO camera_tours (name, description, dwell_seconds, created_by, is_active)
+		VALUES ($1, $2, $3, $4, true)
+		RETURNING id, is_active, created_at, updated_at
+	`,
+		tour.Name,
+		nullString(tour.Description),
+		tour.DwellSeconds,
+		tour.CreatedBy,
+	).Scan(&tour.ID, &tour.IsActive, &tour.CreatedAt, &tour.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert tour: %w", err)
+	}
+
+	if err := insertTourSteps(ctx, tx, tour.ID, tour.Steps); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	tour.StepCount = len(tour.Steps)
+	return nil
+}
+
+// GetByID retrieves a tour with its steps
+func (r *TourRepository) GetByID(ctx context.Context, id string) (*domain.CameraTour, error) {
+	tour := &domain.CameraTour{}
+	var description sql.NullString
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, description, dwell_seconds, created_by, is_active, created_at, updated_at
+		FROM camera_tours
+		WHERE id = $1 AND is_active = true
+	`, id).Scan(
+		&tour.ID,
+		&tour.Name,
+		&description,
+		&tour.DwellSeconds,
+		&tour.CreatedBy,
+		&tour.IsActive,
+		&tour.CreatedAt,
+		&tour.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTourNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tour: %w", err)
+	}
+	tour.Description = description.String
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT step_index, camera_id, dwell_seconds, ptz_preset_id
+		FROM camera_tour_steps
+		WHERE tour_id = $1
+		ORDER BY step_index ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tour steps: %w", err)
+	}
+	defer rows.Close()
+
+	steps := []domain.TourStep{}
+	for rows.Next() {
+		var step domain.TourStep
+		var dwellSeconds, presetID sql.NullInt64
+
+		if err := rows.Scan(&step.StepIndex, &step.CameraID, &dwellSeconds, &presetID); err != nil {
+			return nil, fmt.Errorf("failed to scan tour step: %w", err)
+		}
+
+		step.DwellSeconds = int(dwellSeconds.Int64)
+		if presetID.Valid {
+			preset := int(presetID.Int64)
+			step.PresetID = &preset
+		}
+
+		steps = append(steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tour steps: %w", err)
+	}
+
+	tour.Steps = steps
+	tour.StepCount = len(steps)
+	return tour, nil
+}
+
+// List retrieves all active tours without steps
+func (r *TourRepository) List(ctx context.Context) ([]*domain.CameraTour, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT t.id, t.name, t.description, t.dwell_seconds, t.created_by, t.is_active,
+		       t.created_at, t.updated_at, COUNT(s.id) AS step_count
+		FROM camera_tours t
+		LEFT JOIN camera_tour_steps s ON s.tour_id = t.id
+		WHERE t.is_active = true
+		GROUP BY t.id
+		ORDER BY t.updated_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tours: %w", err)
+	}
+	defer rows.Close()
+
+	tours := []*domain.CameraTour{}
+	for rows.Next() {
+		var tour domain.CameraTour
+		var description sql.NullString
+
+		err := rows.Scan(
+			&tour.ID,
+			&tour.Name,
+			&description,
+			&tour.DwellSeconds,
+			&tour.CreatedBy,
+			&tour.IsActive,
+			&tour.CreatedAt,
+			&tour.UpdatedAt,
+			&tour.StepCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tour: %w", err)
+		}
+		tour.Description = description.String
+
+		tours = append(tours, &tour)
+	}
+
+	return tours, rows.Err()
+}
+
+// Update replaces a tour's settings and steps
+func (r *TourRepository) Update(ctx context.Context, id string, request *domain.UpdateTourRequest) (*domain.CameraTour, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	tour := &domain.CameraTour{
+		ID:           id,
+		Name:         request.Name,
+		Description:  request.Description,
+		DwellSeconds: request.DwellSeconds,
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE camera_tours
+		SET name = $1, description = $2, dwell_seconds = $3, updated_at = NOW()
+		WHERE id = $4 AND is_active = true
+		RETURNING created_by, is_active, created_at, updated_at
+	`, request.Name, nullString(request.Description), request.DwellSeconds, id).Scan(
+		&tour.CreatedBy,
+		&tour.IsActive,
+		&tour.CreatedAt,
+		&tour.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTourNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tour: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM camera_tour_steps WHERE tour_id = $1", id); err != nil {
+		return nil, fmt.Errorf("failed to delete tour steps: %w", err)
+	}
+
+	if err := insertTourSteps(ctx, tx, id, request.Steps); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	tour.Steps = request.Steps
+	tour.StepCount = len(request.Steps)
+
+	r.logger.Info().
+		Str("tour_id", id).
+		Str("name", request.Name).
+		Msg("Tour updated successfully")
+
+	return tour, nil
+}
+
+// Delete deletes a tour by ID (soft delete)
+func (r *TourRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE camera_tours
+		SET is_active = false, updated_at = NOW()
+		WHERE id = $1 AND is_active = true
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tour: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrTourNotFound, id)
+	}
+
+	r.logger.Info().
+		Str("tour_id", id).
+		Msg("Tour deleted successfully")
+
+	return nil
+}
+
+// insertTourSteps inserts the ordered steps of a tour
+func insertTourSteps(ctx context.Context, tx *sql.Tx, tourID string, steps []domain.TourStep) error {
+	for _, step := range steps {
+		var dwellSeconds sql.NullInt64
+		if step.DwellSeconds > 0 {
+			dwellSeconds = sql.NullInt64{Int64: int64(step.DwellSeconds), Valid: true}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO camera_tour_steps (tour_id, step_index, camera_id, dwell_seconds, ptz_preset_id)
+			VALUES ($1, $2, $3, $4, $5)
+		`, tourID, step.StepIndex, step.CameraID, dwellSeconds, step.PresetID)
+		if err != nil {
+			return fmt.Errorf("failed to insert tour step: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,372 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// wallMonitorColumns are the columns read by scanWallMonitor; m is video_wall_monitors, lp is layout_preferences
+const wallMonitorColumns = `
+	m.id, m.wall_id, m.name, m.position_index, m.layout_id, lp.name, m.camera_id,
+	m.assigned_by, m.assigned_at, m.created_at, m.updated_at
+`
+
+// VideoWallRepository implements domain.VideoWallRepository using PostgreSQL
+type VideoWallRepository struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+// NewVideoWallRepository creates a new PostgreSQL video wall repository
+func NewVideoWallRepository(db *sql.DB, logger zerolog.Logger) *VideoWallRepository {
+	return &VideoWallRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new video wall with its monitors
+func (r *VideoWallRepository) Create(ctx context.Context, wall *domain.VideoWall) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO video_walls (name, description, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`, wall.Name, nullString(wall.Description), wall.CreatedBy).Scan(&wall.ID, &wall.CreatedAt, &wall.UpdatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: a wall named %q already exists", domain.ErrInvalidVideoWall, wall.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert video wall: %w", err)
+	}
+
+	for i := range wall.Monitors {
+		monitor := &wall.Monitors[i]
+		monitor.WallID = wall.ID
+		if err := insertWallMonitor(ctx, tx, monitor); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a video wall with its monitors
+func (r *VideoWallRepository) GetByID(ctx context.Context, id string) (*domain.VideoWall, error) {
+	wall := &domain.VideoWall{}
+	var description sql.NullString
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, description, created_by, created_at, updated_at
+		FROM video_walls
+		WHERE id = $1
+	`, id).Scan(&wall.ID, &wall.Name, &description, &wall.CreatedBy, &wall.CreatedAt, &wall.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrVideoWallNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video wall: %w", err)
+	}
+	wall.Description = description.String
+
+	monitors, err := r.listMonitors(ctx, "WHERE m.wall_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	wall.Monitors = monitors[id]
+	if wall.Monitors == nil {
+		wall.Monitors = []domain.WallMonitor{}
+	}
+
+	return wall, nil
+}
+
+// List retrieves all video walls with their monitors
+func (r *VideoWallRepository) List(ctx context.Context) ([]*domain.VideoWall, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, description, created_by, created_at, updated_at
+		FROM video_walls
+		ORDER BY name ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video walls: %w", err)
+	}
+	defer rows.Close()
+
+	walls := []*domain.VideoWall{}
+	for rows.Next() {
+		var wall domain.VideoWall
+		var description sql.NullString
+
+		if err := rows.Scan(&wall.ID, &wall.Name, &description, &wall.CreatedBy, &wall.CreatedAt, &wall.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan video wall: %w", err)
+		}
+		wall.Description = description.String
+
+		walls = append(walls, &wall)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read video walls: %w", err)
+	}
+
+	monitors, err := r.listMonitors(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, wall := range walls {
+		wall.Monitors = monitors[wall.ID]
+		if wall.Monitors == nil {
+			wall.Monitors = []domain.WallMonitor{}
+		}
+	}
+
+	return walls, nil
+}
+
+// Update updates a video wall's name and description
+func (r *VideoWallRepository) Update(ctx context.Context, id string, request *domain.UpdateVideoWallRequest) (*domain.VideoWall, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE video_walls
+		SET name = $1, description = $2, updated_at = NOW()
+		WHERE id = $3
+	`, request.Name, nullString(request.Description), id)
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("%w: a wall named %q already exists", domain.ErrInvalidVideoWall, request.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update video wall: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrVideoWallNotFound, id)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Delete deletes a video wall and its monitors
+func (r *VideoWallRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM video_walls WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete video wall: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrVideoWallNotFound, id)
+	}
+
+	r.logger.Info().
+		Str("wall_id", id).
+		Msg("Video wall deleted successfully")
+
+	return nil
+}
+
+// AddMonitor adds a monitor to a video wall
+func (r *VideoWallRepository) AddMonitor(ctx context.Context, monitor *domain.WallMonitor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertWallMonitor(ctx, tx, monitor); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetMonitor retrieves a monitor by ID
+func (r *VideoWallRepository) GetMonitor(ctx context.Context, monitorID string) (*domain.WallMonitor, error) {
+	monitors, err := r.listMonitors(ctx, "WHERE m.id = $1", monitorID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wallMonitors := range monitors {
+		if len(wallMonitors) > 0 {
+			return &wallMonitors[0], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", domain.ErrWallMonitorNotFound, monitorID)
+}
+
+// UpdateMonitor renames or moves a monitor
+func (r *VideoWallRepository) UpdateMonitor(ctx context.Context, wallID, monitorID string, request *domain.WallMonitorRequest) (*domain.WallMonitor, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE video_wall_monitors
+		SET name = $1, position_index = $2, updated_at = NOW()
+		WHERE id = $3 AND wall_id = $4
+	`, request.Name, request.PositionIndex, monitorID, wallID)
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("%w: monitor name or position already used on this wall", domain.ErrInvalidVideoWall)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update video wall monitor: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWallMonitorNotFound, monitorID)
+	}
+
+	return r.GetMonitor(ctx, monitorID)
+}
+
+// DeleteMonitor removes a monitor from a video wall
+func (r *VideoWallRepository) DeleteMonitor(ctx context.Context, wallID, monitorID string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM video_wall_monitors WHERE id = $1 AND wall_id = $2", monitorID, wallID)
+	if err != nil {
+		return fmt.Errorf("failed to delete video wall monitor: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrWallMonitorNotFound, monitorID)
+	}
+
+	return nil
+}
+
+// SetMonitorContent records what a monitor is showing; empty IDs blank it
+func (r *VideoWallRepository) SetMonitorContent(ctx context.Context, wallID, monitorID, layoutID, cameraID, assignedBy string) (*domain.WallMonitor, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE video_wall_monitors
+		SET layout_id = $1, camera_id = $2, assigned_by = $3, assigned_at = NOW(), updated_at = NOW()
+		WHERE id = $4 AND wall_id = $5
+	`, nullString(layoutID), nullString(cameraID), nullString(assignedBy), monitorID, wallID)
+	if isForeignKeyViolation(err) {
+		return nil, fmt.Errorf("%w: unknown camera %s", domain.ErrInvalidVideoWall, cameraID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set video wall monitor content: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWallMonitorNotFound, monitorID)
+	}
+
+	return r.GetMonitor(ctx, monitorID)
+}
+
+// listMonitors retrieves monitors matching the where clause, grouped by wall ID and ordered by position
+func (r *VideoWallRepository) listMonitors(ctx context.Context, where string, args ...interface{}) (map[string][]domain.WallMonitor, error) {
+	query := `SELECT ` + wallMonitorColumns + `
+		FROM video_wall_monitors m
+		LEFT JOIN layout_preferences lp ON lp.id = m.layout_id
+		` + where + `
+		ORDER BY m.wall_id, m.position_index ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video wall monitors: %w", err)
+	}
+	defer rows.Close()
+
+	monitors := make(map[string][]domain.WallMonitor)
+	for rows.Next() {
+		monitor, err := scanWallMonitor(rows)
+		if err != nil {
+			return nil, err
+		}
+		monitors[monitor.WallID] = append(monitors[monitor.WallID], *monitor)
+	}
+
+	return monitors, rows.Err()
+}
+
+// insertWallMonitor inserts a monitor of a video wall
+func insertWallMonitor(ctx context.Context, tx *sql.Tx, monitor *domain.WallMonitor) error {
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO video_wall_monitors (wall_id, name, position_index)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`, monitor.WallID, monitor.Name, monitor.PositionIndex).Scan(&monitor.ID, &monitor.CreatedAt, &monitor.UpdatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: monitor name or position already used on this wall", domain.ErrInvalidVideoWall)
+	}
+	if isForeignKeyViolation(err) {
+		return fmt.Errorf("%w: %s", domain.ErrVideoWallNotFound, monitor.WallID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert video wall monitor: %w", err)
+	}
+
+	monitor.ContentType = domain.WallContentBlank
+	return nil
+}
+
+// scanWallMonitor scans a row selected with wallMonitorColumns
+func scanWallMonitor(rows *sql.Rows) (*domain.WallMonitor, error) {
+	var monitor domain.WallMonitor
+	var layoutID, layoutName, cameraID, assignedBy sql.NullString
+	var assignedAt sql.NullTime
+
+	err := rows.Scan(
+		&monitor.ID,
+		&monitor.WallID,
+		&monitor.Name,
+		&monitor.PositionIndex,
+		&layoutID,
+		&layoutName,
+		&cameraID,
+		&assignedBy,
+		&assignedAt,
+		&monitor.CreatedAt,
+		&monitor.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan video wall monitor: %w", err)
+	}
+
+	monitor.LayoutID = layoutID.String
+	monitor.LayoutName = layoutName.String
+	monitor.CameraID = cameraID.String
+	monitor.AssignedBy = assignedBy.String
+	if assignedAt.Valid {
+		monitor.AssignedAt = &assignedAt.Time
+	}
+
+	switch {
+	case monitor.LayoutID != "":
+		monitor.ContentType = domain.WallContentLayout
+	case monitor.CameraID != "":
+		monitor.ContentType = domain.WallContentCamera
+	default:
+		monitor.ContentType = domain.WallContentBlank
+	}
+
+	return &monitor, nil
+}
+
+// isForeignKeyViolation reports whether err is a PostgreSQL foreign key violation
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
@@ -0,0 +1,145 @@
+package valkey
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// acquirePTZLockScript sets the lock unless another user with equal or higher priority holds it
+// KEYS[1] = lock key, ARGV[1] = lock JSON, ARGV[2] = lease in milliseconds
+// Returns {1, previous lock or "", stored lock} or {0, holder}
+var acquirePTZLockScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+local lock = cjson.decode(ARGV[1])
+if current then
+	local holder = cjson.decode(current)
+	if holder.user_id ~= lock.user_id then
+		if lock.priority <= holder.priority then
+			return {0, current}
+		end
+	else
+		lock.acquired_at = holder.acquired_at
+	end
+end
+local value = cjson.encode(lock)
+redis.call('SET', KEYS[1], value, 'PX', ARGV[2])
+return {1, current or '', value}
+`)
+
+// releasePTZLockScript deletes the lock if ARGV[1] holds it or ARGV[2] outranks the holder
+// Returns {1, removed lock or ""} or {0, holder}
+var releasePTZLockScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return {1, ''}
+end
+local holder = cjson.decode(current)
+if holder.user_id ~= ARGV[1] and tonumber(ARGV[2]) <= holder.priority then
+	return {0, current}
+end
+redis.call('DEL', KEYS[1])
+return {1, current}
+`)
+
+// PTZLockRepository implements domain.PTZLockRepository using Valkey
+// Each lock is a JSON string whose TTL is the lease, so abandoned locks expire on their own
+type PTZLockRepository struct {
+	client *redis.Client
+	logger zerolog.Logger
+}
+
+// NewPTZLockRepository creates a new Valkey PTZ lock repository
+func NewPTZLockRepository(client *redis.Client, logger zerolog.Logger) *PTZLockRepository {
+	return &PTZLockRepository{
+		client: client,
+		logger: logger,
+	}
+}
+
+// Acquire stores lock unless the camera is held by another user with equal or higher priority
+func (r *PTZLockRepository) Acquire(ctx context.Context, lock *domain.PTZLock, lease time.Duration) (*domain.PTZLock, error) {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal PTZ lock: %w", err)
+	}
+
+	result, err := acquirePTZLockScript.Run(ctx, r.client, []string{ptzLockKey(lock.CameraID)}, data, lease.Milliseconds()).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire PTZ lock: %w", err)
+	}
+
+	if ok, _ := result[0].(int64); ok == 0 {
+		return nil, lockedError(result[1])
+	}
+
+	// The stored lock keeps the original acquisition time on renewal
+	if stored, err := decodePTZLock(result[2]); err == nil && stored != nil {
+		lock.AcquiredAt = stored.AcquiredAt
+	}
+
+	return decodePTZLock(result[1])
+}
+
+// Release removes the lock if userID holds it or priority is higher than the holder's
+func (r *PTZLockRepository) Release(ctx context.Context, cameraID, userID string, priority int) (*domain.PTZLock, error) {
+	result, err := releasePTZLockScript.Run(ctx, r.client, []string{ptzLockKey(cameraID)}, userID, priority).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to release PTZ lock: %w", err)
+	}
+
+	if ok, _ := result[0].(int64); ok == 0 {
+		return nil, lockedError(result[1])
+	}
+
+	return decodePTZLock(result[1])
+}
+
+// Get returns the current lock, nil if the camera is free
+func (r *PTZLockRepository) Get(ctx context.Context, cameraID string) (*domain.PTZLock, error) {
+	data, err := r.client.Get(ctx, ptzLockKey(cameraID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PTZ lock: %w", err)
+	}
+
+	return decodePTZLock(data)
+}
+
+func ptzLockKey(cameraID string) string {
+	return fmt.Sprintf("ptz:lock:%s", cameraID)
+}
+
+// decodePTZLock decodes a lock returned by a script; an empty string means no lock
+func decodePTZLock(value interface{}) (*domain.PTZLock, error) {
+	data, _ := value.(string)
+	if data == "" {
+		return nil, nil
+	}
+
+	var lock domain.PTZLock
+	if err := json.Unmarshal([]byte(data), &lock); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal PTZ lock: %w", err)
+	}
+
+	return &lock, nil
+}
+
+func lockedError(value interface{}) error {
+	holder, err := decodePTZLock(value)
+	if err != nil {
+		return err
+	}
+	if holder == nil {
+		return domain.ErrPTZLocked
+	}
+
+	return &domain.PTZLockedError{Holder: holder}
+}
@@ -0,0 +1,68 @@
+package valkey
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/rta/cctv/go-api/internal/domain"
+)
+
+// SnapshotCache caches camera snapshots in Valkey
+type SnapshotCache struct {
+	client *redis.Client
+	logger zerolog.Logger
+}
+
+// NewSnapshotCache creates a new Valkey snapshot cache
+func NewSnapshotCache(client *redis.Client, logger zerolog.Logger) *SnapshotCache {
+	return &SnapshotCache{
+		client: client,
+		logger: logger,
+	}
+}
+
+// Get retrieves a cached snapshot, returning nil if none is cached
+func (c *SnapshotCache) Get(ctx context.Context, cameraID string) (*domain.Snapshot, error) {
+	key := fmt.Sprintf("snapshot:camera:%s", cameraID)
+
+	data, err := c.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	capturedAt, _ := time.Parse(time.RFC3339Nano, data["captured_at"])
+
+	return &domain.Snapshot{
+		CameraID:    cameraID,
+		Image:       []byte(data["image"]),
+		ContentType: data["content_type"],
+		Source:      data["source"],
+		CapturedAt:  capturedAt,
+	}, nil
+}
+
+// Set caches a snapshot for the given TTL
+func (c *SnapshotCache) Set(ctx context.Context, snapshot *domain.Snapshot, ttl time.Duration) error {
+	key := fmt.Sprintf("snapshot:camera:%s", snapshot.CameraID)
+
+	pipe := c.client.TxPipeline()
+	pipe.HSet(ctx, key,
+		"image", snapshot.Image,
+		"content_type", snapshot.ContentType,
+		"source", snapshot.Source,
+		"captured_at", snapshot.CapturedAt.Format(time.RFC3339Nano),
+	)
+	pipe.Expire(ctx, key, ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to cache snapshot: %w", err)
+	}
+
+	return nil
+}
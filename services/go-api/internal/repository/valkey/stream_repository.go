@@ -89,7 +89,7 @@ func (r *StreamRepository) GetReservationFromHash(ctx context.Context, reservati
 		return nil, fmt.Errorf("failed to check reservation existence: %w", err)
 	}
 	if exists == 0 {
-		return nil, fmt.Errorf("reservation not found: %s", reservationID)
+		return nil, fmt.Errorf("%w: %s", domain.ErrReservationNotFound, reservationID)
 	}
 
 	// Get stream-counter HASH fields
@@ -99,7 +99,7 @@ func (r *StreamRepository) GetReservationFromHash(ctx context.Context, reservati
 	}
 
 	if len(data) == 0 {
-		return nil, fmt.Errorf("reservation not found: %s", reservationID)
+		return nil, fmt.Errorf("%w: %s", domain.ErrReservationNotFound, reservationID)
 	}
 
 	// Get go-api metadata from separate key
@@ -0,0 +1,66 @@
+package valkey
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// DefaultVMSCacheNamespace is the key prefix of the vms-service Valkey cache
+const DefaultVMSCacheNamespace = "vms:cache:"
+
+// vmsCameraSources are the sources vms-service caches camera lists by
+var vmsCameraSources = []string{"DUBAI_POLICE", "METRO", "BUS", "OTHER"}
+
+// VMSCameraCache drops the camera responses vms-service caches in the shared Valkey
+// The keys copy cache.CameraKeys of vms-service, which builds separately; both are checked
+// against services/testdata/vms_camera_cache_keys.json. vms-service must run with
+// CACHE_BACKEND=valkey, as a memory cache cannot be reached from here.
+type VMSCameraCache struct {
+	client    *redis.Client
+	namespace string
+	logger    zerolog.Logger
+}
+
+// NewVMSCameraCache creates a new vms-service camera cache invalidator
+// namespace is the CACHE_NAMESPACE vms-service runs with
+func NewVMSCameraCache(client *redis.Client, namespace string, logger zerolog.Logger) *VMSCameraCache {
+	if namespace == "" {
+		namespace = DefaultVMSCacheNamespace
+	}
+
+	return &VMSCameraCache{
+		client:    client,
+		namespace: namespace,
+		logger:    logger,
+	}
+}
+
+// InvalidateCameras removes the cached cameras, their PTZ capabilities and the camera lists
+func (c *VMSCameraCache) InvalidateCameras(ctx context.Context, cameraIDs ...string) error {
+	if len(cameraIDs) == 0 {
+		return nil
+	}
+
+	keys := vmsCameraKeys(c.namespace, cameraIDs)
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate vms-service camera cache: %w", err)
+	}
+
+	c.logger.Debug().Int("cameras", len(cameraIDs)).Msg("Invalidated vms-service camera cache")
+	return nil
+}
+
+// vmsCameraKeys returns the keys of the cameras and of every camera list
+func vmsCameraKeys(namespace string, cameraIDs []string) []string {
+	keys := []string{namespace + "cameras:all"}
+	for _, source := range vmsCameraSources {
+		keys = append(keys, namespace+"cameras:source:"+source)
+	}
+	for _, id := range cameraIDs {
+		keys = append(keys, namespace+"camera:"+id, namespace+"camera:"+id+":ptz")
+	}
+	return keys
+}
@@ -0,0 +1,34 @@
+package valkey
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+)
+
+// vmsCacheKeysFile is shared with vms-service, which caches under the same keys
+const vmsCacheKeysFile = "../../../../testdata/vms_camera_cache_keys.json"
+
+func TestVMSCameraKeysSharedVectors(t *testing.T) {
+	data, err := os.ReadFile(vmsCacheKeysFile)
+	if err != nil {
+		t.Fatalf("read vectors: %v", err)
+	}
+	var vectors struct {
+		Namespace string   `json:"namespace"`
+		CameraID  string   `json:"camera_id"`
+		Keys      []string `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("decode vectors: %v", err)
+	}
+
+	keys := vmsCameraKeys(vectors.Namespace, []string{vectors.CameraID})
+	slices.Sort(keys)
+	want := slices.Clone(vectors.Keys)
+	slices.Sort(want)
+	if !slices.Equal(keys, want) {
+		t.Errorf("vmsCameraKeys = %v, want %v", keys, want)
+	}
+}
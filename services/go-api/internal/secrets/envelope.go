@@ -0,0 +1,191 @@
+// Package secrets encrypts camera credentials at rest and masks them in URLs
+// The value format is shared with vms-service through the cameras table; keep both copies in sync.
+// Both check the vectors in services/testdata/credentials_envelope.json.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// prefix marks values produced by Envelope.Encrypt
+// Format: enc:v1:<key id>:<wrapped data key>:<ciphertext>, both parts base64 with the nonce first
+const prefix = "enc:v1:"
+
+// keySize is the size of master and data keys (AES-256)
+const keySize = 32
+
+// ErrUnknownKey is returned when a value was encrypted with a master key that is not loaded
+var ErrUnknownKey = errors.New("unknown credentials key")
+
+// ErrMalformed is returned when a value looks encrypted but cannot be parsed
+var ErrMalformed = errors.New("malformed encrypted value")
+
+// Envelope encrypts secrets with envelope encryption
+// Every secret gets its own random data key; the data key is stored next to the
+// ciphertext, wrapped by the master key. The master key ID is stored with the value
+// so a value sealed with another key is reported instead of failing authentication.
+type Envelope struct {
+	keyID  string
+	master cipher.AEAD
+}
+
+// NewEnvelope creates an envelope that encrypts with the master key keyID
+func NewEnvelope(keyID string, key []byte) (*Envelope, error) {
+	if keyID == "" || strings.Contains(keyID, ":") {
+		return nil, fmt.Errorf("invalid credentials key id %q", keyID)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credentials key: %w", err)
+	}
+
+	return &Envelope{
+		keyID:  keyID,
+		master: aead,
+	}, nil
+}
+
+// Encrypt seals plaintext; an empty plaintext stays empty
+func (e *Envelope) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	// The key ID is authenticated with the wrapped key so it cannot be swapped
+	wrappedKey, err := seal(e.master, dataKey, []byte(e.keyID))
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataAEAD, []byte(plaintext), wrappedKey)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + e.keyID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt opens a value produced by Encrypt; an empty value stays empty
+func (e *Envelope) Decrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if !IsEncrypted(value) {
+		return "", ErrMalformed
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", ErrMalformed
+	}
+
+	if parts[0] != e.keyID {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, parts[0])
+	}
+
+	wrappedKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	dataKey, err := open(e.master, wrappedKey, []byte(e.keyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	plaintext, err := open(dataAEAD, ciphertext, wrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether value was produced by Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// LoadKey reads a base64 master key from value, or from the file at path when value is empty
+func LoadKey(value, path string) ([]byte, error) {
+	if value == "" && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials key file: %w", err)
+		}
+		value = string(data)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, errors.New("no credentials key configured")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("credentials key is not valid base64: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("credentials key must be %d bytes, got %d", keySize, len(key))
+	}
+
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext and prepends the random nonce
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open splits off the nonce and decrypts
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
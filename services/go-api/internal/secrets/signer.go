@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSignature is returned when a signed token was altered, or signed for another purpose or key
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Signer signs tokens with HMAC-SHA256, bound to a single purpose
+// A token signed for one purpose never verifies for another, even with the same key.
+// Go-api only; vms-service has no copy of this file.
+type Signer struct {
+	purpose string
+	key     []byte
+}
+
+// NewSigner creates a signer for purpose, e.g. "share-link"
+func NewSigner(purpose string, key []byte) (*Signer, error) {
+	if purpose == "" {
+		return nil, errors.New("signer purpose is required")
+	}
+	if len(key) < keySize {
+		return nil, fmt.Errorf("signing key must be at least %d bytes, got %d", keySize, len(key))
+	}
+
+	return &Signer{purpose: purpose, key: key}, nil
+}
+
+// Sign returns payload followed by a dot and its signature
+// payload must not contain whitespace; dots are allowed
+func (s *Signer) Sign(payload string) string {
+	return payload + "." + s.MAC(payload)
+}
+
+// Verify checks a token produced by Sign and returns its payload
+func (s *Signer) Verify(token string) (string, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i <= 0 {
+		return "", ErrInvalidSignature
+	}
+
+	payload, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(s.MAC(payload))) {
+		return "", ErrInvalidSignature
+	}
+
+	return payload, nil
+}
+
+// MAC returns the URL-safe base64 HMAC of value under this signer's purpose
+// Also usable as a keyed hash, e.g. for short PINs that must not be stored in clear
+func (s *Signer) MAC(value string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(s.purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
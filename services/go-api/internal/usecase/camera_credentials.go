@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rta/cctv/go-api/internal/secrets"
+	"github.com/rs/zerolog"
+)
+
+// CredentialRepository defines the interface for reading and rewriting stored camera credentials
+type CredentialRepository interface {
+	GetCameraCredentials(ctx context.Context, id string) (*domain.CameraCredentials, error)
+	ListCameraCredentials(ctx context.Context) ([]*domain.CameraCredentials, error)
+	UpdateCameraCredentials(ctx context.Context, creds *domain.CameraCredentials) error
+}
+
+// CredentialVault keeps camera credentials encrypted at rest
+// Credentials are only decrypted here, for the code paths that dial cameras
+type CredentialVault struct {
+	repo     CredentialRepository
+	envelope *secrets.Envelope
+	cache    CameraCache
+	logger   zerolog.Logger
+}
+
+// NewCredentialVault creates a new credential vault
+func NewCredentialVault(repo CredentialRepository, envelope *secrets.Envelope, cache CameraCache, logger zerolog.Logger) *CredentialVault {
+	return &CredentialVault{
+		repo:     repo,
+		envelope: envelope,
+		cache:    cache,
+		logger:   logger,
+	}
+}
+
+// Encrypt encrypts a camera password for storage
+func (v *CredentialVault) Encrypt(password string) (string, error) {
+	return v.envelope.Encrypt(password)
+}
+
+// DialURL returns rtspURL with the camera's credentials added
+// The result is only for the component that connects to the camera; never log or return it
+func (v *CredentialVault) DialURL(ctx context.Context, cameraID, rtspURL string) (string, error) {
+	username, password, err := v.ONVIFCredentials(ctx, cameraID)
+	if err != nil {
+		return "", err
+	}
+
+	cleanURL, _, _ := secrets.SplitURLCredentials(rtspURL)
+	return secrets.WithURLCredentials(cleanURL, username, password), nil
+}
+
+// ONVIFCredentials returns the decrypted username and password of a camera
+// Credentials still embedded in an unsealed RTSP URL are used when none are stored
+func (v *CredentialVault) ONVIFCredentials(ctx context.Context, cameraID string) (string, string, error) {
+	creds, err := v.repo.GetCameraCredentials(ctx, cameraID)
+	if err != nil {
+		return "", "", err
+	}
+
+	username := creds.OnvifUsername
+	password := creds.OnvifPasswordEncrypted
+	if secrets.IsEncrypted(password) {
+		password, err = v.envelope.Decrypt(password)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to decrypt credentials of camera %s: %w", cameraID, err)
+		}
+	} else if password != "" {
+		v.logger.Warn().Str("camera_id", cameraID).Msg("Camera password is stored unencrypted")
+	}
+
+	if password == "" {
+		if _, urlUser, urlPassword := secrets.SplitURLCredentials(creds.RTSPURL); urlPassword != "" {
+			username, password = urlUser, urlPassword
+		}
+	}
+
+	return username, password, nil
+}
+
+// SealStoredCredentials encrypts passwords stored in plain text and moves credentials
+// out of RTSP URLs, returning how many cameras were rewritten
+func (v *CredentialVault) SealStoredCredentials(ctx context.Context) (int, error) {
+	list, err := v.repo.ListCameraCredentials(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Sealing rewrites RTSP URLs, so cached cameras are dropped even after a failure
+	var sealedIDs []string
+	defer func() {
+		invalidateCameras(ctx, v.cache, v.logger, sealedIDs...)
+	}()
+
+	sealed := 0
+	for _, creds := range list {
+		changed := false
+
+		cleanURL, urlUser, urlPassword := secrets.SplitURLCredentials(creds.RTSPURL)
+		if cleanURL != creds.RTSPURL {
+			creds.RTSPURL = cleanURL
+			changed = true
+		}
+
+		password := ""
+		if creds.OnvifPasswordEncrypted != "" && !secrets.IsEncrypted(creds.OnvifPasswordEncrypted) {
+			password = creds.OnvifPasswordEncrypted
+		} else if creds.OnvifPasswordEncrypted == "" && urlPassword != "" {
+			creds.OnvifUsername = urlUser
+			password = urlPassword
+		}
+		if creds.OnvifUsername == "" && urlUser != "" {
+			creds.OnvifUsername = urlUser
+			changed = true
+		}
+
+		if password != "" {
+			creds.OnvifPasswordEncrypted, err = v.envelope.Encrypt(password)
+			if err != nil {
+				return sealed, err
+			}
+			changed = true
+		}
+
+		if !changed {
+			continue
+		}
+
+		if err := v.repo.UpdateCameraCredentials(ctx, creds); err != nil {
+			return sealed, err
+		}
+		sealedIDs = append(sealedIDs, creds.CameraID)
+		sealed++
+	}
+
+	if sealed > 0 {
+		v.logger.Info().Int("cameras", sealed).Msg("Sealed plain text camera credentials")
+	}
+
+	return sealed, nil
+}
@@ -0,0 +1,517 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rta/cctv/go-api/internal/secrets"
+)
+
+// maxProbeWorkers bounds the concurrent RTSP host probes of an import
+const maxProbeWorkers = 16
+
+// HostChecker checks whether a camera host accepts connections
+type HostChecker interface {
+	CheckTCP(ctx context.Context, address string) error
+}
+
+// cameraFileRow is a parsed file row with the problems found while parsing it
+type cameraFileRow struct {
+	row    int
+	record domain.CameraRecord
+	result domain.CameraImportRowResult
+}
+
+// ImportCameraFile validates a CSV or JSON camera file and, unless it is a dry run, writes it
+// In commit mode the valid rows are written and invalid rows skipped; in atomic mode
+// nothing is written when any row is invalid. Writes always happen in one transaction.
+func (u *CameraUsecase) ImportCameraFile(ctx context.Context, file io.Reader, opts domain.CameraImportOptions) (*domain.CameraImportReport, error) {
+	switch opts.Mode {
+	case domain.CameraImportDryRun, domain.CameraImportCommit, domain.CameraImportAtomic:
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", domain.ErrInvalidCameraFile, opts.Mode)
+	}
+
+	var rows []*cameraFileRow
+	var err error
+	switch opts.Format {
+	case domain.CameraFileCSV:
+		rows, err = parseCameraCSV(file)
+	case domain.CameraFileJSON:
+		rows, err = parseCameraJSON(file)
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", domain.ErrInvalidCameraFile, opts.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no cameras in file", domain.ErrInvalidCameraFile)
+	}
+
+	existing, err := u.repo.ListRTSPURLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u.validateCameraRows(rows, existing)
+
+	if opts.CheckReachability {
+		u.probeCameraRows(ctx, rows)
+	}
+
+	report := &domain.CameraImportReport{
+		Mode:  opts.Mode,
+		Total: len(rows),
+		Rows:  make([]domain.CameraImportRowResult, 0, len(rows)),
+	}
+
+	valid := []domain.CameraRecord{}
+	for _, row := range rows {
+		if len(row.result.Errors) > 0 {
+			row.result.Action = domain.CameraImportSkip
+			report.Invalid++
+		} else {
+			valid = append(valid, row.record)
+			report.Valid++
+		}
+		if len(row.result.Warnings) > 0 {
+			report.Warnings++
+		}
+		report.Rows = append(report.Rows, row.result)
+	}
+
+	if opts.Mode == domain.CameraImportDryRun || len(valid) == 0 {
+		return report, nil
+	}
+	if opts.Mode == domain.CameraImportAtomic && report.Invalid > 0 {
+		return report, nil
+	}
+
+	// Credentials are stored encrypted, never as part of the URL
+	for i := range valid {
+		cleanURL, username, password := secrets.SplitURLCredentials(valid[i].RTSPURL)
+		valid[i].RTSPURL = cleanURL
+		valid[i].Username = username
+		if valid[i].PasswordEncrypted, err = u.vault.Encrypt(password); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Created, report.Updated, err = u.repo.UpsertCameraRecords(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+	report.Committed = true
+
+	ids := make([]string, len(valid))
+	for i := range valid {
+		ids[i] = valid[i].ID
+	}
+	invalidateCameras(ctx, u.cache, u.logger, ids...)
+
+	u.logger.Info().
+		Str("mode", opts.Mode).
+		Int("created", report.Created).
+		Int("updated", report.Updated).
+		Int("skipped", report.Invalid).
+		Msg("Camera file imported")
+
+	return report, nil
+}
+
+// ExportCameras writes the cameras matching query as a CSV or JSON file that ImportCameraFile accepts
+func (u *CameraUsecase) ExportCameras(ctx context.Context, query domain.CameraQuery, format string, w io.Writer) error {
+	cameras, err := u.repo.ListCameras(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	records := make([]domain.CameraRecord, 0, len(cameras))
+	for _, camera := range cameras {
+		rtspURL, _, _ := secrets.SplitURLCredentials(camera.RTSPURL)
+		record := domain.CameraRecord{
+			ID:         camera.ID,
+			Name:       camera.Name,
+			NameAr:     camera.NameAr,
+			Source:     camera.Source,
+			RTSPURL:    rtspURL,
+			PTZEnabled: &camera.PTZEnabled,
+			Status:     camera.Status,
+		}
+		if location := camera.Location; location != nil {
+			latitude, longitude := location.Latitude, location.Longitude
+			record.Latitude = &latitude
+			record.Longitude = &longitude
+			record.Address = location.Address
+			record.AddressAr = location.AddressAr
+		}
+		records = append(records, record)
+	}
+
+	switch format {
+	case domain.CameraFileJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{"cameras": records})
+
+	case domain.CameraFileCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(domain.CameraFileColumns); err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := writer.Write(cameraRecordToCSV(record)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+
+	default:
+		return fmt.Errorf("%w: unknown format %q", domain.ErrInvalidCameraFile, format)
+	}
+}
+
+// validateCameraRows checks every row and flags duplicates within the file and against existing cameras
+// existing maps camera IDs to their RTSP URLs
+func (u *CameraUsecase) validateCameraRows(rows []*cameraFileRow, existing map[string]string) {
+	urlOwners := make(map[string]string, len(existing))
+	for id, rtspURL := range existing {
+		urlOwners[normalizeRTSPURL(rtspURL)] = id
+	}
+
+	seenIDs := make(map[string]int)
+	for _, row := range rows {
+		record := &row.record
+		result := &row.result
+		result.ID = record.ID
+
+		result.Errors = append(result.Errors, validateCameraRecord(record)...)
+
+		if record.ID != "" {
+			if first, ok := seenIDs[record.ID]; ok {
+				result.Errors = append(result.Errors, fmt.Sprintf("duplicate id, first used on row %d", first))
+			} else {
+				seenIDs[record.ID] = row.row
+			}
+
+			if _, ok := existing[record.ID]; ok {
+				result.Action = domain.CameraImportUpdate
+			} else {
+				result.Action = domain.CameraImportCreate
+			}
+		}
+
+		if record.RTSPURL != "" {
+			key := normalizeRTSPURL(record.RTSPURL)
+			if owner, ok := urlOwners[key]; ok && owner != record.ID {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("rtsp_url is also used by camera %s", owner))
+			} else if !ok {
+				urlOwners[key] = record.ID
+			}
+		}
+	}
+}
+
+// validateCameraRecord validates one camera record and normalizes its enums
+func validateCameraRecord(record *domain.CameraRecord) []string {
+	var errs []string
+
+	if record.ID == "" {
+		errs = append(errs, "id is required")
+	} else if len(record.ID) > 255 || strings.ContainsAny(record.ID, " \t/") {
+		errs = append(errs, "id must be at most 255 characters without spaces or slashes")
+	}
+
+	if record.Name == "" {
+		errs = append(errs, "name is required")
+	}
+
+	// An empty source keeps the existing one, or is OTHER for a new camera
+	record.Source = strings.ToUpper(record.Source)
+	switch record.Source {
+	case "", "DUBAI_POLICE", "METRO", "BUS", "OTHER":
+	default:
+		errs = append(errs, fmt.Sprintf("invalid source %q", record.Source))
+	}
+
+	record.Status = strings.ToUpper(record.Status)
+	switch record.Status {
+	case "", "ONLINE", "OFFLINE", "ERROR":
+	default:
+		errs = append(errs, fmt.Sprintf("invalid status %q", record.Status))
+	}
+
+	if record.RTSPURL == "" {
+		errs = append(errs, "rtsp_url is required")
+	} else if _, err := rtspAddress(record.RTSPURL); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if (record.Latitude == nil) != (record.Longitude == nil) {
+		errs = append(errs, "latitude and longitude must be set together")
+	} else if record.Latitude != nil {
+		err := validateLocation(&domain.CameraLocationUpdate{
+			CameraID:  record.ID,
+			Latitude:  record.Latitude,
+			Longitude: record.Longitude,
+		})
+		if err != nil {
+			errs = append(errs, strings.TrimPrefix(err.Error(), domain.ErrInvalidLocation.Error()+": "))
+		}
+	}
+
+	return errs
+}
+
+// probeCameraRows warns about valid rows whose RTSP host does not accept connections
+func (u *CameraUsecase) probeCameraRows(ctx context.Context, rows []*cameraFileRow) {
+	// Probe each host:port once
+	byAddress := make(map[string][]*cameraFileRow)
+	for _, row := range rows {
+		if len(row.result.Errors) > 0 {
+			continue
+		}
+		address, err := rtspAddress(row.record.RTSPURL)
+		if err != nil {
+			continue
+		}
+		byAddress[address] = append(byAddress[address], row)
+	}
+
+	addresses := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	workers := maxProbeWorkers
+	if len(byAddress) < workers {
+		workers = len(byAddress)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for address := range addresses {
+				err := u.prober.CheckTCP(ctx, address)
+				if err == nil {
+					continue
+				}
+				mu.Lock()
+				for _, row := range byAddress[address] {
+					row.result.Warnings = append(row.result.Warnings, fmt.Sprintf("RTSP host %s is unreachable", address))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for address := range byAddress {
+		addresses <- address
+	}
+	close(addresses)
+	wg.Wait()
+}
+
+// parseCameraCSV parses a CSV camera file with a header row
+func parseCameraCSV(file io.Reader) ([]*cameraFileRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // Short rows are reported per row
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: file is empty", domain.ErrInvalidCameraFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCameraFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"id", "name", "rtsp_url"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", domain.ErrInvalidCameraFile, required)
+		}
+	}
+
+	rows := []*cameraFileRow{}
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", domain.ErrInvalidCameraFile, line, err)
+		}
+
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(fields) {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+
+		row := &cameraFileRow{row: line}
+		row.result.Row = line
+		row.record = domain.CameraRecord{
+			ID:        get("id"),
+			Name:      get("name"),
+			NameAr:    get("name_ar"),
+			Source:    get("source"),
+			RTSPURL:   get("rtsp_url"),
+			Status:    get("status"),
+			Address:   get("address"),
+			AddressAr: get("address_ar"),
+		}
+
+		if value := get("ptz_enabled"); value != "" {
+			enabled, err := parseBool(value)
+			if err != nil {
+				row.result.Errors = append(row.result.Errors, err.Error())
+			}
+			row.record.PTZEnabled = &enabled
+		}
+
+		for column, target := range map[string]**float64{"latitude": &row.record.Latitude, "longitude": &row.record.Longitude} {
+			value := get(column)
+			if value == "" {
+				continue
+			}
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				row.result.Errors = append(row.result.Errors, fmt.Sprintf("%s %q is not a number", column, value))
+				continue
+			}
+			*target = &number
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseCameraJSON parses a JSON camera file: an array of records or {"cameras": [...]}
+func parseCameraJSON(file io.Reader) ([]*cameraFileRow, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCameraFile, err)
+	}
+
+	var records []json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		var wrapped struct {
+			Cameras []json.RawMessage `json:"cameras"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidCameraFile, err)
+		}
+		records = wrapped.Cameras
+	}
+
+	rows := make([]*cameraFileRow, 0, len(records))
+	for i, raw := range records {
+		row := &cameraFileRow{row: i + 1}
+		row.result.Row = i + 1
+
+		// A malformed element is a row error, not a file error
+		if err := json.Unmarshal(raw, &row.record); err != nil {
+			row.result.Errors = append(row.result.Errors, fmt.Sprintf("invalid record: %v", err))
+		}
+		row.record.ID = strings.TrimSpace(row.record.ID)
+		row.record.RTSPURL = strings.TrimSpace(row.record.RTSPURL)
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// cameraRecordToCSV formats a record in CameraFileColumns order
+func cameraRecordToCSV(record domain.CameraRecord) []string {
+	formatFloat := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', -1, 64)
+	}
+	formatBool := func(value *bool) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatBool(*value)
+	}
+
+	return []string{
+		record.ID,
+		record.Name,
+		record.NameAr,
+		record.Source,
+		record.RTSPURL,
+		formatBool(record.PTZEnabled),
+		record.Status,
+		formatFloat(record.Latitude),
+		formatFloat(record.Longitude),
+		record.Address,
+		record.AddressAr,
+	}
+}
+
+// rtspAddress returns the host:port an RTSP URL connects to (port 554 when not set)
+func rtspAddress(rtspURL string) (string, error) {
+	parsed, err := url.Parse(rtspURL)
+	if err != nil || (parsed.Scheme != "rtsp" && parsed.Scheme != "rtsps") || parsed.Hostname() == "" {
+		return "", fmt.Errorf("rtsp_url must be an rtsp:// URL with a host")
+	}
+
+	port := parsed.Port()
+	if port == "" {
+		port = "554"
+		if parsed.Scheme == "rtsps" {
+			port = "322"
+		}
+	}
+
+	return net.JoinHostPort(parsed.Hostname(), port), nil
+}
+
+// normalizeRTSPURL strips credentials and fills in the default port, so the same stream
+// matches however it is written
+func normalizeRTSPURL(rtspURL string) string {
+	parsed, err := url.Parse(rtspURL)
+	if err != nil {
+		return rtspURL
+	}
+
+	address, err := rtspAddress(rtspURL)
+	if err != nil {
+		return rtspURL
+	}
+
+	return strings.ToLower(parsed.Scheme) + "://" + strings.ToLower(address) + parsed.RequestURI()
+}
+
+// parseBool accepts true/false, yes/no and 1/0
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1":
+		return true, nil
+	case "false", "no", "n", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("ptz_enabled %q is not a boolean", value)
+}
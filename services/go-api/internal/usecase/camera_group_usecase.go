@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// CameraGroupUseCase implements domain.CameraGroupUseCase
+type CameraGroupUseCase struct {
+	groupRepo domain.CameraGroupRepository
+	logger    zerolog.Logger
+}
+
+// NewCameraGroupUseCase creates a new camera group use case
+func NewCameraGroupUseCase(groupRepo domain.CameraGroupRepository, logger zerolog.Logger) *CameraGroupUseCase {
+	return &CameraGroupUseCase{
+		groupRepo: groupRepo,
+		logger:    logger,
+	}
+}
+
+// CreateGroup creates a new camera group, optionally with initial cameras
+func (uc *CameraGroupUseCase) CreateGroup(ctx context.Context, request *domain.CreateCameraGroupRequest) (*domain.CameraGroup, error) {
+	if request.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", domain.ErrInvalidCameraGroup)
+	}
+
+	if request.CreatedBy == "" {
+		return nil, fmt.Errorf("%w: a user identity (X-User-ID) is required", domain.ErrInvalidCameraGroup)
+	}
+
+	if err := uc.checkParentExists(ctx, request.ParentID); err != nil {
+		return nil, err
+	}
+
+	group := &domain.CameraGroup{
+		ParentID:    request.ParentID,
+		Name:        request.Name,
+		NameAr:      request.NameAr,
+		Description: request.Description,
+		SortOrder:   request.SortOrder,
+		CreatedBy:   request.CreatedBy,
+	}
+
+	if err := uc.groupRepo.Create(ctx, group); err != nil {
+		return nil, fmt.Errorf("failed to create camera group: %w", err)
+	}
+
+	if len(request.CameraIDs) > 0 {
+		added, unknown, err := uc.groupRepo.AddCameras(ctx, group.ID, uniqueStrings(request.CameraIDs))
+		if err != nil {
+			uc.logger.Error().Err(err).Str("group_id", group.ID).Msg("Failed to add initial cameras to group")
+			// Continue anyway - the group exists and cameras can be added later
+		}
+		if len(unknown) > 0 {
+			uc.logger.Warn().Strs("camera_ids", unknown).Str("group_id", group.ID).Msg("Skipped unknown cameras")
+		}
+		group.CameraCount = added
+	}
+
+	uc.logger.Info().
+		Str("group_id", group.ID).
+		Str("name", group.Name).
+		Str("created_by", group.CreatedBy).
+		Msg("Camera group created successfully")
+
+	return group, nil
+}
+
+// GetGroup retrieves a group by ID
+func (uc *CameraGroupUseCase) GetGroup(ctx context.Context, id string) (*domain.CameraGroup, error) {
+	group, err := uc.groupRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera group: %w", err)
+	}
+
+	return group, nil
+}
+
+// ListGroups retrieves all groups as a flat list
+func (uc *CameraGroupUseCase) ListGroups(ctx context.Context) ([]*domain.CameraGroup, error) {
+	groups, err := uc.groupRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list camera groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// GetTree retrieves all groups nested under their parents
+func (uc *CameraGroupUseCase) GetTree(ctx context.Context, includeCameras bool) (*domain.CameraGroupTreeResponse, error) {
+	groups, err := uc.groupRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list camera groups: %w", err)
+	}
+
+	var memberships map[string][]string
+	if includeCameras {
+		memberships, err = uc.groupRepo.ListMemberships(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list camera group memberships: %w", err)
+		}
+	}
+
+	byID := make(map[string]*domain.CameraGroup, len(groups))
+	for _, group := range groups {
+		if includeCameras {
+			group.CameraIDs = memberships[group.ID]
+		}
+		byID[group.ID] = group
+	}
+
+	// Groups arrive sorted, so appending keeps siblings in order
+	roots := []*domain.CameraGroup{}
+	for _, group := range groups {
+		if group.ParentID == nil {
+			roots = append(roots, group)
+			continue
+		}
+
+		parent, ok := byID[*group.ParentID]
+		if !ok {
+			roots = append(roots, group)
+			continue
+		}
+		parent.Children = append(parent.Children, group)
+	}
+
+	return &domain.CameraGroupTreeResponse{
+		Groups: roots,
+		Total:  len(groups),
+	}, nil
+}
+
+// UpdateGroup updates a group, including moving it to another parent
+func (uc *CameraGroupUseCase) UpdateGroup(ctx context.Context, id string, request *domain.UpdateCameraGroupRequest) (*domain.CameraGroup, error) {
+	if request.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", domain.ErrInvalidCameraGroup)
+	}
+
+	if request.ParentID != nil {
+		// A group cannot be moved under itself or one of its descendants
+		subtree, err := uc.groupRepo.SubtreeIDs(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update camera group: %w", err)
+		}
+		if len(subtree) == 0 {
+			return nil, fmt.Errorf("%w: %s", domain.ErrCameraGroupNotFound, id)
+		}
+		for _, descendantID := range subtree {
+			if descendantID == *request.ParentID {
+				return nil, fmt.Errorf("%w: cannot move a group under itself or its subgroups", domain.ErrInvalidCameraGroup)
+			}
+		}
+
+		if err := uc.checkParentExists(ctx, request.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	group, err := uc.groupRepo.Update(ctx, id, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update camera group: %w", err)
+	}
+
+	return group, nil
+}
+
+// DeleteGroup deletes a group and its subgroups
+func (uc *CameraGroupUseCase) DeleteGroup(ctx context.Context, id string) error {
+	if err := uc.groupRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete camera group: %w", err)
+	}
+
+	return nil
+}
+
+// AddCameras adds cameras to a group
+func (uc *CameraGroupUseCase) AddCameras(ctx context.Context, groupID string, request *domain.CameraGroupMembersRequest) (*domain.CameraGroupMembersResponse, error) {
+	if len(request.CameraIDs) == 0 {
+		return nil, fmt.Errorf("%w: camera_ids is required", domain.ErrInvalidCameraGroup)
+	}
+
+	// Make sure the group exists so a typo does not look like a no-op
+	if _, err := uc.groupRepo.GetByID(ctx, groupID); err != nil {
+		return nil, fmt.Errorf("failed to add cameras: %w", err)
+	}
+
+	added, unknown, err := uc.groupRepo.AddCameras(ctx, groupID, uniqueStrings(request.CameraIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add cameras: %w", err)
+	}
+
+	uc.logger.Info().
+		Str("group_id", groupID).
+		Int("added", added).
+		Int("unknown", len(unknown)).
+		Msg("Cameras added to group")
+
+	return &domain.CameraGroupMembersResponse{
+		Added:   added,
+		Unknown: unknown,
+	}, nil
+}
+
+// RemoveCamera removes a camera from a group
+func (uc *CameraGroupUseCase) RemoveCamera(ctx context.Context, groupID, cameraID string) error {
+	if err := uc.groupRepo.RemoveCamera(ctx, groupID, cameraID); err != nil {
+		return fmt.Errorf("failed to remove camera: %w", err)
+	}
+
+	return nil
+}
+
+// checkParentExists validates that a parent group exists (nil means root)
+func (uc *CameraGroupUseCase) checkParentExists(ctx context.Context, parentID *string) error {
+	if parentID == nil {
+		return nil
+	}
+
+	_, err := uc.groupRepo.GetByID(ctx, *parentID)
+	if errors.Is(err, domain.ErrCameraGroupNotFound) {
+		return fmt.Errorf("%w: parent group %s does not exist", domain.ErrInvalidCameraGroup, *parentID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check parent group: %w", err)
+	}
+
+	return nil
+}
+
+// uniqueStrings removes duplicates while keeping the original order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rta/cctv/go-api/internal/secrets"
 	"github.com/rs/zerolog"
 )
 
@@ -14,18 +15,32 @@ type CameraRepository interface {
 	GetCamera(ctx context.Context, id string) (*domain.Camera, error)
 	ListCameras(ctx context.Context, query domain.CameraQuery) ([]*domain.Camera, error)
 	DeleteCamera(ctx context.Context, id string) error
+	UpdateCameraLocation(ctx context.Context, update *domain.CameraLocationUpdate) error
+	ListRTSPURLs(ctx context.Context) (map[string]string, error)
+	UpsertCameraRecords(ctx context.Context, records []domain.CameraRecord) (created, updated int, err error)
+}
+
+// CameraCache drops copies of cameras cached outside go-api, such as by vms-service
+type CameraCache interface {
+	InvalidateCameras(ctx context.Context, cameraIDs ...string) error
 }
 
 // CameraUsecase handles camera business logic
 type CameraUsecase struct {
 	repo   CameraRepository
+	prober HostChecker
+	vault  *CredentialVault
+	cache  CameraCache
 	logger zerolog.Logger
 }
 
 // NewCameraUsecase creates a new camera usecase
-func NewCameraUsecase(repo CameraRepository, logger zerolog.Logger) *CameraUsecase {
+func NewCameraUsecase(repo CameraRepository, prober HostChecker, vault *CredentialVault, cache CameraCache, logger zerolog.Logger) *CameraUsecase {
 	return &CameraUsecase{
 		repo:   repo,
+		prober: prober,
+		vault:  vault,
+		cache:  cache,
 		logger: logger,
 	}
 }
@@ -62,6 +77,13 @@ func (u *CameraUsecase) ImportCameras(ctx context.Context, req domain.ImportCame
 			camera.Status = "ONLINE"
 		}
 
+		if err := u.sealImportCredentials(&camera); err != nil {
+			u.logger.Error().Err(err).Str("camera_id", camera.MilestoneID).Msg("Failed to encrypt camera credentials")
+			response.Failed++
+			response.Errors = append(response.Errors, fmt.Sprintf("Camera %s: failed to encrypt credentials", camera.Name))
+			continue
+		}
+
 		// Import camera
 		err := u.repo.ImportCamera(ctx, &camera)
 		if err != nil {
@@ -85,14 +107,53 @@ func (u *CameraUsecase) ImportCameras(ctx context.Context, req domain.ImportCame
 	return response, nil
 }
 
-// GetCamera retrieves a camera by ID
+// sealImportCredentials moves the credentials of a discovered camera out of its stream URLs
+// and encrypts the password
+// A camera discovered without credentials keeps those already stored for it.
+func (u *CameraUsecase) sealImportCredentials(camera *domain.ImportCameraRequest) error {
+	username, password := camera.OnvifUsername, camera.OnvifPassword
+
+	for i := range camera.Streams {
+		cleanURL, urlUser, urlPassword := secrets.SplitURLCredentials(camera.Streams[i].RtspURL)
+		camera.Streams[i].RtspURL = cleanURL
+		if password == "" && urlPassword != "" {
+			username, password = urlUser, urlPassword
+		}
+	}
+
+	encrypted, err := u.vault.Encrypt(password)
+	if err != nil {
+		return err
+	}
+
+	camera.OnvifUsername = username
+	camera.OnvifPassword = ""
+	camera.OnvifPasswordEncrypted = encrypted
+	return nil
+}
+
+// GetCamera retrieves a camera by ID, with credentials masked
 func (u *CameraUsecase) GetCamera(ctx context.Context, id string) (*domain.Camera, error) {
-	return u.repo.GetCamera(ctx, id)
+	camera, err := u.repo.GetCamera(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	camera.RTSPURL = secrets.MaskURL(camera.RTSPURL)
+	return camera, nil
 }
 
-// ListCameras retrieves cameras with filters
+// ListCameras retrieves cameras with filters, with credentials masked
 func (u *CameraUsecase) ListCameras(ctx context.Context, query domain.CameraQuery) ([]*domain.Camera, error) {
-	return u.repo.ListCameras(ctx, query)
+	cameras, err := u.repo.ListCameras(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, camera := range cameras {
+		camera.RTSPURL = secrets.MaskURL(camera.RTSPURL)
+	}
+	return cameras, nil
 }
 
 // DeleteCamera deletes a camera by ID
@@ -108,7 +169,115 @@ func (u *CameraUsecase) DeleteCamera(ctx context.Context, id string) error {
 		u.logger.Error().Err(err).Str("camera_id", id).Msg("Failed to delete camera")
 		return fmt.Errorf("failed to delete camera: %w", err)
 	}
+	invalidateCameras(ctx, u.cache, u.logger, id)
 
 	u.logger.Info().Str("camera_id", id).Msg("Successfully deleted camera")
 	return nil
 }
+
+// UpdateCameraLocation sets the coordinates of a single camera
+func (u *CameraUsecase) UpdateCameraLocation(ctx context.Context, update *domain.CameraLocationUpdate) error {
+	if err := validateLocation(update); err != nil {
+		return err
+	}
+
+	if err := u.repo.UpdateCameraLocation(ctx, update); err != nil {
+		return err
+	}
+	invalidateCameras(ctx, u.cache, u.logger, update.CameraID)
+
+	u.logger.Info().
+		Str("camera_id", update.CameraID).
+		Float64("latitude", *update.Latitude).
+		Float64("longitude", *update.Longitude).
+		Msg("Camera location updated")
+
+	return nil
+}
+
+// BulkUpdateLocations sets coordinates for many cameras, reporting per-camera failures
+func (u *CameraUsecase) BulkUpdateLocations(ctx context.Context, req domain.BulkLocationUpdateRequest) (*domain.BulkLocationUpdateResponse, error) {
+	response := &domain.BulkLocationUpdateResponse{
+		Errors: []string{},
+	}
+
+	for i := range req.Locations {
+		update := &req.Locations[i]
+		if err := u.UpdateCameraLocation(ctx, update); err != nil {
+			response.Failed++
+			response.Errors = append(response.Errors, fmt.Sprintf("Camera %s: %v", update.CameraID, err))
+			continue
+		}
+		response.Updated++
+	}
+
+	return response, nil
+}
+
+// GetCamerasGeoJSON returns cameras with coordinates as a GeoJSON FeatureCollection
+func (u *CameraUsecase) GetCamerasGeoJSON(ctx context.Context, query domain.CameraQuery) (*domain.GeoJSONFeatureCollection, error) {
+	cameras, err := u.repo.ListCameras(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &domain.GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []domain.GeoJSONFeature{},
+	}
+
+	for _, camera := range cameras {
+		if camera.Location == nil {
+			continue // Not placed on the map yet
+		}
+
+		collection.Features = append(collection.Features, domain.GeoJSONFeature{
+			Type: "Feature",
+			ID:   camera.ID,
+			Geometry: domain.GeoJSONGeometry{
+				Type:        "Point",
+				Coordinates: []float64{camera.Location.Longitude, camera.Location.Latitude},
+			},
+			Properties: map[string]interface{}{
+				"name":        camera.Name,
+				"name_ar":     camera.NameAr,
+				"source":      camera.Source,
+				"status":      camera.Status,
+				"ptz_enabled": camera.PTZEnabled,
+				"address":     camera.Location.Address,
+				"address_ar":  camera.Location.AddressAr,
+			},
+		})
+	}
+
+	return collection, nil
+}
+
+// validateLocation validates a coordinates update
+func validateLocation(update *domain.CameraLocationUpdate) error {
+	if update.CameraID == "" {
+		return fmt.Errorf("%w: camera ID is required", domain.ErrInvalidLocation)
+	}
+
+	if update.Latitude == nil || update.Longitude == nil {
+		return fmt.Errorf("%w: latitude and longitude are required", domain.ErrInvalidLocation)
+	}
+
+	if *update.Latitude < -90 || *update.Latitude > 90 {
+		return fmt.Errorf("%w: latitude %f out of range", domain.ErrInvalidLocation, *update.Latitude)
+	}
+
+	if *update.Longitude < -180 || *update.Longitude > 180 {
+		return fmt.Errorf("%w: longitude %f out of range", domain.ErrInvalidLocation, *update.Longitude)
+	}
+
+	return nil
+}
+
+// invalidateCameras drops cached copies of changed cameras
+// A failure is only logged; the copies expire with their TTL.
+func invalidateCameras(ctx context.Context, cache CameraCache, logger zerolog.Logger, cameraIDs ...string) {
+	if err := cache.InvalidateCameras(ctx, cameraIDs...); err != nil {
+		logger.Warn().Err(err).Int("cameras", len(cameraIDs)).Msg("Failed to invalidate cached cameras")
+	}
+}
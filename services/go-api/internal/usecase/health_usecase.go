@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// BreakerReporter reports the circuit breaker state of a downstream service
+type BreakerReporter interface {
+	Health() domain.DependencyHealth
+}
+
+// HealthCheck is one dependency checked for readiness
+type HealthCheck struct {
+	Name     string
+	Critical bool                            // The instance is unhealthy when this check fails
+	Ping     func(ctx context.Context) error // Must return once ctx is done
+	Breaker  BreakerReporter                 // Optional, for downstream services behind a circuit breaker
+}
+
+// HealthUseCase checks whether the instance and its dependencies can serve traffic
+type HealthUseCase struct {
+	checks  []HealthCheck
+	timeout time.Duration
+	logger  zerolog.Logger
+}
+
+// NewHealthUseCase creates a new health use case
+// timeout bounds every single check
+func NewHealthUseCase(checks []HealthCheck, timeout time.Duration, logger zerolog.Logger) *HealthUseCase {
+	return &HealthUseCase{
+		checks:  checks,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Readiness runs every check concurrently and reports per-dependency status and latency
+// A failing critical check makes the instance unhealthy; a failing non-critical check
+// or an open circuit breaker only degrades it
+func (u *HealthUseCase) Readiness(ctx context.Context) *domain.ReadinessReport {
+	report := &domain.ReadinessReport{
+		Status:       domain.HealthHealthy,
+		CheckedAt:    time.Now().UTC(),
+		Dependencies: make([]domain.DependencyStatus, len(u.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range u.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			report.Dependencies[i] = u.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, dependency := range report.Dependencies {
+		switch {
+		case dependency.Status == domain.HealthUnhealthy && dependency.Critical:
+			report.Status = domain.HealthUnhealthy
+		case dependency.Status != domain.HealthHealthy && report.Status == domain.HealthHealthy:
+			report.Status = domain.HealthDegraded
+		}
+	}
+
+	if report.Status != domain.HealthHealthy {
+		u.logger.Warn().Str("status", report.Status).Interface("dependencies", report.Dependencies).Msg("Readiness check failed")
+	}
+
+	return report
+}
+
+// run checks a single dependency within the check timeout
+func (u *HealthUseCase) run(ctx context.Context, check HealthCheck) domain.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	status := domain.DependencyStatus{
+		Name:     check.Name,
+		Status:   domain.HealthHealthy,
+		Critical: check.Critical,
+	}
+
+	start := time.Now()
+	err := check.Ping(ctx)
+	status.LatencyMS = math.Round(float64(time.Since(start).Microseconds())/10) / 100
+
+	if check.Breaker != nil {
+		status.Breaker = check.Breaker.Health().State
+	}
+
+	switch {
+	case err != nil:
+		if ctx.Err() == context.DeadlineExceeded {
+			status.Error = "timed out after " + u.timeout.String()
+		} else {
+			status.Error = err.Error()
+		}
+		status.Status = domain.HealthUnhealthy
+	case status.Breaker != "" && status.Breaker != domain.BreakerClosed:
+		// Reachable now, but recent calls failed and some are still being rejected
+		status.Status = domain.HealthDegraded
+	}
+
+	return status
+}
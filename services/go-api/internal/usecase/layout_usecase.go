@@ -1,7 +1,10 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/rta/cctv/go-api/internal/domain"
 	"github.com/rs/zerolog"
@@ -10,13 +13,15 @@ import (
 // LayoutUseCase implements domain.LayoutUseCase
 type LayoutUseCase struct {
 	layoutRepo domain.LayoutRepository
+	groupRepo  domain.CameraGroupRepository
 	logger     zerolog.Logger
 }
 
 // NewLayoutUseCase creates a new layout use case
-func NewLayoutUseCase(layoutRepo domain.LayoutRepository, logger zerolog.Logger) *LayoutUseCase {
+func NewLayoutUseCase(layoutRepo domain.LayoutRepository, groupRepo domain.CameraGroupRepository, logger zerolog.Logger) *LayoutUseCase {
 	return &LayoutUseCase{
 		layoutRepo: layoutRepo,
+		groupRepo:  groupRepo,
 		logger:     logger,
 	}
 }
@@ -59,26 +64,27 @@ func (uc *LayoutUseCase) CreateLayout(request *domain.CreateLayoutRequest) (*dom
 }
 
 // GetLayout retrieves a layout by ID
-func (uc *LayoutUseCase) GetLayout(id string) (*domain.LayoutPreference, error) {
-	if id == "" {
-		return nil, fmt.Errorf("layout ID is required")
+// The owner also gets the layout's shares
+func (uc *LayoutUseCase) GetLayout(id string, actor *domain.Actor) (*domain.LayoutPreference, error) {
+	layout, err := uc.authorize(id, actor, domain.LayoutPermissionView)
+	if err != nil {
+		return nil, err
 	}
 
-	layout, err := uc.layoutRepo.GetByID(id)
-	if err != nil {
-		uc.logger.Error().
-			Err(err).
-			Str("layout_id", id).
-			Msg("Failed to get layout")
-		return nil, fmt.Errorf("failed to get layout: %w", err)
+	if layout.Permission == domain.LayoutPermissionOwner {
+		shares, err := uc.layoutRepo.GetShares(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get layout shares: %w", err)
+		}
+		layout.Shares = shares
 	}
 
 	return layout, nil
 }
 
-// ListLayouts retrieves all layouts with optional filtering
-func (uc *LayoutUseCase) ListLayouts(layoutType *domain.LayoutType, scope *domain.LayoutScope, createdBy *string) (*domain.LayoutListResponse, error) {
-	layouts, err := uc.layoutRepo.List(layoutType, scope, createdBy)
+// ListLayouts retrieves all layouts visible to the actor with optional filtering
+func (uc *LayoutUseCase) ListLayouts(layoutType *domain.LayoutType, scope *domain.LayoutScope, createdBy *string, actor *domain.Actor) (*domain.LayoutListResponse, error) {
+	layouts, err := uc.layoutRepo.List(layoutType, scope, createdBy, actor)
 	if err != nil {
 		uc.logger.Error().
 			Err(err).
@@ -100,18 +106,24 @@ func (uc *LayoutUseCase) ListLayouts(layoutType *domain.LayoutType, scope *domai
 }
 
 // UpdateLayout updates an existing layout
-func (uc *LayoutUseCase) UpdateLayout(id string, request *domain.UpdateLayoutRequest) (*domain.LayoutPreference, error) {
-	if id == "" {
-		return nil, fmt.Errorf("layout ID is required")
-	}
-
+// The request must carry the version being edited; a stale version is rejected
+func (uc *LayoutUseCase) UpdateLayout(id string, request *domain.UpdateLayoutRequest, actor *domain.Actor) (*domain.LayoutPreference, error) {
 	// Validate request
 	if err := uc.validateUpdateRequest(request); err != nil {
 		return nil, err
 	}
 
+	if request.Version == 0 {
+		return nil, domain.ErrLayoutVersionRequired
+	}
+
+	existing, err := uc.authorize(id, actor, domain.LayoutPermissionEdit)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update in repository
-	layout, err := uc.layoutRepo.Update(id, request)
+	layout, err := uc.layoutRepo.Update(id, request, actor.UserID, domain.LayoutChangeUpdate)
 	if err != nil {
 		uc.logger.Error().
 			Err(err).
@@ -119,19 +131,16 @@ func (uc *LayoutUseCase) UpdateLayout(id string, request *domain.UpdateLayoutReq
 			Msg("Failed to update layout")
 		return nil, fmt.Errorf("failed to update layout: %w", err)
 	}
-
-	uc.logger.Info().
-		Str("layout_id", id).
-		Str("name", request.Name).
-		Msg("Layout updated successfully")
+	layout.Permission = existing.Permission
 
 	return layout, nil
 }
 
 // DeleteLayout deletes a layout by ID
-func (uc *LayoutUseCase) DeleteLayout(id string) error {
-	if id == "" {
-		return fmt.Errorf("layout ID is required")
+// Only the owner may delete a layout
+func (uc *LayoutUseCase) DeleteLayout(id string, actor *domain.Actor) error {
+	if _, err := uc.authorize(id, actor, domain.LayoutPermissionOwner); err != nil {
+		return err
 	}
 
 	if err := uc.layoutRepo.Delete(id); err != nil {
@@ -144,42 +153,314 @@ func (uc *LayoutUseCase) DeleteLayout(id string) error {
 
 	uc.logger.Info().
 		Str("layout_id", id).
+		Str("deleted_by", actor.UserID).
 		Msg("Layout deleted successfully")
 
 	return nil
 }
 
+// GetLayoutShares retrieves the shares of a layout
+// Only the owner may see who a layout is shared with
+func (uc *LayoutUseCase) GetLayoutShares(id string, actor *domain.Actor) ([]domain.LayoutShare, error) {
+	if _, err := uc.authorize(id, actor, domain.LayoutPermissionOwner); err != nil {
+		return nil, err
+	}
+
+	shares, err := uc.layoutRepo.GetShares(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get layout shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+// ShareLayout replaces the shares of a layout
+// Only the owner may share a layout
+func (uc *LayoutUseCase) ShareLayout(id string, request *domain.ShareLayoutRequest, actor *domain.Actor) ([]domain.LayoutShare, error) {
+	if err := validateShares(request.Shares); err != nil {
+		return nil, err
+	}
+
+	layout, err := uc.authorize(id, actor, domain.LayoutPermissionOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sharing with the owner is a no-op
+	shares := []domain.LayoutShare{}
+	for _, share := range request.Shares {
+		if share.PrincipalType == domain.LayoutPrincipalUser && share.PrincipalID == layout.CreatedBy {
+			continue
+		}
+		shares = append(shares, share)
+	}
+
+	if err := uc.layoutRepo.ReplaceShares(id, shares, actor.UserID); err != nil {
+		uc.logger.Error().
+			Err(err).
+			Str("layout_id", id).
+			Msg("Failed to share layout")
+		return nil, fmt.Errorf("failed to share layout: %w", err)
+	}
+
+	return uc.layoutRepo.GetShares(id)
+}
+
+// ListLayoutVersions retrieves the version history of a layout
+func (uc *LayoutUseCase) ListLayoutVersions(id string, actor *domain.Actor) ([]*domain.LayoutVersion, error) {
+	if _, err := uc.authorize(id, actor, domain.LayoutPermissionView); err != nil {
+		return nil, err
+	}
+
+	versions, err := uc.layoutRepo.ListVersions(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list layout versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// RevertLayout restores an earlier version as a new version
+// History is never rewritten: reverting to version 2 of a layout at version 5 creates version 6
+func (uc *LayoutUseCase) RevertLayout(id string, request *domain.RevertLayoutRequest, actor *domain.Actor) (*domain.LayoutPreference, error) {
+	if request.ToVersion < 1 {
+		return nil, fmt.Errorf("%w: to_version is required", domain.ErrInvalidLayout)
+	}
+
+	if request.Version == 0 {
+		return nil, domain.ErrLayoutVersionRequired
+	}
+
+	existing, err := uc.authorize(id, actor, domain.LayoutPermissionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := uc.layoutRepo.GetVersion(id, request.ToVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get layout version: %w", err)
+	}
+
+	updateRequest := &domain.UpdateLayoutRequest{
+		Name:        target.Name,
+		Description: target.Description,
+		Cameras:     target.Cameras,
+		Version:     request.Version,
+	}
+
+	layout, err := uc.layoutRepo.Update(id, updateRequest, actor.UserID, domain.LayoutChangeRevert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revert layout: %w", err)
+	}
+	layout.Permission = existing.Permission
+
+	uc.logger.Info().
+		Str("layout_id", id).
+		Int("to_version", request.ToVersion).
+		Int("version", layout.Version).
+		Str("changed_by", actor.UserID).
+		Msg("Layout reverted")
+
+	return layout, nil
+}
+
+// authorize loads a layout and checks that the actor has the required permission on it
+// The actor's permission is set on the returned layout
+func (uc *LayoutUseCase) authorize(id string, actor *domain.Actor, required domain.LayoutPermission) (*domain.LayoutPreference, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: layout ID is required", domain.ErrInvalidLayout)
+	}
+
+	// Anonymous callers may only read global layouts
+	if actor.IsAnonymous() && required != domain.LayoutPermissionView {
+		return nil, fmt.Errorf("%w: a user identity is required", domain.ErrLayoutForbidden)
+	}
+
+	layout, err := uc.layoutRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get layout: %w", err)
+	}
+
+	permission, err := uc.permissionFor(layout, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if permission == "" {
+		// Do not reveal layouts the actor cannot see
+		return nil, fmt.Errorf("%w: %s", domain.ErrLayoutNotFound, id)
+	}
+	if !permission.Allows(required) {
+		return nil, fmt.Errorf("%w: %s permission required", domain.ErrLayoutForbidden, required)
+	}
+
+	layout.Permission = permission
+	return layout, nil
+}
+
+// permissionFor returns the actor's permission on a layout, or "" when the actor cannot see it
+func (uc *LayoutUseCase) permissionFor(layout *domain.LayoutPreference, actor *domain.Actor) (domain.LayoutPermission, error) {
+	if !actor.IsAnonymous() && layout.CreatedBy == actor.UserID {
+		return domain.LayoutPermissionOwner, nil
+	}
+
+	var permission domain.LayoutPermission
+	if !actor.IsAnonymous() {
+		shares, err := uc.layoutRepo.GetShares(layout.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get layout shares: %w", err)
+		}
+
+		for _, share := range shares {
+			matches := (share.PrincipalType == domain.LayoutPrincipalUser && share.PrincipalID == actor.UserID) ||
+				(share.PrincipalType == domain.LayoutPrincipalGroup && actor.InGroup(share.PrincipalID))
+			if matches && !permission.Allows(share.Permission) {
+				permission = share.Permission
+			}
+		}
+	}
+
+	if permission == "" && layout.Scope == domain.LayoutScopeGlobal {
+		permission = domain.LayoutPermissionView
+	}
+
+	return permission, nil
+}
+
+// validateShares validates the shares of a share layout request
+func validateShares(shares []domain.LayoutShare) error {
+	seen := make(map[string]bool)
+	for _, share := range shares {
+		if share.PrincipalType != domain.LayoutPrincipalUser && share.PrincipalType != domain.LayoutPrincipalGroup {
+			return fmt.Errorf("%w: invalid principal type: %s", domain.ErrInvalidLayout, share.PrincipalType)
+		}
+
+		if share.PrincipalID == "" {
+			return fmt.Errorf("%w: principal_id is required", domain.ErrInvalidLayout)
+		}
+
+		if share.Permission != domain.LayoutPermissionView && share.Permission != domain.LayoutPermissionEdit {
+			return fmt.Errorf("%w: invalid share permission: %s", domain.ErrInvalidLayout, share.Permission)
+		}
+
+		key := share.PrincipalType + ":" + share.PrincipalID
+		if seen[key] {
+			return fmt.Errorf("%w: duplicate share for %s", domain.ErrInvalidLayout, key)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// CreateLayoutFromGroup creates a layout filled with the cameras of a camera group
+func (uc *LayoutUseCase) CreateLayoutFromGroup(ctx context.Context, request *domain.CreateLayoutFromGroupRequest) (*domain.LayoutPreference, error) {
+	if request.GroupID == "" {
+		return nil, fmt.Errorf("%w: group_id is required", domain.ErrInvalidCameraGroup)
+	}
+
+	group, err := uc.groupRepo.GetByID(ctx, request.GroupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera group: %w", err)
+	}
+
+	cameraIDs, err := uc.groupRepo.ListCameraIDs(ctx, request.GroupID, request.IncludeSubgroups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group cameras: %w", err)
+	}
+
+	if len(cameraIDs) == 0 {
+		return nil, fmt.Errorf("%w: group %s has no cameras", domain.ErrInvalidCameraGroup, group.Name)
+	}
+
+	// Only as many cameras as the grid has cells
+	if capacity := gridCapacity(request.GridLayout); capacity > 0 && len(cameraIDs) > capacity {
+		uc.logger.Info().
+			Str("group_id", request.GroupID).
+			Int("cameras", len(cameraIDs)).
+			Int("capacity", capacity).
+			Msg("Group has more cameras than the grid, extra cameras left out")
+		cameraIDs = cameraIDs[:capacity]
+	}
+
+	name := request.Name
+	if name == "" {
+		name = group.Name
+	}
+
+	createRequest := &domain.CreateLayoutRequest{
+		Name:        name,
+		Description: request.Description,
+		LayoutType:  request.LayoutType,
+		GridLayout:  request.GridLayout,
+		Scope:       request.Scope,
+		CreatedBy:   request.CreatedBy,
+	}
+	for i, cameraID := range cameraIDs {
+		createRequest.Cameras = append(createRequest.Cameras, domain.LayoutCameraAssignment{
+			CameraID:      cameraID,
+			PositionIndex: i,
+		})
+	}
+
+	return uc.CreateLayout(createRequest)
+}
+
+// gridCapacity returns the number of cells of a grid layout ("3x3" -> 9, "9-way-1-hotspot" -> 9)
+// Returns 0 when the grid size is unknown
+func gridCapacity(gridLayout string) int {
+	if rows, cols, ok := strings.Cut(gridLayout, "x"); ok {
+		r, errR := strconv.Atoi(rows)
+		c, errC := strconv.Atoi(cols)
+		if errR == nil && errC == nil {
+			return r * c
+		}
+	}
+
+	if ways, _, ok := strings.Cut(gridLayout, "-way"); ok {
+		if n, err := strconv.Atoi(ways); err == nil {
+			return n
+		}
+	}
+
+	return 0
+}
+
 // validateCreateRequest validates the create layout request
 func (uc *LayoutUseCase) validateCreateRequest(request *domain.CreateLayoutRequest) error {
 	if request.Name == "" {
-		return fmt.Errorf("layout name is required")
+		return fmt.Errorf("%w: layout name is required", domain.ErrInvalidLayout)
 	}
 
 	if request.LayoutType != domain.LayoutTypeStandard && request.LayoutType != domain.LayoutTypeHotspot {
-		return fmt.Errorf("invalid layout type: %s", request.LayoutType)
+		return fmt.Errorf("%w: invalid layout type: %s", domain.ErrInvalidLayout, request.LayoutType)
 	}
 
 	if request.Scope != domain.LayoutScopeGlobal && request.Scope != domain.LayoutScopeLocal {
-		return fmt.Errorf("invalid scope: %s", request.Scope)
+		return fmt.Errorf("%w: invalid scope: %s", domain.ErrInvalidLayout, request.Scope)
 	}
 
 	if request.CreatedBy == "" {
-		return fmt.Errorf("created_by is required")
+		return fmt.Errorf("%w: a user identity is required", domain.ErrLayoutForbidden)
 	}
 
 	if len(request.Cameras) == 0 {
-		return fmt.Errorf("at least one camera is required")
+		return fmt.Errorf("%w: at least one camera is required", domain.ErrInvalidLayout)
 	}
 
 	// Validate camera positions are unique and sequential
 	positions := make(map[int]bool)
 	for _, camera := range request.Cameras {
-		if camera.CameraID == "" {
-			return fmt.Errorf("camera ID is required")
+		if camera.CameraID == "" && camera.TourID == "" {
+			return fmt.Errorf("%w: camera ID or tour ID is required", domain.ErrInvalidLayout)
+		}
+
+		if camera.CameraID != "" && camera.TourID != "" {
+			return fmt.Errorf("%w: position %d cannot have both a camera and a tour", domain.ErrInvalidLayout, camera.PositionIndex)
 		}
 
 		if positions[camera.PositionIndex] {
-			return fmt.Errorf("duplicate position index: %d", camera.PositionIndex)
+			return fmt.Errorf("%w: duplicate position index: %d", domain.ErrInvalidLayout, camera.PositionIndex)
 		}
 
 		positions[camera.PositionIndex] = true
@@ -191,22 +472,26 @@ func (uc *LayoutUseCase) validateCreateRequest(request *domain.CreateLayoutReque
 // validateUpdateRequest validates the update layout request
 func (uc *LayoutUseCase) validateUpdateRequest(request *domain.UpdateLayoutRequest) error {
 	if request.Name == "" {
-		return fmt.Errorf("layout name is required")
+		return fmt.Errorf("%w: layout name is required", domain.ErrInvalidLayout)
 	}
 
 	if len(request.Cameras) == 0 {
-		return fmt.Errorf("at least one camera is required")
+		return fmt.Errorf("%w: at least one camera is required", domain.ErrInvalidLayout)
 	}
 
 	// Validate camera positions are unique
 	positions := make(map[int]bool)
 	for _, camera := range request.Cameras {
-		if camera.CameraID == "" {
-			return fmt.Errorf("camera ID is required")
+		if camera.CameraID == "" && camera.TourID == "" {
+			return fmt.Errorf("%w: camera ID or tour ID is required", domain.ErrInvalidLayout)
+		}
+
+		if camera.CameraID != "" && camera.TourID != "" {
+			return fmt.Errorf("%w: position %d cannot have both a camera and a tour", domain.ErrInvalidLayout, camera.PositionIndex)
 		}
 
 		if positions[camera.PositionIndex] {
-			return fmt.Errorf("duplicate position index: %d", camera.PositionIndex)
+			return fmt.Errorf("%w: duplicate position index: %d", domain.ErrInvalidLayout, camera.PositionIndex)
 		}
 
 		positions[camera.PositionIndex] = true
@@ -0,0 +1,528 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// PTZNotifier broadcasts PTZ lock changes so every console can show who is driving a camera
+type PTZNotifier interface {
+	BroadcastPTZLock(event *domain.PTZLockEvent)
+}
+
+// PTZPresetStore keeps the named presets of cameras; VMS stores the positions on the camera
+type PTZPresetStore interface {
+	ListPTZPresets(ctx context.Context, cameraID string, refresh bool) ([]*domain.PTZPreset, error)
+	CreatePTZPreset(ctx context.Context, preset *domain.PTZPreset) error
+	UpdatePTZPreset(ctx context.Context, preset *domain.PTZPreset, savePosition bool) error
+	DeletePTZPreset(ctx context.Context, cameraID string, number int) error
+}
+
+// PTZStatusReader reads the current position of cameras
+type PTZStatusReader interface {
+	GetPTZStatus(ctx context.Context, cameraID string) (*domain.PTZStatus, error)
+}
+
+// maxPTZPresetNameLength bounds preset names in either language, in characters
+const maxPTZPresetNameLength = 100
+
+// PTZUseCase sends PTZ commands on behalf of operators, one operator per camera at a time
+// An operator holds a camera's lock for a lease that renews on every command. An operator
+// whose role has a higher priority than the holder's takes control; anyone else is refused.
+// Every command, including refused ones, is recorded in the PTZ history.
+type PTZUseCase struct {
+	lockRepo    domain.PTZLockRepository
+	historyRepo domain.PTZHistoryRepository
+	controller  PTZController
+	presets     PTZPresetStore
+	status      PTZStatusReader
+	notifier    PTZNotifier
+	priorities  map[string]int // Group name -> priority; groups not listed have priority 0
+	lease       time.Duration
+	logger      zerolog.Logger
+}
+
+// NewPTZUseCase creates a new PTZ use case
+func NewPTZUseCase(
+	lockRepo domain.PTZLockRepository,
+	historyRepo domain.PTZHistoryRepository,
+	controller PTZController,
+	presets PTZPresetStore,
+	status PTZStatusReader,
+	notifier PTZNotifier,
+	priorities map[string]int,
+	lease time.Duration,
+	logger zerolog.Logger,
+) *PTZUseCase {
+	return &PTZUseCase{
+		lockRepo:    lockRepo,
+		historyRepo: historyRepo,
+		controller:  controller,
+		presets:     presets,
+		status:      status,
+		notifier:    notifier,
+		priorities:  priorities,
+		lease:       lease,
+		logger:      logger,
+	}
+}
+
+// Control takes or renews the actor's lock on the camera, then sends the command
+// A preset command names the preset by preset_id or by preset_name in either language.
+func (uc *PTZUseCase) Control(ctx context.Context, cmd domain.PTZCommand, actor *domain.Actor) (*domain.PTZLock, error) {
+	cmd.UserID = actor.UserID
+
+	if cmd.Command == "preset" {
+		if err := uc.resolvePreset(ctx, &cmd); err != nil {
+			return nil, err
+		}
+	}
+
+	lock, err := uc.acquire(ctx, cmd.CameraID, actor)
+	if errors.Is(err, domain.ErrPTZLocked) {
+		// Refused commands are part of the history too
+		role, _ := uc.priorityOf(actor)
+		uc.record(ctx, cmd, domain.PTZSourceOperator, role, time.Now(), err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.execute(ctx, cmd, domain.PTZSourceOperator, lock.Role); err != nil {
+		return nil, err
+	}
+
+	return lock, nil
+}
+
+// AcquireLock takes or renews control of a camera without moving it
+func (uc *PTZUseCase) AcquireLock(ctx context.Context, cameraID string, actor *domain.Actor) (*domain.PTZLock, error) {
+	return uc.acquire(ctx, cameraID, actor)
+}
+
+// ReleaseLock gives up control of a camera; a higher-priority operator may release another's lock
+// Releasing a camera nobody controls is not an error
+func (uc *PTZUseCase) ReleaseLock(ctx context.Context, cameraID string, actor *domain.Actor) error {
+	if actor.IsAnonymous() {
+		return fmt.Errorf("%w: user identity is required", domain.ErrInvalidPTZRequest)
+	}
+
+	_, priority := uc.priorityOf(actor)
+	released, err := uc.lockRepo.Release(ctx, cameraID, actor.UserID, priority)
+	if err != nil {
+		return err
+	}
+	if released == nil {
+		return nil
+	}
+
+	uc.logger.Info().
+		Str("camera_id", cameraID).
+		Str("user_id", actor.UserID).
+		Str("holder", released.UserID).
+		Msg("PTZ lock released")
+
+	uc.notifier.BroadcastPTZLock(&domain.PTZLockEvent{
+		Event:          domain.PTZLockReleased,
+		CameraID:       cameraID,
+		PreviousUserID: released.UserID,
+	})
+
+	return nil
+}
+
+// GetLock returns who controls a camera, nil if nobody does
+func (uc *PTZUseCase) GetLock(ctx context.Context, cameraID string) (*domain.PTZLock, error) {
+	return uc.lockRepo.Get(ctx, cameraID)
+}
+
+// Unattended returns a controller for automated callers such as tours
+// It never takes a lock and leaves alone cameras an operator is driving.
+// Commands are recorded in the history with the given source.
+func (uc *PTZUseCase) Unattended(source string) PTZController {
+	return unattendedPTZ{uc: uc, source: source}
+}
+
+// ListHistory retrieves recorded PTZ commands, newest first
+func (uc *PTZUseCase) ListHistory(ctx context.Context, query domain.PTZHistoryQuery) ([]*domain.PTZCommandRecord, error) {
+	if query.From != nil && query.To != nil && query.To.Before(*query.From) {
+		return nil, fmt.Errorf("%w: to is before from", domain.ErrInvalidPTZRequest)
+	}
+
+	records, err := uc.historyRepo.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PTZ history: %w", err)
+	}
+
+	return records, nil
+}
+
+// GetStatus reads the current pan, tilt and zoom of a camera; no lock is needed to look
+func (uc *PTZUseCase) GetStatus(ctx context.Context, cameraID string) (*domain.PTZStatus, error) {
+	return uc.status.GetPTZStatus(ctx, cameraID)
+}
+
+// ListPresets retrieves the presets of a camera; refresh reads them from the camera first
+func (uc *PTZUseCase) ListPresets(ctx context.Context, cameraID string, refresh bool) ([]*domain.PTZPreset, error) {
+	return uc.presets.ListPTZPresets(ctx, cameraID, refresh)
+}
+
+// SavePreset stores the camera's current position as a new named preset
+// The actor must be able to control the camera, so nobody else moves it while it is saved.
+func (uc *PTZUseCase) SavePreset(ctx context.Context, cameraID string, request *domain.SavePTZPresetRequest, actor *domain.Actor) (*domain.PTZPreset, error) {
+	if err := validatePresetRequest(request); err != nil {
+		return nil, err
+	}
+	if _, err := uc.acquire(ctx, cameraID, actor); err != nil {
+		return nil, err
+	}
+
+	preset := &domain.PTZPreset{
+		CameraID:  cameraID,
+		Number:    request.Number,
+		Name:      request.Name,
+		NameAr:    request.NameAr,
+		CreatedBy: actor.UserID,
+	}
+	if err := uc.presets.CreatePTZPreset(ctx, preset); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info().
+		Str("camera_id", cameraID).
+		Int("number", preset.Number).
+		Str("name", preset.Name).
+		Str("user_id", actor.UserID).
+		Msg("PTZ preset saved")
+
+	return preset, nil
+}
+
+// UpdatePreset renames a preset; with SavePosition the actor must be able to control the
+// camera, whose current position is stored under the preset
+func (uc *PTZUseCase) UpdatePreset(ctx context.Context, cameraID string, number int, request *domain.SavePTZPresetRequest, actor *domain.Actor) (*domain.PTZPreset, error) {
+	request.Number = number
+	if err := validatePresetRequest(request); err != nil {
+		return nil, err
+	}
+	if request.SavePosition {
+		if _, err := uc.acquire(ctx, cameraID, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	preset := &domain.PTZPreset{
+		CameraID: cameraID,
+		Number:   number,
+		Name:     request.Name,
+		NameAr:   request.NameAr,
+	}
+	if err := uc.presets.UpdatePTZPreset(ctx, preset, request.SavePosition); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info().
+		Str("camera_id", cameraID).
+		Int("number", number).
+		Str("name", preset.Name).
+		Bool("save_position", request.SavePosition).
+		Str("user_id", actor.UserID).
+		Msg("PTZ preset updated")
+
+	return preset, nil
+}
+
+// DeletePreset removes a preset from the camera
+func (uc *PTZUseCase) DeletePreset(ctx context.Context, cameraID string, number int, actor *domain.Actor) error {
+	if actor.IsAnonymous() {
+		return fmt.Errorf("%w: user identity is required", domain.ErrInvalidPTZRequest)
+	}
+
+	if err := uc.presets.DeletePTZPreset(ctx, cameraID, number); err != nil {
+		return err
+	}
+
+	uc.logger.Info().
+		Str("camera_id", cameraID).
+		Int("number", number).
+		Str("user_id", actor.UserID).
+		Msg("PTZ preset deleted")
+
+	return nil
+}
+
+// resolvePreset sets the preset number of a preset command named by preset_name
+// Names match case-insensitively in English and exactly in Arabic.
+func (uc *PTZUseCase) resolvePreset(ctx context.Context, cmd *domain.PTZCommand) error {
+	name := strings.TrimSpace(cmd.PresetName)
+	if name == "" {
+		if cmd.PresetID < 1 || cmd.PresetID > domain.MaxPTZPresetNumber {
+			return fmt.Errorf("%w: preset_id must be 1-256, or preset_name given", domain.ErrInvalidPTZRequest)
+		}
+		return nil
+	}
+
+	presets, err := uc.presets.ListPTZPresets(ctx, cmd.CameraID, false)
+	if err != nil {
+		return err
+	}
+	for _, preset := range presets {
+		if strings.EqualFold(preset.Name, name) || (preset.NameAr != "" && preset.NameAr == name) {
+			cmd.PresetID = preset.Number
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", domain.ErrPTZPresetNotFound, name)
+}
+
+// validatePresetRequest trims the names of a preset request and checks them and the number
+func validatePresetRequest(request *domain.SavePTZPresetRequest) error {
+	request.Name = strings.TrimSpace(request.Name)
+	request.NameAr = strings.TrimSpace(request.NameAr)
+
+	if request.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidPTZRequest)
+	}
+	if utf8.RuneCountInString(request.Name) > maxPTZPresetNameLength || utf8.RuneCountInString(request.NameAr) > maxPTZPresetNameLength {
+		return fmt.Errorf("%w: preset names must be at most 100 characters", domain.ErrInvalidPTZRequest)
+	}
+	if request.Number < 0 || request.Number > domain.MaxPTZPresetNumber {
+		return fmt.Errorf("%w: number must be 1-256", domain.ErrInvalidPTZRequest)
+	}
+	return nil
+}
+
+// GetHomePolicy retrieves a camera's return-to-home policy
+func (uc *PTZUseCase) GetHomePolicy(ctx context.Context, cameraID string) (*domain.PTZHomePolicy, error) {
+	return uc.historyRepo.GetHomePolicy(ctx, cameraID)
+}
+
+// SetHomePolicy creates or replaces a camera's return-to-home policy
+func (uc *PTZUseCase) SetHomePolicy(ctx context.Context, cameraID string, request *domain.SetPTZHomePolicyRequest) (*domain.PTZHomePolicy, error) {
+	if request.IdleMinutes < 1 || request.IdleMinutes > 1440 {
+		return nil, fmt.Errorf("%w: idle_minutes must be between 1 and 1440", domain.ErrInvalidPTZRequest)
+	}
+	if request.PresetID != nil && *request.PresetID < 1 {
+		return nil, fmt.Errorf("%w: preset_id must be positive", domain.ErrInvalidPTZRequest)
+	}
+	if request.UpdatedBy == "" {
+		return nil, fmt.Errorf("%w: user identity is required", domain.ErrInvalidPTZRequest)
+	}
+
+	policy := &domain.PTZHomePolicy{
+		CameraID:    cameraID,
+		IdleMinutes: request.IdleMinutes,
+		PresetID:    request.PresetID,
+		Enabled:     request.Enabled == nil || *request.Enabled,
+		UpdatedBy:   request.UpdatedBy,
+	}
+
+	if err := uc.historyRepo.SetHomePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info().
+		Str("camera_id", cameraID).
+		Int("idle_minutes", policy.IdleMinutes).
+		Bool("enabled", policy.Enabled).
+		Str("user_id", request.UpdatedBy).
+		Msg("PTZ home policy saved")
+
+	return policy, nil
+}
+
+// DeleteHomePolicy removes a camera's return-to-home policy
+func (uc *PTZUseCase) DeleteHomePolicy(ctx context.Context, cameraID string) error {
+	return uc.historyRepo.DeleteHomePolicy(ctx, cameraID)
+}
+
+// RunHomeReturn sends cameras home once nobody has moved them for their policy's idle period
+// Every instance runs it; two instances seeing the same camera due both send it home, which is harmless
+func (uc *PTZUseCase) RunHomeReturn(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			uc.returnIdleCamerasHome(ctx)
+		}
+	}
+}
+
+func (uc *PTZUseCase) returnIdleCamerasHome(ctx context.Context) {
+	policies, err := uc.historyRepo.ListHomeReturnsDue(ctx)
+	if err != nil {
+		uc.logger.Error().Err(err).Msg("Failed to list cameras due to return home")
+		return
+	}
+
+	home := uc.Unattended(domain.PTZSourceHomePolicy)
+	for _, policy := range policies {
+		cmd := domain.PTZCommand{
+			CameraID: policy.CameraID,
+			Command:  "home",
+			UserID:   domain.PTZHomePolicyUserID,
+		}
+		if policy.PresetID != nil {
+			cmd.Command = "preset"
+			cmd.PresetID = *policy.PresetID
+		}
+
+		if err := home.ControlPTZ(ctx, cmd); err != nil {
+			uc.logger.Warn().Err(err).Str("camera_id", policy.CameraID).Msg("Failed to return idle camera home")
+			continue
+		}
+
+		uc.logger.Info().Str("camera_id", policy.CameraID).Int("idle_minutes", policy.IdleMinutes).Msg("Idle camera returned home")
+	}
+}
+
+// execute sends a command to the camera and records it
+func (uc *PTZUseCase) execute(ctx context.Context, cmd domain.PTZCommand, source, role string) error {
+	start := time.Now()
+	err := uc.controller.ControlPTZ(ctx, cmd)
+	uc.record(ctx, cmd, source, role, start, err)
+	return err
+}
+
+// record appends a command to the PTZ history
+// Failures are logged but never fail the command; the record survives a cancelled request
+func (uc *PTZUseCase) record(ctx context.Context, cmd domain.PTZCommand, source, role string, start time.Time, cmdErr error) {
+	record := &domain.PTZCommandRecord{
+		CameraID:  cmd.CameraID,
+		UserID:    cmd.UserID,
+		Role:      role,
+		Source:    source,
+		Command:   cmd.Command,
+		Result:    domain.PTZResultSuccess,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if cmd.Speed > 0 {
+		speed := cmd.Speed
+		record.Speed = &speed
+	}
+	if cmd.Command == "preset" {
+		presetID := cmd.PresetID
+		record.PresetID = &presetID
+	}
+
+	switch {
+	case errors.Is(cmdErr, domain.ErrPTZLocked):
+		record.Result = domain.PTZResultLocked
+		record.Error = cmdErr.Error()
+		record.LatencyMS = 0
+	case cmdErr != nil:
+		record.Result = domain.PTZResultFailed
+		record.Error = cmdErr.Error()
+	}
+
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := uc.historyRepo.Record(recordCtx, record); err != nil {
+		uc.logger.Error().Err(err).
+			Str("camera_id", cmd.CameraID).
+			Str("user_id", cmd.UserID).
+			Str("command", cmd.Command).
+			Str("result", record.Result).
+			Msg("Failed to record PTZ command")
+	}
+}
+
+func (uc *PTZUseCase) acquire(ctx context.Context, cameraID string, actor *domain.Actor) (*domain.PTZLock, error) {
+	if actor.IsAnonymous() {
+		return nil, fmt.Errorf("%w: user identity is required", domain.ErrInvalidPTZRequest)
+	}
+
+	role, priority := uc.priorityOf(actor)
+	now := time.Now().UTC()
+	lock := &domain.PTZLock{
+		CameraID:   cameraID,
+		UserID:     actor.UserID,
+		Role:       role,
+		Priority:   priority,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(uc.lease),
+	}
+
+	previous, err := uc.lockRepo.Acquire(ctx, lock, uc.lease)
+	if err != nil {
+		var locked *domain.PTZLockedError
+		if errors.As(err, &locked) {
+			uc.logger.Info().
+				Str("camera_id", cameraID).
+				Str("user_id", actor.UserID).
+				Int("priority", priority).
+				Str("holder", locked.Holder.UserID).
+				Int("holder_priority", locked.Holder.Priority).
+				Msg("PTZ control refused, camera is locked")
+		}
+		return nil, err
+	}
+
+	event := &domain.PTZLockEvent{
+		Event:    domain.PTZLockRenewed,
+		CameraID: cameraID,
+		Lock:     lock,
+	}
+	switch {
+	case previous == nil:
+		event.Event = domain.PTZLockAcquired
+		uc.logger.Info().Str("camera_id", cameraID).Str("user_id", actor.UserID).Str("role", role).Msg("PTZ lock acquired")
+	case previous.UserID != actor.UserID:
+		event.Event = domain.PTZLockTakeover
+		event.PreviousUserID = previous.UserID
+		uc.logger.Warn().
+			Str("camera_id", cameraID).
+			Str("user_id", actor.UserID).
+			Str("role", role).
+			Str("previous_user_id", previous.UserID).
+			Str("previous_role", previous.Role).
+			Msg("PTZ control taken over")
+	}
+
+	uc.notifier.BroadcastPTZLock(event)
+
+	return lock, nil
+}
+
+// priorityOf returns the actor's highest-priority group and its priority
+func (uc *PTZUseCase) priorityOf(actor *domain.Actor) (string, int) {
+	role, priority := "", 0
+	for _, group := range actor.Groups {
+		if p, ok := uc.priorities[group]; ok && p > priority {
+			role, priority = group, p
+		}
+	}
+	return role, priority
+}
+
+// unattendedPTZ moves cameras nobody is driving
+type unattendedPTZ struct {
+	uc     *PTZUseCase
+	source string
+}
+
+func (p unattendedPTZ) ControlPTZ(ctx context.Context, cmd domain.PTZCommand) error {
+	lock, err := p.uc.lockRepo.Get(ctx, cmd.CameraID)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		err := &domain.PTZLockedError{Holder: lock}
+		p.uc.record(ctx, cmd, p.source, "", time.Now(), err)
+		return err
+	}
+
+	return p.uc.execute(ctx, cmd, p.source, "")
+}
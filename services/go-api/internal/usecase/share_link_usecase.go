@@ -0,0 +1,397 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rta/cctv/go-api/internal/secrets"
+	"github.com/rs/zerolog"
+)
+
+// ShareLinkUseCase implements domain.ShareLinkUseCase
+// A link's token is "<link id>.<expiry unix>.<signature>"; the signer is bound to share links,
+// so no other signed value can be redeemed as one
+type ShareLinkUseCase struct {
+	linkRepo      domain.ShareLinkRepository
+	streamUseCase *StreamUseCase
+	auditRepo     AuditRepository
+	signer        *secrets.Signer
+	guestURL      string
+	logger        zerolog.Logger
+}
+
+// NewShareLinkUseCase creates a new share link use case
+// guestURL is the guest viewer page; the token is appended as the URL fragment so it never reaches server logs
+func NewShareLinkUseCase(
+	linkRepo domain.ShareLinkRepository,
+	streamUseCase *StreamUseCase,
+	auditRepo AuditRepository,
+	signer *secrets.Signer,
+	guestURL string,
+	logger zerolog.Logger,
+) *ShareLinkUseCase {
+	return &ShareLinkUseCase{
+		linkRepo:      linkRepo,
+		streamUseCase: streamUseCase,
+		auditRepo:     auditRepo,
+		signer:        signer,
+		guestURL:      guestURL,
+		logger:        logger,
+	}
+}
+
+// CreateLink creates a share link and its signed token
+func (uc *ShareLinkUseCase) CreateLink(ctx context.Context, request *domain.CreateShareLinkRequest) (*domain.ShareLinkCreated, error) {
+	if request.CameraID == "" {
+		return nil, fmt.Errorf("%w: camera_id is required", domain.ErrInvalidShareLink)
+	}
+	if request.CreatedBy == "" {
+		return nil, fmt.Errorf("%w: a user identity (X-User-ID) is required", domain.ErrInvalidShareLink)
+	}
+
+	duration := time.Duration(request.ExpiresInMinutes) * time.Minute
+	if duration <= 0 || duration > domain.ShareLinkMaxDuration {
+		return nil, fmt.Errorf("%w: expires_in_minutes must be between 1 and %d", domain.ErrInvalidShareLink, int(domain.ShareLinkMaxDuration/time.Minute))
+	}
+
+	maxViewers := request.MaxViewers
+	if maxViewers == 0 {
+		maxViewers = 1
+	}
+	if maxViewers < 1 || maxViewers > domain.ShareLinkMaxViewers {
+		return nil, fmt.Errorf("%w: max_viewers must be between 1 and %d", domain.ErrInvalidShareLink, domain.ShareLinkMaxViewers)
+	}
+
+	if request.PIN != "" && !isValidPIN(request.PIN) {
+		return nil, fmt.Errorf("%w: pin must be 4 to 12 digits", domain.ErrInvalidShareLink)
+	}
+
+	link := &domain.ShareLink{
+		ID:          uuid.New().String(),
+		CameraID:    request.CameraID,
+		Label:       request.Label,
+		CreatedBy:   request.CreatedBy,
+		ExpiresAt:   time.Now().Add(duration).Truncate(time.Second),
+		MaxViewers:  maxViewers,
+		PINRequired: request.PIN != "",
+	}
+	if link.PINRequired {
+		link.PINHash = uc.pinHash(link.ID, request.PIN)
+	}
+
+	if err := uc.linkRepo.Create(ctx, link); err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	token := uc.signer.Sign(link.ID + "." + strconv.FormatInt(link.ExpiresAt.Unix(), 10))
+
+	uc.audit(ctx, &domain.AuditEvent{
+		Action:     domain.AuditActionShareLinkCreate,
+		ActorID:    link.CreatedBy,
+		TargetType: domain.AuditTargetShareLink,
+		TargetID:   link.ID,
+		CameraID:   link.CameraID,
+		Details: map[string]interface{}{
+			"label":        link.Label,
+			"expires_at":   link.ExpiresAt,
+			"max_viewers":  link.MaxViewers,
+			"pin_required": link.PINRequired,
+		},
+	})
+
+	uc.logger.Info().
+		Str("link_id", link.ID).
+		Str("camera_id", link.CameraID).
+		Str("created_by", link.CreatedBy).
+		Time("expires_at", link.ExpiresAt).
+		Int("max_viewers", link.MaxViewers).
+		Msg("Share link created")
+
+	return &domain.ShareLinkCreated{
+		ShareLink: link,
+		Token:     token,
+		URL:       uc.guestURL + "#" + token,
+	}, nil
+}
+
+// GetLink retrieves a share link
+func (uc *ShareLinkUseCase) GetLink(ctx context.Context, id string) (*domain.ShareLink, error) {
+	return uc.linkRepo.GetByID(ctx, id)
+}
+
+// ListLinks retrieves share links, optionally for one camera
+func (uc *ShareLinkUseCase) ListLinks(ctx context.Context, cameraID string) ([]*domain.ShareLink, error) {
+	return uc.linkRepo.List(ctx, cameraID)
+}
+
+// RevokeLink revokes a share link and disconnects its viewers
+func (uc *ShareLinkUseCase) RevokeLink(ctx context.Context, id string, request *domain.RevokeShareLinkRequest) (*domain.ShareLink, error) {
+	if request.ActorID == "" {
+		return nil, fmt.Errorf("%w: the revoking user is required", domain.ErrInvalidShareLink)
+	}
+
+	link, err := uc.linkRepo.Revoke(ctx, id, request)
+	if err != nil {
+		return nil, err
+	}
+
+	evicted := uc.evictViewers(ctx, link, request.ActorID, "share link revoked")
+
+	uc.audit(ctx, &domain.AuditEvent{
+		Action:     domain.AuditActionShareLinkRevoke,
+		ActorID:    request.ActorID,
+		TargetType: domain.AuditTargetShareLink,
+		TargetID:   link.ID,
+		CameraID:   link.CameraID,
+		Reason:     request.Reason,
+		Details: map[string]interface{}{
+			"viewers_disconnected": evicted,
+		},
+	})
+
+	uc.logger.Info().
+		Str("link_id", link.ID).
+		Str("camera_id", link.CameraID).
+		Str("actor_id", request.ActorID).
+		Int("viewers_disconnected", evicted).
+		Msg("Share link revoked")
+
+	// Counts changed while evicting
+	return uc.linkRepo.GetByID(ctx, id)
+}
+
+// Redeem starts a view-only stream for a guest holding a share link token
+// Every attempt is audited, including the ones that are turned down
+func (uc *ShareLinkUseCase) Redeem(ctx context.Context, request *domain.RedeemShareLinkRequest) (*domain.StreamResponse, error) {
+	linkID, expiresAt, err := uc.parseToken(request.Token)
+	if err != nil {
+		uc.auditDenied(ctx, "unknown", "", request, "invalid_token", nil)
+		return nil, domain.ErrShareLinkUnavailable
+	}
+	if !time.Now().Before(expiresAt) {
+		uc.auditDenied(ctx, linkID, "", request, domain.ShareLinkExpired, nil)
+		return nil, domain.ErrShareLinkUnavailable
+	}
+
+	link, err := uc.linkRepo.GetByID(ctx, linkID)
+	if errors.Is(err, domain.ErrShareLinkNotFound) {
+		uc.auditDenied(ctx, linkID, "", request, "not_found", nil)
+		return nil, domain.ErrShareLinkUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	if link.Status != domain.ShareLinkActive {
+		uc.auditDenied(ctx, link.ID, link.CameraID, request, link.Status, nil)
+		return nil, domain.ErrShareLinkUnavailable
+	}
+
+	if link.PINRequired && !hmac.Equal([]byte(uc.pinHash(link.ID, request.PIN)), []byte(link.PINHash)) {
+		failures, err := uc.linkRepo.RecordPINFailure(ctx, link.ID)
+		if err != nil {
+			uc.logger.Error().Err(err).Str("link_id", link.ID).Msg("Failed to record share link PIN failure")
+		}
+		uc.auditDenied(ctx, link.ID, link.CameraID, request, "wrong_pin", map[string]interface{}{"pin_failures": failures})
+		if failures >= domain.ShareLinkMaxPINFailures {
+			uc.logger.Warn().Str("link_id", link.ID).Int("pin_failures", failures).Msg("Share link locked after too many wrong PINs")
+		}
+		return nil, domain.ErrShareLinkPIN
+	}
+
+	redemptionID, err := uc.linkRepo.ClaimViewer(ctx, link.ID, request.ClientIP)
+	if errors.Is(err, domain.ErrShareLinkFull) {
+		uc.auditDenied(ctx, link.ID, link.CameraID, request, "viewer_limit", nil)
+		return nil, err
+	}
+	if errors.Is(err, domain.ErrShareLinkUnavailable) {
+		// Expired, revoked or locked since it was read
+		uc.auditDenied(ctx, link.ID, link.CameraID, request, "unavailable", nil)
+		return nil, domain.ErrShareLinkUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := uc.streamUseCase.RequestStream(ctx, domain.StreamRequest{
+		CameraID:    link.CameraID,
+		UserID:      "guest:" + link.ID,
+		ClientIP:    request.ClientIP,
+		QuotaSource: domain.StreamSourceGuest,
+		ValidFor:    time.Until(link.ExpiresAt),
+	})
+	if err != nil {
+		if releaseErr := uc.linkRepo.ReleaseClaim(ctx, redemptionID); releaseErr != nil {
+			uc.logger.Warn().Err(releaseErr).Str("redemption_id", redemptionID).Msg("Failed to release share link claim")
+		}
+		uc.auditDenied(ctx, link.ID, link.CameraID, request, "stream_failed", map[string]interface{}{"error": err.Error()})
+		return nil, err
+	}
+
+	if err := uc.linkRepo.AttachReservation(ctx, redemptionID, response.ReservationID); err != nil {
+		uc.logger.Error().Err(err).Str("redemption_id", redemptionID).Msg("Failed to attach reservation to share link redemption")
+	}
+
+	uc.audit(ctx, &domain.AuditEvent{
+		Action:     domain.AuditActionShareLinkRedeem,
+		ActorID:    "guest:" + link.ID,
+		TargetType: domain.AuditTargetShareLink,
+		TargetID:   link.ID,
+		CameraID:   link.CameraID,
+		Details: map[string]interface{}{
+			"redemption_id":  redemptionID,
+			"reservation_id": response.ReservationID,
+			"client_ip":      request.ClientIP,
+		},
+	})
+
+	uc.logger.Info().
+		Str("link_id", link.ID).
+		Str("camera_id", link.CameraID).
+		Str("reservation_id", response.ReservationID).
+		Str("client_ip", request.ClientIP).
+		Msg("Share link redeemed")
+
+	return response, nil
+}
+
+// RunReaper periodically disconnects guests still watching through links that expired
+// or were revoked, including links revoked through another go-api instance
+// Guest LiveKit tokens stop working at link expiry, but a viewer already connected stays until removed
+func (uc *ShareLinkUseCase) RunReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			uc.reapEndedLinks(ctx)
+		}
+	}
+}
+
+// reapEndedLinks disconnects the viewers of every link that is no longer active
+func (uc *ShareLinkUseCase) reapEndedLinks(ctx context.Context) {
+	links, err := uc.linkRepo.ListEndedWithViewers(ctx)
+	if err != nil {
+		uc.logger.Error().Err(err).Msg("Failed to list ended share links")
+		return
+	}
+
+	for _, link := range links {
+		reason := "share link expired"
+		if link.RevokedAt != nil {
+			reason = "share link revoked"
+		}
+
+		evicted := uc.evictViewers(ctx, link, "system", reason)
+		uc.logger.Info().
+			Str("link_id", link.ID).
+			Str("status", link.Status).
+			Int("viewers_disconnected", evicted).
+			Msg("Disconnected viewers of ended share link")
+	}
+}
+
+// evictViewers disconnects every guest watching through link and returns how many were disconnected
+func (uc *ShareLinkUseCase) evictViewers(ctx context.Context, link *domain.ShareLink, actorID, reason string) int {
+	reservationIDs, err := uc.linkRepo.ListActiveReservationIDs(ctx, link.ID)
+	if err != nil {
+		uc.logger.Error().Err(err).Str("link_id", link.ID).Msg("Failed to list share link viewers")
+		return 0
+	}
+
+	evicted := 0
+	for _, reservationID := range reservationIDs {
+		err := uc.streamUseCase.evictViewer(ctx, link.CameraID, viewerIdentityPrefix+reservationID, actorID, reason)
+		if err != nil {
+			uc.logger.Warn().Err(err).Str("link_id", link.ID).Str("reservation_id", reservationID).Msg("Failed to disconnect share link viewer")
+			continue
+		}
+		evicted++
+	}
+
+	return evicted
+}
+
+// parseToken verifies a share link token and returns the link ID and expiry it carries
+func (uc *ShareLinkUseCase) parseToken(token string) (string, time.Time, error) {
+	payload, err := uc.signer.Verify(token)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	linkID, expiry, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", time.Time{}, secrets.ErrInvalidSignature
+	}
+	if _, err := uuid.Parse(linkID); err != nil {
+		return "", time.Time{}, secrets.ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", time.Time{}, secrets.ErrInvalidSignature
+	}
+
+	return linkID, time.Unix(unix, 0), nil
+}
+
+// pinHash returns the keyed hash stored for a link's PIN
+// The link ID is part of the input so equal PINs on different links hash differently
+func (uc *ShareLinkUseCase) pinHash(linkID, pin string) string {
+	return uc.signer.MAC("pin:" + linkID + ":" + pin)
+}
+
+// auditDenied records a redemption that was turned down and why
+func (uc *ShareLinkUseCase) auditDenied(ctx context.Context, linkID, cameraID string, request *domain.RedeemShareLinkRequest, reason string, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["client_ip"] = request.ClientIP
+
+	uc.audit(ctx, &domain.AuditEvent{
+		Action:     domain.AuditActionShareLinkRedeemDeny,
+		ActorID:    "guest:" + linkID,
+		TargetType: domain.AuditTargetShareLink,
+		TargetID:   linkID,
+		CameraID:   cameraID,
+		Reason:     reason,
+		Details:    details,
+	})
+
+	uc.logger.Warn().
+		Str("link_id", linkID).
+		Str("client_ip", request.ClientIP).
+		Str("reason", reason).
+		Msg("Share link redemption denied")
+}
+
+// audit writes an audit event; a failed write is logged but does not fail the action
+func (uc *ShareLinkUseCase) audit(ctx context.Context, event *domain.AuditEvent) {
+	if err := uc.auditRepo.RecordEvent(ctx, event); err != nil {
+		uc.logger.Error().Err(err).Str("action", event.Action).Str("target_id", event.TargetID).Msg("Failed to write audit record")
+	}
+}
+
+// isValidPIN reports whether pin is 4 to 12 digits
+func isValidPIN(pin string) bool {
+	if len(pin) < 4 || len(pin) > 12 {
+		return false
+	}
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rta/cctv/go-api/internal/client"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"golang.org/x/sync/singleflight"
+)
+
+// SnapshotCache defines the interface for caching camera snapshots
+type SnapshotCache interface {
+	Get(ctx context.Context, cameraID string) (*domain.Snapshot, error)
+	Set(ctx context.Context, snapshot *domain.Snapshot, ttl time.Duration) error
+}
+
+// SnapshotUseCase captures still images from cameras without starting a stream
+type SnapshotUseCase struct {
+	cameraRepo   CameraRepository
+	vault        *CredentialVault
+	onvifClient  *client.OnvifClient
+	ffmpegClient *client.FFmpegClient
+	cache        SnapshotCache
+	cacheTTL     time.Duration
+	group        singleflight.Group
+	logger       zerolog.Logger
+}
+
+// NewSnapshotUseCase creates a new snapshot use case
+func NewSnapshotUseCase(
+	cameraRepo CameraRepository,
+	vault *CredentialVault,
+	onvifClient *client.OnvifClient,
+	ffmpegClient *client.FFmpegClient,
+	cache SnapshotCache,
+	cacheTTL time.Duration,
+	logger zerolog.Logger,
+) *SnapshotUseCase {
+	return &SnapshotUseCase{
+		cameraRepo:   cameraRepo,
+		vault:        vault,
+		onvifClient:  onvifClient,
+		ffmpegClient: ffmpegClient,
+		cache:        cache,
+		cacheTTL:     cacheTTL,
+		logger:       logger,
+	}
+}
+
+// GetSnapshot returns a recent still image of a camera
+// Served from cache when fresh; concurrent misses for the same camera share one capture
+func (u *SnapshotUseCase) GetSnapshot(ctx context.Context, cameraID string) (*domain.Snapshot, error) {
+	cached, err := u.cache.Get(ctx, cameraID)
+	if err != nil {
+		u.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to read snapshot cache")
+	}
+	if cached != nil {
+		return cached, nil
+	}
+
+	result, err, shared := u.group.Do(cameraID, func() (interface{}, error) {
+		// Detach from the first caller so its cancellation doesn't fail the other waiters
+		captureCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		return u.capture(captureCtx, cameraID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if shared {
+		u.logger.Debug().Str("camera_id", cameraID).Msg("Snapshot request coalesced")
+	}
+
+	return result.(*domain.Snapshot), nil
+}
+
+// capture grabs a fresh snapshot, trying ONVIF GetSnapshotUri first and falling back to ffmpeg on RTSP
+func (u *SnapshotUseCase) capture(ctx context.Context, cameraID string) (*domain.Snapshot, error) {
+	camera, err := u.cameraRepo.GetCamera(ctx, cameraID)
+	if err != nil {
+		return nil, err
+	}
+
+	username, password, err := u.vault.ONVIFCredentials(ctx, cameraID)
+	if err != nil {
+		return nil, err
+	}
+	rtspURL, _ := url.Parse(camera.RTSPURL)
+
+	snapshot := &domain.Snapshot{
+		CameraID:   cameraID,
+		CapturedAt: time.Now(),
+	}
+
+	// 1. ONVIF snapshot URI
+	if deviceURL := onvifDeviceURL(camera, rtspURL); deviceURL != "" {
+		image, contentType, err := u.fetchONVIFSnapshot(ctx, deviceURL, username, password)
+		if err == nil {
+			snapshot.Image = image
+			snapshot.ContentType = contentType
+			snapshot.Source = domain.SnapshotSourceONVIF
+		} else {
+			u.logger.Debug().Err(err).Str("camera_id", cameraID).Msg("ONVIF snapshot failed, falling back to RTSP")
+		}
+	}
+
+	// 2. Single frame from RTSP via ffmpeg
+	if snapshot.Image == nil {
+		if camera.RTSPURL == "" {
+			return nil, fmt.Errorf("camera has no rtsp url")
+		}
+
+		dialURL, err := u.vault.DialURL(ctx, cameraID, camera.RTSPURL)
+		if err != nil {
+			return nil, err
+		}
+
+		image, err := u.ffmpegClient.GrabFrame(ctx, dialURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture snapshot: %w", err)
+		}
+		snapshot.Image = image
+		snapshot.ContentType = "image/jpeg"
+		snapshot.Source = domain.SnapshotSourceRTSP
+	}
+
+	if err := u.cache.Set(ctx, snapshot, u.cacheTTL); err != nil {
+		u.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to cache snapshot")
+	}
+
+	u.logger.Info().
+		Str("camera_id", cameraID).
+		Str("source", snapshot.Source).
+		Int("bytes", len(snapshot.Image)).
+		Msg("Snapshot captured")
+
+	return snapshot, nil
+}
+
+// fetchONVIFSnapshot resolves the snapshot URI over ONVIF and downloads the image
+func (u *SnapshotUseCase) fetchONVIFSnapshot(ctx context.Context, deviceURL, username, password string) ([]byte, string, error) {
+	snapshotURI, err := u.onvifClient.GetSnapshotURI(ctx, deviceURL, username, password)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return u.onvifClient.FetchSnapshot(ctx, deviceURL, snapshotURI, username, password)
+}
+
+// onvifDeviceURL returns the camera's ONVIF device service URL
+// Uses the discovered endpoint if known, otherwise derives it from the camera address
+func onvifDeviceURL(camera *domain.Camera, rtspURL *url.URL) string {
+	if camera.OnvifEndpoint != "" {
+		return camera.OnvifEndpoint
+	}
+
+	host := camera.IPAddress
+	if host == "" && rtspURL != nil {
+		host = rtspURL.Hostname()
+	}
+	if host == "" {
+		return ""
+	}
+
+	port := camera.OnvifPort
+	if port == 0 {
+		port = 80
+	}
+
+	return fmt.Sprintf("http://%s/onvif/device_service", net.JoinHostPort(host, strconv.Itoa(port)))
+}
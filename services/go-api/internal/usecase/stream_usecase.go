@@ -9,9 +9,24 @@ import (
 	"github.com/rta/cctv/go-api/internal/client"
 	"github.com/rta/cctv/go-api/internal/domain"
 	"github.com/rta/cctv/go-api/internal/repository"
+	"github.com/rta/cctv/go-api/internal/secrets"
 	"github.com/rs/zerolog"
 )
 
+// AuditRepository defines the interface for recording operator actions
+type AuditRepository interface {
+	RecordEvent(ctx context.Context, event *domain.AuditEvent) error
+}
+
+// StreamSessionRepository defines the interface for persisting live viewing sessions
+type StreamSessionRepository interface {
+	CreateSession(ctx context.Context, session *domain.StreamSession) error
+	TouchSession(ctx context.Context, reservationID string) error
+	EndSession(ctx context.Context, reservationID, endReason string) error
+	ListActiveReservationIDs(ctx context.Context) ([]string, error)
+	ListSessions(ctx context.Context, query domain.StreamSessionQuery) ([]*domain.StreamSession, error)
+}
+
 // StreamUseCase handles stream business logic
 type StreamUseCase struct {
 	streamCounterClient  *client.StreamCounterClient
@@ -21,7 +36,11 @@ type StreamUseCase struct {
 	livekitIngressClient *client.LiveKitIngressClient
 	dockerClient         *client.DockerClient
 	streamRepo           repository.StreamRepository
+	auditRepo            AuditRepository
+	sessionRepo          StreamSessionRepository
+	vault                *CredentialVault
 	livekitURL           string
+	evictGroups          []string // Groups allowed to evict viewers
 	logger               zerolog.Logger
 }
 
@@ -34,7 +53,11 @@ func NewStreamUseCase(
 	livekitIngressClient *client.LiveKitIngressClient,
 	dockerClient *client.DockerClient,
 	streamRepo repository.StreamRepository,
+	auditRepo AuditRepository,
+	sessionRepo StreamSessionRepository,
+	vault *CredentialVault,
 	livekitURL string,
+	evictGroups []string,
 	logger zerolog.Logger,
 ) *StreamUseCase {
 	return &StreamUseCase{
@@ -45,7 +68,11 @@ func NewStreamUseCase(
 		livekitIngressClient: livekitIngressClient,
 		dockerClient:         dockerClient,
 		streamRepo:           streamRepo,
+		auditRepo:            auditRepo,
+		sessionRepo:          sessionRepo,
+		vault:                vault,
 		livekitURL:           livekitURL,
+		evictGroups:          evictGroups,
 		logger:               logger,
 	}
 }
@@ -63,6 +90,17 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 		return nil, fmt.Errorf("camera is not online: %s", camera.Status)
 	}
 
+	// Guests are counted against their own quota, whatever the camera's agency
+	source := camera.Source
+	if req.QuotaSource != "" {
+		source = req.QuotaSource
+	}
+
+	validFor := time.Hour
+	if req.ValidFor > 0 && req.ValidFor < validFor {
+		validFor = req.ValidFor
+	}
+
 	// 2. Check if camera stream is already active (resource sharing)
 	existingReservation, err := u.streamRepo.GetReservationByCameraID(ctx, req.CameraID)
 	if err != nil {
@@ -78,7 +116,7 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 			Msg("Reusing existing stream resources for additional viewer")
 
 		// Create a new reservation for quota tracking
-		reservation, err := u.streamCounterClient.ReserveStream(ctx, req.CameraID, camera.Source, req.UserID)
+		reservation, err := u.streamCounterClient.ReserveStream(ctx, req.CameraID, source, req.UserID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to reserve stream slot: %w", err)
 		}
@@ -96,7 +134,7 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 			roomName,
 			participantIdentity,
 			false, // viewers cannot publish
-			time.Hour,
+			validFor,
 		)
 		if err != nil {
 			// Rollback reservation
@@ -110,12 +148,12 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 			CameraID:      req.CameraID,
 			CameraName:    camera.Name,
 			UserID:        req.UserID,
-			Source:        camera.Source,
+			Source:        source,
 			RoomName:      roomName,
 			Token:         token,
 			IngressID:     existingReservation.IngressID, // Reuse existing ingress
 			ReservedAt:    time.Now(),
-			ExpiresAt:     time.Now().Add(time.Hour),
+			ExpiresAt:     time.Now().Add(validFor),
 			LastHeartbeat: time.Now(),
 		}
 
@@ -123,6 +161,8 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 			u.logger.Warn().Err(err).Msg("Failed to save reservation metadata")
 		}
 
+		u.recordSession(ctx, streamReservation, req.ClientIP)
+
 		u.logger.Info().
 			Str("reservation_id", reservation.ReservationID).
 			Str("user_id", req.UserID).
@@ -149,15 +189,23 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 		Msg("Creating new stream resources (first viewer)")
 
 	// 3. Check agency limit (Stream Counter)
-	reservation, err := u.streamCounterClient.ReserveStream(ctx, req.CameraID, camera.Source, req.UserID)
+	reservation, err := u.streamCounterClient.ReserveStream(ctx, req.CameraID, source, req.UserID)
 	if err != nil {
 		// If reservation fails, it's likely due to limit exceeded
 		return nil, fmt.Errorf("failed to reserve stream: %w", err)
 	}
 
+	// The stream source needs the decrypted camera credentials
+	dialURL, err := u.vault.DialURL(ctx, req.CameraID, camera.RTSPURL)
+	if err != nil {
+		u.streamCounterClient.ReleaseStream(ctx, reservation.ReservationID)
+		u.logger.Error().Err(err).Str("camera_id", req.CameraID).Msg("Failed to load camera credentials")
+		return nil, fmt.Errorf("failed to load camera credentials: %w", err)
+	}
+
 	// 3. Configure MediaMTX to pull RTSP stream from camera
 	mediaMTXPath := fmt.Sprintf("camera_%s", req.CameraID)
-	err = u.mediaMTXClient.ConfigurePath(ctx, mediaMTXPath, camera.RTSPURL)
+	err = u.mediaMTXClient.ConfigurePath(ctx, mediaMTXPath, dialURL)
 	if err != nil {
 		// Rollback reservation
 		u.streamCounterClient.ReleaseStream(ctx, reservation.ReservationID)
@@ -194,7 +242,7 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 	// Pulls RTSP from MediaMTX and pushes to LiveKit WHIP endpoint
 	// No transcoding - just RTP repackaging for low latency
 	pusherContainerName := fmt.Sprintf("whip-pusher-%s", req.CameraID)
-	err = u.startWHIPPusher(ctx, pusherContainerName, camera.RTSPURL, ingressInfo.Url, ingressInfo.StreamKey)
+	err = u.startWHIPPusher(ctx, pusherContainerName, dialURL, ingressInfo.Url, ingressInfo.StreamKey)
 	if err != nil {
 		// Rollback
 		u.streamCounterClient.ReleaseStream(ctx, reservation.ReservationID)
@@ -218,7 +266,7 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 		roomName,
 		participantIdentity,
 		false, // viewers cannot publish
-		time.Hour,
+		validFor,
 	)
 	if err != nil {
 		// Rollback
@@ -232,12 +280,12 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 		CameraID:      req.CameraID,
 		CameraName:    camera.Name,
 		UserID:        req.UserID,
-		Source:        camera.Source,
+		Source:        source,
 		RoomName:      roomName,
 		Token:         token,
 		IngressID:     ingressID,
 		ReservedAt:    time.Now(),
-		ExpiresAt:     time.Now().Add(time.Hour),
+		ExpiresAt:     time.Now().Add(validFor),
 		LastHeartbeat: time.Now(),
 	}
 
@@ -248,14 +296,17 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 		// Don't fail the request, just log the warning
 	}
 
+	// Persist viewing session for audit
+	u.recordSession(ctx, streamReservation, req.ClientIP)
+
 	// 7. Audit log
 	u.logger.Info().
 		Str("reservation_id", reservation.ReservationID).
 		Str("user_id", req.UserID).
 		Str("camera_id", req.CameraID).
-		Str("source", camera.Source).
+		Str("source", source).
 		Str("ingress_id", ingressID).
-		Str("rtsp_url", camera.RTSPURL).
+		Str("rtsp_url", secrets.MaskURL(camera.RTSPURL)).
 		Msg("Stream requested successfully")
 
 	return &domain.StreamResponse{
@@ -272,6 +323,11 @@ func (u *StreamUseCase) RequestStream(ctx context.Context, req domain.StreamRequ
 
 // ReleaseStream releases a stream reservation
 func (u *StreamUseCase) ReleaseStream(ctx context.Context, reservationID string) error {
+	return u.releaseStream(ctx, reservationID, domain.SessionEndReleased)
+}
+
+// releaseStream releases a stream reservation and closes its viewing session with the given end reason
+func (u *StreamUseCase) releaseStream(ctx context.Context, reservationID, endReason string) error {
 	// Get reservation details from HASH (stream-counter format)
 	reservation, err := u.streamRepo.GetReservationFromHash(ctx, reservationID)
 	if err != nil {
@@ -289,6 +345,11 @@ func (u *StreamUseCase) ReleaseStream(ctx context.Context, reservationID string)
 		u.logger.Warn().Err(err).Msg("Failed to delete reservation metadata")
 	}
 
+	// Close the viewing session
+	if err := u.sessionRepo.EndSession(ctx, reservationID, endReason); err != nil {
+		u.logger.Warn().Err(err).Str("reservation_id", reservationID).Msg("Failed to end stream session")
+	}
+
 	// Check if there are any other viewers for this camera
 	remainingReservation, err := u.streamRepo.GetReservationByCameraID(ctx, reservation.CameraID)
 	if err != nil {
@@ -358,6 +419,10 @@ func (u *StreamUseCase) SendHeartbeat(ctx context.Context, reservationID string)
 		u.logger.Warn().Err(err).Msg("Failed to update heartbeat in repository")
 	}
 
+	if err := u.sessionRepo.TouchSession(ctx, reservationID); err != nil {
+		u.logger.Warn().Err(err).Msg("Failed to update stream session heartbeat")
+	}
+
 	return nil
 }
 
@@ -474,7 +539,7 @@ func (u *StreamUseCase) startWHIPPusher(ctx context.Context, containerName, rtsp
 
 	u.logger.Info().
 		Str("container", containerName).
-		Str("rtsp_url", rtspURL).
+		Str("rtsp_url", secrets.MaskURL(rtspURL)).
 		Msg("Successfully started WHIP pusher container")
 
 	return nil
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+)
+
+// recordSession persists a viewing session for a new reservation
+// Failures are logged but never fail the stream request
+func (u *StreamUseCase) recordSession(ctx context.Context, reservation *domain.StreamReservation, clientIP string) {
+	session := &domain.StreamSession{
+		ReservationID: reservation.ID,
+		CameraID:      reservation.CameraID,
+		Agency:        reservation.Source,
+		ViewerID:      reservation.UserID,
+		ViewerIP:      clientIP,
+		RoomName:      reservation.RoomName,
+		StartedAt:     reservation.ReservedAt,
+	}
+
+	if err := u.sessionRepo.CreateSession(ctx, session); err != nil {
+		u.logger.Warn().Err(err).Str("reservation_id", reservation.ID).Msg("Failed to persist stream session")
+	}
+}
+
+// ListSessions retrieves persisted viewing sessions
+func (u *StreamUseCase) ListSessions(ctx context.Context, query domain.StreamSessionQuery) ([]*domain.StreamSession, error) {
+	if query.From != nil && query.To != nil && query.To.Before(*query.From) {
+		return nil, fmt.Errorf("%w: to is before from", domain.ErrInvalidSessionQuery)
+	}
+
+	sessions, err := u.sessionRepo.ListSessions(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RunSessionReaper periodically closes sessions whose reservation has expired in stream-counter
+// Reservations expire when heartbeats stop, so no release call is ever made for them
+func (u *StreamUseCase) RunSessionReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			u.reapExpiredSessions(ctx)
+		}
+	}
+}
+
+// reapExpiredSessions closes active sessions that no longer have a reservation
+func (u *StreamUseCase) reapExpiredSessions(ctx context.Context) {
+	reservationIDs, err := u.sessionRepo.ListActiveReservationIDs(ctx)
+	if err != nil {
+		u.logger.Error().Err(err).Msg("Failed to list active stream sessions")
+		return
+	}
+
+	for _, reservationID := range reservationIDs {
+		_, err := u.streamRepo.GetReservationFromHash(ctx, reservationID)
+		if err == nil {
+			continue // Still active
+		}
+		if !errors.Is(err, domain.ErrReservationNotFound) {
+			u.logger.Warn().Err(err).Str("reservation_id", reservationID).Msg("Failed to check reservation")
+			continue
+		}
+
+		if err := u.sessionRepo.EndSession(ctx, reservationID, domain.SessionEndExpired); err != nil {
+			u.logger.Warn().Err(err).Str("reservation_id", reservationID).Msg("Failed to expire stream session")
+			continue
+		}
+
+		u.logger.Info().Str("reservation_id", reservationID).Msg("Stream session expired")
+	}
+}
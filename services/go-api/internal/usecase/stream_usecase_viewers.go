@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/rta/cctv/go-api/internal/domain"
+)
+
+// viewerIdentityPrefix is prepended to the reservation ID to form a viewer's LiveKit identity
+const viewerIdentityPrefix = "viewer_"
+
+// ListViewers lists the viewers currently connected to a camera's LiveKit room
+// Each participant is joined with its stream-counter reservation to resolve the user ID
+func (u *StreamUseCase) ListViewers(ctx context.Context, cameraID string) ([]*domain.Viewer, error) {
+	roomName := fmt.Sprintf("camera_%s", cameraID)
+
+	participants, err := u.livekitClient.ListParticipants(ctx, roomName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list viewers: %w", err)
+	}
+
+	viewers := []*domain.Viewer{}
+	for _, participant := range participants {
+		// Skip the WHIP ingress publisher, it is not a viewer
+		if participant.Kind == livekit.ParticipantInfo_INGRESS ||
+			!strings.HasPrefix(participant.Identity, viewerIdentityPrefix) {
+			continue
+		}
+
+		reservationID := strings.TrimPrefix(participant.Identity, viewerIdentityPrefix)
+		viewer := &domain.Viewer{
+			Identity:      participant.Identity,
+			ReservationID: reservationID,
+			CameraID:      cameraID,
+			State:         participant.State.String(),
+			JoinedAt:      time.Unix(participant.JoinedAt, 0),
+		}
+
+		reservation, err := u.streamRepo.GetReservationFromHash(ctx, reservationID)
+		if err != nil {
+			u.logger.Debug().Err(err).Str("reservation_id", reservationID).Msg("No reservation for viewer")
+		} else {
+			viewer.UserID = reservation.UserID
+		}
+
+		viewers = append(viewers, viewer)
+	}
+
+	return viewers, nil
+}
+
+// EvictViewer disconnects a viewer from a camera, releases its reservation and records an audit event
+// Only callers in one of the supervisor groups may evict.
+func (u *StreamUseCase) EvictViewer(ctx context.Context, cameraID, identity string, actor *domain.Actor, req domain.EvictViewerRequest) error {
+	if actor.IsAnonymous() {
+		return fmt.Errorf("%w: a user identity is required", domain.ErrEvictForbidden)
+	}
+	if !u.canEvict(actor) {
+		return fmt.Errorf("%w: %s is not in %s", domain.ErrEvictForbidden, actor.UserID, strings.Join(u.evictGroups, ", "))
+	}
+
+	return u.evictViewer(ctx, cameraID, identity, actor.UserID, req.Reason)
+}
+
+// evictViewer disconnects a viewer on behalf of actorID without checking its groups
+// Share links use it to disconnect their guests when they end.
+func (u *StreamUseCase) evictViewer(ctx context.Context, cameraID, identity, actorID, reason string) error {
+	if !strings.HasPrefix(identity, viewerIdentityPrefix) {
+		return domain.ErrViewerNotFound
+	}
+
+	roomName := fmt.Sprintf("camera_%s", cameraID)
+	reservationID := strings.TrimPrefix(identity, viewerIdentityPrefix)
+
+	// Look up the reservation first so the user ID is known for the audit record
+	// Only a missing reservation is tolerated; a failed lookup must not turn into a 404
+	reservation, err := u.streamRepo.GetReservationFromHash(ctx, reservationID)
+	if err != nil && !errors.Is(err, domain.ErrReservationNotFound) {
+		return fmt.Errorf("failed to look up viewer reservation: %w", err)
+	}
+	if err != nil {
+		u.logger.Warn().Err(err).Str("reservation_id", reservationID).Msg("No reservation found for evicted viewer")
+	}
+	if reservation != nil && reservation.CameraID != cameraID {
+		return domain.ErrViewerNotFound
+	}
+
+	// Remove the participant from the LiveKit room
+	removeErr := u.livekitClient.RemoveParticipant(ctx, roomName, identity)
+	if removeErr != nil {
+		if reservation == nil {
+			// Neither a participant nor a reservation exists
+			return domain.ErrViewerNotFound
+		}
+		u.logger.Warn().Err(removeErr).Str("identity", identity).Msg("Failed to remove participant, releasing reservation anyway")
+	}
+
+	// Release the reservation (frees quota and tears down resources if this was the last viewer)
+	if reservation != nil {
+		if err := u.releaseStream(ctx, reservationID, domain.SessionEndEvicted); err != nil {
+			return fmt.Errorf("failed to release reservation: %w", err)
+		}
+	}
+
+	userID := ""
+	if reservation != nil {
+		userID = reservation.UserID
+	}
+
+	// Audit log
+	event := &domain.AuditEvent{
+		Action:     domain.AuditActionViewerEvict,
+		ActorID:    actorID,
+		TargetType: domain.AuditTargetViewer,
+		TargetID:   identity,
+		CameraID:   cameraID,
+		Reason:     reason,
+		Details: map[string]interface{}{
+			"reservation_id":      reservationID,
+			"user_id":             userID,
+			"participant_removed": removeErr == nil,
+		},
+	}
+	if err := u.auditRepo.RecordEvent(ctx, event); err != nil {
+		u.logger.Error().Err(err).Str("identity", identity).Msg("Failed to write audit record for viewer eviction")
+		// Continue anyway - the viewer has already been evicted
+	}
+
+	u.logger.Info().
+		Str("camera_id", cameraID).
+		Str("identity", identity).
+		Str("user_id", userID).
+		Str("actor_id", actorID).
+		Str("reason", reason).
+		Msg("Viewer evicted")
+
+	return nil
+}
+
+// canEvict reports whether the actor belongs to a group allowed to evict viewers
+func (u *StreamUseCase) canEvict(actor *domain.Actor) bool {
+	for _, group := range u.evictGroups {
+		if actor.InGroup(group) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,428 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// tourPrefetchLead is how long before a switch the next camera's stream is reserved
+	// It must stay below domain.MinTourDwellSeconds
+	tourPrefetchLead = 3 * time.Second
+
+	// tourKeepAliveTimeout stops a run whose client stopped sending keep-alives
+	tourKeepAliveTimeout = 90 * time.Second
+
+	// tourHeartbeatInterval is how often the engine heartbeats the reservations it holds
+	tourHeartbeatInterval = 30 * time.Second
+
+	// tourTick is the resolution of the run loop
+	tourTick = 500 * time.Millisecond
+)
+
+// TourStreamer reserves, keeps alive and releases streams for tour steps
+type TourStreamer interface {
+	RequestStream(ctx context.Context, req domain.StreamRequest) (*domain.StreamResponse, error)
+	ReleaseStream(ctx context.Context, reservationID string) error
+	SendHeartbeat(ctx context.Context, reservationID string) error
+}
+
+// PTZController moves cameras to the presets of tour steps
+type PTZController interface {
+	ControlPTZ(ctx context.Context, cmd domain.PTZCommand) error
+}
+
+// TourEngine plays tours in layout cells
+// Each run holds the current step's reservation and, from tourPrefetchLead before a switch,
+// the next step's reservation, so the client can connect to the next room before it is shown.
+// The previous reservation is released at the switch: a cell never holds more than two.
+type TourEngine struct {
+	tourRepo domain.TourRepository
+	streamer TourStreamer
+	ptz      PTZController
+	logger   zerolog.Logger
+
+	mu   sync.Mutex
+	runs map[string]*tourRun
+}
+
+// tourRun is the engine-side state of a playing tour
+type tourRun struct {
+	mu     sync.Mutex
+	state  domain.TourRun
+	tour   *domain.CameraTour
+	req    domain.StartTourRequest
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewTourEngine creates a new tour engine
+func NewTourEngine(tourRepo domain.TourRepository, streamer TourStreamer, ptz PTZController, logger zerolog.Logger) *TourEngine {
+	return &TourEngine{
+		tourRepo: tourRepo,
+		streamer: streamer,
+		ptz:      ptz,
+		logger:   logger,
+		runs:     make(map[string]*tourRun),
+	}
+}
+
+// StartRun starts playing a tour in a layout cell
+// A run already playing in the same cell for the same user is stopped first
+func (e *TourEngine) StartRun(ctx context.Context, tourID string, req domain.StartTourRequest) (*domain.TourRun, error) {
+	if req.UserID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", domain.ErrInvalidTour)
+	}
+
+	tour, err := e.tourRepo.GetByID(ctx, tourID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tour: %w", err)
+	}
+	if len(tour.Steps) == 0 {
+		return nil, fmt.Errorf("%w: tour has no steps", domain.ErrInvalidTour)
+	}
+
+	e.stopCellRuns(ctx, req.UserID, req.LayoutID, req.PositionIndex)
+
+	run := &tourRun{
+		tour: tour,
+		req:  req,
+		done: make(chan struct{}),
+	}
+
+	// Reserve the first step that can be streamed
+	current := e.reserveFrom(ctx, run, 0, nil)
+	if current == nil {
+		return nil, fmt.Errorf("failed to start tour: no camera in the tour could be reserved")
+	}
+	e.recallPreset(ctx, run, current)
+
+	now := time.Now()
+	run.state = domain.TourRun{
+		ID:            uuid.New().String(),
+		TourID:        tour.ID,
+		UserID:        req.UserID,
+		LayoutID:      req.LayoutID,
+		PositionIndex: req.PositionIndex,
+		Current:       current,
+		SwitchAt:      now.Add(tour.Dwell(tour.Steps[current.StepIndex])),
+		StartedAt:     now,
+		LastKeepAlive: now,
+	}
+
+	// Runs outlive the HTTP request that started them
+	runCtx, cancel := context.WithCancel(context.Background())
+	run.cancel = cancel
+
+	e.mu.Lock()
+	e.runs[run.state.ID] = run
+	e.mu.Unlock()
+
+	go e.loop(runCtx, run)
+
+	e.logger.Info().
+		Str("run_id", run.state.ID).
+		Str("tour_id", tour.ID).
+		Str("user_id", req.UserID).
+		Int("position_index", req.PositionIndex).
+		Msg("Tour run started")
+
+	return run.snapshot(), nil
+}
+
+// GetRun returns the current state of a run
+func (e *TourEngine) GetRun(runID string) (*domain.TourRun, error) {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	return run.snapshot(), nil
+}
+
+// KeepAlive marks the run's client as alive and returns the current state
+func (e *TourEngine) KeepAlive(runID string) (*domain.TourRun, error) {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	run.mu.Lock()
+	run.state.LastKeepAlive = time.Now()
+	run.mu.Unlock()
+
+	return run.snapshot(), nil
+}
+
+// StopRun stops a run and releases its reservations
+func (e *TourEngine) StopRun(ctx context.Context, runID string) error {
+	run, err := e.getRun(runID)
+	if err != nil {
+		return err
+	}
+
+	run.cancel()
+
+	select {
+	case <-run.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// StopAll stops every run, used on shutdown
+func (e *TourEngine) StopAll(ctx context.Context) {
+	e.mu.Lock()
+	runs := make([]*tourRun, 0, len(e.runs))
+	for _, run := range e.runs {
+		runs = append(runs, run)
+	}
+	e.mu.Unlock()
+
+	for _, run := range runs {
+		run.cancel()
+	}
+
+	for _, run := range runs {
+		select {
+		case <-run.done:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// loop drives a run: prefetch, switch, heartbeats and keep-alive expiry
+func (e *TourEngine) loop(ctx context.Context, run *tourRun) {
+	defer close(run.done)
+	defer e.finish(run)
+
+	ticker := time.NewTicker(tourTick)
+	defer ticker.Stop()
+
+	lastHeartbeat := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			run.mu.Lock()
+			lastKeepAlive := run.state.LastKeepAlive
+			switchAt := run.state.SwitchAt
+			current := run.state.Current
+			next := run.state.Next
+			run.mu.Unlock()
+
+			if now.Sub(lastKeepAlive) > tourKeepAliveTimeout {
+				e.logger.Info().Str("run_id", run.state.ID).Msg("Tour run client went away, stopping")
+				return
+			}
+
+			// Reserve the next camera ahead of the switch
+			if next == nil && len(run.tour.Steps) > 1 && !now.Before(switchAt.Add(-tourPrefetchLead)) {
+				next = e.reserveFrom(ctx, run, (current.StepIndex+1)%len(run.tour.Steps), current)
+				if next == nil {
+					// Nothing else can be streamed, keep showing the current camera
+					run.mu.Lock()
+					run.state.SwitchAt = now.Add(run.tour.Dwell(run.tour.Steps[current.StepIndex]))
+					run.mu.Unlock()
+					continue
+				}
+
+				run.mu.Lock()
+				run.state.Next = next
+				run.mu.Unlock()
+			}
+
+			// Switch and release the previous reservation
+			if next != nil && !now.Before(switchAt) {
+				run.mu.Lock()
+				run.state.Current = next
+				run.state.Next = nil
+				run.state.SwitchAt = now.Add(run.tour.Dwell(run.tour.Steps[next.StepIndex]))
+				run.mu.Unlock()
+
+				if reservationID(current) != reservationID(next) {
+					e.release(current)
+				}
+				e.recallPreset(ctx, run, next)
+			}
+
+			if now.Sub(lastHeartbeat) >= tourHeartbeatInterval {
+				lastHeartbeat = now
+				e.heartbeat(ctx, run)
+			}
+		}
+	}
+}
+
+// reserveFrom reserves the first streamable step starting at index start
+// The current step is skipped; when its camera comes up again its reservation is shared.
+// Presets are not recalled here: the step may be prefetched while its camera is still on screen.
+func (e *TourEngine) reserveFrom(ctx context.Context, run *tourRun, start int, current *domain.TourRunStep) *domain.TourRunStep {
+	steps := run.tour.Steps
+
+	for i := 0; i < len(steps); i++ {
+		index := (start + i) % len(steps)
+		if current != nil && index == current.StepIndex {
+			continue
+		}
+		step := steps[index]
+
+		runStep := &domain.TourRunStep{
+			StepIndex: index,
+			CameraID:  step.CameraID,
+			PresetID:  step.PresetID,
+		}
+
+		if current != nil && current.CameraID == step.CameraID {
+			runStep.Stream = current.Stream
+			return runStep
+		}
+
+		stream, err := e.streamer.RequestStream(ctx, domain.StreamRequest{
+			CameraID: step.CameraID,
+			UserID:   run.req.UserID,
+			Quality:  run.req.Quality,
+			ClientIP: run.req.ClientIP,
+		})
+		if err != nil {
+			e.logger.Warn().Err(err).Str("tour_id", run.tour.ID).Str("camera_id", step.CameraID).Msg("Skipping tour step, camera could not be reserved")
+			continue
+		}
+
+		runStep.Stream = stream
+		return runStep
+	}
+
+	return nil
+}
+
+// recallPreset moves the camera of a step that just became current to the step's preset
+// A failed recall is only logged; the camera is shown where it points.
+func (e *TourEngine) recallPreset(ctx context.Context, run *tourRun, step *domain.TourRunStep) {
+	if step.PresetID == nil {
+		return
+	}
+
+	cmd := domain.PTZCommand{
+		CameraID: step.CameraID,
+		Command:  "preset",
+		PresetID: *step.PresetID,
+		UserID:   run.req.UserID,
+	}
+	if err := e.ptz.ControlPTZ(ctx, cmd); err != nil {
+		e.logger.Warn().Err(err).Str("camera_id", step.CameraID).Int("preset_id", *step.PresetID).Msg("Failed to recall tour preset")
+	}
+}
+
+// heartbeat keeps the run's reservations alive in stream-counter
+func (e *TourEngine) heartbeat(ctx context.Context, run *tourRun) {
+	run.mu.Lock()
+	steps := []*domain.TourRunStep{run.state.Current, run.state.Next}
+	run.mu.Unlock()
+
+	seen := map[string]bool{}
+	for _, step := range steps {
+		id := reservationID(step)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if err := e.streamer.SendHeartbeat(ctx, id); err != nil {
+			e.logger.Warn().Err(err).Str("reservation_id", id).Msg("Failed to heartbeat tour reservation")
+		}
+	}
+}
+
+// finish releases a run's reservations and forgets it
+func (e *TourEngine) finish(run *tourRun) {
+	run.mu.Lock()
+	current, next := run.state.Current, run.state.Next
+	run.mu.Unlock()
+
+	e.release(current)
+	if reservationID(next) != reservationID(current) {
+		e.release(next)
+	}
+
+	e.mu.Lock()
+	delete(e.runs, run.state.ID)
+	e.mu.Unlock()
+
+	e.logger.Info().Str("run_id", run.state.ID).Str("tour_id", run.tour.ID).Msg("Tour run stopped")
+}
+
+// release releases a step's reservation
+func (e *TourEngine) release(step *domain.TourRunStep) {
+	id := reservationID(step)
+	if id == "" {
+		return
+	}
+
+	// The run context may already be cancelled, the release must still go out
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := e.streamer.ReleaseStream(ctx, id); err != nil {
+		e.logger.Warn().Err(err).Str("reservation_id", id).Msg("Failed to release tour reservation")
+	}
+}
+
+// stopCellRuns stops the runs of a user in a layout cell
+func (e *TourEngine) stopCellRuns(ctx context.Context, userID, layoutID string, positionIndex int) {
+	e.mu.Lock()
+	ids := []string{}
+	for id, run := range e.runs {
+		if run.req.UserID == userID && run.req.LayoutID == layoutID && run.req.PositionIndex == positionIndex {
+			ids = append(ids, id)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, id := range ids {
+		if err := e.StopRun(ctx, id); err != nil {
+			e.logger.Warn().Err(err).Str("run_id", id).Msg("Failed to stop previous tour run in cell")
+		}
+	}
+}
+
+// getRun looks up a run by ID
+func (e *TourEngine) getRun(runID string) (*tourRun, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	run, ok := e.runs[runID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTourRunNotFound, runID)
+	}
+
+	return run, nil
+}
+
+// snapshot returns a copy of the run state
+func (r *tourRun) snapshot() *domain.TourRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.state
+	return &state
+}
+
+// reservationID returns the reservation of a step, or "" if none
+func reservationID(step *domain.TourRunStep) string {
+	if step == nil || step.Stream == nil {
+		return ""
+	}
+	return step.Stream.ReservationID
+}
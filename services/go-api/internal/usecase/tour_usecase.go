@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// TourUseCase implements domain.TourUseCase
+type TourUseCase struct {
+	tourRepo domain.TourRepository
+	logger   zerolog.Logger
+}
+
+// NewTourUseCase creates a new tour use case
+func NewTourUseCase(tourRepo domain.TourRepository, logger zerolog.Logger) *TourUseCase {
+	return &TourUseCase{
+		tourRepo: tourRepo,
+		logger:   logger,
+	}
+}
+
+// CreateTour creates a new tour
+func (uc *TourUseCase) CreateTour(ctx context.Context, request *domain.CreateTourRequest) (*domain.CameraTour, error) {
+	if request.CreatedBy == "" {
+		return nil, fmt.Errorf("%w: created_by is required", domain.ErrInvalidTour)
+	}
+
+	dwellSeconds, err := validateTour(request.Name, request.DwellSeconds, request.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	tour := &domain.CameraTour{
+		Name:         request.Name,
+		Description:  request.Description,
+		DwellSeconds: dwellSeconds,
+		CreatedBy:    request.CreatedBy,
+		Steps:        request.Steps,
+	}
+
+	if err := uc.tourRepo.Create(ctx, tour); err != nil {
+		return nil, fmt.Errorf("failed to create tour: %w", err)
+	}
+
+	uc.logger.Info().
+		Str("tour_id", tour.ID).
+		Str("name", tour.Name).
+		Int("steps", len(tour.Steps)).
+		Str("created_by", tour.CreatedBy).
+		Msg("Tour created successfully")
+
+	return tour, nil
+}
+
+// GetTour retrieves a tour by ID
+func (uc *TourUseCase) GetTour(ctx context.Context, id string) (*domain.CameraTour, error) {
+	tour, err := uc.tourRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tour: %w", err)
+	}
+
+	return tour, nil
+}
+
+// ListTours retrieves all tours
+func (uc *TourUseCase) ListTours(ctx context.Context) ([]*domain.CameraTour, error) {
+	tours, err := uc.tourRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tours: %w", err)
+	}
+
+	return tours, nil
+}
+
+// UpdateTour updates an existing tour
+// Runs already playing the tour keep the version they started with
+func (uc *TourUseCase) UpdateTour(ctx context.Context, id string, request *domain.UpdateTourRequest) (*domain.CameraTour, error) {
+	dwellSeconds, err := validateTour(request.Name, request.DwellSeconds, request.Steps)
+	if err != nil {
+		return nil, err
+	}
+	request.DwellSeconds = dwellSeconds
+
+	tour, err := uc.tourRepo.Update(ctx, id, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tour: %w", err)
+	}
+
+	return tour, nil
+}
+
+// DeleteTour deletes a tour by ID
+func (uc *TourUseCase) DeleteTour(ctx context.Context, id string) error {
+	if err := uc.tourRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete tour: %w", err)
+	}
+
+	return nil
+}
+
+// validateTour validates tour settings and renumbers the steps in order
+// Returns the dwell time to store (the default when unset)
+func validateTour(name string, dwellSeconds int, steps []domain.TourStep) (int, error) {
+	if name == "" {
+		return 0, fmt.Errorf("%w: name is required", domain.ErrInvalidTour)
+	}
+
+	if dwellSeconds == 0 {
+		dwellSeconds = domain.DefaultTourDwellSeconds
+	}
+	if dwellSeconds < domain.MinTourDwellSeconds {
+		return 0, fmt.Errorf("%w: dwell_seconds must be at least %d", domain.ErrInvalidTour, domain.MinTourDwellSeconds)
+	}
+
+	if len(steps) == 0 {
+		return 0, fmt.Errorf("%w: at least one step is required", domain.ErrInvalidTour)
+	}
+
+	for i := range steps {
+		step := &steps[i]
+		step.StepIndex = i
+
+		if step.CameraID == "" {
+			return 0, fmt.Errorf("%w: step %d: camera_id is required", domain.ErrInvalidTour, i)
+		}
+
+		if step.DwellSeconds != 0 && step.DwellSeconds < domain.MinTourDwellSeconds {
+			return 0, fmt.Errorf("%w: step %d: dwell_seconds must be at least %d", domain.ErrInvalidTour, i, domain.MinTourDwellSeconds)
+		}
+
+		if step.PresetID != nil && (*step.PresetID < 1 || *step.PresetID > 256) {
+			return 0, fmt.Errorf("%w: step %d: preset_id must be 1-256", domain.ErrInvalidTour, i)
+		}
+	}
+
+	return dwellSeconds, nil
+}
@@ -0,0 +1,332 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rta/cctv/go-api/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// WallNotifier delivers video wall commands and state changes to WebSocket clients
+type WallNotifier interface {
+	// SendWallCommand sends a command to the display clients of a monitor
+	// Returns false when no display client for the monitor is connected
+	SendWallCommand(monitorID string, command *domain.WallCommand) bool
+
+	// IsMonitorConnected reports whether a display client for the monitor is connected
+	IsMonitorConnected(monitorID string) bool
+
+	// BroadcastWallUpdate tells every client, e.g. supervisor consoles, that a monitor changed
+	BroadcastWallUpdate(monitor *domain.WallMonitor)
+}
+
+// VideoWallUseCase implements domain.VideoWallUseCase
+type VideoWallUseCase struct {
+	wallRepo      domain.VideoWallRepository
+	layoutUseCase domain.LayoutUseCase
+	notifier      WallNotifier
+	logger        zerolog.Logger
+}
+
+// NewVideoWallUseCase creates a new video wall use case
+func NewVideoWallUseCase(wallRepo domain.VideoWallRepository, layoutUseCase domain.LayoutUseCase, notifier WallNotifier, logger zerolog.Logger) *VideoWallUseCase {
+	return &VideoWallUseCase{
+		wallRepo:      wallRepo,
+		layoutUseCase: layoutUseCase,
+		notifier:      notifier,
+		logger:        logger,
+	}
+}
+
+// CreateWall creates a new video wall
+func (uc *VideoWallUseCase) CreateWall(ctx context.Context, request *domain.CreateVideoWallRequest) (*domain.VideoWall, error) {
+	if request.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", domain.ErrInvalidVideoWall)
+	}
+
+	if request.CreatedBy == "" {
+		return nil, fmt.Errorf("%w: a user identity (X-User-ID) is required", domain.ErrInvalidVideoWall)
+	}
+
+	wall := &domain.VideoWall{
+		Name:        request.Name,
+		Description: request.Description,
+		CreatedBy:   request.CreatedBy,
+		Monitors:    []domain.WallMonitor{},
+	}
+
+	for _, monitor := range request.Monitors {
+		if err := validateWallMonitor(&monitor); err != nil {
+			return nil, err
+		}
+		wall.Monitors = append(wall.Monitors, domain.WallMonitor{
+			Name:          monitor.Name,
+			PositionIndex: monitor.PositionIndex,
+		})
+	}
+
+	if err := uc.wallRepo.Create(ctx, wall); err != nil {
+		return nil, fmt.Errorf("failed to create video wall: %w", err)
+	}
+
+	uc.logger.Info().
+		Str("wall_id", wall.ID).
+		Str("name", wall.Name).
+		Int("monitors", len(wall.Monitors)).
+		Msg("Video wall created successfully")
+
+	return wall, nil
+}
+
+// GetWall retrieves a video wall with the content and status of each monitor
+func (uc *VideoWallUseCase) GetWall(ctx context.Context, id string) (*domain.VideoWall, error) {
+	wall, err := uc.wallRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video wall: %w", err)
+	}
+
+	uc.markOnline(wall)
+	return wall, nil
+}
+
+// ListWalls retrieves all video walls
+func (uc *VideoWallUseCase) ListWalls(ctx context.Context) ([]*domain.VideoWall, error) {
+	walls, err := uc.wallRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list video walls: %w", err)
+	}
+
+	for _, wall := range walls {
+		uc.markOnline(wall)
+	}
+	return walls, nil
+}
+
+// UpdateWall updates a video wall
+func (uc *VideoWallUseCase) UpdateWall(ctx context.Context, id string, request *domain.UpdateVideoWallRequest) (*domain.VideoWall, error) {
+	if request.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", domain.ErrInvalidVideoWall)
+	}
+
+	wall, err := uc.wallRepo.Update(ctx, id, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update video wall: %w", err)
+	}
+
+	uc.markOnline(wall)
+	return wall, nil
+}
+
+// DeleteWall deletes a video wall
+func (uc *VideoWallUseCase) DeleteWall(ctx context.Context, id string) error {
+	if err := uc.wallRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete video wall: %w", err)
+	}
+
+	return nil
+}
+
+// AddMonitor adds a monitor to a video wall
+func (uc *VideoWallUseCase) AddMonitor(ctx context.Context, wallID string, request *domain.WallMonitorRequest) (*domain.WallMonitor, error) {
+	if err := validateWallMonitor(request); err != nil {
+		return nil, err
+	}
+
+	monitor := &domain.WallMonitor{
+		WallID:        wallID,
+		Name:          request.Name,
+		PositionIndex: request.PositionIndex,
+	}
+
+	if err := uc.wallRepo.AddMonitor(ctx, monitor); err != nil {
+		return nil, fmt.Errorf("failed to add video wall monitor: %w", err)
+	}
+
+	monitor.Online = uc.notifier.IsMonitorConnected(monitor.ID)
+	return monitor, nil
+}
+
+// UpdateMonitor renames or moves a monitor
+func (uc *VideoWallUseCase) UpdateMonitor(ctx context.Context, wallID, monitorID string, request *domain.WallMonitorRequest) (*domain.WallMonitor, error) {
+	if err := validateWallMonitor(request); err != nil {
+		return nil, err
+	}
+
+	monitor, err := uc.wallRepo.UpdateMonitor(ctx, wallID, monitorID, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update video wall monitor: %w", err)
+	}
+
+	monitor.Online = uc.notifier.IsMonitorConnected(monitor.ID)
+	return monitor, nil
+}
+
+// DeleteMonitor removes a monitor from a video wall
+// A connected display is blanked first
+func (uc *VideoWallUseCase) DeleteMonitor(ctx context.Context, wallID, monitorID string) error {
+	if err := uc.wallRepo.DeleteMonitor(ctx, wallID, monitorID); err != nil {
+		return fmt.Errorf("failed to delete video wall monitor: %w", err)
+	}
+
+	uc.notifier.SendWallCommand(monitorID, &domain.WallCommand{
+		WallID:      wallID,
+		MonitorID:   monitorID,
+		ContentType: domain.WallContentBlank,
+		IssuedAt:    time.Now(),
+	})
+
+	return nil
+}
+
+// Display shows a layout or a camera on a monitor
+// The actor must be able to view the layout; the display client receives it in full
+func (uc *VideoWallUseCase) Display(ctx context.Context, wallID, monitorID string, request *domain.WallDisplayRequest, actor *domain.Actor) (*domain.WallMonitor, error) {
+	if request.LayoutID != "" && request.CameraID != "" {
+		return nil, fmt.Errorf("%w: set layout_id or camera_id, not both", domain.ErrInvalidVideoWall)
+	}
+
+	command, err := uc.buildCommand(wallID, monitorID, request.LayoutID, request.CameraID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	monitor, err := uc.wallRepo.SetMonitorContent(ctx, wallID, monitorID, request.LayoutID, request.CameraID, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set video wall monitor content: %w", err)
+	}
+
+	delivered := uc.notifier.SendWallCommand(monitorID, command)
+	monitor.Online = delivered || uc.notifier.IsMonitorConnected(monitorID)
+	uc.notifier.BroadcastWallUpdate(monitor)
+
+	uc.logger.Info().
+		Str("wall_id", wallID).
+		Str("monitor_id", monitorID).
+		Str("content_type", string(command.ContentType)).
+		Str("layout_id", request.LayoutID).
+		Str("camera_id", request.CameraID).
+		Str("issued_by", actor.UserID).
+		Bool("delivered", delivered).
+		Msg("Video wall monitor switched")
+
+	return monitor, nil
+}
+
+// DisplayBatch switches several monitors of a wall at once
+// All assignments are checked before any monitor is switched
+func (uc *VideoWallUseCase) DisplayBatch(ctx context.Context, wallID string, request *domain.WallDisplayBatchRequest, actor *domain.Actor) (*domain.VideoWall, error) {
+	if len(request.Assignments) == 0 {
+		return nil, fmt.Errorf("%w: at least one assignment is required", domain.ErrInvalidVideoWall)
+	}
+
+	wall, err := uc.wallRepo.GetByID(ctx, wallID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video wall: %w", err)
+	}
+
+	onWall := make(map[string]bool, len(wall.Monitors))
+	for _, monitor := range wall.Monitors {
+		onWall[monitor.ID] = true
+	}
+
+	seen := make(map[string]bool, len(request.Assignments))
+	for _, assignment := range request.Assignments {
+		if !onWall[assignment.MonitorID] {
+			return nil, fmt.Errorf("%w: %s", domain.ErrWallMonitorNotFound, assignment.MonitorID)
+		}
+		if seen[assignment.MonitorID] {
+			return nil, fmt.Errorf("%w: monitor %s assigned twice", domain.ErrInvalidVideoWall, assignment.MonitorID)
+		}
+		if assignment.LayoutID != "" && assignment.CameraID != "" {
+			return nil, fmt.Errorf("%w: monitor %s: set layout_id or camera_id, not both", domain.ErrInvalidVideoWall, assignment.MonitorID)
+		}
+		seen[assignment.MonitorID] = true
+
+		// Fail on layouts the actor cannot see before switching anything
+		if _, err := uc.buildCommand(wallID, assignment.MonitorID, assignment.LayoutID, assignment.CameraID, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, assignment := range request.Assignments {
+		if _, err := uc.Display(ctx, wallID, assignment.MonitorID, &assignment.WallDisplayRequest, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	return uc.GetWall(ctx, wallID)
+}
+
+// ResendCommand sends a monitor its current content again, e.g. after its display client reconnects
+func (uc *VideoWallUseCase) ResendCommand(ctx context.Context, monitorID string) {
+	monitor, err := uc.wallRepo.GetMonitor(ctx, monitorID)
+	if err != nil {
+		uc.logger.Warn().Err(err).Str("monitor_id", monitorID).Msg("Display connected for unknown video wall monitor")
+		return
+	}
+
+	// Re-check the layout with the identity of whoever assigned it
+	command, err := uc.buildCommand(monitor.WallID, monitorID, monitor.LayoutID, monitor.CameraID, &domain.Actor{UserID: monitor.AssignedBy})
+	if err != nil {
+		uc.logger.Warn().Err(err).Str("monitor_id", monitorID).Msg("Failed to restore video wall monitor content")
+		return
+	}
+	command.IssuedBy = monitor.AssignedBy
+
+	uc.notifier.SendWallCommand(monitorID, command)
+
+	monitor.Online = true
+	uc.notifier.BroadcastWallUpdate(monitor)
+}
+
+// buildCommand builds the command that switches a monitor to a layout, a camera or blank
+func (uc *VideoWallUseCase) buildCommand(wallID, monitorID, layoutID, cameraID string, actor *domain.Actor) (*domain.WallCommand, error) {
+	command := &domain.WallCommand{
+		WallID:      wallID,
+		MonitorID:   monitorID,
+		ContentType: domain.WallContentBlank,
+		IssuedBy:    actor.UserID,
+		IssuedAt:    time.Now(),
+	}
+
+	switch {
+	case layoutID != "":
+		layout, err := uc.layoutUseCase.GetLayout(layoutID, actor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get layout: %w", err)
+		}
+		// Displays don't manage sharing
+		layout.Shares = nil
+
+		command.ContentType = domain.WallContentLayout
+		command.Layout = layout
+
+	case cameraID != "":
+		command.ContentType = domain.WallContentCamera
+		command.CameraID = cameraID
+	}
+
+	return command, nil
+}
+
+// markOnline sets the connection status of a wall's monitors
+func (uc *VideoWallUseCase) markOnline(wall *domain.VideoWall) {
+	for i := range wall.Monitors {
+		wall.Monitors[i].Online = uc.notifier.IsMonitorConnected(wall.Monitors[i].ID)
+	}
+}
+
+// validateWallMonitor validates a monitor request
+func validateWallMonitor(request *domain.WallMonitorRequest) error {
+	if request.Name == "" {
+		return fmt.Errorf("%w: monitor name is required", domain.ErrInvalidVideoWall)
+	}
+
+	if request.PositionIndex < 0 {
+		return fmt.Errorf("%w: position_index must not be negative", domain.ErrInvalidVideoWall)
+	}
+
+	return nil
+}
@@ -69,6 +69,7 @@ func main() {
 		Metro:       getEnvInt("LIMIT_METRO", 30),
 		Bus:         getEnvInt("LIMIT_BUS", 20),
 		Other:       getEnvInt("LIMIT_OTHER", 400),
+		Guest:       getEnvInt("LIMIT_GUEST", 10),
 		Total:       getEnvInt("LIMIT_TOTAL", 500),
 	}
 
@@ -78,6 +79,7 @@ func main() {
 		string(domain.SourceMetro):       limits.Metro,
 		string(domain.SourceBus):         limits.Bus,
 		string(domain.SourceOther):       limits.Other,
+		string(domain.SourceGuest):       limits.Guest,
 	}); err != nil {
 		logger.Fatal().Err(err).Msg("Failed to initialize stream limits")
 	}
@@ -50,7 +50,7 @@ func (h *Handler) ReserveStream(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !req.Source.IsValid() {
-		respondError(w, http.StatusBadRequest, "Invalid source. Must be one of: DUBAI_POLICE, METRO, BUS, OTHER", "INVALID_SOURCE")
+		respondError(w, http.StatusBadRequest, "Invalid source. Must be one of: DUBAI_POLICE, METRO, BUS, OTHER, GUEST", "INVALID_SOURCE")
 		return
 	}
 
@@ -223,6 +223,7 @@ func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 		string(domain.SourceMetro),
 		string(domain.SourceBus),
 		string(domain.SourceOther),
+		string(domain.SourceGuest),
 	}
 
 	statsData, err := h.valkey.GetStats(ctx, sources)
@@ -338,6 +339,10 @@ func respondLimitExceeded(w http.ResponseWriter, source domain.CameraSource, cur
 			"en": "Camera limit reached for Other",
 			"ar": "تم الوصول إلى حد الكاميرات للأخرى",
 		},
+		domain.SourceGuest: {
+			"en": "Camera limit reached for guest links",
+			"ar": "تم الوصول إلى حد الكاميرات لروابط الضيوف",
+		},
 	}
 
 	msg := messages[source]
@@ -10,12 +10,13 @@ const (
 	SourceMetro       CameraSource = "METRO"
 	SourceBus         CameraSource = "BUS"
 	SourceOther       CameraSource = "OTHER"
+	SourceGuest       CameraSource = "GUEST" // Guest share links, whatever the camera's agency
 )
 
 // IsValid checks if camera source is valid
 func (s CameraSource) IsValid() bool {
 	switch s {
-	case SourceDubaiPolice, SourceMetro, SourceBus, SourceOther:
+	case SourceDubaiPolice, SourceMetro, SourceBus, SourceOther, SourceGuest:
 		return true
 	}
 	return false
@@ -106,6 +107,7 @@ type LimitConfig struct {
 	Metro       int `json:"metro"`
 	Bus         int `json:"bus"`
 	Other       int `json:"other"`
+	Guest       int `json:"guest"`
 	Total       int `json:"total"`
 }
 
@@ -120,6 +122,8 @@ func (c *LimitConfig) GetLimit(source CameraSource) int {
 		return c.Bus
 	case SourceOther:
 		return c.Other
+	case SourceGuest:
+		return c.Guest
 	default:
 		return 0
 	}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -15,12 +16,20 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"github.com/rta/cctv/vms-service/internal/client"
 	httpdelivery "github.com/rta/cctv/vms-service/internal/delivery/http"
+	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/internal/repository"
 	"github.com/rta/cctv/vms-service/internal/repository/cache"
 	postgresrepo "github.com/rta/cctv/vms-service/internal/repository/postgres"
+	"github.com/rta/cctv/vms-service/internal/secrets"
+	"github.com/rta/cctv/vms-service/internal/usecase"
+	"github.com/rta/cctv/vms-service/pkg/cameraalarms"
+	"github.com/rta/cctv/vms-service/pkg/cameraevents"
 )
 
 func main() {
@@ -58,23 +67,153 @@ func main() {
 
 	logger.Info().Msg("Connected to PostgreSQL database")
 
-	// Initialize PostgreSQL repository
-	cameraRepo := postgresrepo.NewPostgresRepository(db, logger)
+	// Camera credentials are envelope-encrypted by go-api; PTZ decrypts them with the same key
+	credentialsKey, err := secrets.LoadKey(getEnv("CREDENTIALS_KEY", ""), getEnv("CREDENTIALS_KEY_FILE", ""))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load credentials key (set CREDENTIALS_KEY or CREDENTIALS_KEY_FILE)")
+	}
+	envelope, err := secrets.NewEnvelope(getEnv("CREDENTIALS_KEY_ID", "k1"), credentialsKey)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to initialize credentials encryption")
+	}
+
+	// Background jobs stop on shutdown
+	backgroundCtx, stopBackground := context.WithCancel(ctx)
+	defer stopBackground()
+
+	// Valkey carries the camera events stream and, with CACHE_BACKEND=valkey, the cache
+	var valkeyClient *redis.Client
+	if valkeyAddr := getEnv("VALKEY_ADDR", ""); valkeyAddr != "" {
+		valkeyClient = redis.NewClient(&redis.Options{
+			Addr:     valkeyAddr,
+			Password: getEnv("VALKEY_PASSWORD", ""),
+		})
+		defer valkeyClient.Close()
+
+		if err := valkeyClient.Ping(ctx).Err(); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to Valkey")
+		}
+	}
 
 	// Initialize cache
-	cacheTTL := 5 * time.Minute
-	cleanupInterval := 10 * time.Minute
-	cacheRepo := cache.NewMemoryCache(cacheTTL, cleanupInterval)
+	cacheTTL := parseDurationEnv(logger, "CACHE_TTL", "5m")
+	if cacheTTL == 0 {
+		logger.Fatal().Msg("Invalid CACHE_TTL, must be above 0")
+	}
+	cacheRepo, err := initCache(valkeyClient, cacheTTL, logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to initialize cache")
+	}
+
+	// Initialize PostgreSQL repository
+	cameraRepo := postgresrepo.NewPostgresRepository(db, envelope, cacheRepo, logger)
 
-	logger.Info().
-		Dur("cache_ttl", cacheTTL).
-		Msg("Initialized in-memory cache")
+	// Camera change events are recorded in the outbox with every change and relayed to Valkey
+	if valkeyClient != nil {
+		stream := getEnv("CAMERA_EVENTS_STREAM", cameraevents.DefaultStream)
+		outboxRepo := postgresrepo.NewOutboxRepository(db, logger)
+		relay := usecase.NewCameraEventRelay(outboxRepo, valkeyClient, stream, logger)
+		go relay.Run(backgroundCtx, time.Second)
+
+		logger.Info().Str("stream", stream).Msg("Camera event relay started")
+	} else {
+		logger.Warn().Msg("VALKEY_ADDR not set, camera events stay in the outbox until a relay runs")
+	}
+
+	// Camera health probe; HEALTH_PROBE_INTERVAL=0 turns probing off but keeps the health API
+	probeInterval := parseDurationEnv(logger, "HEALTH_PROBE_INTERVAL", "1m")
+	probeTimeout := parseDurationEnv(logger, "HEALTH_PROBE_TIMEOUT", "5s")
+	probeWorkers, err := strconv.Atoi(getEnv("HEALTH_PROBE_WORKERS", "20"))
+	if err != nil || probeWorkers < 1 {
+		logger.Fatal().Str("value", getEnv("HEALTH_PROBE_WORKERS", "")).Msg("Invalid HEALTH_PROBE_WORKERS")
+	}
+	failureThreshold, err := strconv.Atoi(getEnv("HEALTH_FAILURE_THRESHOLD", "2"))
+	if err != nil || failureThreshold < 1 {
+		logger.Fatal().Str("value", getEnv("HEALTH_FAILURE_THRESHOLD", "")).Msg("Invalid HEALTH_FAILURE_THRESHOLD")
+	}
+
+	healthProber := usecase.NewHealthProber(cameraRepo, client.NewCameraChecker(probeTimeout), probeWorkers, failureThreshold, logger)
+	if probeInterval > 0 {
+		go healthProber.Run(backgroundCtx, probeInterval)
+
+		logger.Info().
+			Dur("interval", probeInterval).
+			Int("workers", probeWorkers).
+			Int("failure_threshold", failureThreshold).
+			Msg("Camera health probe started")
+	} else {
+		logger.Warn().Msg("HEALTH_PROBE_INTERVAL is 0, camera health probe disabled")
+	}
+
+	// PTZ patrols; progress and operator pauses are kept in memory, so PTZ_PATROL_SCHEDULER
+	// must be true on exactly one instance
+	patrolScheduler := usecase.NewPTZPatrolScheduler(cameraRepo, cameraRepo, logger)
+	runPatrols, err := strconv.ParseBool(getEnv("PTZ_PATROL_SCHEDULER", "true"))
+	if err != nil {
+		logger.Fatal().Str("value", getEnv("PTZ_PATROL_SCHEDULER", "")).Msg("Invalid PTZ_PATROL_SCHEDULER")
+	}
+
+	// Camera alarms from ONVIF events; ALARM_SUBSCRIBE_INTERVAL=0 turns subscriptions off but keeps the alarms API
+	alarmInterval := parseDurationEnv(logger, "ALARM_SUBSCRIBE_INTERVAL", "1m")
+	alarmTTL := parseDurationEnv(logger, "ALARM_SUBSCRIPTION_TTL", "10m")
+	alarmStream := getEnv("CAMERA_ALARMS_STREAM", cameraalarms.DefaultStream)
+	alarmSubscriber := usecase.NewAlarmSubscriber(cameraRepo, client.NewONVIFEventClient(parseDurationEnv(logger, "ALARM_TIMEOUT", "5s")), valkeyClient, alarmStream, alarmTTL, logger)
+	if alarmInterval > 0 {
+		go alarmSubscriber.Run(backgroundCtx, alarmInterval)
+
+		event := logger.Info().Dur("interval", alarmInterval).Dur("subscription_ttl", alarmTTL)
+		if valkeyClient != nil {
+			event = event.Str("stream", alarmStream)
+		}
+		event.Msg("Camera alarm subscriber started")
+	} else {
+		logger.Warn().Msg("ALARM_SUBSCRIBE_INTERVAL is 0, camera alarm subscriptions disabled")
+	}
 
 	// Initialize HTTP handler
-	handler := httpdelivery.NewHandler(cameraRepo, cacheRepo, logger)
+	handler := httpdelivery.NewHandler(cameraRepo, cameraRepo, cameraRepo, cacheRepo, healthProber, patrolScheduler, alarmSubscriber, logger)
+
+	// Milestone camera sync runs only when a Milestone server is configured
+	var milestoneHandler *httpdelivery.MilestoneHandler
+	if milestoneURL := getEnv("MILESTONE_BASE_URL", ""); milestoneURL != "" {
+		milestoneClient := client.NewMilestoneClient(client.MilestoneConfig{
+			BaseURL:  milestoneURL,
+			Username: getEnv("MILESTONE_USER", ""),
+			Password: getEnv("MILESTONE_PASS", ""),
+			AuthType: getEnv("MILESTONE_AUTH_TYPE", ""),
+		}, logger)
+
+		// Cameras only the recording server reaches are controlled through it
+		cameraRepo.RegisterPTZDriver(client.NewMilestonePTZDriver(milestoneClient))
+
+		syncSource := domain.CameraSource(getEnv("MILESTONE_SYNC_SOURCE", string(domain.SourceOther)))
+		milestoneSync := usecase.NewMilestoneSync(milestoneClient, cameraRepo, syncSource, logger)
+		milestoneHandler = httpdelivery.NewMilestoneHandler(milestoneClient, milestoneSync, cameraRepo, logger)
+
+		syncInterval, err := time.ParseDuration(getEnv("MILESTONE_SYNC_INTERVAL", "10m"))
+		if err != nil || syncInterval <= 0 {
+			logger.Fatal().Str("value", getEnv("MILESTONE_SYNC_INTERVAL", "")).Msg("Invalid MILESTONE_SYNC_INTERVAL")
+		}
+		go milestoneSync.Run(backgroundCtx, syncInterval)
+
+		logger.Info().
+			Str("milestone_url", milestoneURL).
+			Dur("sync_interval", syncInterval).
+			Msg("Milestone camera sync enabled")
+	} else {
+		logger.Warn().Msg("MILESTONE_BASE_URL not set, Milestone camera sync disabled")
+	}
+
+	// Started once every PTZ driver is registered
+	if runPatrols {
+		go patrolScheduler.Run(backgroundCtx, time.Second)
+		logger.Info().Msg("PTZ patrol scheduler started")
+	} else {
+		logger.Warn().Msg("PTZ_PATROL_SCHEDULER is false, PTZ patrols run on another instance")
+	}
 
 	// Create router
-	router := httpdelivery.NewRouter(handler, nil)
+	router := httpdelivery.NewRouter(handler, milestoneHandler)
 
 	// Start HTTP server
 	port := getEnv("PORT", "8081")
@@ -100,6 +239,7 @@ func main() {
 	<-sigChan
 
 	logger.Info().Msg("Shutting down VMS Service")
+	stopBackground()
 
 	// Graceful shutdown
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -200,3 +340,42 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// initCache creates the cache selected by CACHE_BACKEND, valkey or memory
+// go-api drops the cameras it changes from the Valkey cache only, so valkey is the default
+// and memory is meant for running vms-service without go-api.
+func initCache(valkeyClient *redis.Client, ttl time.Duration, logger zerolog.Logger) (repository.CacheRepository, error) {
+	switch backend := getEnv("CACHE_BACKEND", "valkey"); backend {
+	case "memory":
+		cleanupInterval := parseDurationEnv(logger, "CACHE_CLEANUP", "10m")
+
+		logger.Warn().
+			Dur("cache_ttl", ttl).
+			Msg("Initialized in-memory cache; camera changes made by go-api are served stale until the entries expire")
+		return cache.NewMemoryCache(ttl, cleanupInterval), nil
+
+	case "valkey":
+		if valkeyClient == nil {
+			return nil, fmt.Errorf("CACHE_BACKEND=valkey requires VALKEY_ADDR; set CACHE_BACKEND=memory only when go-api does not write cameras")
+		}
+		namespace := getEnv("CACHE_NAMESPACE", cache.DefaultNamespace)
+
+		logger.Info().
+			Dur("cache_ttl", ttl).
+			Str("namespace", namespace).
+			Msg("Initialized Valkey cache")
+		return cache.NewValkeyCache(valkeyClient, namespace, ttl), nil
+
+	default:
+		return nil, fmt.Errorf("unknown CACHE_BACKEND %q, must be memory or valkey", backend)
+	}
+}
+
+// parseDurationEnv parses a duration environment variable, exiting on an invalid or negative value
+func parseDurationEnv(logger zerolog.Logger, key, fallback string) time.Duration {
+	value, err := time.ParseDuration(getEnv(key, fallback))
+	if err != nil || value < 0 {
+		logger.Fatal().Str("value", getEnv(key, "")).Msgf("Invalid %s", key)
+	}
+	return value
+}
@@ -0,0 +1,345 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRTSPPort is used for RTSP URLs without a port
+const defaultRTSPPort = "554"
+
+// CameraChecker runs the network checks of the camera health probe
+// Every check is bounded by the checker timeout as well as by ctx.
+type CameraChecker struct {
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+// NewCameraChecker creates a new camera checker
+func NewCameraChecker(timeout time.Duration) *CameraChecker {
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &CameraChecker{
+		timeout: timeout,
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				MaxIdleConnsPerHost: 1,
+				IdleConnTimeout:     30 * time.Second,
+			},
+		},
+	}
+}
+
+// RTSPAddress returns the host:port an RTSP URL connects to
+func RTSPAddress(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = defaultRTSPPort
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// CheckTCP connects to address and closes the connection
+func (c *CameraChecker) CheckTCP(ctx context.Context, address string) error {
+	dialer := &net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// CheckRTSP runs an OPTIONS and DESCRIBE handshake against u
+// Credentials in u answer Basic or Digest challenges; they are never sent in the request URL.
+func (c *CameraChecker) CheckRTSP(ctx context.Context, u *url.URL) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", RTSPAddress(u))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	session := &rtspSession{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		target: requestURL(u),
+		user:   u.User,
+	}
+
+	if _, err := session.do("OPTIONS", nil); err != nil {
+		return fmt.Errorf("OPTIONS: %w", err)
+	}
+
+	status, err := session.do("DESCRIBE", map[string]string{"Accept": "application/sdp"})
+	if err != nil {
+		return fmt.Errorf("DESCRIBE: %w", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("DESCRIBE: status %d", status)
+	}
+
+	return nil
+}
+
+// requestURL is u without user info, as sent on the request line
+func requestURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	return clean.String()
+}
+
+// rtspSession sends requests over one RTSP connection
+type rtspSession struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	target string
+	user   *url.Userinfo
+	cseq   int
+	auth   func(method string) string // Authorization header, once challenged
+}
+
+// do sends a request, answering a 401 challenge once, and returns the response status
+// OPTIONS may be refused with 401 or 405 by cameras that only authenticate DESCRIBE;
+// that still proves an RTSP server is answering.
+func (s *rtspSession) do(method string, headers map[string]string) (int, error) {
+	status, header, err := s.roundTrip(method, headers)
+	if err != nil {
+		return 0, err
+	}
+
+	if status == http.StatusUnauthorized {
+		if s.user == nil {
+			if method == "OPTIONS" {
+				return status, nil
+			}
+			return 0, fmt.Errorf("authentication required")
+		}
+
+		s.auth, err = challengeAuth(header.Values("Www-Authenticate"), s.user, s.target)
+		if err != nil {
+			return 0, err
+		}
+
+		status, _, err = s.roundTrip(method, headers)
+		if err != nil {
+			return 0, err
+		}
+		if status == http.StatusUnauthorized {
+			return 0, fmt.Errorf("authentication failed")
+		}
+	}
+
+	if status >= 400 && !(method == "OPTIONS" && status == http.StatusMethodNotAllowed) {
+		return 0, fmt.Errorf("status %d", status)
+	}
+
+	return status, nil
+}
+
+// roundTrip writes one request and reads its response, discarding the body
+func (s *rtspSession) roundTrip(method string, headers map[string]string) (int, textproto.MIMEHeader, error) {
+	s.cseq++
+
+	var req bytes.Buffer
+	fmt.Fprintf(&req, "%s %s RTSP/1.0\r\n", method, s.target)
+	fmt.Fprintf(&req, "CSeq: %d\r\n", s.cseq)
+	req.WriteString("User-Agent: rta-cctv-vms-service\r\n")
+	for name, value := range headers {
+		fmt.Fprintf(&req, "%s: %s\r\n", name, value)
+	}
+	if s.auth != nil {
+		fmt.Fprintf(&req, "Authorization: %s\r\n", s.auth(method))
+	}
+	req.WriteString("\r\n")
+
+	if _, err := s.conn.Write(req.Bytes()); err != nil {
+		return 0, nil, err
+	}
+
+	tp := textproto.NewReader(s.reader)
+	line, err := tp.ReadLine()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// RTSP/1.0 200 OK
+	parts := strings.SplitN(line, " ", 3)
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "RTSP/") {
+		return 0, nil, fmt.Errorf("not an RTSP response: %q", line)
+	}
+	status, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid RTSP status line: %q", line)
+	}
+
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if length, _ := strconv.ParseInt(header.Get("Content-Length"), 10, 64); length > 0 {
+		if _, err := io.CopyN(io.Discard, s.reader, length); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return status, header, nil
+}
+
+// challengeAuth builds the Authorization header for a 401 challenge, preferring Digest
+func challengeAuth(challenges []string, user *url.Userinfo, uri string) (func(method string) string, error) {
+	username := user.Username()
+	password, _ := user.Password()
+
+	for _, challenge := range challenges {
+		if !strings.HasPrefix(strings.ToLower(challenge), "digest ") {
+			continue
+		}
+
+		params := parseAuthParams(challenge[len("digest "):])
+		realm, nonce := params["realm"], params["nonce"]
+		qop := ""
+		for _, q := range strings.Split(params["qop"], ",") {
+			if strings.TrimSpace(q) == "auth" {
+				qop = "auth"
+			}
+		}
+
+		nc := 0
+		return func(method string) string {
+			ha1 := md5Hex(username + ":" + realm + ":" + password)
+			ha2 := md5Hex(method + ":" + uri)
+
+			if qop == "" {
+				response := md5Hex(ha1 + ":" + nonce + ":" + ha2)
+				return fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+					username, realm, nonce, uri, response)
+			}
+
+			nc++
+			cnonce := randomHex(8)
+			count := fmt.Sprintf("%08x", nc)
+			response := md5Hex(ha1 + ":" + nonce + ":" + count + ":" + cnonce + ":" + qop + ":" + ha2)
+			return fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s", qop=%s, nc=%s, cnonce="%s"`,
+				username, realm, nonce, uri, response, qop, count, cnonce)
+		}, nil
+	}
+
+	for _, challenge := range challenges {
+		if strings.HasPrefix(strings.ToLower(challenge), "basic") {
+			token := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+			return func(string) string { return "Basic " + token }, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported authentication challenge")
+}
+
+// parseAuthParams parses the comma separated key="value" pairs of a challenge
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				value, s = s, ""
+			} else {
+				value, s = s[:end], s[end:]
+			}
+		}
+		params[key] = strings.TrimSpace(value)
+	}
+	return params
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// getSystemDateAndTime is the ONVIF device service request answered without authentication
+const getSystemDateAndTime = `<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope">
+	<s:Body xmlns:tds="http://www.onvif.org/ver10/device/wsdl">
+		<tds:GetSystemDateAndTime/>
+	</s:Body>
+</s:Envelope>`
+
+// CheckONVIF calls GetSystemDateAndTime on the ONVIF device service at endpoint
+// The call needs no credentials, so it checks the ONVIF stack without locking accounts.
+func (c *CameraChecker) CheckONVIF(ctx context.Context, endpoint string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(getSystemDateAndTime))
+	if err != nil {
+		return fmt.Errorf("invalid ONVIF endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", `application/soap+xml; charset=utf-8; action="http://www.onvif.org/ver10/device/wsdl/GetSystemDateAndTime"`)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if bytes.Contains(body, []byte("Fault>")) {
+		return fmt.Errorf("SOAP fault")
+	}
+	if !bytes.Contains(body, []byte("GetSystemDateAndTimeResponse")) {
+		return fmt.Errorf("unexpected response")
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// MilestonePTZRequest is a PTZ command sent through the Milestone recording server
+type MilestonePTZRequest struct {
+	Action string  `json:"action"` // move, stop, preset or home
+	Pan    float64 `json:"pan,omitempty"`
+	Tilt   float64 `json:"tilt,omitempty"`
+	Zoom   float64 `json:"zoom,omitempty"`
+	Speed  float64 `json:"speed,omitempty"`
+	Preset int     `json:"preset,omitempty"`
+}
+
+// ControlPTZ sends a PTZ command to a Milestone camera
+func (m *MilestoneClient) ControlPTZ(ctx context.Context, cameraID string, ptzReq MilestonePTZRequest) error {
+	if err := m.ensureAuthenticated(ctx); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/rest/v1/cameras/%s/ptz", m.baseURL, cameraID)
+
+	body, err := json.Marshal(ptzReq)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	m.mu.RLock()
+	token := m.token
+	m.mu.RUnlock()
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
+// MilestonePTZDriver controls cameras through the Milestone XProtect server
+// It suits cameras only the recording server can reach.
+type MilestonePTZDriver struct {
+	client *MilestoneClient
+}
+
+// NewMilestonePTZDriver creates a Milestone PTZ driver
+func NewMilestonePTZDriver(client *MilestoneClient) *MilestonePTZDriver {
+	return &MilestonePTZDriver{client: client}
+}
+
+// Name identifies the driver
+func (d *MilestonePTZDriver) Name() string {
+	return domain.PTZDriverMilestone
+}
+
+// Detect checks that Milestone reports PTZ for the camera's device
+func (d *MilestonePTZDriver) Detect(ctx context.Context, target *PTZTarget) error {
+	if target.MilestoneDeviceID == "" {
+		return fmt.Errorf("camera is not linked to a Milestone device")
+	}
+
+	camera, err := d.client.GetCamera(ctx, target.MilestoneDeviceID)
+	if err != nil {
+		return err
+	}
+	if caps := camera.PTZCapabilities; caps == nil || !(caps.Pan || caps.Tilt || caps.Zoom) {
+		return fmt.Errorf("milestone reports no PTZ for the device")
+	}
+	return nil
+}
+
+// Execute sends a command through Milestone
+func (d *MilestonePTZDriver) Execute(ctx context.Context, target *PTZTarget, cmd *domain.PTZCommand) error {
+	var ptzReq MilestonePTZRequest
+	switch cmd.Action {
+	case domain.PTZActionMove:
+		ptzReq = MilestonePTZRequest{Action: "move", Pan: cmd.Pan, Tilt: cmd.Tilt, Zoom: cmd.Zoom, Speed: cmd.Speed}
+	case domain.PTZActionStop:
+		ptzReq = MilestonePTZRequest{Action: "stop"}
+	case domain.PTZActionGoToPreset:
+		ptzReq = MilestonePTZRequest{Action: "preset", Preset: cmd.Preset, Speed: cmd.Speed}
+	case domain.PTZActionGoHome:
+		ptzReq = MilestonePTZRequest{Action: "home"}
+	default:
+		return fmt.Errorf("%w: %s through Milestone", domain.ErrPTZNotSupported, cmd.Action)
+	}
+
+	return d.client.ControlPTZ(ctx, target.MilestoneDeviceID, ptzReq)
+}
@@ -0,0 +1,346 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// ONVIFEventClient reads camera events through ONVIF PullPoint subscriptions
+type ONVIFEventClient struct {
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+// NewONVIFEventClient creates a new ONVIF event client
+// timeout bounds each SOAP request, on top of the time PullMessages waits for events.
+func NewONVIFEventClient(timeout time.Duration) *ONVIFEventClient {
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &ONVIFEventClient{
+		httpClient: &http.Client{},
+		timeout:    timeout,
+	}
+}
+
+// PullPoint is a PullPoint subscription held on a camera
+type PullPoint struct {
+	Address         string
+	TerminationTime time.Time // By the local clock; cameras often keep their own time
+
+	// referenceParameters go back in the header of every request, as some cameras require
+	referenceParameters string
+}
+
+// EventService finds the ONVIF event service of the camera at u
+// endpoint is its device service, or empty to try the usual ports.
+func (c *ONVIFEventClient) EventService(ctx context.Context, u *url.URL, endpoint string) (string, error) {
+	var lastErr error
+	for _, candidate := range deviceServiceCandidates(u, endpoint) {
+		services := &eventCapabilitiesResponse{}
+		err := c.call(ctx, u, candidate, "", nil, getEventCapabilitiesRequest, 0, services)
+		if err == nil {
+			eventURL := serviceURL(candidate, services.Capabilities.Events.XAddr)
+			if eventURL == "" {
+				return "", fmt.Errorf("camera has no ONVIF event service")
+			}
+			return eventURL, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		lastErr = err
+	}
+
+	return "", fmt.Errorf("no ONVIF device service answered: %w", lastErr)
+}
+
+// CreatePullPoint subscribes to every event of the camera, for ttl unless renewed
+func (c *ONVIFEventClient) CreatePullPoint(ctx context.Context, u *url.URL, eventURL string, ttl time.Duration) (*PullPoint, error) {
+	response := &createPullPointResponse{}
+	request := fmt.Sprintf(createPullPointRequest, xsDuration(ttl))
+	if err := c.call(ctx, u, eventURL, createPullPointAction, nil, request, 0, response); err != nil {
+		return nil, fmt.Errorf("CreatePullPointSubscription: %w", err)
+	}
+
+	address := serviceURL(eventURL, response.SubscriptionReference.Address)
+	if address == "" {
+		return nil, fmt.Errorf("CreatePullPointSubscription returned no subscription address")
+	}
+
+	var parameters strings.Builder
+	for _, parameter := range response.SubscriptionReference.ReferenceParameters.Elements {
+		// Re-encoded with their namespace, which the response may have declared on the envelope
+		data, err := xml.Marshal(parameter)
+		if err == nil {
+			parameters.Write(data)
+		}
+	}
+
+	return &PullPoint{
+		Address:             address,
+		TerminationTime:     terminationTime(response.CurrentTime, response.TerminationTime, ttl),
+		referenceParameters: parameters.String(),
+	}, nil
+}
+
+// PullMessages waits up to wait for events and returns those that normalize to alarms
+// Events of other topics are dropped.
+func (c *ONVIFEventClient) PullMessages(ctx context.Context, u *url.URL, pullPoint *PullPoint, wait time.Duration, limit int) ([]domain.CameraAlarm, error) {
+	response := &pullMessagesResponse{}
+	request := fmt.Sprintf(pullMessagesRequest, xsDuration(wait), limit)
+	if err := c.call(ctx, u, pullPoint.Address, pullMessagesAction, pullPoint, request, wait, response); err != nil {
+		return nil, fmt.Errorf("PullMessages: %w", err)
+	}
+
+	now := time.Now().UTC()
+	alarms := make([]domain.CameraAlarm, 0, len(response.Messages))
+	for _, message := range response.Messages {
+		if alarm, ok := normalizeEvent(message, now); ok {
+			alarms = append(alarms, alarm)
+		}
+	}
+	return alarms, nil
+}
+
+// Renew extends the subscription by ttl
+func (c *ONVIFEventClient) Renew(ctx context.Context, u *url.URL, pullPoint *PullPoint, ttl time.Duration) error {
+	response := &renewResponse{}
+	request := fmt.Sprintf(renewRequest, xsDuration(ttl))
+	if err := c.call(ctx, u, pullPoint.Address, renewAction, pullPoint, request, 0, response); err != nil {
+		return fmt.Errorf("Renew: %w", err)
+	}
+
+	pullPoint.TerminationTime = terminationTime(response.CurrentTime, response.TerminationTime, ttl)
+	return nil
+}
+
+// Unsubscribe ends the subscription; cameras also drop it at its termination time
+func (c *ONVIFEventClient) Unsubscribe(ctx context.Context, u *url.URL, pullPoint *PullPoint) error {
+	if err := c.call(ctx, u, pullPoint.Address, unsubscribeAction, pullPoint, unsubscribeRequest, 0, nil); err != nil {
+		return fmt.Errorf("Unsubscribe: %w", err)
+	}
+	return nil
+}
+
+// call sends a SOAP request, addressed with WS-Addressing when action is set
+// wait extends the timeout for requests the camera holds open.
+func (c *ONVIFEventClient) call(ctx context.Context, u *url.URL, endpoint, action string, pullPoint *PullPoint, body string, wait time.Duration, out interface{}) error {
+	var header strings.Builder
+	if username, password := credentials(u); username != "" {
+		header.WriteString(WSSecurityHeader(username, password))
+	}
+	if action != "" {
+		fmt.Fprintf(&header, `<wsa:Action s:mustUnderstand="1">%s</wsa:Action><wsa:To s:mustUnderstand="1">%s</wsa:To>`,
+			action, xmlEscape(endpoint))
+	}
+	if pullPoint != nil {
+		header.WriteString(pullPoint.referenceParameters)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout+wait)
+	defer cancel()
+
+	return postSOAP(ctx, c.httpClient, endpoint, fmt.Sprintf(eventEnvelopeTemplate, header.String(), body), out)
+}
+
+// alarmTopics map ONVIF topics to alarms, first match wins
+// A topic matches when one of its segments contains segment; the state is read from the first
+// of fields the event carries.
+var alarmTopics = []struct {
+	segment   string
+	alarmType domain.AlarmType
+	fields    []string
+}{
+	{"MotionAlarm", domain.AlarmMotion, []string{"State"}},
+	{"MotionDetector", domain.AlarmMotion, []string{"IsMotion", "State"}}, // CellMotionDetector and vendor rules
+	{"Tamper", domain.AlarmTamper, []string{"IsTamper", "State"}},
+	{"GlobalSceneChange", domain.AlarmTamper, []string{"State"}},
+	{"ImageTooBlurry", domain.AlarmTamper, []string{"State"}},
+	{"ImageTooDark", domain.AlarmTamper, []string{"State"}},
+	{"ImageTooBright", domain.AlarmTamper, []string{"State"}},
+	{"DigitalInput", domain.AlarmDigitalInput, []string{"LogicalState", "State"}},
+}
+
+// normalizeEvent converts a notification into an alarm; ok is false for other topics and
+// for events without a readable state
+func normalizeEvent(message notificationMessage, receivedAt time.Time) (domain.CameraAlarm, bool) {
+	topic := stripTopicPrefixes(message.Topic)
+
+	for _, mapping := range alarmTopics {
+		if !topicHasSegment(topic, mapping.segment) {
+			continue
+		}
+
+		state, ok := alarmState(message.Message.Message.Data, mapping.fields)
+		if !ok {
+			return domain.CameraAlarm{}, false
+		}
+
+		occurredAt, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(message.Message.Message.UtcTime))
+		if err != nil {
+			occurredAt = receivedAt
+		}
+
+		alarm := domain.CameraAlarm{
+			Type:       mapping.alarmType,
+			State:      state,
+			Topic:      topic,
+			OccurredAt: occurredAt.UTC(),
+			ReceivedAt: receivedAt,
+		}
+		if sources := message.Message.Message.Source; len(sources) > 0 {
+			alarm.Source = sources[0].Value
+		}
+		return alarm, true
+	}
+
+	return domain.CameraAlarm{}, false
+}
+
+// stripTopicPrefixes turns tns1:RuleEngine/CellMotionDetector/Motion into RuleEngine/CellMotionDetector/Motion
+func stripTopicPrefixes(topic string) string {
+	segments := strings.Split(strings.TrimSpace(topic), "/")
+	for i, segment := range segments {
+		if colon := strings.LastIndex(segment, ":"); colon >= 0 {
+			segments[i] = segment[colon+1:]
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func topicHasSegment(topic, segment string) bool {
+	for _, s := range strings.Split(topic, "/") {
+		if strings.Contains(s, segment) {
+			return true
+		}
+	}
+	return false
+}
+
+// alarmState reads the first of fields present in data
+func alarmState(data []simpleItem, fields []string) (domain.AlarmState, bool) {
+	for _, field := range fields {
+		for _, item := range data {
+			if item.Name != field {
+				continue
+			}
+			switch strings.ToLower(strings.TrimSpace(item.Value)) {
+			case "true", "1", "active", "on":
+				return domain.AlarmActive, true
+			case "false", "0", "inactive", "off":
+				return domain.AlarmInactive, true
+			}
+		}
+	}
+	return "", false
+}
+
+// terminationTime converts the camera's termination time to the local clock
+// Falls back to ttl from now when the camera does not report its times.
+func terminationTime(current, termination string, ttl time.Duration) time.Time {
+	now := time.Now()
+	currentTime, err1 := time.Parse(time.RFC3339Nano, strings.TrimSpace(current))
+	terminationAt, err2 := time.Parse(time.RFC3339Nano, strings.TrimSpace(termination))
+	if err1 != nil || err2 != nil || !terminationAt.After(currentTime) {
+		return now.Add(ttl)
+	}
+	return now.Add(terminationAt.Sub(currentTime))
+}
+
+// xsDuration formats d as an xs:duration in seconds, e.g. PT600S
+func xsDuration(d time.Duration) string {
+	return fmt.Sprintf("PT%dS", int(d.Seconds()))
+}
+
+// ============================================================================
+// SOAP messages
+// ============================================================================
+
+const eventEnvelopeTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"
+            xmlns:wsa="http://www.w3.org/2005/08/addressing"
+            xmlns:tds="http://www.onvif.org/ver10/device/wsdl"
+            xmlns:tev="http://www.onvif.org/ver10/events/wsdl"
+            xmlns:wsnt="http://docs.oasis-open.org/wsn/b-2">
+  <s:Header>
+    %s
+  </s:Header>
+  <s:Body>
+    %s
+  </s:Body>
+</s:Envelope>`
+
+const (
+	createPullPointAction = "http://www.onvif.org/ver10/events/wsdl/EventPortType/CreatePullPointSubscriptionRequest"
+	pullMessagesAction    = "http://www.onvif.org/ver10/events/wsdl/PullPointSubscription/PullMessagesRequest"
+	renewAction           = "http://docs.oasis-open.org/wsn/bw-2/SubscriptionManager/RenewRequest"
+	unsubscribeAction     = "http://docs.oasis-open.org/wsn/bw-2/SubscriptionManager/UnsubscribeRequest"
+)
+
+const (
+	getEventCapabilitiesRequest = `<tds:GetCapabilities><tds:Category>Events</tds:Category></tds:GetCapabilities>`
+	createPullPointRequest      = `<tev:CreatePullPointSubscription><tev:InitialTerminationTime>%s</tev:InitialTerminationTime></tev:CreatePullPointSubscription>`
+	pullMessagesRequest         = `<tev:PullMessages><tev:Timeout>%s</tev:Timeout><tev:MessageLimit>%d</tev:MessageLimit></tev:PullMessages>`
+	renewRequest                = `<wsnt:Renew><wsnt:TerminationTime>%s</wsnt:TerminationTime></wsnt:Renew>`
+	unsubscribeRequest          = `<wsnt:Unsubscribe/>`
+)
+
+type eventCapabilitiesResponse struct {
+	Capabilities struct {
+		Events struct {
+			XAddr string `xml:"XAddr"`
+		} `xml:"Events"`
+	} `xml:"Capabilities"`
+}
+
+// xmlElement is an element kept as it was received
+type xmlElement struct {
+	XMLName xml.Name
+	Content string `xml:",innerxml"`
+}
+
+type createPullPointResponse struct {
+	SubscriptionReference struct {
+		Address             string `xml:"Address"`
+		ReferenceParameters struct {
+			Elements []xmlElement `xml:",any"`
+		} `xml:"ReferenceParameters"`
+	} `xml:"SubscriptionReference"`
+	CurrentTime     string `xml:"CurrentTime"`
+	TerminationTime string `xml:"TerminationTime"`
+}
+
+type renewResponse struct {
+	CurrentTime     string `xml:"CurrentTime"`
+	TerminationTime string `xml:"TerminationTime"`
+}
+
+type pullMessagesResponse struct {
+	Messages []notificationMessage `xml:"NotificationMessage"`
+}
+
+// notificationMessage is a wsnt:NotificationMessage carrying a tt:Message
+type notificationMessage struct {
+	Topic   string `xml:"Topic"`
+	Message struct {
+		Message struct {
+			UtcTime string       `xml:"UtcTime,attr"`
+			Source  []simpleItem `xml:"Source>SimpleItem"`
+			Data    []simpleItem `xml:"Data>SimpleItem"`
+		} `xml:"Message"`
+	} `xml:"Message"`
+}
+
+type simpleItem struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:"Value,attr"`
+}
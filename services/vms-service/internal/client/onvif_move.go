@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// ONVIF generic spaces absolute and relative moves are sent in
+const (
+	positionGenericPanTiltSpace    = "http://www.onvif.org/ver10/tptz/PanTiltSpaces/PositionGenericSpace"
+	positionGenericZoomSpace       = "http://www.onvif.org/ver10/tptz/ZoomSpaces/PositionGenericSpace"
+	translationGenericPanTiltSpace = "http://www.onvif.org/ver10/tptz/PanTiltSpaces/TranslationGenericSpace"
+	translationGenericZoomSpace    = "http://www.onvif.org/ver10/tptz/ZoomSpaces/TranslationGenericSpace"
+)
+
+// AbsoluteMove moves the camera to a position in the generic position spaces
+// Zoom is left out for cameras without an absolute zoom space. speed 0 uses the camera's default.
+func (d *PTZDiscoverer) AbsoluteMove(ctx context.Context, caps *domain.PTZCapabilities, u *url.URL, position domain.PTZPosition, speed float64) error {
+	if caps.Spaces == nil || len(caps.Spaces.AbsolutePanTilt) == 0 {
+		return fmt.Errorf("%w: camera has no absolute pan/tilt space", domain.ErrPTZNotSupported)
+	}
+	username, password := credentials(u)
+
+	vector := panTiltVector(caps.Spaces.AbsolutePanTilt, positionGenericPanTiltSpace, position.Pan, position.Tilt)
+	if position.Zoom != nil && len(caps.Spaces.AbsoluteZoom) > 0 {
+		vector += zoomVector(caps.Spaces.AbsoluteZoom, positionGenericZoomSpace, *position.Zoom)
+	}
+
+	request := fmt.Sprintf(absoluteMoveRequest, xmlEscape(caps.ProfileToken), vector, speedVector(speed))
+	if err := d.call(ctx, caps.ServiceURL, username, password, request, nil); err != nil {
+		return fmt.Errorf("AbsoluteMove: %w", err)
+	}
+	return nil
+}
+
+// RelativeMove moves the camera by a translation in the generic translation spaces
+// A zero zoom translation, or a camera without a relative zoom space, leaves the zoom alone.
+func (d *PTZDiscoverer) RelativeMove(ctx context.Context, caps *domain.PTZCapabilities, u *url.URL, translation domain.PTZPosition, speed float64) error {
+	if caps.Spaces == nil || len(caps.Spaces.RelativePanTilt) == 0 {
+		return fmt.Errorf("%w: camera has no relative pan/tilt space", domain.ErrPTZNotSupported)
+	}
+	username, password := credentials(u)
+
+	vector := panTiltVector(caps.Spaces.RelativePanTilt, translationGenericPanTiltSpace, translation.Pan, translation.Tilt)
+	if translation.Zoom != nil && *translation.Zoom != 0 && len(caps.Spaces.RelativeZoom) > 0 {
+		vector += zoomVector(caps.Spaces.RelativeZoom, translationGenericZoomSpace, *translation.Zoom)
+	}
+
+	request := fmt.Sprintf(relativeMoveRequest, xmlEscape(caps.ProfileToken), vector, speedVector(speed))
+	if err := d.call(ctx, caps.ServiceURL, username, password, request, nil); err != nil {
+		return fmt.Errorf("RelativeMove: %w", err)
+	}
+	return nil
+}
+
+// GetStatus reads the position and movement state of the camera
+func (d *PTZDiscoverer) GetStatus(ctx context.Context, caps *domain.PTZCapabilities, u *url.URL) (*domain.PTZStatus, error) {
+	username, password := credentials(u)
+
+	response := &statusResponse{}
+	request := fmt.Sprintf(getStatusRequest, xmlEscape(caps.ProfileToken))
+	if err := d.call(ctx, caps.ServiceURL, username, password, request, response); err != nil {
+		return nil, fmt.Errorf("GetStatus: %w", err)
+	}
+
+	status := &domain.PTZStatus{
+		PanTiltStatus: response.Status.MoveStatus.PanTilt,
+		ZoomStatus:    response.Status.MoveStatus.Zoom,
+		Error:         response.Status.Error,
+		ReadAt:        time.Now().UTC(),
+	}
+	if position := response.Status.Position; position != nil && position.PanTilt != nil {
+		status.Position = &domain.PTZPosition{Pan: position.PanTilt.X, Tilt: position.PanTilt.Y}
+		if position.Zoom != nil {
+			zoom := position.Zoom.X
+			status.Position.Zoom = &zoom
+		}
+	}
+	if utc, err := time.Parse(time.RFC3339, response.Status.UTCTime); err == nil {
+		status.UTCTime = &utc
+	}
+
+	return status, nil
+}
+
+// panTiltVector is the PanTilt element of a move, in the generic space when the camera lists it
+// Cameras that only list their own space get no space attribute and use their default.
+func panTiltVector(spaces []domain.PTZSpace, generic string, x, y float64) string {
+	if hasSpace(spaces, generic) {
+		return fmt.Sprintf(`<tt:PanTilt x="%f" y="%f" space="%s"/>`, x, y, generic)
+	}
+	return fmt.Sprintf(`<tt:PanTilt x="%f" y="%f"/>`, x, y)
+}
+
+// zoomVector is the Zoom element of a move, in the generic space when the camera lists it
+func zoomVector(spaces []domain.PTZSpace, generic string, x float64) string {
+	if hasSpace(spaces, generic) {
+		return fmt.Sprintf(`<tt:Zoom x="%f" space="%s"/>`, x, generic)
+	}
+	return fmt.Sprintf(`<tt:Zoom x="%f"/>`, x)
+}
+
+// speedVector is the Speed element of a move, empty for the camera's default speed
+func speedVector(speed float64) string {
+	if speed <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(`<tptz:Speed><tt:PanTilt x="%f" y="%f"/><tt:Zoom x="%f"/></tptz:Speed>`, speed, speed, speed)
+}
+
+func hasSpace(spaces []domain.PTZSpace, uri string) bool {
+	for _, space := range spaces {
+		if space.URI == uri {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	absoluteMoveRequest = `<tptz:AbsoluteMove><tptz:ProfileToken>%s</tptz:ProfileToken><tptz:Position>%s</tptz:Position>%s</tptz:AbsoluteMove>`
+	relativeMoveRequest = `<tptz:RelativeMove><tptz:ProfileToken>%s</tptz:ProfileToken><tptz:Translation>%s</tptz:Translation>%s</tptz:RelativeMove>`
+	getStatusRequest    = `<tptz:GetStatus><tptz:ProfileToken>%s</tptz:ProfileToken></tptz:GetStatus>`
+)
+
+type statusResponse struct {
+	Status struct {
+		Position *struct {
+			PanTilt *struct {
+				X float64 `xml:"x,attr"`
+				Y float64 `xml:"y,attr"`
+			} `xml:"PanTilt"`
+			Zoom *struct {
+				X float64 `xml:"x,attr"`
+			} `xml:"Zoom"`
+		} `xml:"Position"`
+		MoveStatus struct {
+			PanTilt string `xml:"PanTilt"`
+			Zoom    string `xml:"Zoom"`
+		} `xml:"MoveStatus"`
+		Error   string `xml:"Error"`
+		UTCTime string `xml:"UtcTime"`
+	} `xml:"PTZStatus"`
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// ONVIFPreset is a preset stored on a camera
+type ONVIFPreset struct {
+	Token string
+	Name  string
+}
+
+// GetPresets lists the presets stored on the camera for the profile in caps
+// u carries the camera credentials.
+func (d *PTZDiscoverer) GetPresets(ctx context.Context, caps *domain.PTZCapabilities, u *url.URL) ([]ONVIFPreset, error) {
+	username, password := credentials(u)
+
+	response := &presetsResponse{}
+	request := fmt.Sprintf(getPresetsRequest, xmlEscape(caps.ProfileToken))
+	if err := d.call(ctx, caps.ServiceURL, username, password, request, response); err != nil {
+		return nil, fmt.Errorf("GetPresets: %w", err)
+	}
+
+	presets := make([]ONVIFPreset, 0, len(response.Presets))
+	for _, preset := range response.Presets {
+		if preset.Token == "" {
+			continue
+		}
+		presets = append(presets, ONVIFPreset{Token: preset.Token, Name: preset.Name})
+	}
+	return presets, nil
+}
+
+// SetPreset saves the camera's current position as a preset and returns its token
+// An empty token creates a new preset; otherwise the position of that preset is overwritten.
+func (d *PTZDiscoverer) SetPreset(ctx context.Context, caps *domain.PTZCapabilities, u *url.URL, token, name string) (string, error) {
+	username, password := credentials(u)
+
+	fields := ""
+	if name != "" {
+		fields += fmt.Sprintf("<tptz:PresetName>%s</tptz:PresetName>", xmlEscape(name))
+	}
+	if token != "" {
+		fields += fmt.Sprintf("<tptz:PresetToken>%s</tptz:PresetToken>", xmlEscape(token))
+	}
+
+	response := &setPresetResponse{}
+	request := fmt.Sprintf(setPresetRequest, xmlEscape(caps.ProfileToken), fields)
+	if err := d.call(ctx, caps.ServiceURL, username, password, request, response); err != nil {
+		return "", fmt.Errorf("SetPreset: %w", err)
+	}
+
+	if response.PresetToken != "" {
+		return response.PresetToken, nil
+	}
+	if token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("SetPreset: camera returned no preset token")
+}
+
+// RemovePreset deletes a preset from the camera
+func (d *PTZDiscoverer) RemovePreset(ctx context.Context, caps *domain.PTZCapabilities, u *url.URL, token string) error {
+	username, password := credentials(u)
+
+	request := fmt.Sprintf(removePresetRequest, xmlEscape(caps.ProfileToken), xmlEscape(token))
+	if err := d.call(ctx, caps.ServiceURL, username, password, request, nil); err != nil {
+		return fmt.Errorf("RemovePreset: %w", err)
+	}
+	return nil
+}
+
+// IsONVIFFault reports whether err is a SOAP fault returned by the camera, as opposed
+// to a transport failure
+func IsONVIFFault(err error) bool {
+	var fault *soapFault
+	return errors.As(err, &fault)
+}
+
+const (
+	getPresetsRequest   = `<tptz:GetPresets><tptz:ProfileToken>%s</tptz:ProfileToken></tptz:GetPresets>`
+	setPresetRequest    = `<tptz:SetPreset><tptz:ProfileToken>%s</tptz:ProfileToken>%s</tptz:SetPreset>`
+	removePresetRequest = `<tptz:RemovePreset><tptz:ProfileToken>%s</tptz:ProfileToken><tptz:PresetToken>%s</tptz:PresetToken></tptz:RemovePreset>`
+)
+
+type presetsResponse struct {
+	Presets []struct {
+		Token string `xml:"token,attr"`
+		Name  string `xml:"Name"`
+	} `xml:"Preset"`
+}
+
+type setPresetResponse struct {
+	PresetToken string `xml:"PresetToken"`
+}
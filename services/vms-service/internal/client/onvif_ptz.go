@@ -0,0 +1,483 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// onvifPorts are tried in order when a camera has no ONVIF endpoint recorded
+// TP-Link Tapo: 2020, TrueView: 8888, most others: 80
+var onvifPorts = []string{"2020", "8888", "80", "8080", "8000"}
+
+// PTZDiscoverer queries the ONVIF PTZ capabilities of cameras
+type PTZDiscoverer struct {
+	httpClient *http.Client
+}
+
+// NewPTZDiscoverer creates a new ONVIF PTZ discoverer
+// timeout bounds each SOAP request; a camera on an unknown port costs one timeout per port tried.
+func NewPTZDiscoverer(timeout time.Duration) *PTZDiscoverer {
+	if timeout == 0 {
+		timeout = 3 * time.Second
+	}
+
+	return &PTZDiscoverer{
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Discover finds the PTZ service, profiles, spaces and preset limit of the camera at u
+// u carries the camera host and credentials; endpoint is its ONVIF device service,
+// or empty to try the usual ports.
+// The first profile with a PTZ configuration becomes ProfileToken.
+func (d *PTZDiscoverer) Discover(ctx context.Context, u *url.URL, endpoint string) (*domain.PTZCapabilities, error) {
+	username, password := credentials(u)
+
+	candidates := deviceServiceCandidates(u, endpoint)
+
+	var services *capabilitiesResponse
+	var deviceURL string
+	var lastErr error
+	for _, candidate := range candidates {
+		services = &capabilitiesResponse{}
+		err := d.call(ctx, candidate, username, password, getCapabilitiesRequest, services)
+		if err == nil {
+			deviceURL = candidate
+			break
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lastErr = err
+	}
+	if deviceURL == "" {
+		return nil, fmt.Errorf("no ONVIF device service answered: %w", lastErr)
+	}
+
+	ptzURL := serviceURL(deviceURL, services.Capabilities.PTZ.XAddr)
+	mediaURL := serviceURL(deviceURL, services.Capabilities.Media.XAddr)
+	if ptzURL == "" {
+		return nil, fmt.Errorf("camera has no ONVIF PTZ service")
+	}
+	if mediaURL == "" {
+		return nil, fmt.Errorf("camera has no ONVIF media service")
+	}
+
+	profiles := &profilesResponse{}
+	if err := d.call(ctx, mediaURL, username, password, getProfilesRequest, profiles); err != nil {
+		return nil, fmt.Errorf("GetProfiles: %w", err)
+	}
+
+	caps := &domain.PTZCapabilities{
+		Source:           domain.PTZCapabilitiesSourceONVIF,
+		ServiceURL:       ptzURL,
+		DeviceServiceURL: deviceURL,
+		Profiles:         ptzProfiles(profiles),
+	}
+
+	var configToken, nodeToken string
+	for _, profile := range profiles.Profiles {
+		if profile.PTZConfiguration != nil {
+			caps.ProfileToken = profile.Token
+			configToken = profile.PTZConfiguration.Token
+			nodeToken = profile.PTZConfiguration.NodeToken
+			break
+		}
+	}
+	if caps.ProfileToken == "" {
+		return nil, fmt.Errorf("no media profile has a PTZ configuration")
+	}
+
+	options := &configurationOptionsResponse{}
+	request := fmt.Sprintf(getConfigurationOptionsRequest, xmlEscape(configToken))
+	if err := d.call(ctx, ptzURL, username, password, request, options); err != nil {
+		return nil, fmt.Errorf("GetConfigurationOptions: %w", err)
+	}
+	spaces := options.Options.Spaces.domain()
+	caps.Spaces = &spaces
+	caps.TimeoutRange = options.Options.PTZTimeout.domain()
+
+	// The node adds the preset limit and home support; cameras without GetNode still work
+	if nodeToken != "" {
+		node := &nodeResponse{}
+		request := fmt.Sprintf(getNodeRequest, xmlEscape(nodeToken))
+		if err := d.call(ctx, ptzURL, username, password, request, node); err == nil {
+			caps.MaxPresets = node.Node.MaximumNumberOfPresets
+			caps.HomeSupported = node.Node.HomeSupported
+		}
+	}
+
+	caps.Pan = len(spaces.ContinuousPanTilt) > 0 || len(spaces.AbsolutePanTilt) > 0 || len(spaces.RelativePanTilt) > 0
+	caps.Tilt = caps.Pan
+	caps.Zoom = len(spaces.ContinuousZoom) > 0 || len(spaces.AbsoluteZoom) > 0 || len(spaces.RelativeZoom) > 0
+	for _, space := range spaces.ContinuousPanTilt {
+		if space.URI == velocityGenericSpace {
+			caps.MaxPanSpeed = space.X.Max
+			if space.Y != nil {
+				caps.MaxTiltSpeed = space.Y.Max
+			}
+		}
+	}
+
+	discoveredAt := time.Now().UTC()
+	caps.DiscoveredAt = &discoveredAt
+
+	return caps, nil
+}
+
+// deviceServiceCandidates lists the device services to try for the camera at u: endpoint,
+// or the usual ports when it is empty
+func deviceServiceCandidates(u *url.URL, endpoint string) []string {
+	if endpoint != "" {
+		return []string{endpoint}
+	}
+
+	candidates := make([]string, 0, len(onvifPorts))
+	for _, port := range onvifPorts {
+		candidates = append(candidates, fmt.Sprintf("http://%s/onvif/device_service", net.JoinHostPort(u.Hostname(), port)))
+	}
+	return candidates
+}
+
+// ptzProfiles lists the media profiles of a GetProfiles response
+func ptzProfiles(profiles *profilesResponse) []domain.PTZProfile {
+	result := make([]domain.PTZProfile, 0, len(profiles.Profiles))
+	for _, profile := range profiles.Profiles {
+		p := domain.PTZProfile{Token: profile.Token, Name: profile.Name}
+		if profile.PTZConfiguration != nil {
+			p.PTZConfigurationToken = profile.PTZConfiguration.Token
+			p.NodeToken = profile.PTZConfiguration.NodeToken
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// ParsePTZProfileToken reads a GetProfiles response body and returns the token of the
+// first profile with a PTZ configuration
+func ParsePTZProfileToken(body io.Reader) (string, error) {
+	var envelope soapEnvelope
+	if err := xml.NewDecoder(body).Decode(&envelope); err != nil {
+		return "", fmt.Errorf("failed to decode GetProfiles response: %w", err)
+	}
+	if envelope.Body.Fault != nil {
+		return "", envelope.Body.Fault
+	}
+
+	var profiles profilesResponse
+	if err := xml.Unmarshal(envelope.Body.Content, &profiles); err != nil {
+		return "", fmt.Errorf("failed to decode GetProfiles response: %w", err)
+	}
+
+	for _, profile := range profiles.Profiles {
+		if profile.PTZConfiguration != nil {
+			return profile.Token, nil
+		}
+	}
+	return "", fmt.Errorf("no media profile has a PTZ configuration")
+}
+
+// credentials returns the user name and password in u, empty when it has none
+func credentials(u *url.URL) (string, string) {
+	if u.User == nil {
+		return "", ""
+	}
+	password, _ := u.User.Password()
+	return u.User.Username(), password
+}
+
+// call sends a SOAP request to endpoint and decodes the body of the response into out
+// out may be nil for requests whose response carries nothing.
+func (d *PTZDiscoverer) call(ctx context.Context, endpoint, username, password, body string, out interface{}) error {
+	security := ""
+	if username != "" {
+		security = WSSecurityHeader(username, password)
+	}
+	envelope := fmt.Sprintf(soapEnvelopeTemplate, security, body)
+
+	return postSOAP(ctx, d.httpClient, endpoint, envelope, out)
+}
+
+// postSOAP sends a SOAP envelope to endpoint and decodes the body of the response into out
+func postSOAP(ctx context.Context, httpClient *http.Client, endpoint, envelope string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(envelope))
+	if err != nil {
+		return fmt.Errorf("failed to create SOAP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/soap+xml; charset=utf-8")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("SOAP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read SOAP response: %w", err)
+	}
+
+	var response soapEnvelope
+	if err := xml.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("camera returned status %d with invalid SOAP: %w", resp.StatusCode, err)
+	}
+	if response.Body.Fault != nil {
+		return response.Body.Fault
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("camera returned status %d", resp.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := decodeSOAPBody(data, out); err != nil {
+		return fmt.Errorf("failed to decode SOAP response: %w", err)
+	}
+	return nil
+}
+
+// decodeSOAPBody decodes the response element of a SOAP envelope into out
+// Decoding it in place keeps the namespaces declared on the envelope, which elements that are
+// sent back to the camera need.
+func decodeSOAPBody(data []byte, out interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	inBody := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return fmt.Errorf("empty SOAP body")
+		}
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if inBody {
+			return decoder.DecodeElement(out, &start)
+		}
+		inBody = start.Name.Local == "Body"
+	}
+}
+
+// WSSecurityHeader generates the WS-Security UsernameToken header ONVIF authenticates with
+func WSSecurityHeader(username, password string) string {
+	// Random nonce, 20 bytes per the ONVIF spec
+	nonceBytes := make([]byte, 20)
+	rand.Read(nonceBytes)
+	nonce := base64.StdEncoding.EncodeToString(nonceBytes)
+	created := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+
+	// Password Digest = Base64(SHA1(nonce_bytes + created + password))
+	hash := sha1.New()
+	hash.Write(nonceBytes) // Raw nonce bytes, not base64
+	hash.Write([]byte(created))
+	hash.Write([]byte(password))
+	digest := base64.StdEncoding.EncodeToString(hash.Sum(nil))
+
+	return fmt.Sprintf(`<Security s:mustUnderstand="1" xmlns="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd">
+    <UsernameToken>
+      <Username>%s</Username>
+      <Password Type="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest">%s</Password>
+      <Nonce EncodingType="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary">%s</Nonce>
+      <Created xmlns="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd">%s</Created>
+    </UsernameToken>
+  </Security>`, xmlEscape(username), digest, nonce, created)
+}
+
+// serviceURL resolves a service XAddr against the device service that reported it
+// Cameras behind NAT report their internal address, so the scheme and host of the
+// device service are kept and only the path is taken from the XAddr.
+func serviceURL(deviceURL, xaddr string) string {
+	if xaddr == "" {
+		return ""
+	}
+
+	device, err := url.Parse(deviceURL)
+	if err != nil {
+		return ""
+	}
+	service, err := url.Parse(strings.TrimSpace(xaddr))
+	if err != nil || service.Path == "" {
+		return ""
+	}
+
+	device.Path = service.Path
+	device.RawQuery = service.RawQuery
+	return device.String()
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// ============================================================================
+// SOAP messages
+// ============================================================================
+
+const velocityGenericSpace = "http://www.onvif.org/ver10/tptz/PanTiltSpaces/VelocityGenericSpace"
+
+const soapEnvelopeTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"
+            xmlns:tds="http://www.onvif.org/ver10/device/wsdl"
+            xmlns:trt="http://www.onvif.org/ver10/media/wsdl"
+            xmlns:tptz="http://www.onvif.org/ver20/ptz/wsdl"
+            xmlns:tt="http://www.onvif.org/ver10/schema">
+  <s:Header>
+    %s
+  </s:Header>
+  <s:Body>
+    %s
+  </s:Body>
+</s:Envelope>`
+
+const (
+	getCapabilitiesRequest         = `<tds:GetCapabilities><tds:Category>All</tds:Category></tds:GetCapabilities>`
+	getProfilesRequest             = `<trt:GetProfiles/>`
+	getConfigurationOptionsRequest = `<tptz:GetConfigurationOptions><tptz:ConfigurationToken>%s</tptz:ConfigurationToken></tptz:GetConfigurationOptions>`
+	getNodeRequest                 = `<tptz:GetNode><tptz:NodeToken>%s</tptz:NodeToken></tptz:GetNode>`
+)
+
+// soapEnvelope is a SOAP 1.2 response; Content is the response element, which the
+// response types below are decoded from
+type soapEnvelope struct {
+	Body struct {
+		Fault   *soapFault `xml:"Fault"`
+		Content []byte     `xml:",innerxml"`
+	} `xml:"Body"`
+}
+
+// soapFault is a SOAP 1.2 fault, with the SOAP 1.1 faultstring some cameras send
+type soapFault struct {
+	Reason      string `xml:"Reason>Text"`
+	Subcode     string `xml:"Code>Subcode>Value"`
+	FaultString string `xml:"faultstring"`
+}
+
+func (f *soapFault) Error() string {
+	reason := f.Reason
+	if reason == "" {
+		reason = f.FaultString
+	}
+	if f.Subcode != "" {
+		return fmt.Sprintf("ONVIF fault %s: %s", f.Subcode, reason)
+	}
+	return "ONVIF fault: " + reason
+}
+
+type capabilitiesResponse struct {
+	Capabilities struct {
+		Media struct {
+			XAddr string `xml:"XAddr"`
+		} `xml:"Media"`
+		PTZ struct {
+			XAddr string `xml:"XAddr"`
+		} `xml:"PTZ"`
+	} `xml:"Capabilities"`
+}
+
+type profilesResponse struct {
+	Profiles []struct {
+		Token            string `xml:"token,attr"`
+		Name             string `xml:"Name"`
+		PTZConfiguration *struct {
+			Token     string `xml:"token,attr"`
+			NodeToken string `xml:"NodeToken"`
+		} `xml:"PTZConfiguration"`
+	} `xml:"Profiles"`
+}
+
+type configurationOptionsResponse struct {
+	Options struct {
+		Spaces     xmlSpaces   `xml:"Spaces"`
+		PTZTimeout xmlDuration `xml:"PTZTimeout"`
+	} `xml:"PTZConfigurationOptions"`
+}
+
+type nodeResponse struct {
+	Node struct {
+		MaximumNumberOfPresets int  `xml:"MaximumNumberOfPresets"`
+		HomeSupported          bool `xml:"HomeSupported"`
+	} `xml:"PTZNode"`
+}
+
+type xmlRange struct {
+	Min float64 `xml:"Min"`
+	Max float64 `xml:"Max"`
+}
+
+type xmlSpace struct {
+	URI    string    `xml:"URI"`
+	XRange xmlRange  `xml:"XRange"`
+	YRange *xmlRange `xml:"YRange"`
+}
+
+type xmlSpaces struct {
+	AbsolutePanTilt   []xmlSpace `xml:"AbsolutePanTiltPositionSpace"`
+	AbsoluteZoom      []xmlSpace `xml:"AbsoluteZoomPositionSpace"`
+	RelativePanTilt   []xmlSpace `xml:"RelativePanTiltTranslationSpace"`
+	RelativeZoom      []xmlSpace `xml:"RelativeZoomTranslationSpace"`
+	ContinuousPanTilt []xmlSpace `xml:"ContinuousPanTiltVelocitySpace"`
+	ContinuousZoom    []xmlSpace `xml:"ContinuousZoomVelocitySpace"`
+	PanTiltSpeed      []xmlSpace `xml:"PanTiltSpeedSpace"`
+	ZoomSpeed         []xmlSpace `xml:"ZoomSpeedSpace"`
+}
+
+func (s xmlSpaces) domain() domain.PTZSpaces {
+	convert := func(spaces []xmlSpace) []domain.PTZSpace {
+		result := make([]domain.PTZSpace, 0, len(spaces))
+		for _, space := range spaces {
+			converted := domain.PTZSpace{
+				URI: space.URI,
+				X:   domain.PTZRange{Min: space.XRange.Min, Max: space.XRange.Max},
+			}
+			if space.YRange != nil {
+				converted.Y = &domain.PTZRange{Min: space.YRange.Min, Max: space.YRange.Max}
+			}
+			result = append(result, converted)
+		}
+		return result
+	}
+
+	return domain.PTZSpaces{
+		AbsolutePanTilt:   convert(s.AbsolutePanTilt),
+		AbsoluteZoom:      convert(s.AbsoluteZoom),
+		RelativePanTilt:   convert(s.RelativePanTilt),
+		RelativeZoom:      convert(s.RelativeZoom),
+		ContinuousPanTilt: convert(s.ContinuousPanTilt),
+		ContinuousZoom:    convert(s.ContinuousZoom),
+		PanTiltSpeed:      convert(s.PanTiltSpeed),
+		ZoomSpeed:         convert(s.ZoomSpeed),
+	}
+}
+
+// xmlDuration is an xs:duration range such as PT1S to PT10M
+type xmlDuration struct {
+	Min string `xml:"Min"`
+	Max string `xml:"Max"`
+}
+
+func (d xmlDuration) domain() *domain.PTZTimeoutRange {
+	if d.Min == "" && d.Max == "" {
+		return nil
+	}
+	return &domain.PTZTimeoutRange{Min: d.Min, Max: d.Max}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// CGIDriver controls cameras with the generic /cgi-bin/ptz.cgi interface many IP cameras share
+type CGIDriver struct {
+	httpClient *http.Client
+}
+
+// NewCGIDriver creates a generic CGI PTZ driver
+func NewCGIDriver(timeout time.Duration) *CGIDriver {
+	return &CGIDriver{httpClient: &http.Client{Timeout: timeout}}
+}
+
+// Name identifies the driver
+func (d *CGIDriver) Name() string {
+	return domain.PTZDriverCGI
+}
+
+// Detect sends a stop, which leaves a camera at rest where it is
+func (d *CGIDriver) Detect(ctx context.Context, target *PTZTarget) error {
+	return d.request(ctx, target, "action=stop")
+}
+
+// Execute sends a command; MOVE goes in the direction of the first non-zero axis
+func (d *CGIDriver) Execute(ctx context.Context, target *PTZTarget, cmd *domain.PTZCommand) error {
+	switch cmd.Action {
+	case domain.PTZActionMove:
+		var direction string
+		switch {
+		case cmd.Pan < 0:
+			direction = "left"
+		case cmd.Pan > 0:
+			direction = "right"
+		case cmd.Tilt > 0:
+			direction = "up"
+		case cmd.Tilt < 0:
+			direction = "down"
+		case cmd.Zoom > 0:
+			direction = "zoomin"
+		case cmd.Zoom < 0:
+			direction = "zoomout"
+		default:
+			return fmt.Errorf("no movement specified")
+		}
+		return d.request(ctx, target, fmt.Sprintf("action=start&direction=%s&speed=%d", direction, int(cmd.Speed*100)))
+
+	case domain.PTZActionStop:
+		return d.request(ctx, target, "action=stop")
+
+	case domain.PTZActionGoHome:
+		return d.request(ctx, target, "action=gohome")
+
+	case domain.PTZActionGoToPreset:
+		return d.request(ctx, target, fmt.Sprintf("action=preset&id=%d", cmd.Preset))
+
+	default:
+		return fmt.Errorf("%w: %s over CGI", domain.ErrPTZNotSupported, cmd.Action)
+	}
+}
+
+func (d *CGIDriver) request(ctx context.Context, target *PTZTarget, query string) error {
+	status, err := doCameraRequest(ctx, d.httpClient, "GET", httpURL(target, "/cgi-bin/ptz.cgi?"+query), "", target.DialURL.User)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("camera returned status %d", status)
+	}
+	return nil
+}
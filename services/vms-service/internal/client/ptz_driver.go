@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// PTZDriver controls cameras over one PTZ protocol
+type PTZDriver interface {
+	// Name is the driver recorded for cameras it controls, one of the domain.PTZDriver names
+	Name() string
+
+	// Detect reports whether the camera answers the protocol, without moving it
+	Detect(ctx context.Context, target *PTZTarget) error
+
+	// Execute sends a validated command; actions the protocol lacks return domain.ErrPTZNotSupported
+	Execute(ctx context.Context, target *PTZTarget, cmd *domain.PTZCommand) error
+}
+
+// PTZTarget is a camera to control
+type PTZTarget struct {
+	CameraID          string
+	DialURL           *url.URL // Carries decrypted credentials; never log it
+	MilestoneDeviceID string
+}
+
+// httpURL is the camera's web interface at path, on the default HTTP port
+func httpURL(target *PTZTarget, path string) string {
+	return fmt.Sprintf("http://%s%s", target.DialURL.Hostname(), path)
+}
+
+// doCameraRequest sends an HTTP request to a camera and returns the status code
+// A 401 challenge is answered once with the camera's credentials, Digest preferred over Basic.
+func doCameraRequest(ctx context.Context, httpClient *http.Client, method, endpoint, body string, user *url.Userinfo) (int, error) {
+	send := func(authorization string) (int, http.Header, error) {
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, strings.NewReader(body))
+		if err != nil {
+			return 0, nil, err
+		}
+		if body != "" {
+			req.Header.Set("Content-Type", "application/xml")
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return 0, nil, err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+		return resp.StatusCode, resp.Header, nil
+	}
+
+	status, header, err := send("")
+	if err != nil || status != http.StatusUnauthorized || user == nil {
+		return status, err
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return status, err
+	}
+	authorize, err := challengeAuth(header.Values("WWW-Authenticate"), user, parsed.RequestURI())
+	if err != nil {
+		return status, err
+	}
+
+	status, _, err = send(authorize(method))
+	return status, err
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// ISAPIDriver controls Hikvision cameras over ISAPI, on channel 1
+type ISAPIDriver struct {
+	httpClient *http.Client
+}
+
+// NewISAPIDriver creates a Hikvision ISAPI PTZ driver
+func NewISAPIDriver(timeout time.Duration) *ISAPIDriver {
+	return &ISAPIDriver{httpClient: &http.Client{Timeout: timeout}}
+}
+
+// Name identifies the driver
+func (d *ISAPIDriver) Name() string {
+	return domain.PTZDriverISAPI
+}
+
+// Detect reads the PTZ capabilities of channel 1
+func (d *ISAPIDriver) Detect(ctx context.Context, target *PTZTarget) error {
+	return d.request(ctx, target, "GET", "/ISAPI/PTZCtrl/channels/1/capabilities", "")
+}
+
+// Execute sends a command; Hikvision speeds run from -100 to 100
+func (d *ISAPIDriver) Execute(ctx context.Context, target *PTZTarget, cmd *domain.PTZCommand) error {
+	switch cmd.Action {
+	case domain.PTZActionMove:
+		return d.request(ctx, target, "PUT", "/ISAPI/PTZCtrl/channels/1/continuous",
+			continuousData(int(cmd.Pan*100), int(cmd.Tilt*100), int(cmd.Zoom*100)))
+
+	case domain.PTZActionStop:
+		return d.request(ctx, target, "PUT", "/ISAPI/PTZCtrl/channels/1/continuous", continuousData(0, 0, 0))
+
+	case domain.PTZActionGoToPreset:
+		return d.request(ctx, target, "PUT", fmt.Sprintf("/ISAPI/PTZCtrl/channels/1/presets/%d/goto", cmd.Preset), "")
+
+	case domain.PTZActionGoHome:
+		return d.request(ctx, target, "PUT", "/ISAPI/PTZCtrl/channels/1/homeposition/goto", "")
+
+	default:
+		return fmt.Errorf("%w: %s over ISAPI", domain.ErrPTZNotSupported, cmd.Action)
+	}
+}
+
+func (d *ISAPIDriver) request(ctx context.Context, target *PTZTarget, method, path, body string) error {
+	status, err := doCameraRequest(ctx, d.httpClient, method, httpURL(target, path), body, target.DialURL.User)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusNoContent {
+		return fmt.Errorf("camera returned status %d", status)
+	}
+	return nil
+}
+
+func continuousData(pan, tilt, zoom int) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?><PTZData><pan>%d</pan><tilt>%d</tilt><zoom>%d</zoom></PTZData>`, pan, tilt, zoom)
+}
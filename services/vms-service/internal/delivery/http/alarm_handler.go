@@ -0,0 +1,91 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+const (
+	// defaultAlarmLimit is the number of alarms returned without ?limit=
+	defaultAlarmLimit = 100
+
+	// defaultAlarmWindow is how far back alarms are listed without ?since=
+	defaultAlarmWindow = 24 * time.Hour
+)
+
+// ListCameraAlarms retrieves the alarms of a camera, newest first
+// GET /vms/cameras/{id}/alarms?type=motion&since=2024-01-01T00:00:00Z&limit=100
+func (h *Handler) ListCameraAlarms(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+	query := r.URL.Query()
+
+	alarmType := domain.AlarmType(query.Get("type"))
+	switch alarmType {
+	case "", domain.AlarmMotion, domain.AlarmTamper, domain.AlarmDigitalInput:
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid type. Must be one of: motion, tamper, digital_input")
+		return
+	}
+
+	since := time.Now().Add(-defaultAlarmWindow)
+	if sinceParam := query.Get("since"); sinceParam != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceParam)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid since. Must be an RFC 3339 time")
+			return
+		}
+		since = parsed
+	}
+
+	limit := defaultAlarmLimit
+	if limitParam := query.Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 || parsed > domain.MaxCameraAlarms {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit. Must be 1-%d", domain.MaxCameraAlarms))
+			return
+		}
+		limit = parsed
+	}
+
+	alarms, err := h.alarms.ListAlarms(r.Context(), cameraID, alarmType, since, limit)
+	if errors.Is(err, domain.ErrCameraNotFound) {
+		respondError(w, http.StatusNotFound, "Camera not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to list camera alarms")
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve camera alarms")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"camera_id": cameraID,
+		"alarms":    alarms,
+		"total":     len(alarms),
+	})
+}
+
+// GetAlarmSubscription retrieves the ONVIF event subscription held for a camera
+// GET /vms/cameras/{id}/alarms/subscription
+func (h *Handler) GetAlarmSubscription(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+
+	subscription, err := h.alarms.GetSubscription(cameraID)
+	if errors.Is(err, domain.ErrAlarmSubscriptionNotFound) {
+		respondError(w, http.StatusNotFound, "Camera has no alarm subscription")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to get alarm subscription")
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve alarm subscription")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, subscription)
+}
@@ -2,28 +2,49 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rta/cctv/vms-service/internal/domain"
 	"github.com/rta/cctv/vms-service/internal/repository"
+	"github.com/rta/cctv/vms-service/internal/repository/cache"
+	"github.com/rta/cctv/vms-service/internal/usecase"
 	"github.com/rs/zerolog"
 )
 
+const (
+	// defaultHealthWindow is the uptime window of camera health without ?window=
+	defaultHealthWindow = 24 * time.Hour
+
+	// maxHealthWindow bounds ?window= of camera health
+	maxHealthWindow = 30 * 24 * time.Hour
+)
+
 // Handler handles HTTP requests for VMS Service
 type Handler struct {
-	cameraRepo repository.CameraRepository
-	cache      repository.CacheRepository
-	logger     zerolog.Logger
+	cameraRepo   repository.CameraRepository
+	presetRepo   repository.PTZPresetRepository
+	positionRepo repository.PTZPositionRepository
+	cache        repository.CacheRepository
+	healthProber *usecase.HealthProber
+	patrols      *usecase.PTZPatrolScheduler
+	alarms       *usecase.AlarmSubscriber
+	logger       zerolog.Logger
 }
 
 // NewHandler creates a new HTTP handler
-func NewHandler(cameraRepo repository.CameraRepository, cache repository.CacheRepository, logger zerolog.Logger) *Handler {
+func NewHandler(cameraRepo repository.CameraRepository, presetRepo repository.PTZPresetRepository, positionRepo repository.PTZPositionRepository, cache repository.CacheRepository, healthProber *usecase.HealthProber, patrols *usecase.PTZPatrolScheduler, alarms *usecase.AlarmSubscriber, logger zerolog.Logger) *Handler {
 	return &Handler{
-		cameraRepo: cameraRepo,
-		cache:      cache,
-		logger:     logger,
+		cameraRepo:   cameraRepo,
+		presetRepo:   presetRepo,
+		positionRepo: positionRepo,
+		cache:        cache,
+		healthProber: healthProber,
+		patrols:      patrols,
+		alarms:       alarms,
+		logger:       logger,
 	}
 }
 
@@ -33,7 +54,7 @@ func (h *Handler) GetCameras(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Check cache first
-	cacheKey := "cameras:all"
+	cacheKey := cache.CamerasKey
 	if cached, err := h.cache.Get(ctx, cacheKey); err == nil {
 		h.logger.Debug().Msg("Serving cameras from cache")
 		respondJSON(w, http.StatusOK, cached)
@@ -48,13 +69,13 @@ func (h *Handler) GetCameras(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Cache for 5 minutes
+	// Cache for the configured TTL; camera changes invalidate it sooner
 	response := map[string]interface{}{
 		"cameras":      cameras,
 		"total":        len(cameras),
 		"last_updated": time.Now(),
 	}
-	h.cache.Set(ctx, cacheKey, response, 5*time.Minute)
+	h.cache.Set(ctx, cacheKey, response, 0)
 
 	respondJSON(w, http.StatusOK, response)
 }
@@ -71,7 +92,7 @@ func (h *Handler) GetCameraByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check cache
-	cacheKey := "camera:" + cameraID
+	cacheKey := cache.CameraKey(cameraID)
 	if cached, err := h.cache.Get(ctx, cacheKey); err == nil {
 		h.logger.Debug().Str("camera_id", cameraID).Msg("Serving camera from cache")
 		respondJSON(w, http.StatusOK, cached)
@@ -86,8 +107,8 @@ func (h *Handler) GetCameraByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Cache for 5 minutes
-	h.cache.Set(ctx, cacheKey, camera, 5*time.Minute)
+	// Cache for the configured TTL; camera changes invalidate it sooner
+	h.cache.Set(ctx, cacheKey, camera, 0)
 
 	respondJSON(w, http.StatusOK, camera)
 }
@@ -120,7 +141,7 @@ func (h *Handler) GetCamerasBySource(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check cache
-	cacheKey := "cameras:source:" + string(source)
+	cacheKey := cache.CamerasBySourceKey(source)
 	if cached, err := h.cache.Get(ctx, cacheKey); err == nil {
 		h.logger.Debug().Str("source", string(source)).Msg("Serving cameras from cache")
 		respondJSON(w, http.StatusOK, cached)
@@ -141,13 +162,14 @@ func (h *Handler) GetCamerasBySource(w http.ResponseWriter, r *http.Request) {
 		"source":  source,
 	}
 
-	// Cache for 5 minutes
-	h.cache.Set(ctx, cacheKey, response, 5*time.Minute)
+	// Cache for the configured TTL; camera changes invalidate it sooner
+	h.cache.Set(ctx, cacheKey, response, 0)
 
 	respondJSON(w, http.StatusOK, response)
 }
 
 // GetCameraStream retrieves RTSP URL for streaming
+// Credentials are masked; services that dial the camera decrypt them from the database
 // GET /vms/cameras/{id}/stream
 func (h *Handler) GetCameraStream(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -173,6 +195,71 @@ func (h *Handler) GetCameraStream(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetCameraHealth retrieves the probed health and uptime of a camera
+// GET /vms/cameras/{id}/health?window=24h
+func (h *Handler) GetCameraHealth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+
+	window := defaultHealthWindow
+	if windowParam := r.URL.Query().Get("window"); windowParam != "" {
+		parsed, err := time.ParseDuration(windowParam)
+		if err != nil || parsed <= 0 || parsed > maxHealthWindow {
+			respondError(w, http.StatusBadRequest, "Invalid window. Must be a duration up to 720h, e.g. 24h")
+			return
+		}
+		window = parsed
+	}
+
+	health, err := h.healthProber.GetHealth(ctx, cameraID, window)
+	if errors.Is(err, domain.ErrCameraNotFound) {
+		respondError(w, http.StatusNotFound, "Camera not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to get camera health")
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve camera health")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, health)
+}
+
+// GetPTZCapabilities retrieves the PTZ capabilities discovered from a camera
+// GET /vms/cameras/{id}/ptz/capabilities
+func (h *Handler) GetPTZCapabilities(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+
+	// Check cache
+	cacheKey := cache.PTZCapabilitiesKey(cameraID)
+	if cached, err := h.cache.Get(ctx, cacheKey); err == nil {
+		respondJSON(w, http.StatusOK, cached)
+		return
+	}
+
+	caps, err := h.cameraRepo.GetPTZCapabilities(ctx, cameraID)
+	if errors.Is(err, domain.ErrCameraNotFound) {
+		respondError(w, http.StatusNotFound, "Camera not found")
+		return
+	}
+	if errors.Is(err, domain.ErrPTZDiscoveryFailed) {
+		h.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("PTZ capabilities unavailable")
+		respondError(w, http.StatusBadGateway, "Camera did not report its PTZ capabilities")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to get PTZ capabilities")
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve PTZ capabilities")
+		return
+	}
+
+	// Rediscovery invalidates it
+	h.cache.Set(ctx, cacheKey, caps, 0)
+
+	respondJSON(w, http.StatusOK, caps)
+}
+
 // ExecutePTZ handles PTZ control commands
 // POST /vms/cameras/{id}/ptz
 func (h *Handler) ExecutePTZ(w http.ResponseWriter, r *http.Request) {
@@ -192,10 +279,20 @@ func (h *Handler) ExecutePTZ(w http.ResponseWriter, r *http.Request) {
 
 	cmd.CameraID = cameraID
 
+	// The operator takes the camera from its patrol until they leave it alone
+	h.patrols.PauseForOperator(cameraID)
+
 	// Execute PTZ command
 	if err := h.cameraRepo.ExecutePTZCommand(ctx, &cmd); err != nil {
 		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to execute PTZ command")
-		respondError(w, http.StatusBadRequest, err.Error())
+		switch {
+		case errors.Is(err, domain.ErrCameraNotFound):
+			respondError(w, http.StatusNotFound, "Camera not found")
+		case errors.Is(err, domain.ErrPTZCommandFailed):
+			respondError(w, http.StatusBadGateway, err.Error())
+		default:
+			respondError(w, http.StatusBadRequest, err.Error())
+		}
 		return
 	}
 
@@ -211,6 +308,57 @@ func (h *Handler) ExecutePTZ(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetPTZDriver retrieves the PTZ driver detected for a camera
+// GET /vms/cameras/{id}/ptz/driver
+func (h *Handler) GetPTZDriver(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+
+	record, err := h.cameraRepo.GetPTZDriver(ctx, cameraID)
+	switch {
+	case errors.Is(err, domain.ErrCameraNotFound):
+		respondError(w, http.StatusNotFound, "Camera not found")
+		return
+	case errors.Is(err, domain.ErrPTZDriverNotFound):
+		respondError(w, http.StatusNotFound, "No PTZ driver detected yet")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to get PTZ driver")
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve PTZ driver")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, record)
+}
+
+// DetectPTZDriver detects the PTZ driver of a camera again
+// POST /vms/cameras/{id}/ptz/driver/detect
+func (h *Handler) DetectPTZDriver(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+
+	record, err := h.cameraRepo.DetectPTZDriver(ctx, cameraID)
+	switch {
+	case errors.Is(err, domain.ErrCameraNotFound):
+		respondError(w, http.StatusNotFound, "Camera not found")
+		return
+	case errors.Is(err, domain.ErrPTZNotSupported):
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to detect PTZ driver")
+		respondError(w, http.StatusInternalServerError, "Failed to detect PTZ driver")
+		return
+	}
+
+	h.logger.Info().
+		Str("camera_id", cameraID).
+		Str("driver", record.Driver).
+		Msg("PTZ driver detected")
+
+	respondJSON(w, http.StatusOK, record)
+}
+
 // GetRecordingSegments retrieves available recording segments
 // GET /vms/recordings/{camera_id}/segments?start={start}&end={end}
 func (h *Handler) GetRecordingSegments(w http.ResponseWriter, r *http.Request) {
@@ -329,9 +477,9 @@ func (h *Handler) GetExportStatus(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Check Milestone connection
-	if err := h.cameraRepo.Sync(ctx); err != nil {
-		respondError(w, http.StatusServiceUnavailable, "Milestone VMS connection failed")
+	// Check camera store connection
+	if err := h.cameraRepo.HealthCheck(ctx); err != nil {
+		respondError(w, http.StatusServiceUnavailable, "Database connection failed")
 		return
 	}
 
@@ -2,27 +2,33 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/rta/cctv/vms-service/internal/client"
 	"github.com/rta/cctv/vms-service/internal/domain"
 	"github.com/rta/cctv/vms-service/internal/repository"
+	"github.com/rta/cctv/vms-service/internal/secrets"
+	"github.com/rta/cctv/vms-service/internal/usecase"
 	"github.com/rs/zerolog"
 )
 
 // MilestoneHandler handles Milestone-specific HTTP requests
 type MilestoneHandler struct {
 	milestoneClient *client.MilestoneClient
+	milestoneSync   *usecase.MilestoneSync
 	cameraRepo      repository.CameraRepository
 	logger          zerolog.Logger
 }
 
 // NewMilestoneHandler creates a new Milestone handler
-func NewMilestoneHandler(milestoneClient *client.MilestoneClient, cameraRepo repository.CameraRepository, logger zerolog.Logger) *MilestoneHandler {
+func NewMilestoneHandler(milestoneClient *client.MilestoneClient, milestoneSync *usecase.MilestoneSync, cameraRepo repository.CameraRepository, logger zerolog.Logger) *MilestoneHandler {
 	return &MilestoneHandler{
 		milestoneClient: milestoneClient,
+		milestoneSync:   milestoneSync,
 		cameraRepo:      cameraRepo,
 		logger:          logger,
 	}
@@ -100,6 +106,7 @@ func (h *MilestoneHandler) ListMilestoneCameras(w http.ResponseWriter, r *http.R
 		if imported {
 			importedCount++
 		}
+		cam.LiveStreamURL = secrets.MaskURL(cam.LiveStreamURL)
 		cameras[i] = CameraResponse{
 			MilestoneCamera: cam,
 			Imported:        imported,
@@ -133,6 +140,7 @@ func (h *MilestoneHandler) GetMilestoneCamera(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	camera.LiveStreamURL = secrets.MaskURL(camera.LiveStreamURL)
 	h.respondJSON(w, http.StatusOK, camera)
 }
 
@@ -212,7 +220,7 @@ func (h *MilestoneHandler) ImportCamera(w http.ResponseWriter, r *http.Request)
 		Name:              name,
 		NameAr:            req.NameAr,
 		Source:            domain.CameraSource(req.Source),
-		RTSPURL:           milestoneCamera.LiveStreamURL,
+		RTSPURL:           secrets.MaskURL(milestoneCamera.LiveStreamURL),
 		PTZEnabled:        milestoneCamera.PTZCapabilities != nil && (milestoneCamera.PTZCapabilities.Pan || milestoneCamera.PTZCapabilities.Tilt || milestoneCamera.PTZCapabilities.Zoom),
 		Status:            domain.StatusOnline,
 		RecordingServer:   milestoneCamera.RecordingServer,
@@ -243,95 +251,57 @@ func (h *MilestoneHandler) ImportCamera(w http.ResponseWriter, r *http.Request)
 	h.respondJSON(w, http.StatusOK, camera)
 }
 
-// BulkSyncRequest represents a request to sync cameras from Milestone
-type BulkSyncRequest struct {
-	SourceFilter string `json:"sourceFilter,omitempty"`
-	AutoImport   bool   `json:"autoImport"`
-}
-
-// BulkSyncResponse represents the response for bulk sync
-type BulkSyncResponse struct {
-	SyncJobID         string `json:"syncJobId"`
-	Status            string `json:"status"`
-	CamerasDiscovered int    `json:"camerasDiscovered"`
-	CamerasImported   int    `json:"camerasImported"`
-	Message           string `json:"message"`
-}
-
-// BulkSyncCameras synchronizes cameras from Milestone
+// BulkSyncCameras starts a full camera sync from Milestone in the background
 // POST /vms/milestone/sync-all
 func (h *MilestoneHandler) BulkSyncCameras(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	var req BulkSyncRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondError(w, http.StatusBadRequest, "Invalid request body")
-		return
+	initiatedBy := r.Header.Get("X-User-ID")
+	if initiatedBy == "" {
+		initiatedBy = "api"
 	}
 
-	h.logger.Info().
-		Str("source_filter", req.SourceFilter).
-		Bool("auto_import", req.AutoImport).
-		Msg("Starting bulk camera sync from Milestone")
-
-	// List all cameras from Milestone
-	opts := client.ListCamerasOptions{
-		Limit:  1000, // Get all cameras
-		Offset: 0,
+	job, err := h.milestoneSync.Start(r.Context(), initiatedBy)
+	if errors.Is(err, domain.ErrSyncInProgress) {
+		h.respondError(w, http.StatusConflict, err.Error())
+		return
 	}
-
-	cameraList, err := h.milestoneClient.ListCameras(ctx, opts)
 	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to list cameras from Milestone")
-		h.respondError(w, http.StatusInternalServerError, "Failed to sync cameras from Milestone")
+		h.logger.Error().Err(err).Msg("Failed to start Milestone sync")
+		h.respondError(w, http.StatusInternalServerError, "Failed to start Milestone sync")
 		return
 	}
 
-	discovered := len(cameraList.Cameras)
-	imported := 0
-
-	if req.AutoImport {
-		// Get existing cameras
-		existingCameras, err := h.cameraRepo.GetAll(ctx)
-		if err != nil {
-			h.logger.Error().Err(err).Msg("Failed to get existing cameras")
-			h.respondError(w, http.StatusInternalServerError, "Failed to get existing cameras")
-			return
-		}
+	h.logger.Info().
+		Str("job_id", job.ID).
+		Str("initiated_by", initiatedBy).
+		Msg("Milestone sync started")
 
-		existingMap := make(map[string]bool)
-		for _, cam := range existingCameras {
-			if cam.MilestoneDeviceID != "" {
-				existingMap[cam.MilestoneDeviceID] = true
-			}
-		}
+	w.Header().Set("Location", "/vms/milestone/sync/"+job.ID)
+	h.respondJSON(w, http.StatusAccepted, job)
+}
 
-		// Import new cameras
-		for _, milestoneCamera := range cameraList.Cameras {
-			if existingMap[milestoneCamera.ID] {
-				continue // Already imported
-			}
+// GetSyncJob retrieves a Milestone sync job with its per-camera change report
+// GET /vms/milestone/sync/{job_id}
+func (h *MilestoneHandler) GetSyncJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "job_id")
 
-			// Note: Camera creation should be handled by metadata-service
-			// For now, just count the discovered camera
-			imported++
-		}
+	// Job IDs are UUIDs; anything else cannot exist
+	if _, err := uuid.Parse(jobID); err != nil {
+		h.respondError(w, http.StatusNotFound, "Sync job not found")
+		return
 	}
 
-	h.logger.Info().
-		Int("discovered", discovered).
-		Int("imported", imported).
-		Msg("Bulk sync completed")
-
-	response := BulkSyncResponse{
-		SyncJobID:         "sync_" + strconv.FormatInt(r.Context().Value("request_id").(int64), 10),
-		Status:            "completed",
-		CamerasDiscovered: discovered,
-		CamerasImported:   imported,
-		Message:           "Camera synchronization completed successfully",
+	job, err := h.milestoneSync.GetJob(r.Context(), jobID)
+	if errors.Is(err, domain.ErrSyncJobNotFound) {
+		h.respondError(w, http.StatusNotFound, "Sync job not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("job_id", jobID).Msg("Failed to get Milestone sync job")
+		h.respondError(w, http.StatusInternalServerError, "Failed to get sync job")
+		return
 	}
 
-	h.respondJSON(w, http.StatusOK, response)
+	h.respondJSON(w, http.StatusOK, job)
 }
 
 // SyncCameraWithMilestone syncs a single camera with Milestone
@@ -373,7 +343,7 @@ func (h *MilestoneHandler) SyncCameraWithMilestone(w http.ResponseWriter, r *htt
 	}
 
 	// Update camera with latest Milestone data
-	camera.RTSPURL = milestoneCamera.LiveStreamURL
+	camera.RTSPURL = secrets.MaskURL(milestoneCamera.LiveStreamURL)
 	camera.RecordingServer = milestoneCamera.RecordingServer
 	camera.PTZEnabled = milestoneCamera.PTZCapabilities != nil
 
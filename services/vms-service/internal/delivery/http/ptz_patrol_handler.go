@@ -0,0 +1,158 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// maxPatrolSeconds bounds dwell and resume times
+const maxPatrolSeconds = 24 * 60 * 60
+
+// GetPTZPatrol retrieves the patrol of a camera
+// GET /vms/cameras/{id}/ptz/patrol
+func (h *Handler) GetPTZPatrol(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+
+	patrol, err := h.patrols.GetPatrol(r.Context(), cameraID)
+	if err != nil {
+		h.respondPTZPatrolError(w, err, cameraID, "Failed to get PTZ patrol")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, patrol)
+}
+
+// SavePTZPatrol creates or replaces the patrol of a camera
+// PUT /vms/cameras/{id}/ptz/patrol
+func (h *Handler) SavePTZPatrol(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+
+	var patrol domain.PTZPatrol
+	if err := json.NewDecoder(r.Body).Decode(&patrol); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	patrol.CameraID = cameraID
+	if message := validatePatrol(&patrol); message != "" {
+		respondError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	if err := h.patrols.SavePatrol(r.Context(), &patrol); err != nil {
+		h.respondPTZPatrolError(w, err, cameraID, "Failed to save PTZ patrol")
+		return
+	}
+
+	h.logger.Info().
+		Str("camera_id", cameraID).
+		Int("stops", len(patrol.Stops)).
+		Bool("enabled", patrol.Enabled).
+		Msg("PTZ patrol saved")
+
+	respondJSON(w, http.StatusOK, patrol)
+}
+
+// DeletePTZPatrol removes the patrol of a camera
+// DELETE /vms/cameras/{id}/ptz/patrol
+func (h *Handler) DeletePTZPatrol(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+
+	if err := h.patrols.DeletePatrol(r.Context(), cameraID); err != nil {
+		h.respondPTZPatrolError(w, err, cameraID, "Failed to delete PTZ patrol")
+		return
+	}
+
+	h.logger.Info().Str("camera_id", cameraID).Msg("PTZ patrol deleted")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPTZPatrolState retrieves the progress of a camera's patrol
+// GET /vms/cameras/{id}/ptz/patrol/state
+func (h *Handler) GetPTZPatrolState(w http.ResponseWriter, r *http.Request) {
+	cameraID := chi.URLParam(r, "id")
+
+	state, err := h.patrols.GetState(r.Context(), cameraID)
+	if err != nil {
+		h.respondPTZPatrolError(w, err, cameraID, "Failed to get PTZ patrol state")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, state)
+}
+
+// validatePatrol fills in defaults and returns a message for the first invalid field
+func validatePatrol(patrol *domain.PTZPatrol) string {
+	patrol.Name = strings.TrimSpace(patrol.Name)
+	if patrol.DwellSeconds == 0 {
+		patrol.DwellSeconds = domain.DefaultPTZPatrolDwellSeconds
+	}
+	if patrol.ResumeAfterSeconds == 0 {
+		patrol.ResumeAfterSeconds = domain.DefaultPTZPatrolResumeAfterSeconds
+	}
+
+	switch {
+	case utf8.RuneCountInString(patrol.Name) > 100:
+		return "Patrol name must be at most 100 characters"
+	case len(patrol.Stops) == 0 || len(patrol.Stops) > domain.MaxPTZPatrolStops:
+		return fmt.Sprintf("A patrol needs 1-%d stops", domain.MaxPTZPatrolStops)
+	case patrol.DwellSeconds < 1 || patrol.DwellSeconds > maxPatrolSeconds:
+		return "dwell_seconds must be 1-86400"
+	case patrol.ResumeAfterSeconds < 1 || patrol.ResumeAfterSeconds > maxPatrolSeconds:
+		return "resume_after_seconds must be 1-86400"
+	case patrol.Speed < 0 || patrol.Speed > 1:
+		return "speed must be 0.0-1.0"
+	}
+
+	for i, stop := range patrol.Stops {
+		if stop.Preset < 1 || stop.Preset > domain.MaxPTZPresetNumber {
+			return fmt.Sprintf("Stop %d: invalid preset number. Must be 1-256", i+1)
+		}
+		if stop.DwellSeconds < 0 || stop.DwellSeconds > maxPatrolSeconds {
+			return fmt.Sprintf("Stop %d: dwell_seconds must be 1-86400", i+1)
+		}
+	}
+
+	if schedule := patrol.Schedule; schedule != nil {
+		for _, clock := range []string{schedule.Start, schedule.End} {
+			if _, err := time.Parse("15:04", clock); err != nil {
+				return "Schedule start and end must be HH:MM"
+			}
+		}
+		for _, day := range schedule.Days {
+			if day < 0 || day > 6 {
+				return "Schedule days must be 0 (Sunday) to 6"
+			}
+		}
+		if schedule.Timezone != "" {
+			if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+				return "Unknown schedule timezone"
+			}
+		}
+	}
+
+	return ""
+}
+
+// respondPTZPatrolError maps patrol errors to status codes
+func (h *Handler) respondPTZPatrolError(w http.ResponseWriter, err error, cameraID, message string) {
+	switch {
+	case errors.Is(err, domain.ErrCameraNotFound):
+		respondError(w, http.StatusNotFound, "Camera not found")
+	case errors.Is(err, domain.ErrPTZPatrolNotFound):
+		respondError(w, http.StatusNotFound, "PTZ patrol not found")
+	case errors.Is(err, domain.ErrPTZNotSupported):
+		respondError(w, http.StatusBadRequest, "Camera does not support PTZ")
+	default:
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg(message)
+		respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// GetPTZStatus retrieves the current pan, tilt and zoom of a camera
+// GET /vms/cameras/{id}/ptz/status
+func (h *Handler) GetPTZStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+
+	status, err := h.positionRepo.GetPTZStatus(ctx, cameraID)
+	switch {
+	case errors.Is(err, domain.ErrCameraNotFound):
+		respondError(w, http.StatusNotFound, "Camera not found")
+		return
+	case errors.Is(err, domain.ErrPTZNotSupported):
+		respondError(w, http.StatusBadRequest, "Camera does not support PTZ")
+		return
+	case errors.Is(err, domain.ErrPTZDiscoveryFailed), errors.Is(err, domain.ErrPTZCommandFailed):
+		h.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("PTZ status unavailable")
+		respondError(w, http.StatusBadGateway, "Camera did not report its PTZ status")
+		return
+	case err != nil:
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to get PTZ status")
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve PTZ status")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
+
+// GetPTZFieldOfView retrieves the field of view click-to-center uses for a camera
+// GET /vms/cameras/{id}/ptz/field-of-view
+func (h *Handler) GetPTZFieldOfView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+
+	fov, err := h.positionRepo.GetPTZFieldOfView(ctx, cameraID)
+	if errors.Is(err, domain.ErrCameraNotFound) {
+		respondError(w, http.StatusNotFound, "Camera not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to get PTZ field of view")
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve PTZ field of view")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, fov)
+}
+
+// SetPTZFieldOfView configures the field of view of a camera
+// PUT /vms/cameras/{id}/ptz/field-of-view
+func (h *Handler) SetPTZFieldOfView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+
+	var fov domain.PTZFieldOfView
+	if err := json.NewDecoder(r.Body).Decode(&fov); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if message := validateFieldOfView(&fov); message != "" {
+		respondError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	err := h.positionRepo.SetPTZFieldOfView(ctx, cameraID, &fov)
+	if errors.Is(err, domain.ErrCameraNotFound) {
+		respondError(w, http.StatusNotFound, "Camera not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to set PTZ field of view")
+		respondError(w, http.StatusInternalServerError, "Failed to store PTZ field of view")
+		return
+	}
+
+	h.logger.Info().
+		Str("camera_id", cameraID).
+		Float64("horizontal_degrees", fov.HorizontalDegrees).
+		Float64("max_zoom", fov.MaxZoom).
+		Msg("PTZ field of view configured")
+
+	respondJSON(w, http.StatusOK, fov)
+}
+
+// validateFieldOfView returns a message for the first field out of range
+func validateFieldOfView(fov *domain.PTZFieldOfView) string {
+	switch {
+	case fov.HorizontalDegrees < 1 || fov.HorizontalDegrees > 180:
+		return "horizontal_degrees must be 1-180"
+	case fov.VerticalDegrees < 1 || fov.VerticalDegrees > 180:
+		return "vertical_degrees must be 1-180"
+	case fov.PanRangeDegrees < 1 || fov.PanRangeDegrees > 360:
+		return "pan_range_degrees must be 1-360"
+	case fov.TiltRangeDegrees < 1 || fov.TiltRangeDegrees > 360:
+		return "tilt_range_degrees must be 1-360"
+	case fov.MaxZoom < 1 || fov.MaxZoom > 100:
+		return "max_zoom must be 1-100"
+	}
+	return ""
+}
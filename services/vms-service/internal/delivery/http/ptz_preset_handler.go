@@ -0,0 +1,189 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// maxPresetNameLength bounds preset names in either language, in characters
+const maxPresetNameLength = 100
+
+// ptzPresetRequest is the body of preset create and update requests
+type ptzPresetRequest struct {
+	Number       int    `json:"number,omitempty"` // Create only; 0 takes the lowest free number
+	Name         string `json:"name"`
+	NameAr       string `json:"name_ar,omitempty"`
+	CreatedBy    string `json:"created_by,omitempty"`    // Create only
+	SavePosition bool   `json:"save_position,omitempty"` // Update only; stores the current position
+}
+
+// validate trims the names and returns a message for the first invalid field
+func (req *ptzPresetRequest) validate() string {
+	req.Name = strings.TrimSpace(req.Name)
+	req.NameAr = strings.TrimSpace(req.NameAr)
+
+	if req.Name == "" {
+		return "Preset name is required"
+	}
+	if utf8.RuneCountInString(req.Name) > maxPresetNameLength || utf8.RuneCountInString(req.NameAr) > maxPresetNameLength {
+		return "Preset names must be at most 100 characters"
+	}
+	if req.Number < 0 || req.Number > domain.MaxPTZPresetNumber {
+		return "Invalid preset number. Must be 1-256"
+	}
+	return ""
+}
+
+// ListPTZPresets retrieves the presets of a camera
+// GET /vms/cameras/{id}/ptz/presets?refresh=true
+func (h *Handler) ListPTZPresets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+	refresh := r.URL.Query().Get("refresh") == "true"
+
+	presets, err := h.presetRepo.ListPTZPresets(ctx, cameraID, refresh)
+	if err != nil {
+		h.respondPTZPresetError(w, err, cameraID, "Failed to list PTZ presets")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"camera_id": cameraID,
+		"presets":   presets,
+		"total":     len(presets),
+	})
+}
+
+// CreatePTZPreset saves the camera's current position as a named preset
+// POST /vms/cameras/{id}/ptz/presets
+func (h *Handler) CreatePTZPreset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+
+	var req ptzPresetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if message := req.validate(); message != "" {
+		respondError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	preset := &domain.PTZPreset{
+		CameraID:  cameraID,
+		Number:    req.Number,
+		Name:      req.Name,
+		NameAr:    req.NameAr,
+		CreatedBy: req.CreatedBy,
+	}
+	if err := h.presetRepo.CreatePTZPreset(ctx, preset); err != nil {
+		h.respondPTZPresetError(w, err, cameraID, "Failed to create PTZ preset")
+		return
+	}
+
+	h.logger.Info().
+		Str("camera_id", cameraID).
+		Int("number", preset.Number).
+		Str("name", preset.Name).
+		Msg("PTZ preset created")
+
+	respondJSON(w, http.StatusCreated, preset)
+}
+
+// UpdatePTZPreset renames a preset and optionally stores the current position under it
+// PUT /vms/cameras/{id}/ptz/presets/{number}
+func (h *Handler) UpdatePTZPreset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+
+	number, ok := presetNumber(w, r)
+	if !ok {
+		return
+	}
+
+	var req ptzPresetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.Number = number
+	if message := req.validate(); message != "" {
+		respondError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	preset := &domain.PTZPreset{
+		CameraID: cameraID,
+		Number:   number,
+		Name:     req.Name,
+		NameAr:   req.NameAr,
+	}
+	if err := h.presetRepo.UpdatePTZPreset(ctx, preset, req.SavePosition); err != nil {
+		h.respondPTZPresetError(w, err, cameraID, "Failed to update PTZ preset")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, preset)
+}
+
+// DeletePTZPreset removes a preset from the camera
+// DELETE /vms/cameras/{id}/ptz/presets/{number}
+func (h *Handler) DeletePTZPreset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cameraID := chi.URLParam(r, "id")
+
+	number, ok := presetNumber(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.presetRepo.DeletePTZPreset(ctx, cameraID, number); err != nil {
+		h.respondPTZPresetError(w, err, cameraID, "Failed to delete PTZ preset")
+		return
+	}
+
+	h.logger.Info().
+		Str("camera_id", cameraID).
+		Int("number", number).
+		Msg("PTZ preset deleted")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// presetNumber parses the {number} URL parameter, responding 400 when it is invalid
+func presetNumber(w http.ResponseWriter, r *http.Request) (int, bool) {
+	number, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err != nil || number < 1 || number > domain.MaxPTZPresetNumber {
+		respondError(w, http.StatusBadRequest, "Invalid preset number. Must be 1-256")
+		return 0, false
+	}
+	return number, true
+}
+
+// respondPTZPresetError maps preset errors to status codes
+func (h *Handler) respondPTZPresetError(w http.ResponseWriter, err error, cameraID, message string) {
+	switch {
+	case errors.Is(err, domain.ErrCameraNotFound):
+		respondError(w, http.StatusNotFound, "Camera not found")
+	case errors.Is(err, domain.ErrPTZPresetNotFound):
+		respondError(w, http.StatusNotFound, "PTZ preset not found")
+	case errors.Is(err, domain.ErrPTZPresetExists):
+		respondError(w, http.StatusConflict, "A preset with this number or name already exists")
+	case errors.Is(err, domain.ErrPTZNotSupported):
+		respondError(w, http.StatusBadRequest, "Camera does not support PTZ")
+	case errors.Is(err, domain.ErrPTZDiscoveryFailed), errors.Is(err, domain.ErrPTZCommandFailed):
+		h.logger.Warn().Err(err).Str("camera_id", cameraID).Msg(message)
+		respondError(w, http.StatusBadGateway, "Camera did not carry out the preset request")
+	default:
+		h.logger.Error().Err(err).Str("camera_id", cameraID).Msg(message)
+		respondError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -43,7 +43,24 @@ func NewRouter(handler *Handler, milestoneHandler *MilestoneHandler) *chi.Mux {
 			r.Get("/", handler.GetCameras)              // GET /vms/cameras (with optional ?source= filter)
 			r.Get("/{id}", handler.GetCameraByID)       // GET /vms/cameras/{id}
 			r.Get("/{id}/stream", handler.GetCameraStream) // GET /vms/cameras/{id}/stream
+			r.Get("/{id}/health", handler.GetCameraHealth) // GET /vms/cameras/{id}/health
+			r.Get("/{id}/alarms", handler.ListCameraAlarms)                // GET /vms/cameras/{id}/alarms
+			r.Get("/{id}/alarms/subscription", handler.GetAlarmSubscription) // GET /vms/cameras/{id}/alarms/subscription
 			r.Post("/{id}/ptz", handler.ExecutePTZ)     // POST /vms/cameras/{id}/ptz
+			r.Get("/{id}/ptz/capabilities", handler.GetPTZCapabilities) // GET /vms/cameras/{id}/ptz/capabilities
+			r.Get("/{id}/ptz/driver", handler.GetPTZDriver)                 // GET /vms/cameras/{id}/ptz/driver
+			r.Post("/{id}/ptz/driver/detect", handler.DetectPTZDriver)      // POST /vms/cameras/{id}/ptz/driver/detect
+			r.Get("/{id}/ptz/status", handler.GetPTZStatus)                 // GET /vms/cameras/{id}/ptz/status
+			r.Get("/{id}/ptz/field-of-view", handler.GetPTZFieldOfView)     // GET /vms/cameras/{id}/ptz/field-of-view
+			r.Put("/{id}/ptz/field-of-view", handler.SetPTZFieldOfView)     // PUT /vms/cameras/{id}/ptz/field-of-view
+			r.Get("/{id}/ptz/presets", handler.ListPTZPresets)              // GET /vms/cameras/{id}/ptz/presets
+			r.Post("/{id}/ptz/presets", handler.CreatePTZPreset)            // POST /vms/cameras/{id}/ptz/presets
+			r.Put("/{id}/ptz/presets/{number}", handler.UpdatePTZPreset)    // PUT /vms/cameras/{id}/ptz/presets/{number}
+			r.Delete("/{id}/ptz/presets/{number}", handler.DeletePTZPreset) // DELETE /vms/cameras/{id}/ptz/presets/{number}
+			r.Get("/{id}/ptz/patrol", handler.GetPTZPatrol)                 // GET /vms/cameras/{id}/ptz/patrol
+			r.Put("/{id}/ptz/patrol", handler.SavePTZPatrol)                // PUT /vms/cameras/{id}/ptz/patrol
+			r.Delete("/{id}/ptz/patrol", handler.DeletePTZPatrol)           // DELETE /vms/cameras/{id}/ptz/patrol
+			r.Get("/{id}/ptz/patrol/state", handler.GetPTZPatrolState)      // GET /vms/cameras/{id}/ptz/patrol/state
 		})
 
 		// Recording routes
@@ -53,6 +70,16 @@ func NewRouter(handler *Handler, milestoneHandler *MilestoneHandler) *chi.Mux {
 			r.Get("/export/{export_id}", handler.GetExportStatus)        // GET /vms/recordings/export/{export_id}
 		})
 
+		// Milestone routes, only when a Milestone server is configured
+		if milestoneHandler != nil {
+			r.Route("/milestone", func(r chi.Router) {
+				r.Get("/cameras", milestoneHandler.ListMilestoneCameras)    // GET /vms/milestone/cameras
+				r.Get("/cameras/{id}", milestoneHandler.GetMilestoneCamera) // GET /vms/milestone/cameras/{id}
+				r.Post("/sync-all", milestoneHandler.BulkSyncCameras)       // POST /vms/milestone/sync-all
+				r.Get("/sync/{job_id}", milestoneHandler.GetSyncJob)        // GET /vms/milestone/sync/{job_id}
+			})
+		}
+
 	})
 
 	return r
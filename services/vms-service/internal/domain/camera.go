@@ -1,6 +1,18 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrCameraNotFound is returned when a camera does not exist
+var ErrCameraNotFound = errors.New("camera not found")
+
+// ErrPTZDiscoveryFailed is returned when a camera's PTZ capabilities could not be discovered
+var ErrPTZDiscoveryFailed = errors.New("PTZ discovery failed")
+
+// ErrPTZNotSupported is returned for PTZ requests to a camera without PTZ
+var ErrPTZNotSupported = errors.New("camera does not support PTZ")
 
 // CameraSource represents the agency/source of the camera
 type CameraSource string
@@ -16,9 +28,17 @@ const (
 type CameraStatus string
 
 const (
-	StatusOnline  CameraStatus = "ONLINE"
-	StatusOffline CameraStatus = "OFFLINE"
-	StatusError   CameraStatus = "ERROR"
+	StatusOnline         CameraStatus = "ONLINE"
+	StatusOffline        CameraStatus = "OFFLINE"
+	StatusError          CameraStatus = "ERROR"
+	StatusDecommissioned CameraStatus = "DECOMMISSIONED" // Removed from Milestone
+)
+
+// Status reasons set by the Milestone sync; the health probe sets the failed check as reason
+const (
+	StatusReasonMilestoneDisabled = "disabled in Milestone"
+	StatusReasonRemoved           = "removed from Milestone"
+	StatusReasonAwaitingProbe     = "awaiting health probe"
 )
 
 // Camera represents a CCTV camera from Milestone VMS
@@ -30,6 +50,8 @@ type Camera struct {
 	RTSPURL           string                 `json:"rtsp_url"`
 	PTZEnabled        bool                   `json:"ptz_enabled"`
 	Status            CameraStatus           `json:"status"`
+	StatusReason      string                 `json:"status_reason,omitempty"`
+	StatusChangedAt   *time.Time             `json:"status_changed_at,omitempty"`
 	RecordingServer   string                 `json:"recording_server"`
 	MilestoneDeviceID string                 `json:"milestone_device_id"`
 	Metadata          map[string]interface{} `json:"metadata,omitempty"`
@@ -38,25 +60,84 @@ type Camera struct {
 }
 
 // PTZCapabilities represents PTZ control capabilities
+// Capabilities with Source "onvif" were discovered from the camera's PTZ service
 type PTZCapabilities struct {
-	Pan         bool    `json:"pan"`
-	Tilt        bool    `json:"tilt"`
-	Zoom        bool    `json:"zoom"`
-	Focus       bool    `json:"focus"`
-	MaxPanSpeed float64 `json:"max_pan_speed"`
-	MaxTiltSpeed float64 `json:"max_tilt_speed"`
-	MaxZoom     int     `json:"max_zoom"`
+	Pan           bool             `json:"pan"`
+	Tilt          bool             `json:"tilt"`
+	Zoom          bool             `json:"zoom"`
+	Focus         bool             `json:"focus"`
+	MaxPanSpeed   float64          `json:"max_pan_speed"`
+	MaxTiltSpeed  float64          `json:"max_tilt_speed"`
+	MaxZoom       int              `json:"max_zoom,omitempty"` // Optical zoom factor; ONVIF does not report it
+	Source        string           `json:"source,omitempty"`
+	ProfileToken  string           `json:"profile_token,omitempty"` // Media profile PTZ commands are sent with
+	Profiles      []PTZProfile     `json:"profiles,omitempty"`
+	Spaces        *PTZSpaces       `json:"spaces,omitempty"`
+	TimeoutRange  *PTZTimeoutRange `json:"timeout_range,omitempty"`
+	MaxPresets    int              `json:"max_presets,omitempty"`
+	HomeSupported bool             `json:"home_supported"`
+	DiscoveredAt  *time.Time       `json:"discovered_at,omitempty"`
+
+	ServiceURL       string `json:"-"` // ONVIF PTZ service
+	DeviceServiceURL string `json:"-"` // ONVIF device service the PTZ service was found through
+}
+
+// PTZ capability sources
+const (
+	PTZCapabilitiesSourceONVIF = "onvif"
+)
+
+// PTZProfile is an ONVIF media profile of a camera
+type PTZProfile struct {
+	Token                 string `json:"token"`
+	Name                  string `json:"name"`
+	PTZConfigurationToken string `json:"ptz_configuration_token,omitempty"` // Empty for profiles without PTZ
+	NodeToken             string `json:"node_token,omitempty"`
+}
+
+// PTZSpaces are the coordinate spaces a camera supports for each kind of move
+type PTZSpaces struct {
+	AbsolutePanTilt   []PTZSpace `json:"absolute_pan_tilt,omitempty"`
+	AbsoluteZoom      []PTZSpace `json:"absolute_zoom,omitempty"`
+	RelativePanTilt   []PTZSpace `json:"relative_pan_tilt,omitempty"`
+	RelativeZoom      []PTZSpace `json:"relative_zoom,omitempty"`
+	ContinuousPanTilt []PTZSpace `json:"continuous_pan_tilt,omitempty"`
+	ContinuousZoom    []PTZSpace `json:"continuous_zoom,omitempty"`
+	PanTiltSpeed      []PTZSpace `json:"pan_tilt_speed,omitempty"`
+	ZoomSpeed         []PTZSpace `json:"zoom_speed,omitempty"`
+}
+
+// PTZSpace is an ONVIF coordinate space; Y is nil for zoom and speed spaces
+type PTZSpace struct {
+	URI string    `json:"uri"`
+	X   PTZRange  `json:"x"`
+	Y   *PTZRange `json:"y,omitempty"`
+}
+
+// PTZRange is the range of one axis of a space
+type PTZRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// PTZTimeoutRange is the range of continuous move timeouts, as xs:duration
+type PTZTimeoutRange struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
 }
 
 // PTZCommand represents a PTZ control command
 type PTZCommand struct {
-	CameraID string      `json:"camera_id"`
-	Action   PTZAction   `json:"action"`
-	Pan      float64     `json:"pan,omitempty"`       // -1.0 to 1.0
-	Tilt     float64     `json:"tilt,omitempty"`      // -1.0 to 1.0
-	Zoom     float64     `json:"zoom,omitempty"`      // 0.0 to 1.0
-	Speed    float64     `json:"speed,omitempty"`     // 0.0 to 1.0
-	Preset   int         `json:"preset,omitempty"`    // Preset number
+	CameraID   string    `json:"camera_id"`
+	Action     PTZAction `json:"action"`
+	Pan        float64   `json:"pan,omitempty"`         // -1.0 to 1.0
+	Tilt       float64   `json:"tilt,omitempty"`        // -1.0 to 1.0
+	Zoom       float64   `json:"zoom,omitempty"`        // 0.0 to 1.0
+	Speed      float64   `json:"speed,omitempty"`       // 0.0 to 1.0
+	Preset     int       `json:"preset,omitempty"`      // Preset number
+	X          float64   `json:"x,omitempty"`           // CENTER_ON_POINT: 0.0 (left) to 1.0 (right)
+	Y          float64   `json:"y,omitempty"`           // CENTER_ON_POINT: 0.0 (top) to 1.0 (bottom)
+	ZoomFactor float64   `json:"zoom_factor,omitempty"` // CENTER_ON_POINT: magnification change, 1 or 0 keeps the zoom
 }
 
 // PTZAction represents PTZ command type
@@ -66,6 +147,10 @@ const (
 	PTZActionMove           PTZAction = "MOVE"
 	PTZActionStop           PTZAction = "STOP"
 	PTZActionGoToPreset     PTZAction = "GO_TO_PRESET"
+	PTZActionGoHome         PTZAction = "GO_HOME"
+	PTZActionAbsoluteMove   PTZAction = "ABSOLUTE_MOVE"   // Pan, Tilt and Zoom are a position
+	PTZActionRelativeMove   PTZAction = "RELATIVE_MOVE"   // Pan, Tilt and Zoom are a translation
+	PTZActionCenterOnPoint  PTZAction = "CENTER_ON_POINT" // X and Y are image coordinates to center
 	PTZActionSetPreset      PTZAction = "SET_PRESET"
 	PTZActionClearPreset    PTZAction = "CLEAR_PRESET"
 )
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+// ErrAlarmSubscriptionNotFound is returned for cameras without an ONVIF event subscription
+var ErrAlarmSubscriptionNotFound = errors.New("alarm subscription not found")
+
+// MaxCameraAlarms is the most alarms returned by one request
+const MaxCameraAlarms = 500
+
+// AlarmType is the kind of alarm an ONVIF event is normalized to
+type AlarmType string
+
+const (
+	AlarmMotion       AlarmType = "motion"
+	AlarmTamper       AlarmType = "tamper" // Tamper detection, scene change, blurred, too dark or too bright image
+	AlarmDigitalInput AlarmType = "digital_input"
+)
+
+// AlarmState is whether an alarm is raised
+type AlarmState string
+
+const (
+	AlarmActive   AlarmState = "active"
+	AlarmInactive AlarmState = "inactive"
+)
+
+// CameraAlarm is a change of an alarm raised by a camera
+type CameraAlarm struct {
+	ID         string     `json:"id"`
+	CameraID   string     `json:"camera_id"`
+	Type       AlarmType  `json:"type"`
+	State      AlarmState `json:"state"`
+	Source     string     `json:"source,omitempty"` // Video source or input token, when the event names one
+	Topic      string     `json:"topic"`
+	OccurredAt time.Time  `json:"occurred_at"` // Reported by the camera
+	ReceivedAt time.Time  `json:"received_at"`
+}
+
+// AlarmTarget is a camera to subscribe to the events of
+type AlarmTarget struct {
+	CameraID      string
+	DialURL       *url.URL // Carries decrypted credentials; never log it
+	ONVIFEndpoint string   // Device service; the one PTZ discovery found when none is configured
+}
+
+// Alarm subscription states
+const (
+	AlarmSubscriptionConnecting = "connecting"
+	AlarmSubscriptionSubscribed = "subscribed"
+	AlarmSubscriptionRetrying   = "retrying"
+)
+
+// AlarmSubscription is the ONVIF PullPoint subscription held for a camera
+type AlarmSubscription struct {
+	CameraID        string     `json:"camera_id"`
+	State           string     `json:"state"`
+	SubscribedAt    *time.Time `json:"subscribed_at,omitempty"`
+	TerminationTime *time.Time `json:"termination_time,omitempty"` // Renewed before it passes
+	LastEventAt     *time.Time `json:"last_event_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	RetryAt         *time.Time `json:"retry_at,omitempty"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"net/url"
+	"time"
+)
+
+// Health checks run by the camera health probe
+const (
+	HealthCheckTCP   = "tcp"   // Connect to the RTSP port
+	HealthCheckRTSP  = "rtsp"  // OPTIONS and DESCRIBE handshake
+	HealthCheckONVIF = "onvif" // GetSystemDateAndTime, when the camera has an ONVIF endpoint
+)
+
+// Sources of status transitions
+const (
+	StatusSourceProbe         = "probe"
+	StatusSourceMilestoneSync = "milestone_sync"
+)
+
+// HealthCheck is the result of one check of a probe
+type HealthCheck struct {
+	Name      string `json:"name"`
+	OK        bool   `json:"ok"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthProbe is the outcome of probing a camera once
+// Status is the status the camera should have, after the failure threshold is applied
+type HealthProbe struct {
+	Status    CameraStatus  `json:"status"`
+	Reason    string        `json:"reason,omitempty"`
+	Checks    []HealthCheck `json:"checks"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// ProbeTarget is a camera to probe
+type ProbeTarget struct {
+	CameraID      string
+	Status        CameraStatus
+	StatusReason  string
+	DialURL       *url.URL // Carries decrypted credentials; never log it
+	ONVIFEndpoint string
+}
+
+// StatusTransition is a change of camera status
+type StatusTransition struct {
+	Status         CameraStatus `json:"status"`
+	PreviousStatus CameraStatus `json:"previous_status,omitempty"`
+	Reason         string       `json:"reason,omitempty"`
+	Source         string       `json:"source"`
+	ChangedAt      time.Time    `json:"changed_at"`
+}
+
+// CameraHealth is the health of a camera over a window
+type CameraHealth struct {
+	CameraID        string             `json:"camera_id"`
+	Status          CameraStatus       `json:"status"`
+	StatusReason    string             `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time         `json:"status_changed_at,omitempty"`
+	LastProbe       *HealthProbe       `json:"last_probe,omitempty"`
+	Window          string             `json:"window"`
+	UptimePercent   *float64           `json:"uptime_percent"` // Share of the observed window spent ONLINE; nil without history
+	Transitions     []StatusTransition `json:"transitions"`    // Newest first
+}
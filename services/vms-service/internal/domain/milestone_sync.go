@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSyncJobNotFound is returned when a Milestone sync job does not exist
+var ErrSyncJobNotFound = errors.New("sync job not found")
+
+// ErrSyncInProgress is returned when a Milestone sync is started while another one runs
+var ErrSyncInProgress = errors.New("Milestone sync already in progress")
+
+// SyncTypeFull is the sync_type of a full camera sync in milestone_sync_history
+const SyncTypeFull = "full_sync"
+
+// Sync job statuses
+const (
+	SyncJobInProgress = "in_progress"
+	SyncJobCompleted  = "completed"
+	SyncJobFailed     = "failed"
+)
+
+// Actions taken on a camera by a sync
+const (
+	SyncActionCreated        = "created"
+	SyncActionUpdated        = "updated"
+	SyncActionDecommissioned = "decommissioned"
+	SyncActionFailed         = "failed"
+)
+
+// SyncFieldChange is one camera field changed by a sync
+type SyncFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// SyncCameraChange reports what a sync did to one camera
+type SyncCameraChange struct {
+	MilestoneDeviceID string            `json:"milestone_device_id"`
+	CameraID          string            `json:"camera_id,omitempty"`
+	Name              string            `json:"name"`
+	Action            string            `json:"action"`
+	Fields            []SyncFieldChange `json:"fields,omitempty"`
+	Error             string            `json:"error,omitempty"`
+}
+
+// SyncJob is one run of the Milestone camera sync
+// Unchanged cameras are counted but not listed in Changes
+type SyncJob struct {
+	ID                    string             `json:"id"`
+	Type                  string             `json:"type"`
+	Status                string             `json:"status"`
+	InitiatedBy           string             `json:"initiated_by"`
+	CamerasDiscovered     int                `json:"cameras_discovered"`
+	CamerasImported       int                `json:"cameras_imported"`
+	CamerasUpdated        int                `json:"cameras_updated"`
+	CamerasDecommissioned int                `json:"cameras_decommissioned"`
+	Errors                int                `json:"errors"`
+	Error                 string             `json:"error,omitempty"` // Why the job failed
+	Changes               []SyncCameraChange `json:"changes"`
+	StartedAt             time.Time          `json:"started_at"`
+	CompletedAt           *time.Time         `json:"completed_at,omitempty"`
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrPTZDriverNotFound is returned when no driver has been detected for a camera yet
+var ErrPTZDriverNotFound = errors.New("PTZ driver not found")
+
+// PTZ drivers, the protocols a camera is controlled with
+const (
+	PTZDriverONVIF     = "onvif"     // ONVIF PTZ service over SOAP
+	PTZDriverISAPI     = "isapi"     // Hikvision ISAPI
+	PTZDriverCGI       = "cgi"       // Generic /cgi-bin/ptz.cgi
+	PTZDriverMilestone = "milestone" // Through the Milestone XProtect server
+)
+
+// PTZDriverRecord is the driver detected for a camera
+type PTZDriverRecord struct {
+	CameraID            string    `json:"camera_id"`
+	Driver              string    `json:"driver,omitempty"` // Empty when no driver answered the last detection
+	DetectedAt          time.Time `json:"detected_at"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrPTZPatrolNotFound is returned when a camera has no patrol
+var ErrPTZPatrolNotFound = errors.New("PTZ patrol not found")
+
+// PTZ patrol limits and defaults
+const (
+	MaxPTZPatrolStops                  = 64
+	DefaultPTZPatrolDwellSeconds       = 30
+	DefaultPTZPatrolResumeAfterSeconds = 300
+)
+
+// PTZPatrol cycles a camera through presets in order, holding each for a dwell time
+type PTZPatrol struct {
+	CameraID           string             `json:"camera_id"`
+	Name               string             `json:"name,omitempty"`
+	Stops              []PTZPatrolStop    `json:"stops"`
+	DwellSeconds       int                `json:"dwell_seconds"`        // Hold at stops without their own dwell time
+	Speed              float64            `json:"speed,omitempty"`      // 0.0 to 1.0; 0 uses the camera's default
+	Schedule           *PTZPatrolSchedule `json:"schedule,omitempty"`   // Nil runs around the clock
+	ResumeAfterSeconds int                `json:"resume_after_seconds"` // Idle time after a manual command before resuming
+	Enabled            bool               `json:"enabled"`
+	CreatedBy          string             `json:"created_by,omitempty"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+// PTZPatrolStop is a preset a patrol visits
+type PTZPatrolStop struct {
+	Preset       int `json:"preset"`
+	DwellSeconds int `json:"dwell_seconds,omitempty"` // Overrides the patrol's dwell time
+}
+
+// PTZPatrolSchedule is the weekly window a patrol runs in
+// A window ending before it starts runs past midnight, and Days are the days it starts on.
+type PTZPatrolSchedule struct {
+	Days     []int  `json:"days,omitempty"`     // 0 (Sunday) to 6; empty for every day
+	Start    string `json:"start"`              // HH:MM
+	End      string `json:"end"`                // HH:MM; equal to Start for the whole day
+	Timezone string `json:"timezone,omitempty"` // IANA name; empty for the server's time zone
+}
+
+// PTZ patrol states
+const (
+	PTZPatrolRunning         = "running"          // Moving to or holding a stop
+	PTZPatrolPaused          = "paused"           // An operator is using the camera
+	PTZPatrolOutsideSchedule = "outside_schedule" // Waiting for the schedule window
+	PTZPatrolDisabled        = "disabled"
+)
+
+// PTZPatrolState is the progress of a camera's patrol
+type PTZPatrolState struct {
+	CameraID    string     `json:"camera_id"`
+	State       string     `json:"state"`
+	StopIndex   int        `json:"stop_index"`             // Stop last reached, from 0
+	Preset      int        `json:"preset,omitempty"`       // Preset of the stop last reached
+	ArrivedAt   *time.Time `json:"arrived_at,omitempty"`   // When the stop was reached
+	NextMoveAt  *time.Time `json:"next_move_at,omitempty"` // While running
+	PausedUntil *time.Time `json:"paused_until,omitempty"` // While paused; renewed by every manual command
+	LastError   string     `json:"last_error,omitempty"`   // Last failed move; cleared by the next move that succeeds
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// PTZPosition is a camera position in the ONVIF generic spaces
+// Pan and Tilt run from -1 to 1 and Zoom from 0 (wide) to 1 (tele).
+type PTZPosition struct {
+	Pan  float64  `json:"pan"`
+	Tilt float64  `json:"tilt"`
+	Zoom *float64 `json:"zoom,omitempty"` // Nil for cameras without zoom
+}
+
+// PTZStatus is the position and movement of a camera as reported by ONVIF GetStatus
+type PTZStatus struct {
+	CameraID      string       `json:"camera_id"`
+	Position      *PTZPosition `json:"position,omitempty"`        // Nil when the camera does not report it
+	PanTiltStatus string       `json:"pan_tilt_status,omitempty"` // IDLE, MOVING or UNKNOWN
+	ZoomStatus    string       `json:"zoom_status,omitempty"`
+	Error         string       `json:"error,omitempty"`
+	UTCTime       *time.Time   `json:"utc_time,omitempty"` // Camera clock
+	ReadAt        time.Time    `json:"read_at"`
+}
+
+// PTZFieldOfView describes the optics of a PTZ camera, which click-to-center converts image
+// coordinates with
+// ONVIF does not report them, so they are configured per camera model.
+type PTZFieldOfView struct {
+	HorizontalDegrees float64 `json:"horizontal_degrees"` // Horizontal field of view at the wide end
+	VerticalDegrees   float64 `json:"vertical_degrees"`   // Vertical field of view at the wide end
+	PanRangeDegrees   float64 `json:"pan_range_degrees"`  // Pan angle the generic space spans from -1 to 1
+	TiltRangeDegrees  float64 `json:"tilt_range_degrees"` // Tilt angle the generic space spans from -1 to 1
+	MaxZoom           float64 `json:"max_zoom"`           // Optical magnification at zoom position 1
+	Configured        bool    `json:"configured"`         // False when the defaults are in use
+}
+
+// DefaultPTZFieldOfView is used for cameras without a configured field of view
+// It matches a typical 1080p dome with a 20x lens.
+var DefaultPTZFieldOfView = PTZFieldOfView{
+	HorizontalDegrees: 60,
+	VerticalDegrees:   34,
+	PanRangeDegrees:   360,
+	TiltRangeDegrees:  180,
+	MaxZoom:           20,
+}
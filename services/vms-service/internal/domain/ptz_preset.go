@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrPTZPresetNotFound is returned when a camera has no preset with the requested number
+var ErrPTZPresetNotFound = errors.New("PTZ preset not found")
+
+// ErrPTZPresetExists is returned when a preset number or name is already used on the camera
+var ErrPTZPresetExists = errors.New("PTZ preset already exists")
+
+// ErrPTZCommandFailed is returned when the camera did not carry out a PTZ request
+var ErrPTZCommandFailed = errors.New("PTZ command failed")
+
+// MaxPTZPresetNumber is the highest preset number, matching GO_TO_PRESET validation
+const MaxPTZPresetNumber = 256
+
+// PTZPreset is a camera position saved under a number and a name
+// The position lives on the camera under Token; names are stored locally, since
+// cameras hold at most a short ASCII name.
+type PTZPreset struct {
+	CameraID  string    `json:"camera_id"`
+	Number    int       `json:"number"`
+	Token     string    `json:"token"` // ONVIF preset token
+	Name      string    `json:"name"`
+	NameAr    string    `json:"name_ar,omitempty"`
+	OnCamera  bool      `json:"on_camera"` // False once a refresh no longer found the token on the camera
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,70 @@
+// Package cache implements repository.CacheRepository in memory and on Valkey
+// Values are stored as JSON and returned as json.RawMessage by both, so a handler
+// behaves the same whichever cache the service runs with.
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/internal/repository"
+)
+
+// ErrCacheMiss is returned by Get for keys that are missing or expired
+var ErrCacheMiss = errors.New("cache miss")
+
+var (
+	cacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "vms_cache_hits_total",
+		Help: "Cache lookups that found a value",
+	})
+	cacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "vms_cache_misses_total",
+		Help: "Cache lookups that found no value",
+	})
+)
+
+// Keys of the cached camera responses
+const (
+	CamerasKey = "cameras:all"
+)
+
+// CameraKey is the key of a single camera
+func CameraKey(cameraID string) string {
+	return "camera:" + cameraID
+}
+
+// CamerasBySourceKey is the key of the cameras of one source
+func CamerasBySourceKey(source domain.CameraSource) string {
+	return "cameras:source:" + string(source)
+}
+
+// PTZCapabilitiesKey is the key of the PTZ capabilities of a camera
+func PTZCapabilitiesKey(cameraID string) string {
+	return "camera:" + cameraID + ":ptz"
+}
+
+// CameraKeys are the keys InvalidateCamera removes for a camera
+// go-api deletes the same keys from the Valkey cache when it changes cameras, and both
+// services check them against services/testdata/vms_camera_cache_keys.json.
+func CameraKeys(cameraID string) []string {
+	keys := []string{CameraKey(cameraID), PTZCapabilitiesKey(cameraID), CamerasKey}
+	for _, source := range []domain.CameraSource{domain.SourceDubaiPolice, domain.SourceMetro, domain.SourceBus, domain.SourceOther} {
+		keys = append(keys, CamerasBySourceKey(source))
+	}
+	return keys
+}
+
+// InvalidateCamera removes a changed camera, its PTZ capabilities and the camera lists it appears in
+func InvalidateCamera(ctx context.Context, c repository.CacheRepository, cameraID string) error {
+	for _, key := range CameraKeys(cameraID) {
+		if err := c.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+)
+
+// cameraKeysFile is shared with go-api, which deletes the same keys when it changes cameras
+const cameraKeysFile = "../../../../testdata/vms_camera_cache_keys.json"
+
+func TestCameraKeysSharedVectors(t *testing.T) {
+	data, err := os.ReadFile(cameraKeysFile)
+	if err != nil {
+		t.Fatalf("read vectors: %v", err)
+	}
+	var vectors struct {
+		Namespace string   `json:"namespace"`
+		CameraID  string   `json:"camera_id"`
+		Keys      []string `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("decode vectors: %v", err)
+	}
+
+	var keys []string
+	for _, key := range CameraKeys(vectors.CameraID) {
+		keys = append(keys, vectors.Namespace+key)
+	}
+	slices.Sort(keys)
+	want := slices.Clone(vectors.Keys)
+	slices.Sort(want)
+	if !slices.Equal(keys, want) {
+		t.Errorf("CameraKeys = %v, want %v", keys, want)
+	}
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache is a TTL cache local to the process
+// Each instance of the service has its own; run with Valkey to share invalidations.
+type MemoryCache struct {
+	mu         sync.RWMutex
+	items      map[string]memoryItem
+	defaultTTL time.Duration
+}
+
+// NewMemoryCache creates an in-memory cache
+// Entries set with a TTL of 0 live for defaultTTL. Expired entries are never returned and are
+// removed every cleanupInterval.
+func NewMemoryCache(defaultTTL, cleanupInterval time.Duration) *MemoryCache {
+	c := &MemoryCache{
+		items:      make(map[string]memoryItem),
+		defaultTTL: defaultTTL,
+	}
+
+	if cleanupInterval > 0 {
+		go c.cleanup(cleanupInterval)
+	}
+
+	return c
+}
+
+// Set stores value as JSON
+func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache value: %w", err)
+	}
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+
+	c.mu.Lock()
+	c.items[key] = memoryItem{value: data, expiresAt: time.Now().Add(ttl)}
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Get returns the JSON stored under key as json.RawMessage
+func (c *MemoryCache) Get(ctx context.Context, key string) (interface{}, error) {
+	c.mu.RLock()
+	item, ok := c.items[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(item.expiresAt) {
+		cacheMisses.Inc()
+		return nil, ErrCacheMiss
+	}
+
+	cacheHits.Inc()
+	return json.RawMessage(item.value), nil
+}
+
+// Delete removes key
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	delete(c.items, key)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Invalidate removes every entry
+func (c *MemoryCache) Invalidate(ctx context.Context) error {
+	c.mu.Lock()
+	c.items = make(map[string]memoryItem)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// cleanup removes expired entries every interval, for the life of the process
+func (c *MemoryCache) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+
+		c.mu.Lock()
+		for key, item := range c.items {
+			if now.After(item.expiresAt) {
+				delete(c.items, key)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultNamespace prefixes the keys vms-service caches in Valkey
+const DefaultNamespace = "vms:cache:"
+
+// invalidateBatch is the number of keys scanned and removed at a time by Invalidate
+const invalidateBatch = 500
+
+// ValkeyCache is a TTL cache in Valkey, shared by every instance of the service
+// Keys are prefixed with a namespace, so the cache can share a database with the camera events
+// stream and Invalidate removes only the cache.
+type ValkeyCache struct {
+	client     *redis.Client
+	namespace  string
+	defaultTTL time.Duration
+}
+
+// NewValkeyCache creates a Valkey cache; entries set with a TTL of 0 live for defaultTTL
+func NewValkeyCache(client *redis.Client, namespace string, defaultTTL time.Duration) *ValkeyCache {
+	return &ValkeyCache{
+		client:     client,
+		namespace:  namespace,
+		defaultTTL: defaultTTL,
+	}
+}
+
+// Set stores value as JSON
+func (c *ValkeyCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache value: %w", err)
+	}
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+
+	if err := c.client.Set(ctx, c.namespace+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set cache key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get returns the JSON stored under key as json.RawMessage
+func (c *ValkeyCache) Get(ctx context.Context, key string) (interface{}, error) {
+	data, err := c.client.Get(ctx, c.namespace+key).Bytes()
+	if err == redis.Nil {
+		cacheMisses.Inc()
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		cacheMisses.Inc()
+		return nil, fmt.Errorf("failed to get cache key %s: %w", key, err)
+	}
+
+	cacheHits.Inc()
+	return json.RawMessage(data), nil
+}
+
+// Delete removes key
+func (c *ValkeyCache) Delete(ctx context.Context, key string) error {
+	if err := c.client.Del(ctx, c.namespace+key).Err(); err != nil {
+		return fmt.Errorf("failed to delete cache key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Invalidate removes every key in the namespace
+func (c *ValkeyCache) Invalidate(ctx context.Context) error {
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, c.namespace+"*", invalidateBatch).Result()
+		if err != nil {
+			return fmt.Errorf("failed to scan cache: %w", err)
+		}
+
+		if len(keys) > 0 {
+			if err := c.client.Unlink(ctx, keys...).Err(); err != nil {
+				return fmt.Errorf("failed to invalidate cache: %w", err)
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
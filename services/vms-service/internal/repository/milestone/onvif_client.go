@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/rta/cctv/vms-service/internal/client"
 	"github.com/use-go/onvif"
+	"github.com/use-go/onvif/media"
 	"github.com/use-go/onvif/ptz"
+	xsdonvif "github.com/use-go/onvif/xsd/onvif"
 )
 
+// ONVIFClient sends PTZ commands over ONVIF with the camera's PTZ profile
 type ONVIFClient struct {
-	device *onvif.Device
+	device       *onvif.Device
+	profileToken string
 }
 
 // NewONVIFClient creates a new ONVIF client from RTSP URL
@@ -38,19 +43,31 @@ func NewONVIFClient(rtspURL string) (*ONVIFClient, error) {
 		return nil, fmt.Errorf("failed to create ONVIF device: %w", err)
 	}
 
-	return &ONVIFClient{device: device}, nil
+	// Commands go to the first media profile with a PTZ configuration
+	resp, err := device.CallMethod(media.GetProfiles{})
+	if err != nil {
+		return nil, fmt.Errorf("ONVIF get profiles failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	profileToken, err := client.ParsePTZProfileToken(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover PTZ profile: %w", err)
+	}
+
+	return &ONVIFClient{device: device, profileToken: profileToken}, nil
 }
 
 // ContinuousMove sends continuous PTZ movement command
 func (c *ONVIFClient) ContinuousMove(pan, tilt, zoom float64) error {
 	request := ptz.ContinuousMove{
-		ProfileToken: "profile_1", // Usually profile_1, may need to query
-		Velocity: ptz.PTZSpeed{
-			PanTilt: ptz.Vector2D{
+		ProfileToken: xsdonvif.ReferenceToken(c.profileToken),
+		Velocity: xsdonvif.PTZSpeed{
+			PanTilt: xsdonvif.Vector2D{
 				X: pan,
 				Y: tilt,
 			},
-			Zoom: ptz.Vector1D{
+			Zoom: xsdonvif.Vector1D{
 				X: zoom,
 			},
 		},
@@ -66,7 +83,7 @@ func (c *ONVIFClient) ContinuousMove(pan, tilt, zoom float64) error {
 // Stop sends PTZ stop command
 func (c *ONVIFClient) Stop() error {
 	request := ptz.Stop{
-		ProfileToken: "profile_1",
+		ProfileToken: xsdonvif.ReferenceToken(c.profileToken),
 		PanTilt:      true,
 		Zoom:         true,
 	}
@@ -81,7 +98,7 @@ func (c *ONVIFClient) Stop() error {
 // GotoHomePosition sends camera to home position
 func (c *ONVIFClient) GotoHomePosition() error {
 	request := ptz.GotoHomePosition{
-		ProfileToken: "profile_1",
+		ProfileToken: xsdonvif.ReferenceToken(c.profileToken),
 	}
 
 	_, err := c.device.CallMethod(request)
@@ -94,8 +111,8 @@ func (c *ONVIFClient) GotoHomePosition() error {
 // GotoPreset moves camera to a preset position
 func (c *ONVIFClient) GotoPreset(presetToken string) error {
 	request := ptz.GotoPreset{
-		ProfileToken: "profile_1",
-		PresetToken:  presetToken,
+		ProfileToken: xsdonvif.ReferenceToken(c.profileToken),
+		PresetToken:  xsdonvif.ReferenceToken(presetToken),
 	}
 
 	_, err := c.device.CallMethod(request)
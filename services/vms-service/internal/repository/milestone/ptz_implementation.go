@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/rta/cctv/vms-service/internal/domain"
 )
@@ -87,7 +88,7 @@ func (r *MilestoneRepository) validatePTZCommand(cmd *domain.PTZCommand) error {
 		if cmd.Preset < 1 || cmd.Preset > 256 {
 			return fmt.Errorf("invalid preset number: %d (must be 1-256)", cmd.Preset)
 		}
-	case domain.PTZActionStop:
+	case domain.PTZActionStop, domain.PTZActionGoHome:
 		// No validation needed
 	default:
 		return fmt.Errorf("unsupported PTZ action: %s", cmd.Action)
@@ -119,11 +120,11 @@ func (r *MilestoneRepository) executePTZViaONVIF(camera *domain.Camera, cmd *dom
 	case domain.PTZActionStop:
 		return client.Stop()
 
+	case domain.PTZActionGoHome:
+		return client.GotoHomePosition()
+
 	case domain.PTZActionGoToPreset:
-		if cmd.Preset == 1 {
-			return client.GotoHomePosition()
-		}
-		return client.GotoPreset(fmt.Sprintf("preset_%d", cmd.Preset))
+		return client.GotoPreset(strconv.Itoa(cmd.Preset))
 
 	default:
 		return fmt.Errorf("unsupported PTZ action for ONVIF: %s", cmd.Action)
@@ -165,12 +166,11 @@ func (r *MilestoneRepository) executePTZViaRTSP(camera *domain.Camera, cmd *doma
 	case domain.PTZActionStop:
 		ptzURL = fmt.Sprintf("http://%s/cgi-bin/ptz.cgi?action=stop", u.Host)
 
+	case domain.PTZActionGoHome:
+		ptzURL = fmt.Sprintf("http://%s/cgi-bin/ptz.cgi?action=gohome", u.Host)
+
 	case domain.PTZActionGoToPreset:
-		if cmd.Preset == 1 {
-			ptzURL = fmt.Sprintf("http://%s/cgi-bin/ptz.cgi?action=gohome", u.Host)
-		} else {
-			ptzURL = fmt.Sprintf("http://%s/cgi-bin/ptz.cgi?action=preset&id=%d", u.Host, cmd.Preset)
-		}
+		ptzURL = fmt.Sprintf("http://%s/cgi-bin/ptz.cgi?action=preset&id=%d", u.Host, cmd.Preset)
 
 	default:
 		return fmt.Errorf("unsupported PTZ action for RTSP: %s", cmd.Action)
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// ListAlarmTargets retrieves the ONLINE cameras to subscribe to the events of
+// Cameras without an ONVIF endpoint use the device service PTZ discovery found, or are probed
+// on the usual ports by the subscriber.
+func (r *PostgresRepository) ListAlarmTargets(ctx context.Context) ([]*domain.AlarmTarget, error) {
+	query := `
+		SELECT c.id, c.rtsp_url, COALESCE(c.onvif_username, ''), COALESCE(c.onvif_password_encrypted, ''),
+		       COALESCE(NULLIF(c.onvif_endpoint, ''), p.device_service_url, '')
+		FROM cameras c
+		LEFT JOIN camera_ptz_capabilities p ON p.camera_id = c.id
+		WHERE c.status = $1
+		ORDER BY c.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, string(domain.StatusOnline))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alarm targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets := make([]*domain.AlarmTarget, 0)
+	for rows.Next() {
+		target := &domain.AlarmTarget{}
+		var rtspURL, username, password string
+
+		if err := rows.Scan(&target.CameraID, &rtspURL, &username, &password, &target.ONVIFEndpoint); err != nil {
+			return nil, fmt.Errorf("failed to scan alarm target: %w", err)
+		}
+
+		target.DialURL, err = r.dialURL(target.CameraID, rtspURL, username, password)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("camera_id", target.CameraID).Msg("Skipping alarm subscription of camera")
+			continue
+		}
+
+		targets = append(targets, target)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alarm targets: %w", err)
+	}
+
+	return targets, nil
+}
+
+// RecordCameraAlarm stores an alarm
+func (r *PostgresRepository) RecordCameraAlarm(ctx context.Context, alarm *domain.CameraAlarm) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO camera_alarms (id, camera_id, type, state, source, topic, occurred_at, received_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+	`, alarm.ID, alarm.CameraID, string(alarm.Type), string(alarm.State), alarm.Source, alarm.Topic, alarm.OccurredAt, alarm.ReceivedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record alarm of camera %s: %w", alarm.CameraID, err)
+	}
+
+	return nil
+}
+
+// ListCameraAlarms retrieves the alarms of a camera that occurred since a time, newest first
+func (r *PostgresRepository) ListCameraAlarms(ctx context.Context, cameraID string, alarmType domain.AlarmType, since time.Time, limit int) ([]domain.CameraAlarm, error) {
+	if err := r.ensureCamera(ctx, cameraID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, camera_id, type, state, COALESCE(source, ''), topic, occurred_at, received_at
+		FROM camera_alarms
+		WHERE camera_id = $1 AND occurred_at >= $2 AND ($3 = '' OR type = $3)
+		ORDER BY occurred_at DESC
+		LIMIT $4
+	`, cameraID, since, string(alarmType), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query camera alarms: %w", err)
+	}
+	defer rows.Close()
+
+	alarms := []domain.CameraAlarm{}
+	for rows.Next() {
+		var alarm domain.CameraAlarm
+		err := rows.Scan(&alarm.ID, &alarm.CameraID, &alarm.Type, &alarm.State, &alarm.Source, &alarm.Topic, &alarm.OccurredAt, &alarm.ReceivedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan camera alarm: %w", err)
+		}
+		alarms = append(alarms, alarm)
+	}
+
+	return alarms, rows.Err()
+}
+
+// DeleteCameraAlarms removes alarms received before cutoff
+func (r *PostgresRepository) DeleteCameraAlarms(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM camera_alarms WHERE received_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete camera alarms: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/pkg/cameraevents"
+)
+
+// ListProbeTargets retrieves the cameras the health probe should check
+// Decommissioned cameras and cameras disabled in Milestone are skipped.
+func (r *PostgresRepository) ListProbeTargets(ctx context.Context) ([]*domain.ProbeTarget, error) {
+	query := `
+		SELECT id, status, COALESCE(status_reason, ''), rtsp_url, COALESCE(onvif_username, ''),
+		       COALESCE(onvif_password_encrypted, ''), COALESCE(onvif_endpoint, '')
+		FROM cameras
+		WHERE status <> $1 AND COALESCE(status_reason, '') <> $2
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, string(domain.StatusDecommissioned), domain.StatusReasonMilestoneDisabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query probe targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets := make([]*domain.ProbeTarget, 0)
+	for rows.Next() {
+		target := &domain.ProbeTarget{}
+		var rtspURL, username, password string
+
+		err := rows.Scan(&target.CameraID, &target.Status, &target.StatusReason, &rtspURL, &username, &password, &target.ONVIFEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan probe target: %w", err)
+		}
+
+		target.DialURL, err = r.dialURL(target.CameraID, rtspURL, username, password)
+		if err != nil {
+			// Probed without credentials; the RTSP check reports the camera unreachable or refusing
+			r.logger.Warn().Err(err).Str("camera_id", target.CameraID).Msg("Probing camera without credentials")
+			target.DialURL, _ = r.dialURL(target.CameraID, rtspURL, "", "")
+		}
+		if target.DialURL == nil {
+			r.logger.Warn().Str("camera_id", target.CameraID).Msg("Skipping camera with unparseable RTSP URL")
+			continue
+		}
+
+		targets = append(targets, target)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating probe targets: %w", err)
+	}
+
+	return targets, nil
+}
+
+// RecordProbe stores the result of a probe
+// When the probe changes the camera's status, the transition and a camera.status_changed event
+// are written in the same transaction. Cameras whose status changed since the probe
+// started, or that Milestone disabled meanwhile, keep it; only the probe result is stored.
+func (r *PostgresRepository) RecordProbe(ctx context.Context, target *domain.ProbeTarget, probe *domain.HealthProbe) error {
+	probeJSON, err := json.Marshal(probe)
+	if err != nil {
+		return fmt.Errorf("failed to marshal probe: %w", err)
+	}
+
+	if probe.Status == target.Status {
+		return r.storeProbe(ctx, target.CameraID, probe, probeJSON)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	camera := &domain.Camera{ID: target.CameraID, Status: probe.Status, StatusReason: probe.Reason}
+	var recordingServer, milestoneDeviceID sql.NullString
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE cameras
+		SET status = $3, status_reason = NULLIF($4, ''), status_changed_at = NOW(),
+		    last_probe_at = $5, last_probe = $6, last_update = NOW()
+		WHERE id = $1 AND status = $2 AND COALESCE(status_reason, '') <> $7
+		RETURNING name, source, ptz_enabled, recording_server, milestone_device_id
+	`,
+		target.CameraID,
+		string(target.Status),
+		string(probe.Status),
+		probe.Reason,
+		probe.CheckedAt,
+		probeJSON,
+		domain.StatusReasonMilestoneDisabled,
+	).Scan(&camera.Name, &camera.Source, &camera.PTZEnabled, &recordingServer, &milestoneDeviceID)
+	if err == sql.ErrNoRows {
+		return r.storeProbe(ctx, target.CameraID, probe, probeJSON)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update status of camera %s: %w", target.CameraID, err)
+	}
+	camera.RecordingServer = recordingServer.String
+	camera.MilestoneDeviceID = milestoneDeviceID.String
+
+	if err := insertStatusTransition(ctx, tx, camera.ID, target.Status, probe.Status, probe.Reason, domain.StatusSourceProbe); err != nil {
+		return err
+	}
+
+	change := cameraevents.Change{Field: "status", Old: string(target.Status), New: string(probe.Status)}
+	event := cameraevents.New(cameraevents.TypeStatusChanged, eventCamera(camera), []cameraevents.Change{change})
+	if err := insertCameraEvents(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit probe of camera %s: %w", target.CameraID, err)
+	}
+	r.invalidateCamera(ctx, target.CameraID)
+
+	return nil
+}
+
+// storeProbe stores a probe that leaves the status unchanged
+// The reason follows the probe while the camera still has the probed status.
+func (r *PostgresRepository) storeProbe(ctx context.Context, cameraID string, probe *domain.HealthProbe, probeJSON []byte) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE cameras
+		SET status_reason = CASE WHEN status = $2 AND COALESCE(status_reason, '') <> $6
+		                         THEN NULLIF($3, '') ELSE status_reason END,
+		    last_probe_at = $4, last_probe = $5
+		WHERE id = $1
+	`, cameraID, string(probe.Status), probe.Reason, probe.CheckedAt, probeJSON, domain.StatusReasonMilestoneDisabled)
+	if err != nil {
+		return fmt.Errorf("failed to record probe of camera %s: %w", cameraID, err)
+	}
+
+	return nil
+}
+
+// GetCameraHealth retrieves the status and last probe of a camera
+func (r *PostgresRepository) GetCameraHealth(ctx context.Context, cameraID string) (*domain.CameraHealth, error) {
+	health := &domain.CameraHealth{CameraID: cameraID}
+	var statusChangedAt sql.NullTime
+	var lastProbe []byte
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT status, COALESCE(status_reason, ''), status_changed_at, last_probe
+		FROM cameras
+		WHERE id = $1
+	`, cameraID).Scan(&health.Status, &health.StatusReason, &statusChangedAt, &lastProbe)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrCameraNotFound, cameraID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera health: %w", err)
+	}
+
+	if statusChangedAt.Valid {
+		health.StatusChangedAt = &statusChangedAt.Time
+	}
+
+	if len(lastProbe) > 0 {
+		var probe domain.HealthProbe
+		if err := json.Unmarshal(lastProbe, &probe); err != nil {
+			r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to unmarshal last probe")
+		} else {
+			health.LastProbe = &probe
+		}
+	}
+
+	return health, nil
+}
+
+// ListStatusTransitions retrieves the transitions of a camera since a time, newest first,
+// followed by the last transition before it, which gives the status at the start
+func (r *PostgresRepository) ListStatusTransitions(ctx context.Context, cameraID string, since time.Time) ([]domain.StatusTransition, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		(
+			SELECT status, COALESCE(previous_status, ''), COALESCE(reason, ''), source, changed_at
+			FROM camera_status_history
+			WHERE camera_id = $1 AND changed_at >= $2
+		)
+		UNION ALL
+		(
+			SELECT status, COALESCE(previous_status, ''), COALESCE(reason, ''), source, changed_at
+			FROM camera_status_history
+			WHERE camera_id = $1 AND changed_at < $2
+			ORDER BY changed_at DESC
+			LIMIT 1
+		)
+		ORDER BY changed_at DESC
+	`, cameraID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status transitions: %w", err)
+	}
+	defer rows.Close()
+
+	transitions := []domain.StatusTransition{}
+	for rows.Next() {
+		var transition domain.StatusTransition
+		err := rows.Scan(&transition.Status, &transition.PreviousStatus, &transition.Reason, &transition.Source, &transition.ChangedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status transition: %w", err)
+		}
+		transitions = append(transitions, transition)
+	}
+
+	return transitions, rows.Err()
+}
+
+// insertStatusTransition writes a status change to the history inside the transaction of the change
+// previous is empty for the first status of a camera
+func insertStatusTransition(ctx context.Context, tx *sql.Tx, cameraID string, previous, status domain.CameraStatus, reason, source string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO camera_status_history (camera_id, status, previous_status, reason, source)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
+	`, cameraID, string(status), string(previous), reason, source)
+	if err != nil {
+		return fmt.Errorf("failed to record status transition of camera %s: %w", cameraID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/internal/secrets"
+)
+
+// cameraDialURL returns the RTSP URL of a camera with its decrypted credentials
+// Only the code that connects to the camera may use it; never log or return it
+func (r *PostgresRepository) cameraDialURL(ctx context.Context, cameraID string) (*url.URL, error) {
+	var rtspURL string
+	var username, password sql.NullString
+
+	err := r.db.QueryRowContext(ctx,
+		"SELECT rtsp_url, onvif_username, onvif_password_encrypted FROM cameras WHERE id = $1",
+		cameraID,
+	).Scan(&rtspURL, &username, &password)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrCameraNotFound, cameraID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get camera credentials: %w", err)
+	}
+
+	return r.dialURL(cameraID, rtspURL, username.String, password.String)
+}
+
+// dialURL combines a stored RTSP URL with the camera's credentials, decrypting the password
+func (r *PostgresRepository) dialURL(cameraID, rtspURL, username, password string) (*url.URL, error) {
+	cleanURL, urlUser, urlPassword := secrets.SplitURLCredentials(rtspURL)
+	u, err := url.Parse(cleanURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse camera URL: %w", err)
+	}
+
+	plain := password
+	if secrets.IsEncrypted(plain) {
+		plain, err = r.envelope.Decrypt(plain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt credentials of camera %s: %w", cameraID, err)
+		}
+	}
+
+	// Credentials not yet sealed by go-api are still embedded in the URL
+	user := username
+	if plain == "" && urlPassword != "" {
+		user, plain = urlUser, urlPassword
+	}
+
+	if user != "" {
+		u.User = url.UserPassword(user, plain)
+	}
+	return u, nil
+}
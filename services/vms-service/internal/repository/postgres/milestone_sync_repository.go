@@ -0,0 +1,367 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/internal/secrets"
+	"github.com/rta/cctv/vms-service/pkg/cameraevents"
+)
+
+// GetMilestoneCameras retrieves every camera linked to a Milestone device, decommissioned ones included
+func (r *PostgresRepository) GetMilestoneCameras(ctx context.Context) ([]*domain.Camera, error) {
+	query := `
+		SELECT id, name, COALESCE(name_ar, ''), source, rtsp_url, ptz_enabled, status,
+		       COALESCE(recording_server, ''), milestone_device_id, metadata, last_update, created_at,
+		       COALESCE(status_reason, ''), status_changed_at
+		FROM cameras
+		WHERE milestone_device_id IS NOT NULL AND milestone_device_id <> ''
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Milestone cameras: %w", err)
+	}
+	defer rows.Close()
+
+	// A camera that cannot be scanned would be missed by the diff and re-created, so fail instead
+	cameras := make([]*domain.Camera, 0)
+	for rows.Next() {
+		camera, err := r.scanCamera(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan Milestone camera: %w", err)
+		}
+		cameras = append(cameras, camera)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating Milestone cameras: %w", err)
+	}
+
+	return cameras, nil
+}
+
+// CreateSyncedCamera inserts a camera discovered in Milestone and records a camera.added event
+// Credentials in streamURL are split off and encrypted before storage
+func (r *PostgresRepository) CreateSyncedCamera(ctx context.Context, camera *domain.Camera, streamURL string) error {
+	cleanURL, username, password := secrets.SplitURLCredentials(streamURL)
+
+	var sealedPassword sql.NullString
+	if password != "" {
+		sealed, err := r.envelope.Encrypt(password)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt credentials of camera %s: %w", camera.ID, err)
+		}
+		sealedPassword = sql.NullString{String: sealed, Valid: true}
+	}
+
+	metadataJSON, err := json.Marshal(camera.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO cameras (
+			id, name, name_ar, source, rtsp_url, ptz_enabled, status, recording_server,
+			milestone_device_id, milestone_server, metadata, onvif_username,
+			onvif_password_encrypted, status_reason, status_changed_at, last_milestone_sync,
+			created_at, last_update
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $8, $10, $11, $12, NULLIF($13, ''), NOW(), NOW(), NOW(), NOW()
+		)
+		RETURNING created_at, last_update
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		camera.ID,
+		camera.Name,
+		camera.NameAr,
+		string(camera.Source),
+		cleanURL,
+		camera.PTZEnabled,
+		string(camera.Status),
+		camera.RecordingServer,
+		camera.MilestoneDeviceID,
+		metadataJSON,
+		sql.NullString{String: username, Valid: username != ""},
+		sealedPassword,
+		camera.StatusReason,
+	).Scan(&camera.CreatedAt, &camera.LastUpdate)
+	if err != nil {
+		return fmt.Errorf("failed to insert camera %s: %w", camera.ID, err)
+	}
+	camera.StatusChangedAt = &camera.CreatedAt
+
+	err = insertStatusTransition(ctx, tx, camera.ID, "", camera.Status, camera.StatusReason, domain.StatusSourceMilestoneSync)
+	if err != nil {
+		return err
+	}
+
+	if err := insertCameraEvents(ctx, tx, cameraevents.New(cameraevents.TypeAdded, eventCamera(camera), nil)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit camera %s: %w", camera.ID, err)
+	}
+	r.invalidateCamera(ctx, camera.ID)
+
+	camera.RTSPURL = secrets.MaskURL(streamURL)
+	return nil
+}
+
+// UpdateSyncedCamera writes the name, status and recording server of a synced camera
+// and records events for the changed fields
+// The status and reason are written only when the sync changed them, so a concurrent
+// probe result is not overwritten with the status read before it.
+func (r *PostgresRepository) UpdateSyncedCamera(ctx context.Context, camera *domain.Camera, fields []domain.SyncFieldChange) error {
+	var previous *domain.SyncFieldChange
+	statusSynced := false
+	for i, field := range fields {
+		switch field.Field {
+		case "status":
+			previous = &fields[i]
+			statusSynced = true
+		case "status_reason":
+			statusSynced = true
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE cameras
+		SET name = $2, recording_server = $3, milestone_server = $3,
+		    status = CASE WHEN $4 THEN $5 ELSE status END,
+		    status_reason = CASE WHEN $4 THEN NULLIF($6, '') ELSE status_reason END,
+		    status_changed_at = CASE WHEN $7 THEN NOW() ELSE status_changed_at END,
+		    last_milestone_sync = NOW(), last_update = NOW()
+		WHERE id = $1
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		camera.ID,
+		camera.Name,
+		camera.RecordingServer,
+		statusSynced,
+		string(camera.Status),
+		camera.StatusReason,
+		previous != nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update camera %s: %w", camera.ID, err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("camera not found: %s", camera.ID)
+	}
+
+	if previous != nil {
+		err := insertStatusTransition(ctx, tx, camera.ID, domain.CameraStatus(previous.Old), camera.Status, camera.StatusReason, domain.StatusSourceMilestoneSync)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := insertCameraEvents(ctx, tx, syncChangeEvents(camera, fields)...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit camera %s: %w", camera.ID, err)
+	}
+	r.invalidateCamera(ctx, camera.ID)
+
+	return nil
+}
+
+// DecommissionCamera marks a camera removed from Milestone as DECOMMISSIONED
+// and records the transition and a camera.decommissioned event
+func (r *PostgresRepository) DecommissionCamera(ctx context.Context, camera *domain.Camera) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE cameras
+		SET status = $2, status_reason = $3, status_changed_at = NOW(), last_update = NOW()
+		WHERE id = $1
+	`, camera.ID, string(domain.StatusDecommissioned), domain.StatusReasonRemoved)
+	if err != nil {
+		return fmt.Errorf("failed to decommission camera %s: %w", camera.ID, err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("camera not found: %s", camera.ID)
+	}
+
+	err = insertStatusTransition(ctx, tx, camera.ID, camera.Status, domain.StatusDecommissioned, domain.StatusReasonRemoved, domain.StatusSourceMilestoneSync)
+	if err != nil {
+		return err
+	}
+
+	change := cameraevents.Change{Field: "status", Old: string(camera.Status), New: string(domain.StatusDecommissioned)}
+	camera.Status = domain.StatusDecommissioned
+	camera.StatusReason = domain.StatusReasonRemoved
+
+	event := cameraevents.New(cameraevents.TypeDecommissioned, eventCamera(camera), []cameraevents.Change{change})
+	if err := insertCameraEvents(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit camera %s: %w", camera.ID, err)
+	}
+	r.invalidateCamera(ctx, camera.ID)
+
+	return nil
+}
+
+// MarkSynced records that the cameras linked to deviceIDs were seen in Milestone
+func (r *PostgresRepository) MarkSynced(ctx context.Context, deviceIDs []string) error {
+	if len(deviceIDs) == 0 {
+		return nil
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE cameras SET last_milestone_sync = NOW() WHERE milestone_device_id = ANY($1)",
+		pq.Array(deviceIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark cameras synced: %w", err)
+	}
+
+	return nil
+}
+
+// CreateSyncJob records the start of a sync job and sets its ID
+func (r *PostgresRepository) CreateSyncJob(ctx context.Context, job *domain.SyncJob) error {
+	query := `
+		INSERT INTO milestone_sync_history (sync_type, status, initiated_by, started_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query, job.Type, job.Status, job.InitiatedBy, job.StartedAt).Scan(&job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create sync job: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteSyncJob records the outcome and change report of a sync job
+func (r *PostgresRepository) CompleteSyncJob(ctx context.Context, job *domain.SyncJob) error {
+	changesJSON, err := json.Marshal(job.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync changes: %w", err)
+	}
+
+	var errorDetails []byte
+	if job.Error != "" {
+		errorDetails, err = json.Marshal(map[string]string{"error": job.Error})
+		if err != nil {
+			return fmt.Errorf("failed to marshal sync error: %w", err)
+		}
+	}
+
+	query := `
+		UPDATE milestone_sync_history
+		SET status = $2, cameras_discovered = $3, cameras_imported = $4, cameras_updated = $5,
+		    cameras_decommissioned = $6, errors = $7, error_details = $8, changes = $9,
+		    completed_at = $10
+		WHERE id = $1
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		job.ID,
+		job.Status,
+		job.CamerasDiscovered,
+		job.CamerasImported,
+		job.CamerasUpdated,
+		job.CamerasDecommissioned,
+		job.Errors,
+		errorDetails,
+		changesJSON,
+		job.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete sync job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// GetSyncJob retrieves a sync job with its change report
+func (r *PostgresRepository) GetSyncJob(ctx context.Context, id string) (*domain.SyncJob, error) {
+	query := `
+		SELECT id, sync_type, status, COALESCE(initiated_by, ''), COALESCE(cameras_discovered, 0),
+		       COALESCE(cameras_imported, 0), COALESCE(cameras_updated, 0),
+		       COALESCE(cameras_decommissioned, 0), COALESCE(errors, 0), error_details, changes,
+		       started_at, completed_at
+		FROM milestone_sync_history
+		WHERE id = $1
+	`
+
+	job := &domain.SyncJob{}
+	var errorDetails, changesJSON []byte
+	var completedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&job.ID,
+		&job.Type,
+		&job.Status,
+		&job.InitiatedBy,
+		&job.CamerasDiscovered,
+		&job.CamerasImported,
+		&job.CamerasUpdated,
+		&job.CamerasDecommissioned,
+		&job.Errors,
+		&errorDetails,
+		&changesJSON,
+		&job.StartedAt,
+		&completedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrSyncJobNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync job: %w", err)
+	}
+
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+
+	if len(errorDetails) > 0 {
+		var details map[string]interface{}
+		if err := json.Unmarshal(errorDetails, &details); err == nil {
+			job.Error, _ = details["error"].(string)
+		}
+	}
+
+	job.Changes = []domain.SyncCameraChange{}
+	if len(changesJSON) > 0 {
+		if err := json.Unmarshal(changesJSON, &job.Changes); err != nil {
+			r.logger.Warn().Err(err).Str("job_id", id).Msg("Failed to unmarshal sync changes")
+		}
+	}
+
+	return job, nil
+}
@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/pkg/cameraevents"
+	"github.com/rs/zerolog"
+)
+
+// OutboxRepository implements repository.CameraEventOutbox using PostgreSQL
+type OutboxRepository struct {
+	db     *sql.DB
+	logger zerolog.Logger
+}
+
+// NewOutboxRepository creates a new PostgreSQL camera event outbox
+func NewOutboxRepository(db *sql.DB, logger zerolog.Logger) *OutboxRepository {
+	return &OutboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// PublishPending passes up to limit unpublished events, oldest first, to publish and marks
+// them published once it returns nil
+// The rows stay locked until then, so concurrent relays publish disjoint batches.
+func (r *OutboxRepository) PublishPending(ctx context.Context, limit int, publish func(ctx context.Context, events []*cameraevents.Event) error) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT seq, payload
+		FROM camera_events_outbox
+		WHERE published_at IS NULL
+		ORDER BY seq
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query camera events outbox: %w", err)
+	}
+
+	seqs := []int64{}
+	events := []*cameraevents.Event{}
+	for rows.Next() {
+		var seq int64
+		var payload []byte
+		if err := rows.Scan(&seq, &payload); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan camera event: %w", err)
+		}
+
+		var event cameraevents.Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to unmarshal camera event %d: %w", seq, err)
+		}
+
+		seqs = append(seqs, seq)
+		events = append(events, &event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating camera events: %w", err)
+	}
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err := publish(ctx, events); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE camera_events_outbox SET published_at = NOW() WHERE seq = ANY($1)",
+		pq.Array(seqs),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark camera events published: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit camera events: %w", err)
+	}
+
+	return len(events), nil
+}
+
+// DeletePublished removes events published before cutoff
+func (r *OutboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM camera_events_outbox WHERE published_at < $1",
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete published camera events: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// insertCameraEvents writes events to the outbox inside the transaction of the change they describe
+func insertCameraEvents(ctx context.Context, tx *sql.Tx, events ...*cameraevents.Event) error {
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal camera event: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO camera_events_outbox (event_id, event_type, camera_id, payload, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, event.ID, event.Type, event.CameraID, payload, event.OccurredAt)
+		if err != nil {
+			return fmt.Errorf("failed to record camera event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// eventCamera is the state of camera carried by its events
+func eventCamera(camera *domain.Camera) cameraevents.Camera {
+	return cameraevents.Camera{
+		ID:                camera.ID,
+		Name:              camera.Name,
+		Source:            string(camera.Source),
+		Status:            string(camera.Status),
+		PTZEnabled:        camera.PTZEnabled,
+		RecordingServer:   camera.RecordingServer,
+		MilestoneDeviceID: camera.MilestoneDeviceID,
+	}
+}
+
+// syncChangeEvents describes a synced update as events
+// A status change is its own event so consumers can follow cameras going offline.
+func syncChangeEvents(camera *domain.Camera, fields []domain.SyncFieldChange) []*cameraevents.Event {
+	events := []*cameraevents.Event{}
+	updated := []cameraevents.Change{}
+
+	for _, field := range fields {
+		change := cameraevents.Change{Field: field.Field, Old: field.Old, New: field.New}
+		if field.Field == "status" {
+			events = append(events, cameraevents.New(cameraevents.TypeStatusChanged, eventCamera(camera), []cameraevents.Change{change}))
+			continue
+		}
+		updated = append(updated, change)
+	}
+
+	if len(updated) > 0 {
+		events = append(events, cameraevents.New(cameraevents.TypeUpdated, eventCamera(camera), updated))
+	}
+
+	return events
+}
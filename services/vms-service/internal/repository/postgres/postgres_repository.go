@@ -5,32 +5,54 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/lib/pq"
+	"github.com/rta/cctv/vms-service/internal/client"
 	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/internal/repository"
+	"github.com/rta/cctv/vms-service/internal/repository/cache"
+	"github.com/rta/cctv/vms-service/internal/secrets"
 	"github.com/rs/zerolog"
 )
 
 // PostgresRepository implements repository.CameraRepository using PostgreSQL
+// Cameras leave the repository with masked RTSP URLs; PTZ reads the credentials separately
 type PostgresRepository struct {
-	db     *sql.DB
-	logger zerolog.Logger
+	db            *sql.DB
+	envelope      *secrets.Envelope
+	cache         repository.CacheRepository // Invalidated when a camera changes
+	ptzDiscoverer *client.PTZDiscoverer
+	ptzDrivers    []client.PTZDriver // In detection order
+	ptzDetecting  sync.Map           // Camera ID -> *sync.Mutex, one detection per camera at a time
+	logger        zerolog.Logger
 }
 
 // NewPostgresRepository creates a new PostgreSQL repository
-func NewPostgresRepository(db *sql.DB, logger zerolog.Logger) *PostgresRepository {
-	return &PostgresRepository{
-		db:     db,
-		logger: logger,
+// PTZ commands are sent with ONVIF, Hikvision ISAPI or generic CGI; RegisterPTZDriver adds more.
+func NewPostgresRepository(db *sql.DB, envelope *secrets.Envelope, cache repository.CacheRepository, logger zerolog.Logger) *PostgresRepository {
+	r := &PostgresRepository{
+		db:            db,
+		envelope:      envelope,
+		cache:         cache,
+		ptzDiscoverer: client.NewPTZDiscoverer(2 * time.Second),
+		logger:        logger,
 	}
+	r.ptzDrivers = []client.PTZDriver{
+		&onvifPTZDriver{repo: r},
+		client.NewISAPIDriver(3 * time.Second),
+		client.NewCGIDriver(3 * time.Second),
+	}
+	return r
 }
 
 // GetAll retrieves all cameras from the database
 func (r *PostgresRepository) GetAll(ctx context.Context) ([]*domain.Camera, error) {
 	query := `
 		SELECT id, name, name_ar, source, rtsp_url, ptz_enabled, status,
-		       recording_server, milestone_device_id, metadata, last_update, created_at
+		       recording_server, milestone_device_id, metadata, last_update, created_at,
+		       COALESCE(status_reason, ''), status_changed_at
 		FROM cameras
 		ORDER BY created_at DESC
 	`
@@ -62,7 +84,8 @@ func (r *PostgresRepository) GetAll(ctx context.Context) ([]*domain.Camera, erro
 func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Camera, error) {
 	query := `
 		SELECT id, name, name_ar, source, rtsp_url, ptz_enabled, status,
-		       recording_server, milestone_device_id, metadata, last_update, created_at
+		       recording_server, milestone_device_id, metadata, last_update, created_at,
+		       COALESCE(status_reason, ''), status_changed_at
 		FROM cameras
 		WHERE id = $1
 	`
@@ -70,7 +93,7 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Ca
 	row := r.db.QueryRowContext(ctx, query, id)
 	camera, err := r.scanCameraRow(row)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("camera not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", domain.ErrCameraNotFound, id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get camera: %w", err)
@@ -83,7 +106,8 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Ca
 func (r *PostgresRepository) GetBySource(ctx context.Context, source domain.CameraSource) ([]*domain.Camera, error) {
 	query := `
 		SELECT id, name, name_ar, source, rtsp_url, ptz_enabled, status,
-		       recording_server, milestone_device_id, metadata, last_update, created_at
+		       recording_server, milestone_device_id, metadata, last_update, created_at,
+		       COALESCE(status_reason, ''), status_changed_at
 		FROM cameras
 		WHERE source = $1
 		ORDER BY created_at DESC
@@ -108,7 +132,7 @@ func (r *PostgresRepository) GetBySource(ctx context.Context, source domain.Came
 	return cameras, nil
 }
 
-// GetRTSPURL generates RTSP URL for live streaming
+// GetRTSPURL generates RTSP URL for live streaming, with credentials masked
 func (r *PostgresRepository) GetRTSPURL(ctx context.Context, cameraID string) (string, error) {
 	camera, err := r.GetByID(ctx, cameraID)
 	if err != nil {
@@ -118,6 +142,7 @@ func (r *PostgresRepository) GetRTSPURL(ctx context.Context, cameraID string) (s
 }
 
 // GetPTZCapabilities retrieves PTZ capabilities for a camera
+// Capabilities are discovered over ONVIF and cached in camera_ptz_capabilities
 func (r *PostgresRepository) GetPTZCapabilities(ctx context.Context, cameraID string) (*domain.PTZCapabilities, error) {
 	camera, err := r.GetByID(ctx, cameraID)
 	if err != nil {
@@ -132,18 +157,13 @@ func (r *PostgresRepository) GetPTZCapabilities(ctx context.Context, cameraID st
 		}, nil
 	}
 
-	// Default PTZ capabilities for enabled cameras
-	return &domain.PTZCapabilities{
-		Pan:          true,
-		Tilt:         true,
-		Zoom:         true,
-		Focus:        true,
-		MaxPanSpeed:  1.0,
-		MaxTiltSpeed: 1.0,
-		MaxZoom:      20,
-	}, nil
-}
+	u, err := r.cameraDialURL(ctx, cameraID)
+	if err != nil {
+		return nil, err
+	}
 
+	return r.ptzCapabilities(ctx, cameraID, u)
+}
 
 // GetRecordingSegments retrieves available recording segments for time range
 func (r *PostgresRepository) GetRecordingSegments(ctx context.Context, cameraID string, start, end time.Time) ([]*domain.RecordingSegment, error) {
@@ -246,15 +266,9 @@ func (r *PostgresRepository) GetExportStatus(ctx context.Context, exportID strin
 	return export, nil
 }
 
-// Sync performs full synchronization with Milestone VMS
-func (r *PostgresRepository) Sync(ctx context.Context) error {
-	// TODO: Implement actual Milestone SDK sync
-	// This would:
-	// 1. Connect to Milestone VMS
-	// 2. Fetch all cameras
-	// 3. Upsert to database
-	r.logger.Info().Msg("Sync with Milestone VMS (not implemented)")
-	return nil
+// HealthCheck verifies the database connection
+func (r *PostgresRepository) HealthCheck(ctx context.Context) error {
+	return r.db.PingContext(ctx)
 }
 
 // Helper functions
@@ -262,6 +276,7 @@ func (r *PostgresRepository) Sync(ctx context.Context) error {
 func (r *PostgresRepository) scanCamera(rows *sql.Rows) (*domain.Camera, error) {
 	camera := &domain.Camera{}
 	var metadataJSON []byte
+	var statusChangedAt sql.NullTime
 
 	err := rows.Scan(
 		&camera.ID,
@@ -276,10 +291,16 @@ func (r *PostgresRepository) scanCamera(rows *sql.Rows) (*domain.Camera, error)
 		&metadataJSON,
 		&camera.LastUpdate,
 		&camera.CreatedAt,
+		&camera.StatusReason,
+		&statusChangedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+	camera.RTSPURL = secrets.MaskURL(camera.RTSPURL)
+	if statusChangedAt.Valid {
+		camera.StatusChangedAt = &statusChangedAt.Time
+	}
 
 	if len(metadataJSON) > 0 {
 		if err := json.Unmarshal(metadataJSON, &camera.Metadata); err != nil {
@@ -293,6 +314,7 @@ func (r *PostgresRepository) scanCamera(rows *sql.Rows) (*domain.Camera, error)
 func (r *PostgresRepository) scanCameraRow(row *sql.Row) (*domain.Camera, error) {
 	camera := &domain.Camera{}
 	var metadataJSON []byte
+	var statusChangedAt sql.NullTime
 
 	err := row.Scan(
 		&camera.ID,
@@ -307,10 +329,16 @@ func (r *PostgresRepository) scanCameraRow(row *sql.Row) (*domain.Camera, error)
 		&metadataJSON,
 		&camera.LastUpdate,
 		&camera.CreatedAt,
+		&camera.StatusReason,
+		&statusChangedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+	camera.RTSPURL = secrets.MaskURL(camera.RTSPURL)
+	if statusChangedAt.Valid {
+		camera.StatusChangedAt = &statusChangedAt.Time
+	}
 
 	if len(metadataJSON) > 0 {
 		if err := json.Unmarshal(metadataJSON, &camera.Metadata); err != nil {
@@ -320,3 +348,18 @@ func (r *PostgresRepository) scanCameraRow(row *sql.Row) (*domain.Camera, error)
 
 	return camera, nil
 }
+
+// invalidateCamera drops the cached responses of a camera after a committed change
+// A failure only delays the change until the entries expire, so it is logged.
+func (r *PostgresRepository) invalidateCamera(ctx context.Context, cameraID string) {
+	if err := cache.InvalidateCamera(ctx, r.cache, cameraID); err != nil {
+		r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to invalidate cached camera")
+	}
+}
+
+// invalidatePTZCapabilities drops the cached PTZ capabilities of a camera
+func (r *PostgresRepository) invalidatePTZCapabilities(ctx context.Context, cameraID string) {
+	if err := r.cache.Delete(ctx, cache.PTZCapabilitiesKey(cameraID)); err != nil {
+		r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to invalidate cached PTZ capabilities")
+	}
+}
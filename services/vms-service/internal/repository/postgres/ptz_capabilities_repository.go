@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+const (
+	// ptzCapabilitiesTTL is how long discovered capabilities are used before rediscovery
+	ptzCapabilitiesTTL = 24 * time.Hour
+
+	// ptzDiscoveryRetry is how long a failed discovery is not retried
+	ptzDiscoveryRetry = 5 * time.Minute
+)
+
+// ptzCapabilities returns the cached PTZ capabilities of a camera, discovering them when
+// missing or expired
+// When discovery fails the last discovered capabilities are kept in use, so a camera that is
+// briefly unreachable does not lose its profile token.
+func (r *PostgresRepository) ptzCapabilities(ctx context.Context, cameraID string, u *url.URL) (*domain.PTZCapabilities, error) {
+	var endpoint, lastError string
+	var capsJSON []byte
+	var serviceURL, deviceServiceURL, profileToken sql.NullString
+	var discoveredAt, attemptedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(c.onvif_endpoint, ''), p.capabilities, p.profile_token, p.service_url,
+		       p.device_service_url, p.discovered_at, COALESCE(p.last_error, ''), p.attempted_at
+		FROM cameras c
+		LEFT JOIN camera_ptz_capabilities p ON p.camera_id = c.id
+		WHERE c.id = $1
+	`, cameraID).Scan(&endpoint, &capsJSON, &profileToken, &serviceURL, &deviceServiceURL, &discoveredAt, &lastError, &attemptedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrCameraNotFound, cameraID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PTZ capabilities: %w", err)
+	}
+
+	var cached *domain.PTZCapabilities
+	if len(capsJSON) > 0 && profileToken.String != "" {
+		cached = &domain.PTZCapabilities{}
+		if err := json.Unmarshal(capsJSON, cached); err != nil {
+			r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to unmarshal PTZ capabilities")
+			cached = nil
+		} else {
+			cached.ProfileToken = profileToken.String
+			cached.ServiceURL = serviceURL.String
+			cached.DeviceServiceURL = deviceServiceURL.String
+		}
+	}
+
+	if cached != nil && discoveredAt.Valid && time.Since(discoveredAt.Time) < ptzCapabilitiesTTL {
+		return cached, nil
+	}
+	if lastError != "" && attemptedAt.Valid && time.Since(attemptedAt.Time) < ptzDiscoveryRetry {
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("%w: %s", domain.ErrPTZDiscoveryFailed, lastError)
+	}
+
+	// The device service found last time saves probing ports on cameras without an endpoint
+	if endpoint == "" {
+		endpoint = deviceServiceURL.String
+	}
+
+	caps, err := r.ptzDiscoverer.Discover(ctx, u, endpoint)
+	if err != nil {
+		r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("PTZ capability discovery failed")
+		if storeErr := r.recordPTZDiscoveryFailure(ctx, cameraID, err); storeErr != nil {
+			r.logger.Error().Err(storeErr).Str("camera_id", cameraID).Msg("Failed to record PTZ discovery failure")
+		}
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("%w: %v", domain.ErrPTZDiscoveryFailed, err)
+	}
+
+	if err := r.storePTZCapabilities(ctx, cameraID, caps); err != nil {
+		r.logger.Error().Err(err).Str("camera_id", cameraID).Msg("Failed to cache PTZ capabilities")
+	}
+
+	r.logger.Info().
+		Str("camera_id", cameraID).
+		Str("profile_token", caps.ProfileToken).
+		Int("max_presets", caps.MaxPresets).
+		Msg("Discovered PTZ capabilities")
+
+	return caps, nil
+}
+
+// ptzCamera returns the dial URL and PTZ capabilities of a PTZ camera, for ONVIF requests
+func (r *PostgresRepository) ptzCamera(ctx context.Context, cameraID string) (*url.URL, *domain.PTZCapabilities, error) {
+	camera, err := r.GetByID(ctx, cameraID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !camera.PTZEnabled {
+		return nil, nil, fmt.Errorf("%w: %s", domain.ErrPTZNotSupported, cameraID)
+	}
+
+	u, err := r.cameraDialURL(ctx, cameraID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	caps, err := r.ptzCapabilities(ctx, cameraID, u)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return u, caps, nil
+}
+
+// storePTZCapabilities caches discovered capabilities and clears any discovery error
+func (r *PostgresRepository) storePTZCapabilities(ctx context.Context, cameraID string, caps *domain.PTZCapabilities) error {
+	capsJSON, err := json.Marshal(caps)
+	if err != nil {
+		return fmt.Errorf("failed to marshal PTZ capabilities: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO camera_ptz_capabilities (
+			camera_id, profile_token, service_url, device_service_url, capabilities,
+			discovered_at, last_error, attempted_at
+		) VALUES ($1, $2, $3, $4, $5, $6, NULL, $6)
+		ON CONFLICT (camera_id) DO UPDATE
+		SET profile_token = EXCLUDED.profile_token, service_url = EXCLUDED.service_url,
+		    device_service_url = EXCLUDED.device_service_url, capabilities = EXCLUDED.capabilities,
+		    discovered_at = EXCLUDED.discovered_at, last_error = NULL, attempted_at = EXCLUDED.attempted_at
+	`, cameraID, caps.ProfileToken, caps.ServiceURL, caps.DeviceServiceURL, capsJSON, caps.DiscoveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to store PTZ capabilities of camera %s: %w", cameraID, err)
+	}
+	r.invalidatePTZCapabilities(ctx, cameraID)
+
+	return nil
+}
+
+// recordPTZDiscoveryFailure records a failed discovery, keeping the capabilities found before
+func (r *PostgresRepository) recordPTZDiscoveryFailure(ctx context.Context, cameraID string, discoveryErr error) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO camera_ptz_capabilities (camera_id, last_error, attempted_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (camera_id) DO UPDATE
+		SET last_error = EXCLUDED.last_error, attempted_at = EXCLUDED.attempted_at
+	`, cameraID, discoveryErr.Error())
+	if err != nil {
+		return fmt.Errorf("failed to record PTZ discovery failure of camera %s: %w", cameraID, err)
+	}
+
+	return nil
+}
+
+// expirePTZCapabilities makes the next PTZ command rediscover the camera's capabilities
+// Used when the camera rejects a command, e.g. after its profiles were reconfigured.
+func (r *PostgresRepository) expirePTZCapabilities(ctx context.Context, cameraID string) {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE camera_ptz_capabilities SET discovered_at = NULL, last_error = NULL WHERE camera_id = $1",
+		cameraID,
+	)
+	if err != nil {
+		r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to expire PTZ capabilities")
+	}
+	r.invalidatePTZCapabilities(ctx, cameraID)
+}
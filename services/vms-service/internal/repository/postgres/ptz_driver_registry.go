@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/client"
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+const (
+	// ptzDriverFailureThreshold is how many commands in a row may fail before the driver is detected again
+	ptzDriverFailureThreshold = 3
+
+	// ptzDetectRetry is how long a camera no driver answered is not detected again
+	ptzDetectRetry = 5 * time.Minute
+)
+
+// RegisterPTZDriver adds a driver tried after the built-in ones; call it before serving requests
+func (r *PostgresRepository) RegisterPTZDriver(driver client.PTZDriver) {
+	r.ptzDrivers = append(r.ptzDrivers, driver)
+}
+
+// GetPTZDriver retrieves the driver detected for a camera
+func (r *PostgresRepository) GetPTZDriver(ctx context.Context, cameraID string) (*domain.PTZDriverRecord, error) {
+	record, err := r.ptzDriverRecord(ctx, cameraID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		if err := r.ensureCamera(ctx, cameraID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: no PTZ driver detected for %s yet", domain.ErrPTZDriverNotFound, cameraID)
+	}
+	return record, nil
+}
+
+// DetectPTZDriver detects the driver of a PTZ camera again, replacing the recorded one
+func (r *PostgresRepository) DetectPTZDriver(ctx context.Context, cameraID string) (*domain.PTZDriverRecord, error) {
+	target, err := r.ptzTarget(ctx, cameraID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.detectPTZDriver(ctx, target, true); err != nil && !errors.Is(err, domain.ErrPTZCommandFailed) {
+		return nil, err
+	}
+	return r.GetPTZDriver(ctx, cameraID)
+}
+
+// ptzTarget loads a PTZ camera with its decrypted dial URL
+func (r *PostgresRepository) ptzTarget(ctx context.Context, cameraID string) (*client.PTZTarget, error) {
+	camera, err := r.GetByID(ctx, cameraID)
+	if err != nil {
+		return nil, err
+	}
+	if !camera.PTZEnabled {
+		return nil, fmt.Errorf("%w: %s", domain.ErrPTZNotSupported, cameraID)
+	}
+
+	u, err := r.cameraDialURL(ctx, cameraID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client.PTZTarget{
+		CameraID:          cameraID,
+		DialURL:           u,
+		MilestoneDeviceID: camera.MilestoneDeviceID,
+	}, nil
+}
+
+// ptzDriver returns the driver recorded for a camera, detecting it when there is none
+func (r *PostgresRepository) ptzDriver(ctx context.Context, target *client.PTZTarget) (client.PTZDriver, error) {
+	record, err := r.ptzDriverRecord(ctx, target.CameraID)
+	if err != nil {
+		return nil, err
+	}
+	if driver, err, ok := r.recordedDriver(record); ok {
+		return driver, err
+	}
+
+	return r.detectPTZDriver(ctx, target, false)
+}
+
+// recordedDriver resolves a record; ok is false when the camera needs detection
+// A driver that is no longer registered, e.g. Milestone after it was unconfigured, is detected again.
+func (r *PostgresRepository) recordedDriver(record *domain.PTZDriverRecord) (client.PTZDriver, error, bool) {
+	if record == nil {
+		return nil, nil, false
+	}
+	if record.Driver == "" {
+		if time.Since(record.DetectedAt) < ptzDetectRetry {
+			return nil, fmt.Errorf("%w: no PTZ driver answered at %s: %s",
+				domain.ErrPTZCommandFailed, record.DetectedAt.Format(time.RFC3339), record.LastError), true
+		}
+		return nil, nil, false
+	}
+
+	for _, driver := range r.ptzDrivers {
+		if driver.Name() == record.Driver {
+			return driver, nil, true
+		}
+	}
+	return nil, nil, false
+}
+
+// detectPTZDriver tries the drivers in order and records the first the camera answers
+// Concurrent commands to an undetected camera wait for one detection; force detects even when
+// another command has just recorded a driver.
+func (r *PostgresRepository) detectPTZDriver(ctx context.Context, target *client.PTZTarget, force bool) (client.PTZDriver, error) {
+	lock, _ := r.ptzDetecting.LoadOrStore(target.CameraID, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	if !force {
+		record, err := r.ptzDriverRecord(ctx, target.CameraID)
+		if err != nil {
+			return nil, err
+		}
+		if driver, err, ok := r.recordedDriver(record); ok {
+			return driver, err
+		}
+	}
+
+	var failures []string
+	for _, driver := range r.ptzDrivers {
+		err := driver.Detect(ctx, target)
+		if err == nil {
+			r.logger.Info().
+				Str("camera_id", target.CameraID).
+				Str("driver", driver.Name()).
+				Msg("Detected PTZ driver")
+
+			if err := r.storePTZDriver(ctx, target.CameraID, driver.Name(), ""); err != nil {
+				return nil, err
+			}
+			return driver, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		r.logger.Debug().Err(err).Str("camera_id", target.CameraID).Str("driver", driver.Name()).Msg("PTZ driver did not answer")
+		failures = append(failures, fmt.Sprintf("%s: %v", driver.Name(), err))
+	}
+
+	detectErr := fmt.Sprintf("%v", failures)
+	r.logger.Warn().Str("camera_id", target.CameraID).Str("errors", detectErr).Msg("No PTZ driver answered")
+	if err := r.storePTZDriver(ctx, target.CameraID, "", detectErr); err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("%w: no PTZ driver answered: %s", domain.ErrPTZCommandFailed, detectErr)
+}
+
+// recordPTZDriverResult counts failed commands of the recorded driver
+// At the threshold the record is removed, so the next command detects the driver again.
+// Actions the driver does not support are not failures.
+func (r *PostgresRepository) recordPTZDriverResult(ctx context.Context, cameraID, driver string, cmdErr error) {
+	if errors.Is(cmdErr, domain.ErrPTZNotSupported) {
+		return
+	}
+
+	if cmdErr == nil {
+		_, err := r.db.ExecContext(ctx, `
+			UPDATE camera_ptz_drivers SET consecutive_failures = 0, last_error = NULL
+			WHERE camera_id = $1 AND driver = $2 AND consecutive_failures > 0
+		`, cameraID, driver)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to reset PTZ driver failures")
+		}
+		return
+	}
+
+	var failures int
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE camera_ptz_drivers SET consecutive_failures = consecutive_failures + 1, last_error = $3
+		WHERE camera_id = $1 AND driver = $2
+		RETURNING consecutive_failures
+	`, cameraID, driver, cmdErr.Error()).Scan(&failures)
+	if err == sql.ErrNoRows {
+		return
+	}
+	if err != nil {
+		r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to record PTZ driver failure")
+		return
+	}
+
+	if failures >= ptzDriverFailureThreshold {
+		r.logger.Warn().
+			Str("camera_id", cameraID).
+			Str("driver", driver).
+			Int("failures", failures).
+			Msg("PTZ driver keeps failing, detecting again on the next command")
+
+		_, err := r.db.ExecContext(ctx, "DELETE FROM camera_ptz_drivers WHERE camera_id = $1 AND driver = $2", cameraID, driver)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to clear PTZ driver")
+		}
+	}
+}
+
+// ptzDriverRecord reads the driver record of a camera, nil when there is none
+func (r *PostgresRepository) ptzDriverRecord(ctx context.Context, cameraID string) (*domain.PTZDriverRecord, error) {
+	record := &domain.PTZDriverRecord{CameraID: cameraID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(driver, ''), detected_at, consecutive_failures, COALESCE(last_error, '')
+		FROM camera_ptz_drivers WHERE camera_id = $1
+	`, cameraID).Scan(&record.Driver, &record.DetectedAt, &record.ConsecutiveFailures, &record.LastError)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PTZ driver: %w", err)
+	}
+	return record, nil
+}
+
+// storePTZDriver records the outcome of a detection; an empty driver means none answered
+func (r *PostgresRepository) storePTZDriver(ctx context.Context, cameraID, driver, detectErr string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO camera_ptz_drivers (camera_id, driver, detected_at, consecutive_failures, last_error)
+		VALUES ($1, NULLIF($2, ''), NOW(), 0, NULLIF($3, ''))
+		ON CONFLICT (camera_id) DO UPDATE
+		SET driver = EXCLUDED.driver, detected_at = EXCLUDED.detected_at,
+		    consecutive_failures = 0, last_error = EXCLUDED.last_error
+	`, cameraID, driver, detectErr)
+	if err != nil {
+		return fmt.Errorf("failed to store PTZ driver of camera %s: %w", cameraID, err)
+	}
+	return nil
+}
+
+// onvifPTZDriver sends commands to the camera's discovered ONVIF PTZ service
+type onvifPTZDriver struct {
+	repo *PostgresRepository
+}
+
+// Name identifies the driver
+func (d *onvifPTZDriver) Name() string {
+	return domain.PTZDriverONVIF
+}
+
+// Detect discovers the camera's PTZ capabilities, which later commands reuse
+func (d *onvifPTZDriver) Detect(ctx context.Context, target *client.PTZTarget) error {
+	caps, err := d.repo.ptzCapabilities(ctx, target.CameraID, target.DialURL)
+	if err != nil {
+		return err
+	}
+	if caps.ProfileToken == "" {
+		return fmt.Errorf("no media profile with a PTZ configuration")
+	}
+	return nil
+}
+
+// Execute sends a command over ONVIF SOAP
+func (d *onvifPTZDriver) Execute(ctx context.Context, target *client.PTZTarget, cmd *domain.PTZCommand) error {
+	return d.repo.sendPTZViaONVIFSOAP(ctx, target.DialURL, cmd)
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// sendPTZMove sends an absolute, relative or center-on-point command over ONVIF
+func (r *PostgresRepository) sendPTZMove(ctx context.Context, u *url.URL, caps *domain.PTZCapabilities, cmd *domain.PTZCommand) error {
+	switch cmd.Action {
+	case domain.PTZActionAbsoluteMove:
+		zoom := cmd.Zoom
+		position := domain.PTZPosition{Pan: cmd.Pan, Tilt: cmd.Tilt, Zoom: &zoom}
+		return r.ptzDiscoverer.AbsoluteMove(ctx, caps, u, position, cmd.Speed)
+
+	case domain.PTZActionRelativeMove:
+		zoom := cmd.Zoom
+		translation := domain.PTZPosition{Pan: cmd.Pan, Tilt: cmd.Tilt, Zoom: &zoom}
+		return r.ptzDiscoverer.RelativeMove(ctx, caps, u, translation, cmd.Speed)
+
+	case domain.PTZActionCenterOnPoint:
+		fov, err := r.fieldOfView(ctx, cmd.CameraID)
+		if err != nil {
+			return err
+		}
+
+		// Without the current zoom the point is converted as if the camera were at the wide end
+		zoom := 0.0
+		status, err := r.ptzDiscoverer.GetStatus(ctx, caps, u)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("camera_id", cmd.CameraID).Msg("Centering without the current zoom position")
+		} else if status.Position != nil && status.Position.Zoom != nil {
+			zoom = *status.Position.Zoom
+		}
+
+		translation := centerTranslation(fov, zoom, cmd.X, cmd.Y, cmd.ZoomFactor)
+		return r.ptzDiscoverer.RelativeMove(ctx, caps, u, translation, cmd.Speed)
+
+	default:
+		return fmt.Errorf("unsupported PTZ action: %s", cmd.Action)
+	}
+}
+
+// centerTranslation converts a point on the image into the relative move that centers it
+// x and y run from 0 to 1 from the top left corner. The field of view narrows with the
+// magnification, taken to grow linearly from 1 at zoom 0 to MaxZoom at zoom 1. A translation
+// of 1 in the generic space is half the pan or tilt range, so the camera turns by the angle
+// between the point and the image center. zoomFactor multiplies the magnification.
+func centerTranslation(fov *domain.PTZFieldOfView, zoom, x, y, zoomFactor float64) domain.PTZPosition {
+	magnification := 1 + zoom*(fov.MaxZoom-1)
+
+	panDegrees := (x - 0.5) * fov.HorizontalDegrees / magnification
+	tiltDegrees := (0.5 - y) * fov.VerticalDegrees / magnification
+
+	translation := domain.PTZPosition{
+		Pan:  clamp(panDegrees/(fov.PanRangeDegrees/2), -1, 1),
+		Tilt: clamp(tiltDegrees/(fov.TiltRangeDegrees/2), -1, 1),
+	}
+
+	if zoomFactor > 0 && zoomFactor != 1 && fov.MaxZoom > 1 {
+		target := clamp(magnification*zoomFactor, 1, fov.MaxZoom)
+		delta := (target-1)/(fov.MaxZoom-1) - zoom
+		translation.Zoom = &delta
+	}
+
+	return translation
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
+
+// GetPTZStatus reads the position and movement state of a camera
+func (r *PostgresRepository) GetPTZStatus(ctx context.Context, cameraID string) (*domain.PTZStatus, error) {
+	u, caps, err := r.ptzCamera(ctx, cameraID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := r.ptzDiscoverer.GetStatus(ctx, caps, u)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrPTZCommandFailed, err)
+	}
+	status.CameraID = cameraID
+
+	return status, nil
+}
+
+// GetPTZFieldOfView retrieves the field of view of a camera, the defaults when none is configured
+func (r *PostgresRepository) GetPTZFieldOfView(ctx context.Context, cameraID string) (*domain.PTZFieldOfView, error) {
+	if err := r.ensureCamera(ctx, cameraID); err != nil {
+		return nil, err
+	}
+	return r.fieldOfView(ctx, cameraID)
+}
+
+// SetPTZFieldOfView configures the field of view of a camera
+// Discovery leaves it untouched.
+func (r *PostgresRepository) SetPTZFieldOfView(ctx context.Context, cameraID string, fov *domain.PTZFieldOfView) error {
+	if err := r.ensureCamera(ctx, cameraID); err != nil {
+		return err
+	}
+
+	fov.Configured = true
+	fovJSON, err := json.Marshal(fov)
+	if err != nil {
+		return fmt.Errorf("failed to marshal field of view: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO camera_ptz_capabilities (camera_id, field_of_view)
+		VALUES ($1, $2)
+		ON CONFLICT (camera_id) DO UPDATE SET field_of_view = EXCLUDED.field_of_view
+	`, cameraID, fovJSON)
+	if err != nil {
+		return fmt.Errorf("failed to store field of view of camera %s: %w", cameraID, err)
+	}
+
+	return nil
+}
+
+// fieldOfView returns the configured field of view of a camera or a copy of the defaults
+func (r *PostgresRepository) fieldOfView(ctx context.Context, cameraID string) (*domain.PTZFieldOfView, error) {
+	var fovJSON []byte
+	err := r.db.QueryRowContext(ctx,
+		"SELECT field_of_view FROM camera_ptz_capabilities WHERE camera_id = $1",
+		cameraID,
+	).Scan(&fovJSON)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get field of view: %w", err)
+	}
+
+	fov := domain.DefaultPTZFieldOfView
+	if len(fovJSON) > 0 {
+		if err := json.Unmarshal(fovJSON, &fov); err != nil {
+			r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to unmarshal field of view, using defaults")
+			fov = domain.DefaultPTZFieldOfView
+		}
+	}
+
+	return &fov, nil
+}
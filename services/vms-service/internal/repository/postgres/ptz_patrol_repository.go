@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+const patrolColumns = `camera_id, COALESCE(name, ''), stops, dwell_seconds, speed, schedule,
+	resume_after_seconds, enabled, COALESCE(created_by, ''), created_at, updated_at`
+
+// ListPTZPatrols retrieves the patrols of all cameras
+func (r *PostgresRepository) ListPTZPatrols(ctx context.Context) ([]*domain.PTZPatrol, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+patrolColumns+" FROM camera_ptz_patrols ORDER BY camera_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PTZ patrols: %w", err)
+	}
+	defer rows.Close()
+
+	patrols := make([]*domain.PTZPatrol, 0)
+	for rows.Next() {
+		patrol, err := scanPTZPatrol(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PTZ patrol: %w", err)
+		}
+		patrols = append(patrols, patrol)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating PTZ patrols: %w", err)
+	}
+
+	return patrols, nil
+}
+
+// GetPTZPatrol retrieves the patrol of a camera
+func (r *PostgresRepository) GetPTZPatrol(ctx context.Context, cameraID string) (*domain.PTZPatrol, error) {
+	patrol, err := scanPTZPatrol(r.db.QueryRowContext(ctx,
+		"SELECT "+patrolColumns+" FROM camera_ptz_patrols WHERE camera_id = $1",
+		cameraID,
+	))
+	if err == sql.ErrNoRows {
+		if err := r.ensureCamera(ctx, cameraID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", domain.ErrPTZPatrolNotFound, cameraID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PTZ patrol: %w", err)
+	}
+
+	return patrol, nil
+}
+
+// SavePTZPatrol creates or replaces the patrol of a PTZ camera
+// The creator and creation time of a replaced patrol are kept.
+func (r *PostgresRepository) SavePTZPatrol(ctx context.Context, patrol *domain.PTZPatrol) error {
+	var ptzEnabled bool
+	err := r.db.QueryRowContext(ctx, "SELECT ptz_enabled FROM cameras WHERE id = $1", patrol.CameraID).Scan(&ptzEnabled)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", domain.ErrCameraNotFound, patrol.CameraID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check camera: %w", err)
+	}
+	if !ptzEnabled {
+		return fmt.Errorf("%w: %s", domain.ErrPTZNotSupported, patrol.CameraID)
+	}
+
+	stopsJSON, err := json.Marshal(patrol.Stops)
+	if err != nil {
+		return fmt.Errorf("failed to marshal patrol stops: %w", err)
+	}
+	var schedule interface{} // NULL without a schedule
+	if patrol.Schedule != nil {
+		scheduleJSON, err := json.Marshal(patrol.Schedule)
+		if err != nil {
+			return fmt.Errorf("failed to marshal patrol schedule: %w", err)
+		}
+		schedule = scheduleJSON
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO camera_ptz_patrols (
+			camera_id, name, stops, dwell_seconds, speed, schedule, resume_after_seconds, enabled, created_by
+		) VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, NULLIF($9, ''))
+		ON CONFLICT (camera_id) DO UPDATE SET
+			name = EXCLUDED.name,
+			stops = EXCLUDED.stops,
+			dwell_seconds = EXCLUDED.dwell_seconds,
+			speed = EXCLUDED.speed,
+			schedule = EXCLUDED.schedule,
+			resume_after_seconds = EXCLUDED.resume_after_seconds,
+			enabled = EXCLUDED.enabled,
+			updated_at = NOW()
+		RETURNING COALESCE(created_by, ''), created_at, updated_at
+	`, patrol.CameraID, patrol.Name, stopsJSON, patrol.DwellSeconds, patrol.Speed, schedule,
+		patrol.ResumeAfterSeconds, patrol.Enabled, patrol.CreatedBy,
+	).Scan(&patrol.CreatedBy, &patrol.CreatedAt, &patrol.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store PTZ patrol of camera %s: %w", patrol.CameraID, err)
+	}
+
+	return nil
+}
+
+// DeletePTZPatrol removes the patrol of a camera
+func (r *PostgresRepository) DeletePTZPatrol(ctx context.Context, cameraID string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM camera_ptz_patrols WHERE camera_id = $1", cameraID)
+	if err != nil {
+		return fmt.Errorf("failed to delete PTZ patrol: %w", err)
+	}
+
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		if err := r.ensureCamera(ctx, cameraID); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %s", domain.ErrPTZPatrolNotFound, cameraID)
+	}
+
+	return nil
+}
+
+func scanPTZPatrol(row interface{ Scan(...interface{}) error }) (*domain.PTZPatrol, error) {
+	patrol := &domain.PTZPatrol{}
+	var stopsJSON, scheduleJSON []byte
+
+	err := row.Scan(&patrol.CameraID, &patrol.Name, &stopsJSON, &patrol.DwellSeconds, &patrol.Speed, &scheduleJSON,
+		&patrol.ResumeAfterSeconds, &patrol.Enabled, &patrol.CreatedBy, &patrol.CreatedAt, &patrol.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(stopsJSON, &patrol.Stops); err != nil {
+		return nil, fmt.Errorf("invalid stops of camera %s: %w", patrol.CameraID, err)
+	}
+	if len(scheduleJSON) > 0 {
+		patrol.Schedule = &domain.PTZPatrolSchedule{}
+		if err := json.Unmarshal(scheduleJSON, patrol.Schedule); err != nil {
+			return nil, fmt.Errorf("invalid schedule of camera %s: %w", patrol.CameraID, err)
+		}
+	}
+
+	return patrol, nil
+}
@@ -0,0 +1,384 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/rta/cctv/vms-service/internal/client"
+	"github.com/rta/cctv/vms-service/internal/domain"
+)
+
+// pqUniqueViolation is the PostgreSQL error code of a unique constraint violation
+const pqUniqueViolation = "23505"
+
+const presetColumns = `camera_id, number, token, name, COALESCE(name_ar, ''), on_camera,
+	COALESCE(created_by, ''), created_at, updated_at`
+
+// ListPTZPresets retrieves the presets of a camera ordered by number
+// With refresh, the presets stored on the camera are read first: presets saved on the camera
+// outside this service are added under the next free numbers, and presets no longer on the
+// camera are flagged. A camera that cannot be reached leaves the stored presets as they are.
+func (r *PostgresRepository) ListPTZPresets(ctx context.Context, cameraID string, refresh bool) ([]*domain.PTZPreset, error) {
+	if refresh {
+		u, caps, err := r.ptzCamera(ctx, cameraID)
+		if errors.Is(err, domain.ErrCameraNotFound) || errors.Is(err, domain.ErrPTZNotSupported) {
+			return nil, err
+		}
+		if err == nil {
+			err = r.refreshPTZPresets(ctx, cameraID, u, caps)
+		}
+		if err != nil {
+			r.logger.Warn().Err(err).Str("camera_id", cameraID).Msg("Failed to refresh PTZ presets from camera")
+		}
+	} else if err := r.ensureCamera(ctx, cameraID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+presetColumns+" FROM camera_ptz_presets WHERE camera_id = $1 ORDER BY number",
+		cameraID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PTZ presets: %w", err)
+	}
+	defer rows.Close()
+
+	presets := make([]*domain.PTZPreset, 0)
+	for rows.Next() {
+		preset, err := scanPTZPreset(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PTZ preset: %w", err)
+		}
+		presets = append(presets, preset)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating PTZ presets: %w", err)
+	}
+
+	return presets, nil
+}
+
+// GetPTZPreset retrieves one preset of a camera
+func (r *PostgresRepository) GetPTZPreset(ctx context.Context, cameraID string, number int) (*domain.PTZPreset, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT "+presetColumns+" FROM camera_ptz_presets WHERE camera_id = $1 AND number = $2",
+		cameraID, number,
+	)
+	preset, err := scanPTZPreset(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s/%d", domain.ErrPTZPresetNotFound, cameraID, number)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PTZ preset: %w", err)
+	}
+
+	return preset, nil
+}
+
+// CreatePTZPreset saves the camera's current position as a new preset
+// A zero Number takes the lowest free number. Number, Token and the timestamps are set on return.
+func (r *PostgresRepository) CreatePTZPreset(ctx context.Context, preset *domain.PTZPreset) error {
+	u, caps, err := r.ptzCamera(ctx, preset.CameraID)
+	if err != nil {
+		return err
+	}
+
+	// Checked before the camera stores a position that could not be named
+	if err := r.checkPresetAvailable(ctx, preset); err != nil {
+		return err
+	}
+
+	token, err := r.ptzDiscoverer.SetPreset(ctx, caps, u, "", preset.Name)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrPTZCommandFailed, err)
+	}
+	preset.Token = token
+	preset.OnCamera = true
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO camera_ptz_presets (camera_id, number, token, name, name_ar, created_by)
+		VALUES (
+			$1,
+			CASE WHEN $2 > 0 THEN $2 ELSE (
+				SELECT MIN(n) FROM generate_series(1, $7) n
+				WHERE n NOT IN (SELECT number FROM camera_ptz_presets WHERE camera_id = $1)
+			) END,
+			$3, $4, NULLIF($5, ''), NULLIF($6, '')
+		)
+		ON CONFLICT (camera_id, token) DO UPDATE
+		SET name = EXCLUDED.name, name_ar = EXCLUDED.name_ar, created_by = EXCLUDED.created_by,
+		    on_camera = TRUE, updated_at = NOW()
+		RETURNING number, created_at, updated_at
+	`,
+		preset.CameraID, preset.Number, preset.Token, preset.Name, preset.NameAr, preset.CreatedBy,
+		domain.MaxPTZPresetNumber,
+	).Scan(&preset.Number, &preset.CreatedAt, &preset.UpdatedAt)
+	if err != nil {
+		// The position is stored on the camera but could not be named; remove it again
+		if removeErr := r.ptzDiscoverer.RemovePreset(ctx, caps, u, token); removeErr != nil {
+			r.logger.Warn().Err(removeErr).Str("camera_id", preset.CameraID).Str("token", token).Msg("Failed to remove unnamed PTZ preset")
+		}
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: %s", domain.ErrPTZPresetExists, preset.Name)
+		}
+		return fmt.Errorf("failed to store PTZ preset: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePTZPreset renames a preset and, with savePosition, stores the camera's current
+// position under it
+func (r *PostgresRepository) UpdatePTZPreset(ctx context.Context, preset *domain.PTZPreset, savePosition bool) error {
+	current, err := r.GetPTZPreset(ctx, preset.CameraID, preset.Number)
+	if err != nil {
+		return err
+	}
+	preset.Token = current.Token
+	preset.OnCamera = current.OnCamera
+	preset.CreatedBy = current.CreatedBy
+
+	if savePosition {
+		u, caps, err := r.ptzCamera(ctx, preset.CameraID)
+		if err != nil {
+			return err
+		}
+
+		// A preset the camera lost is recreated under the token the camera assigns
+		token := current.Token
+		if !current.OnCamera {
+			token = ""
+		}
+		token, err = r.ptzDiscoverer.SetPreset(ctx, caps, u, token, preset.Name)
+		if err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrPTZCommandFailed, err)
+		}
+		preset.Token = token
+		preset.OnCamera = true
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		UPDATE camera_ptz_presets
+		SET name = $3, name_ar = NULLIF($4, ''), token = $5, on_camera = $6, updated_at = NOW()
+		WHERE camera_id = $1 AND number = $2
+		RETURNING created_at, updated_at
+	`, preset.CameraID, preset.Number, preset.Name, preset.NameAr, preset.Token, preset.OnCamera,
+	).Scan(&preset.CreatedAt, &preset.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s/%d", domain.ErrPTZPresetNotFound, preset.CameraID, preset.Number)
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %s", domain.ErrPTZPresetExists, preset.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update PTZ preset: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePTZPreset removes a preset from the camera and deletes it
+// A camera that rejects the removal no longer has the preset; an unreachable camera keeps
+// the preset so the delete can be retried.
+func (r *PostgresRepository) DeletePTZPreset(ctx context.Context, cameraID string, number int) error {
+	preset, err := r.GetPTZPreset(ctx, cameraID, number)
+	if err != nil {
+		return err
+	}
+
+	if preset.OnCamera {
+		u, caps, err := r.ptzCamera(ctx, cameraID)
+		if err != nil {
+			return err
+		}
+
+		err = r.ptzDiscoverer.RemovePreset(ctx, caps, u, preset.Token)
+		if client.IsONVIFFault(err) {
+			r.logger.Warn().Err(err).Str("camera_id", cameraID).Int("number", number).Msg("Camera rejected PTZ preset removal")
+		} else if err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrPTZCommandFailed, err)
+		}
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		"DELETE FROM camera_ptz_presets WHERE camera_id = $1 AND number = $2",
+		cameraID, number,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete PTZ preset: %w", err)
+	}
+
+	return nil
+}
+
+// presetToken returns the ONVIF token of a preset number
+// Numbers without a stored preset are sent as the token itself, which is how cameras
+// with numeric preset tokens name them.
+func (r *PostgresRepository) presetToken(ctx context.Context, cameraID string, number int) string {
+	var token string
+	err := r.db.QueryRowContext(ctx,
+		"SELECT token FROM camera_ptz_presets WHERE camera_id = $1 AND number = $2",
+		cameraID, number,
+	).Scan(&token)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Warn().Err(err).Str("camera_id", cameraID).Int("number", number).Msg("Failed to look up PTZ preset token")
+		}
+		return strconv.Itoa(number)
+	}
+	return token
+}
+
+// refreshPTZPresets brings the stored presets of a camera in line with the presets on it
+func (r *PostgresRepository) refreshPTZPresets(ctx context.Context, cameraID string, u *url.URL, caps *domain.PTZCapabilities) error {
+	onCamera, err := r.ptzDiscoverer.GetPresets(ctx, caps, u)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT number, token, LOWER(name) FROM camera_ptz_presets WHERE camera_id = $1 FOR UPDATE",
+		cameraID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query PTZ presets: %w", err)
+	}
+	usedNumbers := make(map[int]bool)
+	usedNames := make(map[string]bool)
+	stored := make(map[string]bool)
+	for rows.Next() {
+		var number int
+		var token, name string
+		if err := rows.Scan(&number, &token, &name); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan PTZ preset: %w", err)
+		}
+		usedNumbers[number] = true
+		usedNames[name] = true
+		stored[token] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating PTZ presets: %w", err)
+	}
+
+	tokens := make([]string, 0, len(onCamera))
+	number := 1
+	for _, preset := range onCamera {
+		tokens = append(tokens, preset.Token)
+		if stored[preset.Token] {
+			continue
+		}
+
+		for number <= domain.MaxPTZPresetNumber && usedNumbers[number] {
+			number++
+		}
+		if number > domain.MaxPTZPresetNumber {
+			r.logger.Warn().Str("camera_id", cameraID).Str("token", preset.Token).Msg("No free preset number for camera preset")
+			continue
+		}
+
+		name := strings.TrimSpace(preset.Name)
+		if name == "" || usedNames[strings.ToLower(name)] {
+			name = fmt.Sprintf("Preset %d", number)
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO camera_ptz_presets (camera_id, number, token, name)
+			VALUES ($1, $2, $3, $4)
+		`, cameraID, number, preset.Token, name)
+		if err != nil {
+			return fmt.Errorf("failed to import PTZ preset %s: %w", preset.Token, err)
+		}
+		usedNumbers[number] = true
+		usedNames[strings.ToLower(name)] = true
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE camera_ptz_presets
+		SET on_camera = (token = ANY($2)), updated_at = NOW()
+		WHERE camera_id = $1 AND on_camera <> (token = ANY($2))
+	`, cameraID, pq.Array(tokens))
+	if err != nil {
+		return fmt.Errorf("failed to flag PTZ presets: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit PTZ presets of camera %s: %w", cameraID, err)
+	}
+
+	return nil
+}
+
+// checkPresetAvailable fails with ErrPTZPresetExists when the number or a name of preset is taken
+func (r *PostgresRepository) checkPresetAvailable(ctx context.Context, preset *domain.PTZPreset) error {
+	var taken bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM camera_ptz_presets
+			WHERE camera_id = $1
+			  AND (number = $2 OR LOWER(name) = LOWER($3) OR name_ar = NULLIF($4, ''))
+		)
+	`, preset.CameraID, preset.Number, preset.Name, preset.NameAr).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("failed to check PTZ preset: %w", err)
+	}
+	if taken {
+		return fmt.Errorf("%w: %s", domain.ErrPTZPresetExists, preset.Name)
+	}
+
+	var count int
+	err = r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM camera_ptz_presets WHERE camera_id = $1",
+		preset.CameraID,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to count PTZ presets: %w", err)
+	}
+	if preset.Number == 0 && count >= domain.MaxPTZPresetNumber {
+		return fmt.Errorf("%w: no free preset number", domain.ErrPTZPresetExists)
+	}
+
+	return nil
+}
+
+// ensureCamera fails with ErrCameraNotFound when the camera does not exist
+func (r *PostgresRepository) ensureCamera(ctx context.Context, cameraID string) error {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM cameras WHERE id = $1)", cameraID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check camera: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", domain.ErrCameraNotFound, cameraID)
+	}
+	return nil
+}
+
+func scanPTZPreset(row interface{ Scan(...interface{}) error }) (*domain.PTZPreset, error) {
+	preset := &domain.PTZPreset{}
+	err := row.Scan(&preset.CameraID, &preset.Number, &preset.Token, &preset.Name, &preset.NameAr,
+		&preset.OnCamera, &preset.CreatedBy, &preset.CreatedAt, &preset.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pqUniqueViolation
+}
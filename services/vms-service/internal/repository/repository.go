@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/pkg/cameraevents"
 )
 
 // CameraRepository defines operations for camera data
@@ -27,6 +28,12 @@ type CameraRepository interface {
 	// ExecutePTZCommand sends PTZ command to camera
 	ExecutePTZCommand(ctx context.Context, cmd *domain.PTZCommand) error
 
+	// GetPTZDriver retrieves the PTZ driver detected for a camera
+	GetPTZDriver(ctx context.Context, cameraID string) (*domain.PTZDriverRecord, error)
+
+	// DetectPTZDriver detects the PTZ driver of a camera again
+	DetectPTZDriver(ctx context.Context, cameraID string) (*domain.PTZDriverRecord, error)
+
 	// GetRecordingSegments retrieves available recording segments for time range
 	GetRecordingSegments(ctx context.Context, cameraID string, start, end time.Time) ([]*domain.RecordingSegment, error)
 
@@ -36,8 +43,125 @@ type CameraRepository interface {
 	// GetExportStatus retrieves status of an export job
 	GetExportStatus(ctx context.Context, exportID string) (*domain.RecordingExport, error)
 
-	// Sync performs full synchronization with Milestone VMS
-	Sync(ctx context.Context) error
+	// HealthCheck verifies the connection to the camera store
+	HealthCheck(ctx context.Context) error
+}
+
+// MilestoneSyncRepository defines storage for the Milestone camera sync
+type MilestoneSyncRepository interface {
+	// GetMilestoneCameras retrieves every camera linked to a Milestone device, decommissioned ones included
+	GetMilestoneCameras(ctx context.Context) ([]*domain.Camera, error)
+
+	// CreateSyncedCamera inserts a camera discovered in Milestone
+	// Credentials in streamURL are split off and encrypted before storage
+	CreateSyncedCamera(ctx context.Context, camera *domain.Camera, streamURL string) error
+
+	// UpdateSyncedCamera writes the name, status and recording server of a synced camera
+	// fields lists what changed, for the camera events
+	UpdateSyncedCamera(ctx context.Context, camera *domain.Camera, fields []domain.SyncFieldChange) error
+
+	// DecommissionCamera marks a camera removed from Milestone as DECOMMISSIONED
+	DecommissionCamera(ctx context.Context, camera *domain.Camera) error
+
+	// MarkSynced records that the cameras linked to deviceIDs were seen in Milestone
+	MarkSynced(ctx context.Context, deviceIDs []string) error
+
+	// CreateSyncJob records the start of a sync job and sets its ID
+	CreateSyncJob(ctx context.Context, job *domain.SyncJob) error
+
+	// CompleteSyncJob records the outcome and change report of a sync job
+	CompleteSyncJob(ctx context.Context, job *domain.SyncJob) error
+
+	// GetSyncJob retrieves a sync job with its change report
+	GetSyncJob(ctx context.Context, id string) (*domain.SyncJob, error)
+}
+
+// CameraEventOutbox defines storage for camera change events awaiting publication
+// Writers record events in the transaction of the change they describe.
+type CameraEventOutbox interface {
+	// PublishPending passes up to limit unpublished events, oldest first, to publish and marks
+	// them published once it returns nil. Returns the number published.
+	PublishPending(ctx context.Context, limit int, publish func(ctx context.Context, events []*cameraevents.Event) error) (int, error)
+
+	// DeletePublished removes events published before cutoff
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+// CameraHealthRepository defines storage for camera health probing
+type CameraHealthRepository interface {
+	// ListProbeTargets retrieves the cameras to probe, with decrypted dial URLs
+	ListProbeTargets(ctx context.Context) ([]*domain.ProbeTarget, error)
+
+	// RecordProbe stores a probe result; a changed status is written with its transition
+	RecordProbe(ctx context.Context, target *domain.ProbeTarget, probe *domain.HealthProbe) error
+
+	// GetCameraHealth retrieves the status and last probe of a camera
+	GetCameraHealth(ctx context.Context, cameraID string) (*domain.CameraHealth, error)
+
+	// ListStatusTransitions retrieves the transitions since a time, newest first,
+	// followed by the last transition before it
+	ListStatusTransitions(ctx context.Context, cameraID string, since time.Time) ([]domain.StatusTransition, error)
+}
+
+// PTZPresetRepository defines storage for named PTZ presets, kept in step with the camera
+type PTZPresetRepository interface {
+	// ListPTZPresets retrieves the presets of a camera; refresh reads the presets on the camera first
+	ListPTZPresets(ctx context.Context, cameraID string, refresh bool) ([]*domain.PTZPreset, error)
+
+	// GetPTZPreset retrieves one preset of a camera
+	GetPTZPreset(ctx context.Context, cameraID string, number int) (*domain.PTZPreset, error)
+
+	// CreatePTZPreset saves the camera's current position as a new preset
+	CreatePTZPreset(ctx context.Context, preset *domain.PTZPreset) error
+
+	// UpdatePTZPreset renames a preset, storing the current position under it with savePosition
+	UpdatePTZPreset(ctx context.Context, preset *domain.PTZPreset, savePosition bool) error
+
+	// DeletePTZPreset removes a preset from the camera and deletes it
+	DeletePTZPreset(ctx context.Context, cameraID string, number int) error
+}
+
+// PTZPositionRepository defines camera position reads and the optics click-to-center relies on
+type PTZPositionRepository interface {
+	// GetPTZStatus reads the position and movement state of a camera over ONVIF
+	GetPTZStatus(ctx context.Context, cameraID string) (*domain.PTZStatus, error)
+
+	// GetPTZFieldOfView retrieves the field of view of a camera, the defaults when none is configured
+	GetPTZFieldOfView(ctx context.Context, cameraID string) (*domain.PTZFieldOfView, error)
+
+	// SetPTZFieldOfView configures the field of view of a camera
+	SetPTZFieldOfView(ctx context.Context, cameraID string, fov *domain.PTZFieldOfView) error
+}
+
+// PTZPatrolRepository defines storage for camera patrols
+type PTZPatrolRepository interface {
+	// ListPTZPatrols retrieves the patrols of all cameras
+	ListPTZPatrols(ctx context.Context) ([]*domain.PTZPatrol, error)
+
+	// GetPTZPatrol retrieves the patrol of a camera
+	GetPTZPatrol(ctx context.Context, cameraID string) (*domain.PTZPatrol, error)
+
+	// SavePTZPatrol creates or replaces the patrol of a PTZ camera
+	SavePTZPatrol(ctx context.Context, patrol *domain.PTZPatrol) error
+
+	// DeletePTZPatrol removes the patrol of a camera
+	DeletePTZPatrol(ctx context.Context, cameraID string) error
+}
+
+// CameraAlarmRepository defines storage for the alarms cameras raise over ONVIF events
+type CameraAlarmRepository interface {
+	// ListAlarmTargets retrieves the cameras to subscribe to, with decrypted dial URLs
+	ListAlarmTargets(ctx context.Context) ([]*domain.AlarmTarget, error)
+
+	// RecordCameraAlarm stores an alarm
+	RecordCameraAlarm(ctx context.Context, alarm *domain.CameraAlarm) error
+
+	// ListCameraAlarms retrieves the alarms of a camera that occurred since a time, newest first
+	// alarmType filters the alarms when it is not empty
+	ListCameraAlarms(ctx context.Context, cameraID string, alarmType domain.AlarmType, since time.Time, limit int) ([]domain.CameraAlarm, error)
+
+	// DeleteCameraAlarms removes alarms received before cutoff
+	DeleteCameraAlarms(ctx context.Context, before time.Time) (int64, error)
 }
 
 // CacheRepository defines operations for caching camera data
@@ -0,0 +1,106 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+// vectorsFile is shared with go-api, which reads and writes the same credentials
+const vectorsFile = "../../../testdata/credentials_envelope.json"
+
+type envelopeVectors struct {
+	Key    string `json:"key"`
+	KeyID  string `json:"key_id"`
+	Sealed []struct {
+		Plaintext string `json:"plaintext"`
+		Value     string `json:"value"`
+	} `json:"sealed"`
+	MaskedURLs []struct {
+		URL    string `json:"url"`
+		Masked string `json:"masked"`
+	} `json:"masked_urls"`
+}
+
+func loadVectors(t *testing.T) (*envelopeVectors, *Envelope) {
+	t.Helper()
+
+	data, err := os.ReadFile(vectorsFile)
+	if err != nil {
+		t.Fatalf("read vectors: %v", err)
+	}
+	var vectors envelopeVectors
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("decode vectors: %v", err)
+	}
+
+	key, err := LoadKey(vectors.Key, "")
+	if err != nil {
+		t.Fatalf("load key: %v", err)
+	}
+	envelope, err := NewEnvelope(vectors.KeyID, key)
+	if err != nil {
+		t.Fatalf("new envelope: %v", err)
+	}
+
+	return &vectors, envelope
+}
+
+func TestDecryptSharedVectors(t *testing.T) {
+	vectors, envelope := loadVectors(t)
+
+	for _, vector := range vectors.Sealed {
+		plaintext, err := envelope.Decrypt(vector.Value)
+		if err != nil {
+			t.Errorf("Decrypt(%q): %v", vector.Plaintext, err)
+			continue
+		}
+		if plaintext != vector.Plaintext {
+			t.Errorf("Decrypt = %q, want %q", plaintext, vector.Plaintext)
+		}
+	}
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	vectors, envelope := loadVectors(t)
+
+	for _, vector := range vectors.Sealed {
+		value, err := envelope.Encrypt(vector.Plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt: %v", err)
+		}
+		if !IsEncrypted(value) {
+			t.Errorf("Encrypt(%q) = %q, not an encrypted value", vector.Plaintext, value)
+		}
+
+		plaintext, err := envelope.Decrypt(value)
+		if err != nil || plaintext != vector.Plaintext {
+			t.Errorf("Decrypt(Encrypt(%q)) = %q, %v", vector.Plaintext, plaintext, err)
+		}
+	}
+}
+
+func TestDecryptUnknownKey(t *testing.T) {
+	vectors, _ := loadVectors(t)
+
+	key, _ := LoadKey(vectors.Key, "")
+	other, err := NewEnvelope("other", key)
+	if err != nil {
+		t.Fatalf("new envelope: %v", err)
+	}
+
+	if _, err := other.Decrypt(vectors.Sealed[0].Value); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt with another key ID = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestMaskURLSharedVectors(t *testing.T) {
+	vectors, _ := loadVectors(t)
+
+	for _, vector := range vectors.MaskedURLs {
+		if masked := MaskURL(vector.URL); masked != vector.Masked {
+			t.Errorf("MaskURL(%q) = %q, want %q", vector.URL, masked, vector.Masked)
+		}
+	}
+}
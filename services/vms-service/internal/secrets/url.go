@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"net/url"
+	"strings"
+)
+
+// mask replaces credentials in logs and API responses
+const mask = "***"
+
+// MaskURL hides the user info of a URL, e.g. rtsp://***@10.0.0.5:554/stream1
+// Use it for every URL that is logged or returned to a client
+func MaskURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// Unparseable URLs may still carry credentials before the last @ of the authority
+		if strings.Contains(rawURL, "@") {
+			return mask
+		}
+		return rawURL
+	}
+	if u.User == nil {
+		return rawURL
+	}
+
+	// url.User would percent-encode the mask
+	u.User = nil
+	return strings.Replace(u.String(), "://", "://"+mask+"@", 1)
+}
+
+// SplitURLCredentials removes the user info of a URL and returns it separately
+// URLs that cannot be parsed are returned unchanged
+func SplitURLCredentials(rawURL string) (cleanURL, username, password string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL, "", ""
+	}
+
+	username = u.User.Username()
+	password, _ = u.User.Password()
+	u.User = nil
+	return u.String(), username, password
+}
+
+// WithURLCredentials adds user info to a URL that has none
+// Only the code that dials cameras should build these URLs, and never log them
+func WithURLCredentials(rawURL, username, password string) string {
+	if username == "" {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User != nil {
+		return rawURL
+	}
+
+	if password == "" {
+		u.User = url.User(username)
+	} else {
+		u.User = url.UserPassword(username, password)
+	}
+	return u.String()
+}
@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rta/cctv/vms-service/internal/client"
+	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/internal/repository"
+	"github.com/rta/cctv/vms-service/pkg/cameraalarms"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// alarmPullWait is how long a PullMessages request waits for events
+	alarmPullWait = 10 * time.Second
+
+	// alarmPullLimit is the most events read by one PullMessages request
+	alarmPullLimit = 100
+
+	// alarmRetryMin and alarmRetryMax bound the backoff before subscribing again
+	alarmRetryMin = 5 * time.Second
+	alarmRetryMax = 5 * time.Minute
+
+	// alarmRetention is how long alarms are kept
+	alarmRetention = 30 * 24 * time.Hour
+)
+
+// AlarmSubscriber reads motion, tamper and digital input alarms from cameras over ONVIF
+// Each ONLINE camera gets an ONVIF PullPoint subscription, renewed when half its lifetime
+// has passed and created again after any failure. Events are normalized to alarms; an alarm
+// is stored only when its state changes, so the states a camera reports on every new
+// subscription are not repeated. Stored alarms are then published to a Valkey Stream when a
+// client is set. Subscriptions are held in memory, so only one instance should run it.
+type AlarmSubscriber struct {
+	repo      repository.CameraAlarmRepository
+	events    *client.ONVIFEventClient
+	publisher *redis.Client
+	stream    string
+	ttl       time.Duration
+	logger    zerolog.Logger
+
+	mu            sync.Mutex
+	subscriptions map[string]*alarmSubscription // By camera ID
+}
+
+// alarmSubscription is the subscription loop of one camera
+type alarmSubscription struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	state  domain.AlarmSubscription
+}
+
+// NewAlarmSubscriber creates a new camera alarm subscriber
+// publisher may be nil to only store alarms; ttl is the lifetime requested for subscriptions.
+func NewAlarmSubscriber(repo repository.CameraAlarmRepository, events *client.ONVIFEventClient, publisher *redis.Client, stream string, ttl time.Duration, logger zerolog.Logger) *AlarmSubscriber {
+	if ttl < time.Minute {
+		ttl = time.Minute
+	}
+
+	return &AlarmSubscriber{
+		repo:          repo,
+		events:        events,
+		publisher:     publisher,
+		stream:        stream,
+		ttl:           ttl,
+		logger:        logger,
+		subscriptions: make(map[string]*alarmSubscription),
+	}
+}
+
+// Run subscribes to the ONLINE cameras and checks every interval for cameras that came online
+// or went away, until ctx is cancelled. Alarms older than 30 days are pruned hourly.
+func (s *AlarmSubscriber) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pruneTicker := time.NewTicker(time.Hour)
+	defer pruneTicker.Stop()
+
+	s.reconcile(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			s.stopAll()
+			return
+		case <-ticker.C:
+			s.reconcile(ctx)
+		case <-pruneTicker.C:
+			deleted, err := s.repo.DeleteCameraAlarms(ctx, time.Now().Add(-alarmRetention))
+			if err != nil {
+				s.logger.Warn().Err(err).Msg("Failed to prune camera alarms")
+			} else if deleted > 0 {
+				s.logger.Debug().Int64("deleted", deleted).Msg("Pruned camera alarms")
+			}
+		}
+	}
+}
+
+// reconcile starts subscriptions for new targets and stops those of cameras no longer listed
+func (s *AlarmSubscriber) reconcile(ctx context.Context) {
+	targets, err := s.repo.ListAlarmTargets(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error().Err(err).Msg("Failed to list cameras to subscribe to")
+		}
+		return
+	}
+
+	listed := make(map[string]bool, len(targets))
+	var stopped []*alarmSubscription
+
+	s.mu.Lock()
+	for _, target := range targets {
+		listed[target.CameraID] = true
+		if _, ok := s.subscriptions[target.CameraID]; ok {
+			continue
+		}
+
+		subCtx, cancel := context.WithCancel(ctx)
+		sub := &alarmSubscription{
+			cancel: cancel,
+			done:   make(chan struct{}),
+			state: domain.AlarmSubscription{
+				CameraID: target.CameraID,
+				State:    domain.AlarmSubscriptionConnecting,
+			},
+		}
+		s.subscriptions[target.CameraID] = sub
+		go s.subscribe(subCtx, target, sub)
+	}
+	for id, sub := range s.subscriptions {
+		if !listed[id] {
+			sub.cancel()
+			stopped = append(stopped, sub)
+			delete(s.subscriptions, id)
+		}
+	}
+	s.mu.Unlock()
+
+	// Wait outside the lock; the loops update their state under it
+	for _, sub := range stopped {
+		<-sub.done
+	}
+}
+
+// stopAll stops every subscription and waits for them to unsubscribe
+func (s *AlarmSubscriber) stopAll() {
+	s.mu.Lock()
+	subscriptions := s.subscriptions
+	s.subscriptions = make(map[string]*alarmSubscription)
+	s.mu.Unlock()
+
+	for _, sub := range subscriptions {
+		sub.cancel()
+	}
+	for _, sub := range subscriptions {
+		<-sub.done
+	}
+}
+
+// subscribe holds a subscription on the camera until ctx is cancelled, subscribing again
+// with a growing backoff after each failure
+func (s *AlarmSubscriber) subscribe(ctx context.Context, target *domain.AlarmTarget, sub *alarmSubscription) {
+	defer close(sub.done)
+
+	// Last state by alarm, kept across subscriptions
+	states := make(map[string]domain.AlarmState)
+	backoff := alarmRetryMin
+
+	for {
+		err := s.hold(ctx, target, sub, states)
+		if ctx.Err() != nil {
+			return
+		}
+
+		retryAt := time.Now().Add(backoff).UTC()
+		s.update(sub, func(state *domain.AlarmSubscription) {
+			// A subscription that delivered before failing starts the backoff over
+			if state.State == domain.AlarmSubscriptionSubscribed {
+				backoff = alarmRetryMin
+				retryAt = time.Now().Add(backoff).UTC()
+			}
+			state.State = domain.AlarmSubscriptionRetrying
+			state.LastError = err.Error()
+			state.RetryAt = &retryAt
+		})
+
+		s.logger.Warn().
+			Err(err).
+			Str("camera_id", target.CameraID).
+			Time("retry_at", retryAt).
+			Msg("Camera alarm subscription failed")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(retryAt)):
+		}
+
+		backoff *= 2
+		if backoff > alarmRetryMax {
+			backoff = alarmRetryMax
+		}
+	}
+}
+
+// hold creates a subscription and pulls its events until it fails or ctx is cancelled
+func (s *AlarmSubscriber) hold(ctx context.Context, target *domain.AlarmTarget, sub *alarmSubscription, states map[string]domain.AlarmState) error {
+	eventURL, err := s.events.EventService(ctx, target.DialURL, target.ONVIFEndpoint)
+	if err != nil {
+		return err
+	}
+
+	pullPoint, err := s.events.CreatePullPoint(ctx, target.DialURL, eventURL, s.ttl)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// The camera drops the subscription at its termination time if this fails
+		unsubscribeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.events.Unsubscribe(unsubscribeCtx, target.DialURL, pullPoint); err != nil {
+			s.logger.Debug().Err(err).Str("camera_id", target.CameraID).Msg("Failed to unsubscribe from camera events")
+		}
+	}()
+
+	now := time.Now().UTC()
+	termination := pullPoint.TerminationTime.UTC()
+	s.update(sub, func(state *domain.AlarmSubscription) {
+		state.State = domain.AlarmSubscriptionSubscribed
+		state.SubscribedAt = &now
+		state.TerminationTime = &termination
+		state.LastError = ""
+		state.RetryAt = nil
+	})
+
+	s.logger.Info().
+		Str("camera_id", target.CameraID).
+		Time("termination_time", termination).
+		Msg("Subscribed to camera events")
+
+	for {
+		if time.Until(pullPoint.TerminationTime) < s.ttl/2 {
+			if err := s.events.Renew(ctx, target.DialURL, pullPoint, s.ttl); err != nil {
+				return err
+			}
+			termination := pullPoint.TerminationTime.UTC()
+			s.update(sub, func(state *domain.AlarmSubscription) {
+				state.TerminationTime = &termination
+			})
+		}
+
+		alarms, err := s.events.PullMessages(ctx, target.DialURL, pullPoint, alarmPullWait, alarmPullLimit)
+		if err != nil {
+			return err
+		}
+
+		for i := range alarms {
+			alarm := &alarms[i]
+			key := fmt.Sprintf("%s|%s|%s", alarm.Type, alarm.Topic, alarm.Source)
+			if states[key] == alarm.State {
+				continue
+			}
+			states[key] = alarm.State
+
+			alarm.ID = uuid.New().String()
+			alarm.CameraID = target.CameraID
+			s.handle(ctx, alarm)
+		}
+
+		if len(alarms) > 0 {
+			received := time.Now().UTC()
+			s.update(sub, func(state *domain.AlarmSubscription) {
+				state.LastEventAt = &received
+			})
+		}
+	}
+}
+
+// handle stores an alarm and publishes it
+// A failed publish is only logged; the alarm stays available from the history.
+func (s *AlarmSubscriber) handle(ctx context.Context, alarm *domain.CameraAlarm) {
+	if err := s.repo.RecordCameraAlarm(ctx, alarm); err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error().Err(err).Str("camera_id", alarm.CameraID).Msg("Failed to record camera alarm")
+		}
+		return
+	}
+
+	s.logger.Debug().
+		Str("camera_id", alarm.CameraID).
+		Str("type", string(alarm.Type)).
+		Str("state", string(alarm.State)).
+		Str("source", alarm.Source).
+		Msg("Camera alarm received")
+
+	if s.publisher == nil {
+		return
+	}
+
+	err := cameraalarms.Publish(ctx, s.publisher, s.stream, &cameraalarms.Alarm{
+		SchemaVersion: cameraalarms.SchemaVersion,
+		ID:            alarm.ID,
+		CameraID:      alarm.CameraID,
+		Type:          string(alarm.Type),
+		State:         string(alarm.State),
+		Source:        alarm.Source,
+		Topic:         alarm.Topic,
+		OccurredAt:    alarm.OccurredAt,
+	})
+	if err != nil && ctx.Err() == nil {
+		s.logger.Warn().Err(err).Str("camera_id", alarm.CameraID).Msg("Failed to publish camera alarm")
+	}
+}
+
+// update changes the state of a subscription under the lock
+func (s *AlarmSubscriber) update(sub *alarmSubscription, change func(state *domain.AlarmSubscription)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change(&sub.state)
+}
+
+// GetSubscription retrieves the ONVIF event subscription held for a camera
+func (s *AlarmSubscriber) GetSubscription(cameraID string) (*domain.AlarmSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions[cameraID]
+	if !ok {
+		return nil, domain.ErrAlarmSubscriptionNotFound
+	}
+
+	state := sub.state
+	return &state, nil
+}
+
+// ListAlarms retrieves the alarms of a camera that occurred since a time, newest first
+func (s *AlarmSubscriber) ListAlarms(ctx context.Context, cameraID string, alarmType domain.AlarmType, since time.Time, limit int) ([]domain.CameraAlarm, error) {
+	return s.repo.ListCameraAlarms(ctx, cameraID, alarmType, since, limit)
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rta/cctv/vms-service/internal/repository"
+	"github.com/rta/cctv/vms-service/pkg/cameraevents"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// relayBatchSize is the number of outbox events published per transaction
+	relayBatchSize = 100
+
+	// outboxRetention is how long published events stay in the outbox
+	outboxRetention = 7 * 24 * time.Hour
+)
+
+// CameraEventRelay publishes the camera events outbox to a Valkey Stream
+// An event is marked published only after the stream accepted it, so delivery is at least once.
+type CameraEventRelay struct {
+	outbox repository.CameraEventOutbox
+	client *redis.Client
+	stream string
+	logger zerolog.Logger
+}
+
+// NewCameraEventRelay creates a new camera event relay
+func NewCameraEventRelay(outbox repository.CameraEventOutbox, client *redis.Client, stream string, logger zerolog.Logger) *CameraEventRelay {
+	return &CameraEventRelay{
+		outbox: outbox,
+		client: client,
+		stream: stream,
+		logger: logger,
+	}
+}
+
+// Run publishes pending events every interval and prunes old published ones hourly,
+// until ctx is cancelled
+func (r *CameraEventRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pruneTicker := time.NewTicker(time.Hour)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.publishPending(ctx)
+		case <-pruneTicker.C:
+			deleted, err := r.outbox.DeletePublished(ctx, time.Now().Add(-outboxRetention))
+			if err != nil {
+				r.logger.Warn().Err(err).Msg("Failed to prune camera events outbox")
+			} else if deleted > 0 {
+				r.logger.Debug().Int64("deleted", deleted).Msg("Pruned camera events outbox")
+			}
+		}
+	}
+}
+
+// publishPending drains the outbox batch by batch
+func (r *CameraEventRelay) publishPending(ctx context.Context) {
+	for {
+		published, err := r.outbox.PublishPending(ctx, relayBatchSize, r.publish)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.Error().Err(err).Msg("Failed to relay camera events")
+			}
+			return
+		}
+
+		if published > 0 {
+			r.logger.Debug().Int("published", published).Str("stream", r.stream).Msg("Relayed camera events")
+		}
+		if published < relayBatchSize {
+			return
+		}
+	}
+}
+
+// publish appends events to the stream in order
+func (r *CameraEventRelay) publish(ctx context.Context, events []*cameraevents.Event) error {
+	for _, event := range events {
+		if err := cameraevents.Publish(ctx, r.client, r.stream, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
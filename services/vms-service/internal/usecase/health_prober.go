@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/client"
+	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+// HealthProber checks every camera on an interval and records status changes
+// A camera is probed with a TCP connect to its RTSP port, an RTSP OPTIONS and DESCRIBE
+// handshake and, when it has an ONVIF endpoint, GetSystemDateAndTime. An ONLINE camera
+// goes OFFLINE or ERROR only after failureThreshold consecutive failed probes, so a
+// single dropped packet does not flap it.
+type HealthProber struct {
+	repo             repository.CameraHealthRepository
+	checker          *client.CameraChecker
+	workers          int
+	failureThreshold int
+	logger           zerolog.Logger
+
+	mu       sync.Mutex
+	failures map[string]int // Consecutive failed probes by camera ID
+}
+
+// NewHealthProber creates a new camera health prober
+func NewHealthProber(repo repository.CameraHealthRepository, checker *client.CameraChecker, workers, failureThreshold int, logger zerolog.Logger) *HealthProber {
+	if workers < 1 {
+		workers = 1
+	}
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	return &HealthProber{
+		repo:             repo,
+		checker:          checker,
+		workers:          workers,
+		failureThreshold: failureThreshold,
+		logger:           logger,
+		failures:         make(map[string]int),
+	}
+}
+
+// Run probes all cameras immediately and then every interval until ctx is cancelled
+// A round that outlasts the interval delays the next one rather than overlapping it.
+func (p *HealthProber) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.probeAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeAll probes every target with at most workers probes in flight
+func (p *HealthProber) probeAll(ctx context.Context) {
+	targets, err := p.repo.ListProbeTargets(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error().Err(err).Msg("Failed to list cameras to probe")
+		}
+		return
+	}
+
+	started := time.Now()
+	sem := make(chan struct{}, p.workers)
+	var wg sync.WaitGroup
+
+	for _, target := range targets {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(target *domain.ProbeTarget) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			p.probe(ctx, target)
+		}(target)
+	}
+	wg.Wait()
+
+	p.forget(targets)
+
+	p.logger.Debug().
+		Int("cameras", len(targets)).
+		Dur("duration", time.Since(started)).
+		Msg("Camera health probe round finished")
+}
+
+// probe checks one camera and records the outcome
+func (p *HealthProber) probe(ctx context.Context, target *domain.ProbeTarget) {
+	probe := &domain.HealthProbe{Checks: []domain.HealthCheck{}}
+	status, reason := p.check(ctx, target, probe)
+	probe.CheckedAt = time.Now().UTC()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	probe.Status, probe.Reason = p.applyThreshold(target, status, reason)
+
+	if err := p.repo.RecordProbe(ctx, target, probe); err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error().Err(err).Str("camera_id", target.CameraID).Msg("Failed to record camera probe")
+		}
+		return
+	}
+
+	if probe.Status != target.Status {
+		p.logger.Info().
+			Str("camera_id", target.CameraID).
+			Str("previous_status", string(target.Status)).
+			Str("status", string(probe.Status)).
+			Str("reason", probe.Reason).
+			Msg("Camera status changed")
+	}
+}
+
+// check runs the checks in order, stopping at the first failure, and returns the status they indicate
+// An unreachable port means OFFLINE; a camera that answers but fails RTSP or ONVIF is in ERROR.
+func (p *HealthProber) check(ctx context.Context, target *domain.ProbeTarget, probe *domain.HealthProbe) (domain.CameraStatus, string) {
+	if err := p.run(probe, domain.HealthCheckTCP, func() error {
+		return p.checker.CheckTCP(ctx, client.RTSPAddress(target.DialURL))
+	}); err != nil {
+		return domain.StatusOffline, domain.HealthCheckTCP + ": " + err.Error()
+	}
+
+	if err := p.run(probe, domain.HealthCheckRTSP, func() error {
+		return p.checker.CheckRTSP(ctx, target.DialURL)
+	}); err != nil {
+		return domain.StatusError, domain.HealthCheckRTSP + ": " + err.Error()
+	}
+
+	if target.ONVIFEndpoint != "" {
+		if err := p.run(probe, domain.HealthCheckONVIF, func() error {
+			return p.checker.CheckONVIF(ctx, target.ONVIFEndpoint)
+		}); err != nil {
+			return domain.StatusError, domain.HealthCheckONVIF + ": " + err.Error()
+		}
+	}
+
+	return domain.StatusOnline, ""
+}
+
+// run times one check and adds it to the probe
+func (p *HealthProber) run(probe *domain.HealthProbe, name string, check func() error) error {
+	started := time.Now()
+	err := check()
+
+	result := domain.HealthCheck{
+		Name:      name,
+		OK:        err == nil,
+		LatencyMS: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	probe.Checks = append(probe.Checks, result)
+
+	return err
+}
+
+// applyThreshold holds an ONLINE camera until failureThreshold consecutive probes failed
+func (p *HealthProber) applyThreshold(target *domain.ProbeTarget, status domain.CameraStatus, reason string) (domain.CameraStatus, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if status == domain.StatusOnline {
+		delete(p.failures, target.CameraID)
+		return status, reason
+	}
+
+	p.failures[target.CameraID]++
+	if target.Status == domain.StatusOnline && p.failures[target.CameraID] < p.failureThreshold {
+		return target.Status, target.StatusReason
+	}
+
+	return status, reason
+}
+
+// forget drops the failure counts of cameras no longer probed
+func (p *HealthProber) forget(targets []*domain.ProbeTarget) {
+	probed := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		probed[target.CameraID] = true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id := range p.failures {
+		if !probed[id] {
+			delete(p.failures, id)
+		}
+	}
+}
+
+// GetHealth retrieves the health of a camera over the window ending now
+// Uptime is the share of the window spent ONLINE, counted from the first known status;
+// it is nil when the camera has no status history.
+func (p *HealthProber) GetHealth(ctx context.Context, cameraID string, window time.Duration) (*domain.CameraHealth, error) {
+	health, err := p.repo.GetCameraHealth(ctx, cameraID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	since := now.Add(-window)
+
+	transitions, err := p.repo.ListStatusTransitions(ctx, cameraID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	health.Window = window.String()
+	health.UptimePercent = uptime(transitions, since, now)
+
+	// The transition before the window only gives the status at its start
+	health.Transitions = []domain.StatusTransition{}
+	for _, transition := range transitions {
+		if transition.ChangedAt.Before(since) {
+			break
+		}
+		health.Transitions = append(health.Transitions, transition)
+	}
+
+	return health, nil
+}
+
+// uptime computes the ONLINE share of [since, now] from transitions, newest first
+func uptime(transitions []domain.StatusTransition, since, now time.Time) *float64 {
+	if len(transitions) == 0 {
+		return nil
+	}
+
+	var observed, online time.Duration
+	end := now
+	for _, transition := range transitions {
+		start := transition.ChangedAt
+		if start.Before(since) {
+			start = since
+		}
+		if end.After(start) {
+			observed += end.Sub(start)
+			if transition.Status == domain.StatusOnline {
+				online += end.Sub(start)
+			}
+		}
+		end = start
+	}
+
+	if observed <= 0 {
+		return nil
+	}
+
+	percent := float64(online) / float64(observed) * 100
+	return &percent
+}
@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/client"
+	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// syncPageSize is the number of cameras requested from Milestone per page
+	syncPageSize = 500
+
+	// syncTimeout bounds a single sync run, Milestone paging included
+	syncTimeout = 15 * time.Minute
+
+	// SyncInitiatorScheduler is recorded as the initiator of background syncs
+	SyncInitiatorScheduler = "scheduler"
+)
+
+// MilestoneSync mirrors the Milestone camera list into the cameras table
+// Cameras are matched on milestone_device_id: new devices are inserted, changed names and
+// recording servers are updated, and devices gone from Milestone are decommissioned.
+// Devices disabled in Milestone are taken OFFLINE; the health probe sets the status of the rest.
+// Only one sync runs at a time in this instance.
+type MilestoneSync struct {
+	milestone *client.MilestoneClient
+	repo      repository.MilestoneSyncRepository
+	source    domain.CameraSource // Source of cameras created by the sync
+	logger    zerolog.Logger
+
+	mu      sync.Mutex
+	running string // ID of the job in progress
+}
+
+// NewMilestoneSync creates a new Milestone camera sync
+func NewMilestoneSync(milestone *client.MilestoneClient, repo repository.MilestoneSyncRepository, source domain.CameraSource, logger zerolog.Logger) *MilestoneSync {
+	return &MilestoneSync{
+		milestone: milestone,
+		repo:      repo,
+		source:    source,
+		logger:    logger,
+	}
+}
+
+// Start records a new sync job and runs it in the background
+// Returns a snapshot of the job as started; GetJob returns the report once it completes
+func (s *MilestoneSync) Start(ctx context.Context, initiatedBy string) (*domain.SyncJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running != "" {
+		return nil, fmt.Errorf("%w: job %s", domain.ErrSyncInProgress, s.running)
+	}
+
+	job := &domain.SyncJob{
+		Type:        domain.SyncTypeFull,
+		Status:      domain.SyncJobInProgress,
+		InitiatedBy: initiatedBy,
+		Changes:     []domain.SyncCameraChange{},
+		StartedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.CreateSyncJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.running = job.ID
+	started := *job
+	go s.run(job)
+
+	return &started, nil
+}
+
+// GetJob retrieves a sync job with its change report
+func (s *MilestoneSync) GetJob(ctx context.Context, id string) (*domain.SyncJob, error) {
+	return s.repo.GetSyncJob(ctx, id)
+}
+
+// Run starts a sync immediately and then every interval until ctx is cancelled
+// Ticks that find a sync already running are skipped
+func (s *MilestoneSync) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Start(ctx, SyncInitiatorScheduler); err != nil {
+			s.logger.Warn().Err(err).Msg("Scheduled Milestone sync not started")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run executes a job and stores its report
+func (s *MilestoneSync) run(job *domain.SyncJob) {
+	defer func() {
+		s.mu.Lock()
+		s.running = ""
+		s.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	s.logger.Info().
+		Str("job_id", job.ID).
+		Str("initiated_by", job.InitiatedBy).
+		Msg("Starting Milestone camera sync")
+
+	if err := s.sync(ctx, job); err != nil {
+		job.Status = domain.SyncJobFailed
+		job.Error = err.Error()
+		s.logger.Error().Err(err).Str("job_id", job.ID).Msg("Milestone camera sync failed")
+	} else {
+		job.Status = domain.SyncJobCompleted
+	}
+
+	completedAt := time.Now().UTC()
+	job.CompletedAt = &completedAt
+
+	// The sync context may have run out; the report must still be stored
+	storeCtx, storeCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer storeCancel()
+
+	if err := s.repo.CompleteSyncJob(storeCtx, job); err != nil {
+		s.logger.Error().Err(err).Str("job_id", job.ID).Msg("Failed to store Milestone sync report")
+	}
+
+	s.logger.Info().
+		Str("job_id", job.ID).
+		Str("status", job.Status).
+		Int("discovered", job.CamerasDiscovered).
+		Int("imported", job.CamerasImported).
+		Int("updated", job.CamerasUpdated).
+		Int("decommissioned", job.CamerasDecommissioned).
+		Int("errors", job.Errors).
+		Dur("duration", completedAt.Sub(job.StartedAt)).
+		Msg("Milestone camera sync finished")
+}
+
+// sync diffs Milestone against the cameras table and applies the changes to job
+// Failures on single cameras are reported in the job; only failures that would make
+// the diff unreliable fail the job.
+func (s *MilestoneSync) sync(ctx context.Context, job *domain.SyncJob) error {
+	devices, err := s.listDevices(ctx)
+	if err != nil {
+		return err
+	}
+	job.CamerasDiscovered = len(devices)
+
+	existing, err := s.repo.GetMilestoneCameras(ctx)
+	if err != nil {
+		return err
+	}
+
+	// An empty list is far more likely a Milestone fault than every camera being removed
+	if len(devices) == 0 && len(existing) > 0 {
+		return fmt.Errorf("Milestone returned no cameras; refusing to decommission %d cameras", len(existing))
+	}
+
+	byDevice := make(map[string]*domain.Camera, len(existing))
+	for _, camera := range existing {
+		byDevice[camera.MilestoneDeviceID] = camera
+	}
+
+	seen := make(map[string]bool, len(devices))
+	unchanged := []string{}
+
+	for _, device := range devices {
+		if device.ID == "" || seen[device.ID] {
+			continue
+		}
+		seen[device.ID] = true
+
+		camera, ok := byDevice[device.ID]
+		if !ok {
+			s.create(ctx, job, device)
+			continue
+		}
+
+		fields := diffCamera(camera, device)
+		if len(fields) == 0 {
+			unchanged = append(unchanged, device.ID)
+			continue
+		}
+
+		change := domain.SyncCameraChange{
+			MilestoneDeviceID: device.ID,
+			CameraID:          camera.ID,
+			Name:              camera.Name,
+			Action:            domain.SyncActionUpdated,
+			Fields:            fields,
+		}
+		if err := s.repo.UpdateSyncedCamera(ctx, camera, fields); err != nil {
+			s.fail(job, change, err)
+			continue
+		}
+		job.CamerasUpdated++
+		job.Changes = append(job.Changes, change)
+	}
+
+	for _, camera := range existing {
+		if seen[camera.MilestoneDeviceID] || camera.Status == domain.StatusDecommissioned {
+			continue
+		}
+
+		change := domain.SyncCameraChange{
+			MilestoneDeviceID: camera.MilestoneDeviceID,
+			CameraID:          camera.ID,
+			Name:              camera.Name,
+			Action:            domain.SyncActionDecommissioned,
+			Fields: []domain.SyncFieldChange{
+				{Field: "status", Old: string(camera.Status), New: string(domain.StatusDecommissioned)},
+			},
+		}
+		if err := s.repo.DecommissionCamera(ctx, camera); err != nil {
+			s.fail(job, change, err)
+			continue
+		}
+		job.CamerasDecommissioned++
+		job.Changes = append(job.Changes, change)
+	}
+
+	if err := s.repo.MarkSynced(ctx, unchanged); err != nil {
+		s.logger.Warn().Err(err).Str("job_id", job.ID).Msg("Failed to mark unchanged cameras synced")
+	}
+
+	return nil
+}
+
+// listDevices pages through the full Milestone camera list
+func (s *MilestoneSync) listDevices(ctx context.Context) ([]*client.MilestoneCamera, error) {
+	devices := []*client.MilestoneCamera{}
+	total := 0
+
+	for offset := 0; ; {
+		page, err := s.milestone.ListCameras(ctx, client.ListCamerasOptions{
+			Limit:  syncPageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Milestone cameras at offset %d: %w", offset, err)
+		}
+
+		devices = append(devices, page.Cameras...)
+		offset += len(page.Cameras)
+		total = page.Total
+
+		// Only a short page ends the list; Milestone may omit total, and 0 then means unknown
+		if len(page.Cameras) < syncPageSize || (total > 0 && offset >= total) {
+			break
+		}
+	}
+
+	// A short list would decommission the missing cameras
+	if len(devices) < total {
+		return nil, fmt.Errorf("Milestone listed %d of %d cameras; list changed while paging", len(devices), total)
+	}
+
+	return devices, nil
+}
+
+// create inserts a camera for a device new to the cameras table
+func (s *MilestoneSync) create(ctx context.Context, job *domain.SyncJob, device *client.MilestoneCamera) {
+	metadata := make(map[string]interface{}, len(device.Metadata)+2)
+	for k, v := range device.Metadata {
+		metadata[k] = v
+	}
+	metadata["milestone_enabled"] = device.Enabled
+	metadata["milestone_recording"] = device.Recording
+
+	camera := &domain.Camera{
+		ID:                syncedCameraID(device.ID),
+		Name:              device.Name,
+		Source:            s.source,
+		PTZEnabled:        device.PTZCapabilities != nil && (device.PTZCapabilities.Pan || device.PTZCapabilities.Tilt || device.PTZCapabilities.Zoom),
+		Status:            domain.StatusOffline,
+		StatusReason:      domain.StatusReasonAwaitingProbe,
+		RecordingServer:   device.RecordingServer,
+		MilestoneDeviceID: device.ID,
+		Metadata:          metadata,
+	}
+	if camera.Name == "" {
+		camera.Name = device.ID
+	}
+	if !device.Enabled {
+		camera.StatusReason = domain.StatusReasonMilestoneDisabled
+	}
+
+	change := domain.SyncCameraChange{
+		MilestoneDeviceID: device.ID,
+		CameraID:          camera.ID,
+		Name:              camera.Name,
+		Action:            domain.SyncActionCreated,
+	}
+	if err := s.repo.CreateSyncedCamera(ctx, camera, device.LiveStreamURL); err != nil {
+		s.fail(job, change, err)
+		return
+	}
+
+	job.CamerasImported++
+	job.Changes = append(job.Changes, change)
+}
+
+// fail reports a camera the sync could not write
+func (s *MilestoneSync) fail(job *domain.SyncJob, change domain.SyncCameraChange, err error) {
+	s.logger.Error().
+		Err(err).
+		Str("job_id", job.ID).
+		Str("milestone_device_id", change.MilestoneDeviceID).
+		Str("action", change.Action).
+		Msg("Failed to sync camera from Milestone")
+
+	change.Action = domain.SyncActionFailed
+	change.Error = err.Error()
+	job.Errors++
+	job.Changes = append(job.Changes, change)
+}
+
+// diffCamera applies the Milestone name, status and recording server to camera
+// and returns the fields that changed
+func diffCamera(camera *domain.Camera, device *client.MilestoneCamera) []domain.SyncFieldChange {
+	fields := []domain.SyncFieldChange{}
+
+	if device.Name != "" && device.Name != camera.Name {
+		fields = append(fields, domain.SyncFieldChange{Field: "name", Old: camera.Name, New: device.Name})
+		camera.Name = device.Name
+	}
+
+	if status, reason, ok := syncedStatus(camera, device); ok {
+		if status != camera.Status {
+			fields = append(fields, domain.SyncFieldChange{Field: "status", Old: string(camera.Status), New: string(status)})
+			camera.Status = status
+		}
+		if reason != camera.StatusReason {
+			fields = append(fields, domain.SyncFieldChange{Field: "status_reason", Old: camera.StatusReason, New: reason})
+			camera.StatusReason = reason
+		}
+	}
+
+	if device.RecordingServer != camera.RecordingServer {
+		fields = append(fields, domain.SyncFieldChange{Field: "recording_server", Old: camera.RecordingServer, New: device.RecordingServer})
+		camera.RecordingServer = device.RecordingServer
+	}
+
+	return fields
+}
+
+// syncedStatus returns the status Milestone sets on camera, if any
+// Milestone only decides whether a camera is in service: disabled devices are OFFLINE, and
+// devices enabled again wait OFFLINE for the health probe, which owns the status otherwise.
+func syncedStatus(camera *domain.Camera, device *client.MilestoneCamera) (domain.CameraStatus, string, bool) {
+	if !device.Enabled {
+		return domain.StatusOffline, domain.StatusReasonMilestoneDisabled, true
+	}
+
+	if camera.Status == domain.StatusDecommissioned || camera.StatusReason == domain.StatusReasonMilestoneDisabled {
+		return domain.StatusOffline, domain.StatusReasonAwaitingProbe, true
+	}
+
+	return "", "", false
+}
+
+// syncedCameraID derives a stable camera ID from a Milestone device ID
+func syncedCameraID(deviceID string) string {
+	return "milestone-" + strings.ToLower(deviceID)
+}
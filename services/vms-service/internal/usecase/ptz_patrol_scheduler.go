@@ -0,0 +1,397 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rta/cctv/vms-service/internal/domain"
+	"github.com/rta/cctv/vms-service/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+// PTZPatrolScheduler runs camera patrols
+// Every tick each running patrol whose dwell time is up is sent to its next stop. A patrol
+// runs only inside its schedule window and pauses when an operator sends the camera a PTZ
+// command; it resumes at its next stop once the camera has been left alone for the patrol's
+// resume time. Progress is kept in memory, so patrols start again from their first stop
+// after a restart, and only one vms-service instance should run the scheduler.
+type PTZPatrolScheduler struct {
+	repo       repository.PTZPatrolRepository
+	controller repository.CameraRepository
+	logger     zerolog.Logger
+
+	mu     sync.Mutex
+	loaded bool
+	runs   map[string]*patrolRun // By camera ID
+}
+
+// patrolRun is the progress of one patrol
+type patrolRun struct {
+	patrol      *domain.PTZPatrol
+	generation  int // Bumped when the patrol is replaced, so a move in flight does not apply to the new one
+	next        int // Index of the next stop
+	nextMoveAt  time.Time
+	pausedUntil time.Time
+	moving      bool
+	state       domain.PTZPatrolState
+}
+
+// NewPTZPatrolScheduler creates a new PTZ patrol scheduler
+func NewPTZPatrolScheduler(repo repository.PTZPatrolRepository, controller repository.CameraRepository, logger zerolog.Logger) *PTZPatrolScheduler {
+	return &PTZPatrolScheduler{
+		repo:       repo,
+		controller: controller,
+		logger:     logger,
+		runs:       make(map[string]*patrolRun),
+	}
+}
+
+// Run loads the patrols and advances them every tick until ctx is cancelled
+func (s *PTZPatrolScheduler) Run(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		s.load(ctx)
+		s.advance(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// load reads the patrols once; a failure is retried on the next tick
+func (s *PTZPatrolScheduler) load(ctx context.Context) {
+	s.mu.Lock()
+	loaded := s.loaded
+	s.mu.Unlock()
+	if loaded {
+		return
+	}
+
+	patrols, err := s.repo.ListPTZPatrols(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error().Err(err).Msg("Failed to load PTZ patrols")
+		}
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, patrol := range patrols {
+		// Patrols saved through the API meanwhile are newer
+		if _, ok := s.runs[patrol.CameraID]; !ok {
+			s.runs[patrol.CameraID] = newPatrolRun(patrol)
+		}
+	}
+	s.loaded = true
+
+	s.logger.Info().Int("patrols", len(patrols)).Msg("PTZ patrols loaded")
+}
+
+// newPatrolRun starts a patrol without a state; the next tick gives it one
+func newPatrolRun(patrol *domain.PTZPatrol) *patrolRun {
+	return &patrolRun{
+		patrol: patrol,
+		state:  domain.PTZPatrolState{CameraID: patrol.CameraID},
+	}
+}
+
+// advance updates the state of every patrol and starts the moves that are due
+func (s *PTZPatrolScheduler) advance(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, run := range s.runs {
+		if run.moving || !s.due(run, now) {
+			continue
+		}
+
+		run.moving = true
+		go s.move(ctx, run, run.patrol, run.generation, run.next)
+	}
+}
+
+// due updates the state of a patrol and reports whether it should move to its next stop
+func (s *PTZPatrolScheduler) due(run *patrolRun, now time.Time) bool {
+	patrol := run.patrol
+
+	var state string
+	switch {
+	case !patrol.Enabled:
+		state = domain.PTZPatrolDisabled
+	case !inSchedule(patrol.Schedule, now):
+		state = domain.PTZPatrolOutsideSchedule
+	case now.Before(run.pausedUntil):
+		state = domain.PTZPatrolPaused
+	default:
+		state = domain.PTZPatrolRunning
+	}
+
+	if state != run.state.State {
+		switch {
+		case state == domain.PTZPatrolRunning:
+			// Starting, or resuming after a pause: the camera is not at a stop
+			run.nextMoveAt = now
+		case state != domain.PTZPatrolPaused:
+			// The next window starts again from the first stop
+			run.next = 0
+		}
+
+		s.logger.Info().
+			Str("camera_id", patrol.CameraID).
+			Str("previous_state", run.state.State).
+			Str("state", state).
+			Msg("PTZ patrol state changed")
+
+		run.state.State = state
+		run.state.UpdatedAt = now.UTC()
+	}
+
+	return state == domain.PTZPatrolRunning && !now.Before(run.nextMoveAt)
+}
+
+// move sends the camera to a stop and schedules the one after it
+// A stop the camera fails to reach is skipped after its dwell time, so one bad preset does not stall the patrol.
+func (s *PTZPatrolScheduler) move(ctx context.Context, run *patrolRun, patrol *domain.PTZPatrol, generation, index int) {
+	stop := patrol.Stops[index]
+	err := s.controller.ExecutePTZCommand(ctx, &domain.PTZCommand{
+		CameraID: patrol.CameraID,
+		Action:   domain.PTZActionGoToPreset,
+		Preset:   stop.Preset,
+		Speed:    patrol.Speed,
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run.moving = false
+	if run.generation != generation || ctx.Err() != nil {
+		return
+	}
+
+	now := time.Now()
+	run.next = (index + 1) % len(patrol.Stops)
+	run.nextMoveAt = now.Add(dwell(patrol, stop))
+	run.state.UpdatedAt = now.UTC()
+
+	if errors.Is(err, domain.ErrCameraNotFound) {
+		// The camera was deleted along with its patrol; a patrol saved again since has its own run
+		if s.runs[patrol.CameraID] == run {
+			delete(s.runs, patrol.CameraID)
+		}
+		return
+	}
+	if err != nil {
+		run.state.LastError = fmt.Sprintf("preset %d: %v", stop.Preset, err)
+		s.logger.Warn().
+			Err(err).
+			Str("camera_id", patrol.CameraID).
+			Int("preset", stop.Preset).
+			Msg("PTZ patrol failed to reach stop")
+		return
+	}
+
+	arrived := now.UTC()
+	run.state.StopIndex = index
+	run.state.Preset = stop.Preset
+	run.state.ArrivedAt = &arrived
+	run.state.LastError = ""
+}
+
+// dwell is how long a patrol holds a stop
+func dwell(patrol *domain.PTZPatrol, stop domain.PTZPatrolStop) time.Duration {
+	if stop.DwellSeconds > 0 {
+		return time.Duration(stop.DwellSeconds) * time.Second
+	}
+	return time.Duration(patrol.DwellSeconds) * time.Second
+}
+
+// inSchedule reports whether now falls in the schedule window
+// Schedules are validated when saved; one that no longer parses never runs.
+func inSchedule(schedule *domain.PTZPatrolSchedule, now time.Time) bool {
+	if schedule == nil {
+		return true
+	}
+
+	location, err := patrolLocation(schedule.Timezone)
+	if err != nil {
+		return false
+	}
+	start, err := parseClock(schedule.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(schedule.End)
+	if err != nil {
+		return false
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+
+	switch {
+	case start == end:
+		return onDay(schedule.Days, today)
+	case start < end:
+		return onDay(schedule.Days, today) && minute >= start && minute < end
+	case minute >= start:
+		return onDay(schedule.Days, today)
+	case minute < end:
+		// The part of a window that started yesterday
+		return onDay(schedule.Days, yesterday)
+	default:
+		return false
+	}
+}
+
+// patrolLocations caches time zones by name, since every tick checks every schedule
+var patrolLocations sync.Map
+
+// patrolLocation loads a schedule's time zone; an empty name is the server's time zone
+func patrolLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	if location, ok := patrolLocations.Load(name); ok {
+		return location.(*time.Location), nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	patrolLocations.Store(name, location)
+	return location, nil
+}
+
+// parseClock parses an HH:MM time of day into minutes after midnight
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q (use HH:MM)", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func onDay(days []int, day time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// PauseForOperator pauses the camera's patrol after a manual PTZ command
+// Every command renews the pause, so the patrol resumes only once the camera is left alone.
+func (s *PTZPatrolScheduler) PauseForOperator(cameraID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.runs[cameraID]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	run.pausedUntil = now.Add(time.Duration(run.patrol.ResumeAfterSeconds) * time.Second)
+	if run.state.State == domain.PTZPatrolRunning {
+		s.logger.Info().
+			Str("camera_id", cameraID).
+			Time("resumes_at", run.pausedUntil).
+			Msg("PTZ patrol paused for manual control")
+
+		run.state.State = domain.PTZPatrolPaused
+		run.state.UpdatedAt = now.UTC()
+	}
+}
+
+// GetPatrol retrieves the patrol of a camera
+func (s *PTZPatrolScheduler) GetPatrol(ctx context.Context, cameraID string) (*domain.PTZPatrol, error) {
+	return s.repo.GetPTZPatrol(ctx, cameraID)
+}
+
+// SavePatrol creates or replaces the patrol of a camera; a replaced patrol starts again from its first stop
+// A pause for manual control carries over to the new patrol.
+func (s *PTZPatrolScheduler) SavePatrol(ctx context.Context, patrol *domain.PTZPatrol) error {
+	if err := s.repo.SavePTZPatrol(ctx, patrol); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.runs[patrol.CameraID]
+	if !ok {
+		s.runs[patrol.CameraID] = newPatrolRun(patrol)
+		return nil
+	}
+
+	run.patrol = patrol
+	run.generation++
+	run.next = 0
+	run.nextMoveAt = time.Time{}
+	run.state = domain.PTZPatrolState{CameraID: patrol.CameraID}
+	return nil
+}
+
+// DeletePatrol removes the patrol of a camera; a move in flight still completes
+func (s *PTZPatrolScheduler) DeletePatrol(ctx context.Context, cameraID string) error {
+	if err := s.repo.DeletePTZPatrol(ctx, cameraID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.runs, cameraID)
+	return nil
+}
+
+// GetState retrieves the progress of a camera's patrol
+func (s *PTZPatrolScheduler) GetState(ctx context.Context, cameraID string) (*domain.PTZPatrolState, error) {
+	s.mu.Lock()
+	run, ok := s.runs[cameraID]
+	var state domain.PTZPatrolState
+	if ok {
+		// Brings the state up to date between ticks; a due move still waits for the tick
+		s.due(run, time.Now())
+		state = run.state
+		switch state.State {
+		case domain.PTZPatrolRunning:
+			if !run.moving {
+				nextMoveAt := run.nextMoveAt.UTC()
+				state.NextMoveAt = &nextMoveAt
+			}
+		case domain.PTZPatrolPaused:
+			pausedUntil := run.pausedUntil.UTC()
+			state.PausedUntil = &pausedUntil
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		// Tells an unknown camera from one without a patrol, and covers patrols not loaded yet
+		if _, err := s.repo.GetPTZPatrol(ctx, cameraID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s is not loaded yet", domain.ErrPTZPatrolNotFound, cameraID)
+	}
+
+	return &state, nil
+}
@@ -0,0 +1,8 @@
+-- Rollback audit log table
+
+DROP INDEX IF EXISTS idx_audit_log_created_at;
+DROP INDEX IF EXISTS idx_audit_log_camera_id;
+DROP INDEX IF EXISTS idx_audit_log_actor_id;
+DROP INDEX IF EXISTS idx_audit_log_action;
+
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,25 @@
+-- Migration: Create audit log table
+-- Description: Records operator actions (viewer evictions, etc.) for compliance review
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    action VARCHAR(100) NOT NULL,
+    actor_id VARCHAR(255) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_id VARCHAR(255) NOT NULL,
+    camera_id VARCHAR(255),
+    reason TEXT,
+    details JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Indexes for common audit queries
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_camera_id ON audit_log(camera_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at DESC);
+
+-- Comments for documentation
+COMMENT ON TABLE audit_log IS 'Append-only record of operator actions for compliance review';
+COMMENT ON COLUMN audit_log.action IS 'Action identifier, e.g. viewer.evict';
+COMMENT ON COLUMN audit_log.target_type IS 'Kind of entity acted upon, e.g. viewer';
@@ -0,0 +1,9 @@
+-- Rollback streams table changes
+-- The table and its camera, viewer, status and start time indexes may come from the initial
+-- schema (database/migrations/001), so only what this migration added is removed.
+
+DROP INDEX IF EXISTS idx_streams_reservation_id;
+
+ALTER TABLE streams
+DROP COLUMN IF EXISTS end_reason,
+DROP COLUMN IF EXISTS reservation_id;
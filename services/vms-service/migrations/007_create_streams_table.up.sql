@@ -0,0 +1,41 @@
+-- Migration: Create streams table for live viewing sessions
+-- Description: One row per viewer reservation, written by go-api on reserve and closed on release/expiry
+
+CREATE TABLE IF NOT EXISTS streams (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    camera_id VARCHAR(255) NOT NULL,
+    agency VARCHAR(100) NOT NULL,
+
+    -- LiveKit information
+    livekit_room_name VARCHAR(255) NOT NULL,
+    livekit_token TEXT,
+
+    -- Stream details
+    viewer_id VARCHAR(255) NOT NULL,
+    viewer_ip VARCHAR(50),
+
+    -- Status
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'INACTIVE', 'ERROR')),
+
+    -- Timestamps
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    ended_at TIMESTAMP WITH TIME ZONE,
+    last_heartbeat TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Link sessions to stream-counter reservations and record why they ended
+ALTER TABLE streams
+ADD COLUMN IF NOT EXISTS reservation_id VARCHAR(255),
+ADD COLUMN IF NOT EXISTS end_reason VARCHAR(50);
+
+-- Indexes for compliance queries
+CREATE UNIQUE INDEX IF NOT EXISTS idx_streams_reservation_id ON streams(reservation_id);
+CREATE INDEX IF NOT EXISTS idx_streams_camera_id ON streams(camera_id);
+CREATE INDEX IF NOT EXISTS idx_streams_viewer_id ON streams(viewer_id);
+CREATE INDEX IF NOT EXISTS idx_streams_status ON streams(status);
+CREATE INDEX IF NOT EXISTS idx_streams_started_at ON streams(started_at);
+
+-- Comments for documentation
+COMMENT ON TABLE streams IS 'Live viewing sessions, one row per viewer reservation';
+COMMENT ON COLUMN streams.reservation_id IS 'Stream-counter reservation ID backing this session';
+COMMENT ON COLUMN streams.end_reason IS 'Why the session ended: released, evicted, expired';
@@ -0,0 +1,13 @@
+-- Rollback camera coordinates
+
+DROP INDEX IF EXISTS idx_cameras_lat_lon;
+
+ALTER TABLE cameras
+DROP CONSTRAINT IF EXISTS valid_longitude,
+DROP CONSTRAINT IF EXISTS valid_latitude;
+
+ALTER TABLE cameras
+DROP COLUMN IF EXISTS address_ar,
+DROP COLUMN IF EXISTS address,
+DROP COLUMN IF EXISTS longitude,
+DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,34 @@
+-- Migration: Add geographic coordinates to cameras
+-- Description: Dedicated latitude/longitude columns for map view and geospatial queries
+
+ALTER TABLE cameras
+ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS address TEXT,
+ADD COLUMN IF NOT EXISTS address_ar TEXT;
+
+-- Guarded like the columns above, so a partial rerun does not fail
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'valid_latitude' AND conrelid = 'cameras'::regclass) THEN
+        ALTER TABLE cameras ADD CONSTRAINT valid_latitude CHECK (latitude IS NULL OR latitude BETWEEN -90 AND 90);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'valid_longitude' AND conrelid = 'cameras'::regclass) THEN
+        ALTER TABLE cameras ADD CONSTRAINT valid_longitude CHECK (longitude IS NULL OR longitude BETWEEN -180 AND 180);
+    END IF;
+END $$;
+
+-- Backfill from legacy metadata.location {"lat": .., "lon": ..}
+UPDATE cameras
+SET latitude = (metadata->'location'->>'lat')::DOUBLE PRECISION,
+    longitude = (metadata->'location'->>'lon')::DOUBLE PRECISION
+WHERE latitude IS NULL
+  AND metadata->'location'->>'lat' IS NOT NULL
+  AND metadata->'location'->>'lon' IS NOT NULL;
+
+-- Index for bounding-box prefilters
+CREATE INDEX IF NOT EXISTS idx_cameras_lat_lon ON cameras(latitude, longitude) WHERE latitude IS NOT NULL;
+
+-- Comments for documentation
+COMMENT ON COLUMN cameras.latitude IS 'WGS84 latitude in decimal degrees';
+COMMENT ON COLUMN cameras.longitude IS 'WGS84 longitude in decimal degrees';
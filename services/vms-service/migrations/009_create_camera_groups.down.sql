@@ -0,0 +1,4 @@
+-- Rollback camera groups
+
+DROP TABLE IF EXISTS camera_group_members;
+DROP TABLE IF EXISTS camera_groups;
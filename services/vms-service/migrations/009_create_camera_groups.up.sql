@@ -0,0 +1,35 @@
+-- Migration: Create camera groups
+-- Description: Nested folders (e.g. Metro Red Line > Union Station > Platform 2) with many-to-many camera membership
+
+CREATE TABLE IF NOT EXISTS camera_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    parent_id UUID REFERENCES camera_groups(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    name_ar VARCHAR(255),
+    description TEXT,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT no_self_parent CHECK (parent_id IS NULL OR parent_id <> id)
+);
+
+-- Sibling names must be unique (root groups share the nil parent)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_camera_groups_parent_name
+    ON camera_groups(COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'::UUID), name);
+CREATE INDEX IF NOT EXISTS idx_camera_groups_parent_id ON camera_groups(parent_id);
+
+CREATE TABLE IF NOT EXISTS camera_group_members (
+    group_id UUID NOT NULL REFERENCES camera_groups(id) ON DELETE CASCADE,
+    camera_id VARCHAR(255) NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (group_id, camera_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_camera_group_members_camera_id ON camera_group_members(camera_id);
+
+-- Comments for documentation
+COMMENT ON TABLE camera_groups IS 'Hierarchical camera folders; deleting a group deletes its subgroups';
+COMMENT ON TABLE camera_group_members IS 'Many-to-many camera membership; a camera may belong to several groups';
@@ -0,0 +1,14 @@
+-- Rollback camera tours
+
+ALTER TABLE layout_camera_assignments
+DROP CONSTRAINT IF EXISTS camera_or_tour;
+
+-- Tour cells have no fixed camera and cannot survive the rollback
+DELETE FROM layout_camera_assignments WHERE camera_id IS NULL;
+
+ALTER TABLE layout_camera_assignments
+DROP COLUMN IF EXISTS tour_id,
+ALTER COLUMN camera_id SET NOT NULL;
+
+DROP TABLE IF EXISTS camera_tour_steps;
+DROP TABLE IF EXISTS camera_tours;
@@ -0,0 +1,48 @@
+-- Migration: Create camera tours
+-- Description: Guard tours that cycle a layout cell through a list of cameras
+
+CREATE TABLE IF NOT EXISTS camera_tours (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    dwell_seconds INT NOT NULL DEFAULT 10,
+    created_by VARCHAR(255) NOT NULL,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT valid_tour_dwell CHECK (dwell_seconds >= 5)
+);
+
+-- Ordered tour steps; dwell_seconds overrides the tour default when set
+CREATE TABLE IF NOT EXISTS camera_tour_steps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tour_id UUID NOT NULL REFERENCES camera_tours(id) ON DELETE CASCADE,
+    step_index INT NOT NULL,
+    camera_id VARCHAR(255) NOT NULL,
+    dwell_seconds INT,
+    ptz_preset_id INT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE (tour_id, step_index),
+    CONSTRAINT valid_step_dwell CHECK (dwell_seconds IS NULL OR dwell_seconds >= 5),
+    CONSTRAINT valid_step_preset CHECK (ptz_preset_id IS NULL OR ptz_preset_id BETWEEN 1 AND 256)
+);
+
+CREATE INDEX IF NOT EXISTS idx_camera_tours_active ON camera_tours(is_active);
+CREATE INDEX IF NOT EXISTS idx_camera_tour_steps_tour ON camera_tour_steps(tour_id);
+
+-- A layout cell shows either a single camera or a tour
+-- Tour cells have no camera, so deleting a tour removes its cells rather than leaving them empty
+ALTER TABLE layout_camera_assignments
+ALTER COLUMN camera_id DROP NOT NULL,
+ADD COLUMN IF NOT EXISTS tour_id UUID REFERENCES camera_tours(id) ON DELETE CASCADE;
+
+-- Exactly one of the two; a cell with both would have the tour fight the fixed camera
+ALTER TABLE layout_camera_assignments
+ADD CONSTRAINT camera_or_tour CHECK ((camera_id IS NULL) <> (tour_id IS NULL));
+
+-- Comments for documentation
+COMMENT ON TABLE camera_tours IS 'Guard tours: ordered cameras shown one after another in a layout cell';
+COMMENT ON COLUMN camera_tour_steps.ptz_preset_id IS 'Optional PTZ preset recalled when the step comes on screen';
+COMMENT ON COLUMN layout_camera_assignments.tour_id IS 'Tour played in this cell instead of a fixed camera';
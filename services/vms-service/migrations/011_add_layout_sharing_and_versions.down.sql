@@ -0,0 +1,7 @@
+-- Rollback layout sharing and versioning
+
+DROP TABLE IF EXISTS layout_versions;
+DROP TABLE IF EXISTS layout_shares;
+
+ALTER TABLE layout_preferences
+DROP COLUMN IF EXISTS version;
@@ -0,0 +1,59 @@
+-- Migration: Layout sharing, permissions and versioning
+-- Description: Explicit view/edit shares, optimistic concurrency and a revertable version history
+
+-- Incremented on every change; clients send it back (If-Match) to detect concurrent edits
+ALTER TABLE layout_preferences
+ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+
+-- Shares with users or groups (the creator always owns the layout)
+CREATE TABLE IF NOT EXISTS layout_shares (
+    layout_id UUID NOT NULL REFERENCES layout_preferences(id) ON DELETE CASCADE,
+    principal_type VARCHAR(10) NOT NULL CHECK (principal_type IN ('user', 'group')),
+    principal_id VARCHAR(255) NOT NULL,
+    permission VARCHAR(10) NOT NULL CHECK (permission IN ('view', 'edit')),
+    granted_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (layout_id, principal_type, principal_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_layout_shares_principal ON layout_shares(principal_type, principal_id);
+
+-- Full snapshot of every layout version
+CREATE TABLE IF NOT EXISTS layout_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    layout_id UUID NOT NULL REFERENCES layout_preferences(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    layout_type VARCHAR(50) NOT NULL,
+    grid_layout VARCHAR(50),
+    cameras JSONB NOT NULL DEFAULT '[]',
+    changed_by VARCHAR(255) NOT NULL,
+    change_type VARCHAR(20) NOT NULL CHECK (change_type IN ('create', 'update', 'revert')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE (layout_id, version)
+);
+
+-- Existing layouts start their history at version 1
+INSERT INTO layout_versions (layout_id, version, name, description, layout_type, grid_layout, cameras, changed_by, change_type, created_at)
+SELECT lp.id, lp.version, lp.name, lp.description, lp.layout_type, lp.grid_layout,
+       COALESCE((
+           SELECT jsonb_agg(jsonb_build_object(
+               'camera_id', a.camera_id,
+               'tour_id', a.tour_id,
+               'position_index', a.position_index,
+               'cell_size', a.cell_size
+           ) ORDER BY a.position_index)
+           FROM layout_camera_assignments a
+           WHERE a.layout_id = lp.id
+       ), '[]'::jsonb),
+       lp.created_by, 'create', lp.updated_at
+FROM layout_preferences lp
+ON CONFLICT (layout_id, version) DO NOTHING;
+
+-- Comments for documentation
+COMMENT ON TABLE layout_shares IS 'Explicit layout access for users or groups: view or edit';
+COMMENT ON TABLE layout_versions IS 'Snapshot of a layout after each change, used for history and revert';
+COMMENT ON COLUMN layout_preferences.version IS 'Optimistic concurrency version, exposed as the ETag';
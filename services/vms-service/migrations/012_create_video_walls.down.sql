@@ -0,0 +1,4 @@
+-- Rollback video walls
+
+DROP TABLE IF EXISTS video_wall_monitors;
+DROP TABLE IF EXISTS video_walls;
@@ -0,0 +1,37 @@
+-- Migration: Create video walls
+-- Description: Control room walls made of monitors, each driven by a dashboard display client
+
+CREATE TABLE IF NOT EXISTS video_walls (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS video_wall_monitors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wall_id UUID NOT NULL REFERENCES video_walls(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    position_index INT NOT NULL,
+
+    -- What the monitor is showing; both NULL when it is blank
+    layout_id UUID REFERENCES layout_preferences(id) ON DELETE SET NULL,
+    camera_id VARCHAR(255) REFERENCES cameras(id) ON DELETE SET NULL,
+    assigned_by VARCHAR(255),
+    assigned_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT video_wall_monitors_wall_name UNIQUE (wall_id, name),
+    CONSTRAINT video_wall_monitors_wall_position UNIQUE (wall_id, position_index),
+    CONSTRAINT layout_or_camera CHECK (layout_id IS NULL OR camera_id IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_wall_monitors_wall_id ON video_wall_monitors(wall_id);
+
+-- Comments for documentation
+COMMENT ON TABLE video_walls IS 'Physical video walls in the control room';
+COMMENT ON TABLE video_wall_monitors IS 'Monitors of a video wall and the layout or camera each one shows';
@@ -0,0 +1,4 @@
+-- Rollback: Drop guest share links
+
+DROP TABLE IF EXISTS share_link_redemptions;
+DROP TABLE IF EXISTS share_links;
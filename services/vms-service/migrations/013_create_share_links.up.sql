@@ -0,0 +1,41 @@
+-- Migration: Create guest share links
+-- Description: Time-limited links that let partners without an account watch one camera live
+
+CREATE TABLE IF NOT EXISTS share_links (
+    id UUID PRIMARY KEY,
+    camera_id VARCHAR(255) NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+    label VARCHAR(255),
+    created_by VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    max_viewers INT NOT NULL CHECK (max_viewers > 0),
+
+    -- Keyed hash of the PIN, NULL when the link has none
+    pin_hash VARCHAR(255),
+    pin_failures INT NOT NULL DEFAULT 0,
+
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by VARCHAR(255),
+    revoke_reason TEXT,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS share_link_redemptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    link_id UUID NOT NULL REFERENCES share_links(id) ON DELETE CASCADE,
+
+    -- NULL while the stream is being started
+    reservation_id VARCHAR(255),
+    client_ip VARCHAR(50),
+    redeemed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_camera_id ON share_links(camera_id);
+CREATE INDEX IF NOT EXISTS idx_share_links_expires_at ON share_links(expires_at);
+CREATE INDEX IF NOT EXISTS idx_share_link_redemptions_link_id ON share_link_redemptions(link_id);
+CREATE INDEX IF NOT EXISTS idx_share_link_redemptions_reservation_id ON share_link_redemptions(reservation_id);
+
+-- Comments for documentation
+COMMENT ON TABLE share_links IS 'Guest share links; the signed token itself is never stored';
+COMMENT ON TABLE share_link_redemptions IS 'Guest viewers started from a share link, one row per redemption';
+COMMENT ON COLUMN share_links.pin_failures IS 'Wrong PINs entered; the link locks after 5';
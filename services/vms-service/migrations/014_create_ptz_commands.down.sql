@@ -0,0 +1,4 @@
+-- Rollback: Drop PTZ command history
+
+DROP TABLE IF EXISTS ptz_home_policies;
+DROP TABLE IF EXISTS ptz_commands;
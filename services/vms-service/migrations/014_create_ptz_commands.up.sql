@@ -0,0 +1,39 @@
+-- Migration: Create PTZ command history
+-- Description: Every PTZ command sent through go-api, and per-camera return-to-home policies
+
+CREATE TABLE IF NOT EXISTS ptz_commands (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- No foreign key: the history outlives deleted cameras
+    camera_id VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    role VARCHAR(100),
+    source VARCHAR(20) NOT NULL CHECK (source IN ('operator', 'tour', 'home_policy')),
+    command VARCHAR(20) NOT NULL,
+    speed DOUBLE PRECISION,
+    preset_id INT,
+    result VARCHAR(10) NOT NULL CHECK (result IN ('success', 'failed', 'locked')),
+    error TEXT,
+    latency_ms INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ptz_commands_camera_created ON ptz_commands(camera_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ptz_commands_user_created ON ptz_commands(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ptz_commands_created_at ON ptz_commands(created_at DESC);
+
+-- Cameras that go back to a home position once nobody has moved them for a while
+CREATE TABLE IF NOT EXISTS ptz_home_policies (
+    camera_id VARCHAR(255) PRIMARY KEY REFERENCES cameras(id) ON DELETE CASCADE,
+    idle_minutes INT NOT NULL CHECK (idle_minutes BETWEEN 1 AND 1440),
+    -- NULL sends the camera's own home position
+    preset_id INT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_by VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Comments for documentation
+COMMENT ON TABLE ptz_commands IS 'Append-only history of PTZ commands: who moved which camera, when, and whether it worked';
+COMMENT ON COLUMN ptz_commands.source IS 'operator (API call), tour (preset recall) or home_policy (idle return)';
+COMMENT ON COLUMN ptz_commands.result IS 'success, failed (camera or VMS error) or locked (refused, another operator held the camera)';
+COMMENT ON TABLE ptz_home_policies IS 'Return-to-home after idle_minutes without a PTZ command, driven by ptz_commands';
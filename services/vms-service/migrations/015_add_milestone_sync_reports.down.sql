@@ -0,0 +1,11 @@
+-- Rollback: Remove Milestone camera sync reports
+
+ALTER TABLE milestone_sync_history
+DROP COLUMN IF EXISTS changes,
+DROP COLUMN IF EXISTS cameras_decommissioned;
+
+UPDATE cameras SET status = 'OFFLINE' WHERE status = 'DECOMMISSIONED';
+
+ALTER TABLE cameras DROP CONSTRAINT IF EXISTS valid_status;
+ALTER TABLE cameras
+ADD CONSTRAINT valid_status CHECK (status IN ('ONLINE', 'OFFLINE', 'ERROR'));
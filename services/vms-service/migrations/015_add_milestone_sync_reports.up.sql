@@ -0,0 +1,12 @@
+-- Migration: Milestone camera sync reports
+-- Description: Cameras removed from Milestone are kept as DECOMMISSIONED, and sync jobs record per-camera changes
+
+ALTER TABLE cameras DROP CONSTRAINT IF EXISTS valid_status;
+ALTER TABLE cameras
+ADD CONSTRAINT valid_status CHECK (status IN ('ONLINE', 'OFFLINE', 'ERROR', 'DECOMMISSIONED'));
+
+ALTER TABLE milestone_sync_history
+ADD COLUMN IF NOT EXISTS cameras_decommissioned INT DEFAULT 0,
+ADD COLUMN IF NOT EXISTS changes JSONB;
+
+COMMENT ON COLUMN milestone_sync_history.changes IS 'Cameras created, updated, decommissioned or failed by the sync (JSON)';
@@ -0,0 +1,3 @@
+-- Rollback: Drop camera events outbox
+
+DROP TABLE IF EXISTS camera_events_outbox;
@@ -0,0 +1,18 @@
+-- Migration: Create camera events outbox
+-- Description: Camera change events written in the same transaction as the change, relayed to a Valkey Stream
+
+CREATE TABLE IF NOT EXISTS camera_events_outbox (
+    seq BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    event_type VARCHAR(50) NOT NULL,
+    camera_id VARCHAR(255) NOT NULL, -- No FK: events outlive deleted cameras
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+-- The relay reads unpublished events in order
+CREATE INDEX IF NOT EXISTS idx_camera_events_outbox_unpublished ON camera_events_outbox(seq) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_camera_events_outbox_published_at ON camera_events_outbox(published_at) WHERE published_at IS NOT NULL;
+
+COMMENT ON TABLE camera_events_outbox IS 'Camera change events awaiting or past publication to the camera events stream';
@@ -0,0 +1,9 @@
+-- Rollback: Remove camera health probing
+
+DROP TABLE IF EXISTS camera_status_history;
+
+ALTER TABLE cameras
+DROP COLUMN IF EXISTS last_probe,
+DROP COLUMN IF EXISTS last_probe_at,
+DROP COLUMN IF EXISTS status_changed_at,
+DROP COLUMN IF EXISTS status_reason;
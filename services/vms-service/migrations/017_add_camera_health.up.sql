@@ -0,0 +1,26 @@
+-- Migration: Camera health probing
+-- Description: Status reasons, last probe results and a status history for uptime
+
+ALTER TABLE cameras
+ADD COLUMN IF NOT EXISTS status_reason TEXT,
+ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS last_probe_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN IF NOT EXISTS last_probe JSONB;
+
+CREATE TABLE IF NOT EXISTS camera_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    camera_id VARCHAR(255) NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL,
+    previous_status VARCHAR(50), -- NULL for the first status of a camera
+    reason TEXT,
+    source VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_status_history_source CHECK (source IN ('probe', 'milestone_sync'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_camera_status_history_camera ON camera_status_history(camera_id, changed_at DESC);
+
+COMMENT ON COLUMN cameras.status_reason IS 'Why the camera has its status, e.g. the failed health check';
+COMMENT ON COLUMN cameras.last_probe IS 'Checks of the last health probe (JSON)';
+COMMENT ON TABLE camera_status_history IS 'Camera status transitions written by the health probe and the Milestone sync';
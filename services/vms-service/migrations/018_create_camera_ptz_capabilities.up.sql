@@ -0,0 +1,17 @@
+-- Migration: Camera PTZ capabilities
+-- Description: PTZ capabilities discovered from each camera's ONVIF PTZ service
+
+CREATE TABLE IF NOT EXISTS camera_ptz_capabilities (
+    camera_id VARCHAR(255) PRIMARY KEY REFERENCES cameras(id) ON DELETE CASCADE,
+    profile_token VARCHAR(255),
+    service_url TEXT,
+    device_service_url TEXT,
+    capabilities JSONB,
+    discovered_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    attempted_at TIMESTAMP WITH TIME ZONE
+);
+
+COMMENT ON TABLE camera_ptz_capabilities IS 'ONVIF PTZ profiles, spaces and limits per camera, rediscovered daily';
+COMMENT ON COLUMN camera_ptz_capabilities.profile_token IS 'Media profile PTZ commands are sent with';
+COMMENT ON COLUMN camera_ptz_capabilities.last_error IS 'Error of the last failed discovery; cleared on success';
@@ -0,0 +1,24 @@
+-- Migration: Camera PTZ presets
+-- Description: Named PTZ presets; positions are stored on the camera under the ONVIF token
+
+CREATE TABLE IF NOT EXISTS camera_ptz_presets (
+    camera_id VARCHAR(255) NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL CHECK (number BETWEEN 1 AND 256),
+    token VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    name_ar VARCHAR(100),
+    on_camera BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (camera_id, number),
+    UNIQUE (camera_id, token)
+);
+
+-- Presets are recalled by name, so names are unique per camera in either language
+CREATE UNIQUE INDEX IF NOT EXISTS idx_camera_ptz_presets_name ON camera_ptz_presets(camera_id, LOWER(name));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_camera_ptz_presets_name_ar ON camera_ptz_presets(camera_id, name_ar) WHERE name_ar IS NOT NULL;
+
+COMMENT ON TABLE camera_ptz_presets IS 'Named PTZ presets per camera, kept in step with the presets stored on the camera';
+COMMENT ON COLUMN camera_ptz_presets.token IS 'ONVIF preset token GotoPreset is sent with';
+COMMENT ON COLUMN camera_ptz_presets.on_camera IS 'False when the last refresh did not find the token on the camera';
@@ -0,0 +1 @@
+ALTER TABLE camera_ptz_capabilities DROP COLUMN IF EXISTS field_of_view;
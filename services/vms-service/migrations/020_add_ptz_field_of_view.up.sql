@@ -0,0 +1,6 @@
+-- Migration: PTZ field of view
+-- Description: Optics of PTZ cameras, used to convert clicks on the image to relative moves
+
+ALTER TABLE camera_ptz_capabilities ADD COLUMN IF NOT EXISTS field_of_view JSONB;
+
+COMMENT ON COLUMN camera_ptz_capabilities.field_of_view IS 'Configured field of view, pan/tilt ranges and optical zoom; NULL uses the defaults';
@@ -0,0 +1,21 @@
+-- Migration: Camera PTZ patrols
+-- Description: Guard tours cycling a PTZ camera through its presets on a schedule
+
+CREATE TABLE IF NOT EXISTS camera_ptz_patrols (
+    camera_id VARCHAR(255) PRIMARY KEY REFERENCES cameras(id) ON DELETE CASCADE,
+    name VARCHAR(100),
+    stops JSONB NOT NULL,
+    dwell_seconds INTEGER NOT NULL CHECK (dwell_seconds > 0),
+    speed DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (speed BETWEEN 0 AND 1),
+    schedule JSONB,
+    resume_after_seconds INTEGER NOT NULL CHECK (resume_after_seconds > 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE camera_ptz_patrols IS 'PTZ patrol per camera, run by the vms-service patrol scheduler';
+COMMENT ON COLUMN camera_ptz_patrols.stops IS 'Ordered stops: preset number and optional dwell time override';
+COMMENT ON COLUMN camera_ptz_patrols.schedule IS 'Weekly window the patrol runs in; NULL runs around the clock';
+COMMENT ON COLUMN camera_ptz_patrols.resume_after_seconds IS 'Idle time after a manual PTZ command before the patrol resumes';
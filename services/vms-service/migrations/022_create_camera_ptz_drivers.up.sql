@@ -0,0 +1,14 @@
+-- Migration: Camera PTZ drivers
+-- Description: The PTZ protocol detected per camera, so commands go straight to it
+
+CREATE TABLE IF NOT EXISTS camera_ptz_drivers (
+    camera_id VARCHAR(255) PRIMARY KEY REFERENCES cameras(id) ON DELETE CASCADE,
+    driver VARCHAR(32),
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+COMMENT ON TABLE camera_ptz_drivers IS 'PTZ driver detected per camera; the row is removed to force detection';
+COMMENT ON COLUMN camera_ptz_drivers.driver IS 'onvif, isapi, cgi or milestone; NULL when no driver answered';
+COMMENT ON COLUMN camera_ptz_drivers.consecutive_failures IS 'Failed commands since the last success; the driver is detected again at the threshold';
@@ -0,0 +1,23 @@
+-- Migration: Camera alarms
+-- Description: Motion, tamper and digital input alarms cameras raise over ONVIF events
+
+CREATE TABLE IF NOT EXISTS camera_alarms (
+    id UUID PRIMARY KEY,
+    camera_id VARCHAR(255) NOT NULL REFERENCES cameras(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    state VARCHAR(16) NOT NULL,
+    source VARCHAR(255),
+    topic VARCHAR(255) NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_camera_alarms_camera_occurred ON camera_alarms (camera_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_camera_alarms_received ON camera_alarms (received_at);
+
+COMMENT ON TABLE camera_alarms IS 'Alarm state changes normalized from ONVIF events; pruned after 30 days';
+COMMENT ON COLUMN camera_alarms.type IS 'motion, tamper or digital_input';
+COMMENT ON COLUMN camera_alarms.state IS 'active or inactive';
+COMMENT ON COLUMN camera_alarms.source IS 'Video source or input token the event names, when it names one';
+COMMENT ON COLUMN camera_alarms.topic IS 'ONVIF topic of the event, without namespace prefixes';
+COMMENT ON COLUMN camera_alarms.occurred_at IS 'Time reported by the camera';
@@ -0,0 +1,99 @@
+// Package cameraalarms defines the camera alarms vms-service publishes to a Valkey Stream
+// Alarms are motion, tamper and digital input events read from cameras over ONVIF. They are
+// published once, after they are stored, so consumers that need every alarm read the history
+// from vms-service.
+package cameraalarms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SchemaVersion is the version of the alarm JSON written by this package
+// Adding fields keeps the version; renaming, removing or retyping a field bumps it.
+const SchemaVersion = 1
+
+// DefaultStream is the Valkey Stream camera alarms are published to
+const DefaultStream = "cctv:camera-alarms"
+
+// streamMaxLen bounds the stream; alarms are live notifications, the history stays in vms-service
+const streamMaxLen = 10000
+
+// Alarm types
+const (
+	TypeMotion       = "motion"
+	TypeTamper       = "tamper"
+	TypeDigitalInput = "digital_input"
+)
+
+// Alarm states
+const (
+	StateActive   = "active"
+	StateInactive = "inactive"
+)
+
+// Stream entry fields
+const (
+	FieldSchemaVersion = "schema_version"
+	FieldType          = "type"
+	FieldCameraID      = "camera_id"
+	FieldAlarm         = "alarm" // The Alarm as JSON
+)
+
+// Alarm is a change of an alarm raised by a camera
+type Alarm struct {
+	SchemaVersion int       `json:"schema_version"`
+	ID            string    `json:"id"`
+	CameraID      string    `json:"camera_id"`
+	Type          string    `json:"type"`
+	State         string    `json:"state"`
+	Source        string    `json:"source,omitempty"` // Video source or input token, when the event names one
+	Topic         string    `json:"topic"`            // ONVIF topic without namespace prefixes
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// Publish appends an alarm to stream
+func Publish(ctx context.Context, client *redis.Client, stream string, alarm *Alarm) error {
+	data, err := json.Marshal(alarm)
+	if err != nil {
+		return fmt.Errorf("failed to marshal camera alarm: %w", err)
+	}
+
+	err = client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			FieldSchemaVersion: alarm.SchemaVersion,
+			FieldType:          alarm.Type,
+			FieldCameraID:      alarm.CameraID,
+			FieldAlarm:         data,
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to publish camera alarm %s: %w", alarm.ID, err)
+	}
+
+	return nil
+}
+
+// Decode reads the alarm of a stream entry, refusing schema versions newer than this package
+func Decode(message redis.XMessage) (*Alarm, error) {
+	version, _ := message.Values[FieldSchemaVersion].(string)
+	if v, err := strconv.Atoi(version); err != nil || v > SchemaVersion {
+		return nil, fmt.Errorf("unsupported camera alarm schema version %q", version)
+	}
+
+	data, _ := message.Values[FieldAlarm].(string)
+	var alarm Alarm
+	if err := json.Unmarshal([]byte(data), &alarm); err != nil {
+		return nil, fmt.Errorf("failed to decode camera alarm: %w", err)
+	}
+
+	return &alarm, nil
+}
@@ -0,0 +1,75 @@
+// Package cameraevents defines the camera change events vms-service publishes to a Valkey Stream
+// and a subscriber for the services that consume them
+package cameraevents
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaVersion is the version of the event JSON written by this package
+// Adding fields keeps the version; renaming, removing or retyping a field bumps it.
+const SchemaVersion = 1
+
+// DefaultStream is the Valkey Stream camera events are published to
+const DefaultStream = "cctv:camera-events"
+
+// Event types
+const (
+	TypeAdded          = "camera.added"
+	TypeUpdated        = "camera.updated"        // Name or recording server changed
+	TypeStatusChanged  = "camera.status_changed" // ONLINE, OFFLINE or ERROR
+	TypeDecommissioned = "camera.decommissioned" // Removed from Milestone; stop recording and drop caches
+)
+
+// Stream entry fields
+const (
+	FieldSchemaVersion = "schema_version"
+	FieldType          = "type"
+	FieldCameraID      = "camera_id"
+	FieldEvent         = "event" // The Event as JSON
+)
+
+// Camera is the state of a camera after the change
+type Camera struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	Source            string `json:"source"`
+	Status            string `json:"status"`
+	PTZEnabled        bool   `json:"ptz_enabled"`
+	RecordingServer   string `json:"recording_server,omitempty"`
+	MilestoneDeviceID string `json:"milestone_device_id,omitempty"`
+}
+
+// Change is one camera field changed by the event
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Event is a camera change
+// Delivery is at least once; consumers should ignore event IDs they have already handled
+type Event struct {
+	SchemaVersion int       `json:"schema_version"`
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	CameraID      string    `json:"camera_id"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Camera        Camera    `json:"camera"`
+	Changes       []Change  `json:"changes,omitempty"`
+}
+
+// New creates an event of eventType for camera
+func New(eventType string, camera Camera, changes []Change) *Event {
+	return &Event{
+		SchemaVersion: SchemaVersion,
+		ID:            uuid.New().String(),
+		Type:          eventType,
+		CameraID:      camera.ID,
+		OccurredAt:    time.Now().UTC(),
+		Camera:        camera,
+		Changes:       changes,
+	}
+}
@@ -0,0 +1,183 @@
+package cameraevents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// streamMaxLen bounds the stream; consumers further behind than this lose events
+const streamMaxLen = 100000
+
+// Publish appends an event to stream
+func Publish(ctx context.Context, client *redis.Client, stream string, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal camera event: %w", err)
+	}
+
+	err = client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			FieldSchemaVersion: event.SchemaVersion,
+			FieldType:          event.Type,
+			FieldCameraID:      event.CameraID,
+			FieldEvent:         data,
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to publish camera event %s: %w", event.ID, err)
+	}
+
+	return nil
+}
+
+// Handler processes one event
+// Returning an error leaves the event pending so it is delivered again; return nil for
+// events that can never be processed.
+type Handler func(ctx context.Context, event *Event) error
+
+// Subscriber reads camera events as a member of a Valkey consumer group
+// Each event is delivered to one consumer of the group; give every service its own group.
+type Subscriber struct {
+	client     *redis.Client
+	stream     string
+	group      string
+	consumer   string
+	retryAfter time.Duration
+	logger     zerolog.Logger
+}
+
+// NewSubscriber creates a new subscriber
+// consumer must be unique within group, e.g. the host name
+func NewSubscriber(client *redis.Client, stream, group, consumer string, logger zerolog.Logger) *Subscriber {
+	return &Subscriber{
+		client:     client,
+		stream:     stream,
+		group:      group,
+		consumer:   consumer,
+		retryAfter: 5 * time.Second,
+		logger:     logger,
+	}
+}
+
+// Run passes events to handle until ctx is cancelled
+// A new group starts with events published after it was created. Events the handler fails
+// are retried after a pause, together with any left pending by a previous run of this consumer.
+func (s *Subscriber) Run(ctx context.Context, handle Handler) error {
+	err := s.client.XGroupCreateMkStream(ctx, s.stream, s.group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s: %w", s.group, err)
+	}
+
+	// An ID re-reads this consumer's pending events after it, ">" reads new ones
+	readFrom := "0"
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		streams, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    s.group,
+			Consumer: s.consumer,
+			Streams:  []string{s.stream, readFrom},
+			Count:    100,
+			Block:    5 * time.Second,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			s.logger.Error().Err(err).Str("stream", s.stream).Msg("Failed to read camera events")
+			s.pause(ctx)
+			continue
+		}
+
+		messages := []redis.XMessage{}
+		for _, stream := range streams {
+			messages = append(messages, stream.Messages...)
+		}
+
+		if readFrom != ">" {
+			if len(messages) == 0 {
+				readFrom = ">"
+				continue
+			}
+			// Page through the pending events; undecodable ones stay pending but are passed over
+			readFrom = messages[len(messages)-1].ID
+		}
+
+		failed := false
+		for _, message := range messages {
+			if !s.handle(ctx, message, handle) {
+				failed = true
+			}
+		}
+
+		if failed {
+			readFrom = "0"
+			s.pause(ctx)
+		}
+	}
+}
+
+// handle decodes and processes one message, acknowledging it on success
+func (s *Subscriber) handle(ctx context.Context, message redis.XMessage, handle Handler) bool {
+	event, err := decode(message)
+	if err != nil {
+		// Left pending until a consumer that understands it is deployed
+		s.logger.Warn().Err(err).Str("message_id", message.ID).Msg("Skipping camera event")
+		return true
+	}
+
+	if err := handle(ctx, event); err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("event_id", event.ID).
+			Str("type", event.Type).
+			Str("camera_id", event.CameraID).
+			Msg("Failed to handle camera event")
+		return false
+	}
+
+	if err := s.client.XAck(ctx, s.stream, s.group, message.ID).Err(); err != nil {
+		s.logger.Warn().Err(err).Str("message_id", message.ID).Msg("Failed to acknowledge camera event")
+	}
+	return true
+}
+
+// pause waits retryAfter or until ctx is cancelled
+func (s *Subscriber) pause(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(s.retryAfter):
+	}
+}
+
+// decode reads the event of a stream entry, refusing schema versions newer than this package
+func decode(message redis.XMessage) (*Event, error) {
+	version, _ := message.Values[FieldSchemaVersion].(string)
+	if v, err := strconv.Atoi(version); err != nil || v > SchemaVersion {
+		return nil, fmt.Errorf("unsupported camera event schema version %q", version)
+	}
+
+	data, _ := message.Values[FieldEvent].(string)
+	var event Event
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return nil, fmt.Errorf("failed to decode camera event: %w", err)
+	}
+
+	return &event, nil
+}